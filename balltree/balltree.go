@@ -0,0 +1,236 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package balltree implements ball trees for nearest neighbour search.
+//
+// Ball trees partition space into nested hyperspheres rather than the axis aligned
+// half-spaces of a k-d tree, and so degrade more gracefully as dimensionality increases.
+// The package shares the kdtree Comparable, Interface and Keeper types, so point types
+// and query code written for kdtree may be used unchanged.
+//
+// The Distance method of stored values must return the square of a metric distance,
+// as is the case for kdtree.Point. The Compare method is not used.
+package balltree
+
+import (
+	"math"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Node holds a single point value in a ball tree. All the points in the subtree rooted
+// at the node lie within Radius of the node's point.
+type Node struct {
+	Point       kdtree.Comparable
+	Radius      float64
+	Left, Right *Node
+}
+
+// A Tree implements a ball tree.
+type Tree struct {
+	Root  *Node
+	Count int
+}
+
+// New returns a ball tree constructed from the values in p. p is not altered.
+func New(p kdtree.Interface) *Tree {
+	pts := make([]kdtree.Comparable, p.Len())
+	for i := range pts {
+		pts[i] = p.Index(i)
+	}
+	return &Tree{Root: build(pts), Count: len(pts)}
+}
+
+// dist returns the metric distance between a and b.
+func dist(a, b kdtree.Comparable) float64 { return math.Sqrt(a.Distance(b)) }
+
+// build constructs a subtree from pts. The node's point is chosen from between two
+// distant points of pts, and the remaining points are divided by whichever of the two
+// distant points they are closer to.
+func build(pts []kdtree.Comparable) *Node {
+	switch len(pts) {
+	case 0:
+		return nil
+	case 1:
+		return &Node{Point: pts[0]}
+	}
+	a := farthest(pts, pts[0])
+	b := farthest(pts, pts[a])
+	da := make([]float64, len(pts))
+	db := make([]float64, len(pts))
+	c, best := 0, math.Inf(1)
+	for i, p := range pts {
+		da[i], db[i] = dist(p, pts[a]), dist(p, pts[b])
+		if d := math.Abs(da[i] - db[i]); d < best {
+			c, best = i, d
+		}
+	}
+	n := &Node{Point: pts[c]}
+	for _, p := range pts {
+		n.Radius = math.Max(n.Radius, dist(p, n.Point))
+	}
+
+	last := len(pts) - 1
+	pts[c], pts[last] = pts[last], pts[c]
+	da[c], da[last] = da[last], da[c]
+	db[c], db[last] = db[last], db[c]
+	rest := splitter{pts: pts[:last], da: da[:last], db: db[:last]}
+	sort.Sort(rest)
+	m := len(rest.pts) / 2
+	n.Left = build(rest.pts[:m])
+	n.Right = build(rest.pts[m:])
+	return n
+}
+
+// farthest returns the index of the point in pts most distant from q.
+func farthest(pts []kdtree.Comparable, q kdtree.Comparable) int {
+	var (
+		idx int
+		max = -1.
+	)
+	for i, p := range pts {
+		if d := q.Distance(p); d > max {
+			idx, max = i, d
+		}
+	}
+	return idx
+}
+
+// splitter sorts points by their relative proximity to two pivot points.
+type splitter struct {
+	pts    []kdtree.Comparable
+	da, db []float64
+}
+
+func (s splitter) Len() int           { return len(s.pts) }
+func (s splitter) Less(i, j int) bool { return s.da[i]-s.db[i] < s.da[j]-s.db[j] }
+func (s splitter) Swap(i, j int) {
+	s.pts[i], s.pts[j] = s.pts[j], s.pts[i]
+	s.da[i], s.da[j] = s.da[j], s.da[i]
+	s.db[i], s.db[j] = s.db[j], s.db[i]
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree) Len() int { return t.Count }
+
+// Insert adds a point to the tree. The point is placed in the subtree whose node point is
+// closer, enlarging the balls that contain it. No rebalancing of the tree is performed.
+func (t *Tree) Insert(c kdtree.Comparable) {
+	t.Count++
+	if t.Root == nil {
+		t.Root = &Node{Point: c}
+		return
+	}
+	n := t.Root
+	for {
+		n.Radius = math.Max(n.Radius, dist(c, n.Point))
+		switch {
+		case n.Left == nil:
+			n.Left = &Node{Point: c}
+			return
+		case n.Right == nil:
+			n.Right = &Node{Point: c}
+			return
+		case c.Distance(n.Left.Point) <= c.Distance(n.Right.Point):
+			n = n.Left
+		default:
+			n = n.Right
+		}
+	}
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Tree) Nearest(q kdtree.Comparable) (kdtree.Comparable, float64) {
+	if t.Root == nil {
+		return nil, math.Inf(1)
+	}
+	k := kdtree.NewNKeeper(1)
+	t.Root.search(q, q.Distance(t.Root.Point), k)
+	cd := k.Heap[0]
+	return cd.Comparable, cd.Dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (t *Tree) NearestSet(k kdtree.Keeper, q kdtree.Comparable) {
+	if t.Root == nil {
+		return
+	}
+	t.Root.search(q, q.Distance(t.Root.Point), k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// search searches the subtree rooted at n, where d is the distance between q and the
+// point of n.
+func (n *Node) search(q kdtree.Comparable, d float64, k kdtree.Keeper) {
+	if math.Sqrt(d)-n.Radius > math.Sqrt(k.Max().Dist) {
+		return
+	}
+	k.Keep(kdtree.ComparableDist{Comparable: n.Point, Dist: d})
+	var dl, dr float64
+	if n.Left != nil {
+		dl = q.Distance(n.Left.Point)
+	}
+	if n.Right != nil {
+		dr = q.Distance(n.Right.Point)
+	}
+	first, second := n.Left, n.Right
+	df, ds := dl, dr
+	if second != nil && (first == nil || dr < dl) {
+		first, second = second, first
+		df, ds = ds, df
+	}
+	if first != nil {
+		first.search(q, df, k)
+	}
+	if second != nil {
+		second.search(q, ds, k)
+	}
+}
+
+// An Operation is a function that operates on a Comparable. The tree depth of the point
+// is also provided. If done is returned true, the Operation is indicating that no further
+// work needs to be done and so the Do function should traverse no further.
+type Operation func(c kdtree.Comparable, depth int) (done bool)
+
+// Do performs fn on all values stored in the tree in pre-order. A boolean is returned
+// indicating whether the Do traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn Operation) bool {
+	return t.Root.do(fn, 0)
+}
+
+func (n *Node) do(fn Operation, depth int) bool {
+	if n == nil {
+		return false
+	}
+	return fn(n.Point, depth) || n.Left.do(fn, depth+1) || n.Right.do(fn, depth+1)
+}
+
+// DoWithin performs fn on all values stored in the tree that are within the metric
+// distance r of q, that is, whose squared distance from q is no greater than r². A boolean
+// is returned indicating whether the traversal was interrupted by an Operation returning
+// true.
+func (t *Tree) DoWithin(fn Operation, q kdtree.Comparable, r float64) bool {
+	return t.Root.doWithin(fn, q, r, 0)
+}
+
+func (n *Node) doWithin(fn Operation, q kdtree.Comparable, r float64, depth int) bool {
+	if n == nil {
+		return false
+	}
+	d := dist(q, n.Point)
+	if d-n.Radius > r {
+		return false
+	}
+	if d <= r && fn(n.Point, depth) {
+		return true
+	}
+	return n.Left.doWithin(fn, q, r, depth+1) || n.Right.doWithin(fn, q, r, depth+1)
+}
@@ -0,0 +1,133 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package balltree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var wpData = kdtree.Points{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}
+
+func randPoints(n, dims int) kdtree.Points {
+	p := make(kdtree.Points, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for j := range p[i] {
+			p[i][j] = rand.Float64()
+		}
+	}
+	return p
+}
+
+// isBall returns whether every point in the subtree rooted at n lies within the
+// radius of n and each of its descendants.
+func (n *Node) isBall() bool {
+	if n == nil {
+		return true
+	}
+	ok := !n.Do(func(c kdtree.Comparable, _ int) bool { return dist(c, n.Point) > n.Radius+1e-12 })
+	return ok && n.Left.isBall() && n.Right.isBall()
+}
+
+func (n *Node) Do(fn Operation) bool { return n.do(fn, 0) }
+
+func (s *S) TestNew(c *check.C) {
+	for i, p := range []kdtree.Points{nil, wpData, randPoints(1000, 30)} {
+		orig := append(kdtree.Points(nil), p...)
+		t := New(p)
+		c.Check(t.Len(), check.Equals, len(p), check.Commentf("Test %d", i))
+		c.Check(t.Root.isBall(), check.Equals, true, check.Commentf("Test %d", i))
+		c.Check(p, check.DeepEquals, orig, check.Commentf("Test %d", i))
+		var n int
+		t.Do(func(kdtree.Comparable, int) bool { n++; return false })
+		c.Check(n, check.Equals, len(p))
+	}
+}
+
+func (s *S) TestInsert(c *check.C) {
+	t := &Tree{}
+	p := randPoints(500, 5)
+	for _, e := range p {
+		t.Insert(e)
+	}
+	c.Check(t.Len(), check.Equals, len(p))
+	c.Check(t.Root.isBall(), check.Equals, true)
+	for _, q := range randPoints(50, 5) {
+		got, d := t.Nearest(q)
+		want, wd := nearest(q, p)
+		c.Check(got, check.DeepEquals, want)
+		c.Check(d, check.Equals, wd)
+	}
+}
+
+func nearest(q kdtree.Point, p kdtree.Points) (kdtree.Point, float64) {
+	best, d := p[0], q.Distance(p[0])
+	for _, e := range p[1:] {
+		if ed := q.Distance(e); ed < d {
+			best, d = e, ed
+		}
+	}
+	return best, d
+}
+
+func (s *S) TestNearest(c *check.C) {
+	_, d := (&Tree{}).Nearest(kdtree.Point{0, 0})
+	c.Check(math.IsInf(d, 1), check.Equals, true)
+
+	t := New(wpData)
+	got, d := t.Nearest(kdtree.Point{8, 7})
+	c.Check(got, check.DeepEquals, kdtree.Point{9, 6})
+	c.Check(d, check.Equals, 2.)
+
+	p := randPoints(2000, 25)
+	t = New(p)
+	for _, q := range randPoints(100, 25) {
+		got, d := t.Nearest(q)
+		want, wd := nearest(q, p)
+		c.Check(got, check.DeepEquals, want)
+		c.Check(d, check.Equals, wd)
+	}
+}
+
+func (s *S) TestNearestSet(c *check.C) {
+	p := randPoints(1000, 20)
+	t := New(p)
+	for _, q := range randPoints(20, 20) {
+		dists := make([]float64, len(p))
+		for i, e := range p {
+			dists[i] = q.Distance(e)
+		}
+		sort.Float64s(dists)
+
+		k := kdtree.NewNKeeper(10)
+		t.NearestSet(k, q)
+		c.Assert(k.Len(), check.Equals, 10)
+		for i, cd := range k.Heap {
+			c.Check(cd.Dist, check.Equals, dists[i])
+		}
+
+		r := dists[25]
+		dk := kdtree.NewDistKeeper(r)
+		t.NearestSet(dk, q)
+		c.Check(dk.Len(), check.Equals, 27) // 26 points and the sentinel.
+
+		var n int
+		t.DoWithin(func(kdtree.Comparable, int) bool { n++; return false }, q, math.Sqrt(r))
+		c.Check(n, check.Equals, 26)
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bvh implements a bounding volume hierarchy over extended primitives.
+//
+// Where a k-d tree indexes points, a BVH indexes objects with extent, such as line
+// segments, triangles and boxes, by their axis aligned bounding boxes. A Tree supports
+// queries for the primitives overlapping a box and for the primitives struck by a ray,
+// as used for collision detection and picking.
+//
+// Primitives describe their extent through the kdtree.Bounder interface. The corners of
+// the returned bounding boxes must be comparable with kdtree.Point values, as for
+// kdtree.Point and kdtree.Datum.
+package bvh
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// leafSize is the maximum number of primitives held by a leaf.
+const leafSize = 4
+
+// A Primitive is a value indexed by a Tree.
+type Primitive interface {
+	kdtree.Bounder
+}
+
+// A RayIntersector is a Primitive that can determine where it is struck by a ray.
+type RayIntersector interface {
+	Primitive
+
+	// IntersectRay returns the smallest ray parameter t in [0, tmax]
+	// at which r strikes the receiver, and whether it does so.
+	IntersectRay(r Ray, tmax float64) (t float64, ok bool)
+}
+
+// A Ray is a half line starting at Origin in the direction Dir. The point on the ray at
+// parameter t is Origin + t*Dir.
+type Ray struct {
+	Origin, Dir kdtree.Point
+}
+
+// At returns the point on the ray at parameter t.
+func (r Ray) At(t float64) kdtree.Point {
+	p := make(kdtree.Point, len(r.Origin))
+	for d := range p {
+		p[d] = r.Origin[d] + t*r.Dir[d]
+	}
+	return p
+}
+
+// ErrDims is returned when primitives with differing dimensionality are indexed.
+var ErrDims = errors.New("bvh: dimension mismatch")
+
+// aabb is an axis aligned bounding box.
+type aabb struct {
+	min, max []float64
+}
+
+func boxOf(b *kdtree.Bounding) aabb {
+	dims := b[0].Dims()
+	zero := make(kdtree.Point, dims)
+	box := aabb{min: make([]float64, dims), max: make([]float64, dims)}
+	for d := range box.min {
+		box.min[d] = b[0].Compare(zero, kdtree.Dim(d))
+		box.max[d] = b[1].Compare(zero, kdtree.Dim(d))
+	}
+	return box
+}
+
+func (b aabb) union(c aabb) aabb {
+	u := aabb{min: make([]float64, len(b.min)), max: make([]float64, len(b.max))}
+	for d := range b.min {
+		u.min[d] = math.Min(b.min[d], c.min[d])
+		u.max[d] = math.Max(b.max[d], c.max[d])
+	}
+	return u
+}
+
+func (b aabb) overlaps(c aabb) bool {
+	for d := range b.min {
+		if b.min[d] > c.max[d] || c.min[d] > b.max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// slab returns the parameter at which r enters b, and whether r strikes b at a parameter
+// in [0, tmax].
+func (b aabb) slab(r Ray, tmax float64) (float64, bool) {
+	lo, hi := 0., tmax
+	for d := range b.min {
+		if r.Dir[d] == 0 {
+			if r.Origin[d] < b.min[d] || r.Origin[d] > b.max[d] {
+				return 0, false
+			}
+			continue
+		}
+		inv := 1 / r.Dir[d]
+		t0 := (b.min[d] - r.Origin[d]) * inv
+		t1 := (b.max[d] - r.Origin[d]) * inv
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		lo = math.Max(lo, t0)
+		hi = math.Min(hi, t1)
+		if lo > hi {
+			return 0, false
+		}
+	}
+	return lo, true
+}
+
+type entry struct {
+	p        Primitive
+	box      aabb
+	centroid []float64
+}
+
+type node struct {
+	box         aabb
+	left, right *node
+	entries     []entry
+}
+
+// A Tree is a static bounding volume hierarchy.
+type Tree struct {
+	root  *node
+	dims  int
+	count int
+}
+
+// New returns a BVH indexing the primitives in p. New returns ErrDims if the primitives
+// do not all have the same dimensionality. Primitives with a nil bounding box are not
+// indexed.
+func New(p []Primitive) (*Tree, error) {
+	var entries []entry
+	t := &Tree{}
+	for _, e := range p {
+		b := e.Bounds()
+		if b == nil {
+			continue
+		}
+		box := boxOf(b)
+		if len(entries) == 0 {
+			t.dims = len(box.min)
+		} else if len(box.min) != t.dims {
+			return nil, ErrDims
+		}
+		c := make([]float64, len(box.min))
+		for d := range c {
+			c[d] = (box.min[d] + box.max[d]) / 2
+		}
+		entries = append(entries, entry{p: e, box: box, centroid: c})
+	}
+	t.count = len(entries)
+	if len(entries) != 0 {
+		t.root = build(entries)
+	}
+	return t, nil
+}
+
+// build returns a subtree holding entries. Internal nodes are split at the median
+// centroid along the axis of greatest centroid extent.
+func build(entries []entry) *node {
+	n := &node{box: entries[0].box}
+	for _, e := range entries[1:] {
+		n.box = n.box.union(e.box)
+	}
+	if len(entries) <= leafSize {
+		n.entries = entries
+		return n
+	}
+	var (
+		axis   int
+		spread = -1.
+	)
+	for d := range n.box.min {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, e := range entries {
+			lo = math.Min(lo, e.centroid[d])
+			hi = math.Max(hi, e.centroid[d])
+		}
+		if hi-lo > spread {
+			axis, spread = d, hi-lo
+		}
+	}
+	sort.Sort(byCentroid{entries: entries, axis: axis})
+	mid := len(entries) / 2
+	n.left = build(entries[:mid])
+	n.right = build(entries[mid:])
+	return n
+}
+
+type byCentroid struct {
+	entries []entry
+	axis    int
+}
+
+func (b byCentroid) Len() int { return len(b.entries) }
+func (b byCentroid) Less(i, j int) bool {
+	return b.entries[i].centroid[b.axis] < b.entries[j].centroid[b.axis]
+}
+func (b byCentroid) Swap(i, j int) { b.entries[i], b.entries[j] = b.entries[j], b.entries[i] }
+
+// Len returns the number of primitives indexed by the tree.
+func (t *Tree) Len() int { return t.count }
+
+// Bounds returns the bounding box of all the primitives in the tree, or nil if the tree
+// is empty.
+func (t *Tree) Bounds() *kdtree.Bounding {
+	if t.root == nil {
+		return nil
+	}
+	return &kdtree.Bounding{
+		append(kdtree.Point(nil), t.root.box.min...),
+		append(kdtree.Point(nil), t.root.box.max...),
+	}
+}
+
+// Overlapping calls fn on each primitive whose bounding box overlaps b. Primitives are not
+// tested for overlap beyond their bounding boxes. If fn returns true the traversal is
+// stopped. Overlapping returns whether the traversal was stopped.
+func (t *Tree) Overlapping(fn func(Primitive) (done bool), b *kdtree.Bounding) bool {
+	if t.root == nil {
+		return false
+	}
+	return t.root.overlapping(fn, boxOf(b))
+}
+
+func (n *node) overlapping(fn func(Primitive) bool, q aabb) bool {
+	if !n.box.overlaps(q) {
+		return false
+	}
+	if n.entries != nil {
+		for _, e := range n.entries {
+			if e.box.overlaps(q) && fn(e.p) {
+				return true
+			}
+		}
+		return false
+	}
+	return n.left.overlapping(fn, q) || n.right.overlapping(fn, q)
+}
+
+// Collisions returns the pairs of primitives in the tree whose bounding boxes overlap.
+// Each pair is reported once.
+func (t *Tree) Collisions() [][2]Primitive {
+	if t.root == nil {
+		return nil
+	}
+	var pairs [][2]Primitive
+	collide(t.root, t.root, &pairs)
+	return pairs
+}
+
+func collide(a, b *node, pairs *[][2]Primitive) {
+	if !a.box.overlaps(b.box) {
+		return
+	}
+	switch {
+	case a == b && a.entries != nil:
+		for i, e := range a.entries {
+			for _, f := range a.entries[i+1:] {
+				if e.box.overlaps(f.box) {
+					*pairs = append(*pairs, [2]Primitive{e.p, f.p})
+				}
+			}
+		}
+	case a == b:
+		collide(a.left, a.left, pairs)
+		collide(a.right, a.right, pairs)
+		collide(a.left, a.right, pairs)
+	case a.entries != nil && b.entries != nil:
+		for _, e := range a.entries {
+			for _, f := range b.entries {
+				if e.box.overlaps(f.box) {
+					*pairs = append(*pairs, [2]Primitive{e.p, f.p})
+				}
+			}
+		}
+	case a.entries != nil:
+		collide(a, b.left, pairs)
+		collide(a, b.right, pairs)
+	default:
+		collide(a.left, b, pairs)
+		collide(a.right, b, pairs)
+	}
+}
+
+// hit returns the parameter at which r strikes the primitive of e within tmax. Primitives
+// that are not RayIntersectors are struck where r enters their bounding box.
+func (e entry) hit(r Ray, tmax float64) (float64, bool) {
+	t, ok := e.box.slab(r, tmax)
+	if !ok {
+		return 0, false
+	}
+	if ri, isRI := e.p.(RayIntersector); isRI {
+		return ri.IntersectRay(r, tmax)
+	}
+	return t, true
+}
+
+// Raycast returns the first primitive struck by r at a parameter in [0, tmax], the parameter
+// at which it is struck and whether any primitive was struck.
+func (t *Tree) Raycast(r Ray, tmax float64) (Primitive, float64, bool) {
+	if t.root == nil || len(r.Origin) != t.dims || len(r.Dir) != t.dims {
+		return nil, 0, false
+	}
+	var (
+		best  Primitive
+		found bool
+	)
+	var cast func(n *node)
+	cast = func(n *node) {
+		if _, ok := n.box.slab(r, tmax); !ok {
+			return
+		}
+		if n.entries != nil {
+			for _, e := range n.entries {
+				if h, ok := e.hit(r, tmax); ok {
+					best, tmax, found = e.p, h, true
+				}
+			}
+			return
+		}
+		// Visit the nearer child first to tighten tmax sooner.
+		near, far := n.left, n.right
+		tl, okl := near.box.slab(r, tmax)
+		tr, okr := far.box.slab(r, tmax)
+		if okl && okr && tr < tl {
+			near, far = far, near
+		}
+		cast(near)
+		cast(far)
+	}
+	cast(t.root)
+	return best, tmax, found
+}
+
+// RaycastAll calls fn on each primitive struck by r at a parameter in [0, tmax], with the
+// parameter at which it is struck. Primitives are not visited in order of distance. If fn
+// returns true the traversal is stopped. RaycastAll returns whether the traversal was
+// stopped.
+func (t *Tree) RaycastAll(fn func(p Primitive, t float64) (done bool), r Ray, tmax float64) bool {
+	if t.root == nil || len(r.Origin) != t.dims || len(r.Dir) != t.dims {
+		return false
+	}
+	var cast func(n *node) bool
+	cast = func(n *node) bool {
+		if _, ok := n.box.slab(r, tmax); !ok {
+			return false
+		}
+		if n.entries != nil {
+			for _, e := range n.entries {
+				if h, ok := e.hit(r, tmax); ok && fn(e.p, h) {
+					return true
+				}
+			}
+			return false
+		}
+		return cast(n.left) || cast(n.right)
+	}
+	return cast(t.root)
+}
@@ -0,0 +1,145 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bvh
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randTriangles(rnd *rand.Rand, n int) []Primitive {
+	p := make([]Primitive, n)
+	for i := range p {
+		c := kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100, rnd.Float64() * 100}
+		v := func() kdtree.Point {
+			return kdtree.Point{c[0] + rnd.Float64()*4 - 2, c[1] + rnd.Float64()*4 - 2, c[2] + rnd.Float64()*4 - 2}
+		}
+		p[i] = Triangle{v(), v(), v()}
+	}
+	return p
+}
+
+func overlaps(a, b *kdtree.Bounding) bool {
+	return boxOf(a).overlaps(boxOf(b))
+}
+
+func (s *S) TestNew(c *check.C) {
+	t, err := New(nil)
+	c.Assert(err, check.Equals, nil)
+	c.Check(t.Len(), check.Equals, 0)
+	c.Check(t.Bounds(), check.IsNil)
+	_, _, ok := t.Raycast(Ray{kdtree.Point{0, 0}, kdtree.Point{1, 0}}, 10)
+	c.Check(ok, check.Equals, false)
+	c.Check(t.Collisions(), check.HasLen, 0)
+
+	_, err = New([]Primitive{Box{kdtree.Point{0, 0}, kdtree.Point{1, 1}}, Box{kdtree.Point{0}, kdtree.Point{1}}})
+	c.Check(err, check.Equals, ErrDims)
+
+	t, err = New([]Primitive{Box{kdtree.Point{0, 0}, kdtree.Point{1, 1}}, Segment{kdtree.Point{3, -1}, kdtree.Point{2, 4}}})
+	c.Assert(err, check.Equals, nil)
+	c.Check(t.Bounds(), check.DeepEquals, &kdtree.Bounding{kdtree.Point{0, -1}, kdtree.Point{3, 4}})
+}
+
+func (s *S) TestOverlapping(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	prims := randTriangles(rnd, 1000)
+	t, err := New(prims)
+	c.Assert(err, check.Equals, nil)
+	c.Check(t.Len(), check.Equals, len(prims))
+	for i := 0; i < 50; i++ {
+		lo := kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100, rnd.Float64() * 100}
+		b := &kdtree.Bounding{lo, kdtree.Point{lo[0] + 10, lo[1] + 10, lo[2] + 10}}
+		var want int
+		for _, p := range prims {
+			if overlaps(p.Bounds(), b) {
+				want++
+			}
+		}
+		var got int
+		t.Overlapping(func(p Primitive) bool {
+			c.Check(overlaps(p.Bounds(), b), check.Equals, true)
+			got++
+			return false
+		}, b)
+		c.Check(got, check.Equals, want)
+	}
+}
+
+func (s *S) TestRaycast(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	prims := randTriangles(rnd, 2000)
+	t, _ := New(prims)
+	var hits int
+	for i := 0; i < 200; i++ {
+		r := Ray{
+			Origin: kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100, -10},
+			Dir:    kdtree.Point{rnd.Float64() - 0.5, rnd.Float64() - 0.5, 1},
+		}
+		var (
+			want   Primitive
+			wantT  = 1000.
+			wantN  int
+			wantOK bool
+		)
+		for _, p := range prims {
+			if h, ok := p.(RayIntersector).IntersectRay(r, 1000); ok {
+				wantN++
+				if h < wantT {
+					want, wantT, wantOK = p, h, true
+				}
+			}
+		}
+		got, gotT, ok := t.Raycast(r, 1000)
+		c.Check(ok, check.Equals, wantOK)
+		if ok {
+			hits++
+			c.Check(got, check.DeepEquals, want)
+			c.Check(gotT, check.Equals, wantT)
+		}
+		var n int
+		t.RaycastAll(func(Primitive, float64) bool { n++; return false }, r, 1000)
+		c.Check(n, check.Equals, wantN)
+	}
+	c.Check(hits > 0, check.Equals, true)
+}
+
+func (s *S) TestCollisions(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	prims := randTriangles(rnd, 500)
+	t, _ := New(prims)
+	var want int
+	for i, p := range prims {
+		for _, q := range prims[i+1:] {
+			if overlaps(p.Bounds(), q.Bounds()) {
+				want++
+			}
+		}
+	}
+	pairs := t.Collisions()
+	c.Check(pairs, check.HasLen, want)
+	for _, p := range pairs {
+		c.Check(overlaps(p[0].Bounds(), p[1].Bounds()), check.Equals, true)
+	}
+}
+
+func BenchmarkRaycast(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	t, _ := New(randTriangles(rnd, 1e5))
+	r := Ray{Origin: kdtree.Point{50, 50, -10}, Dir: kdtree.Point{0.1, 0.2, 1}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t.Raycast(r, 1000)
+	}
+}
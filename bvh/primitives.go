@@ -0,0 +1,143 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bvh
+
+import (
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+var (
+	_ RayIntersector = Box{}
+	_ RayIntersector = Segment{}
+	_ RayIntersector = Triangle{}
+)
+
+// epsilon is the tolerance used for parallel rays and degenerate primitives.
+const epsilon = 1e-12
+
+// A Box is an axis aligned box primitive.
+type Box struct {
+	Min, Max kdtree.Point
+}
+
+// Bounds returns the box itself.
+func (b Box) Bounds() *kdtree.Bounding { return &kdtree.Bounding{b.Min, b.Max} }
+
+// IntersectRay returns the parameter at which r enters the box or, if r starts within
+// the box, zero.
+func (b Box) IntersectRay(r Ray, tmax float64) (float64, bool) {
+	return aabb{min: b.Min, max: b.Max}.slab(r, tmax)
+}
+
+// A Segment is a line segment primitive from A to B.
+type Segment struct {
+	A, B kdtree.Point
+}
+
+// Bounds returns the bounding box of the segment.
+func (s Segment) Bounds() *kdtree.Bounding { return bound(s.A, s.B) }
+
+// IntersectRay returns the parameter at which r crosses a two dimensional segment. Rays
+// collinear with the segment are struck at the nearest point of the segment. Segments of
+// other dimensionality are never struck.
+func (s Segment) IntersectRay(r Ray, tmax float64) (float64, bool) {
+	if len(s.A) != 2 {
+		return 0, false
+	}
+	ex, ey := s.B[0]-s.A[0], s.B[1]-s.A[1]
+	wx, wy := s.A[0]-r.Origin[0], s.A[1]-r.Origin[1]
+	den := r.Dir[0]*ey - r.Dir[1]*ex
+	if math.Abs(den) < epsilon {
+		// Parallel; consider only collinear segments.
+		if math.Abs(wx*r.Dir[1]-wy*r.Dir[0]) > epsilon {
+			return 0, false
+		}
+		dd := r.Dir[0]*r.Dir[0] + r.Dir[1]*r.Dir[1]
+		if dd == 0 {
+			return 0, false
+		}
+		t0 := (wx*r.Dir[0] + wy*r.Dir[1]) / dd
+		t1 := ((s.B[0]-r.Origin[0])*r.Dir[0] + (s.B[1]-r.Origin[1])*r.Dir[1]) / dd
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t1 < 0 || t0 > tmax {
+			return 0, false
+		}
+		return math.Max(t0, 0), true
+	}
+	t := (wx*ey - wy*ex) / den
+	u := (wx*r.Dir[1] - wy*r.Dir[0]) / den
+	if t < 0 || t > tmax || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// A Triangle is a triangle primitive with vertices A, B and C.
+type Triangle struct {
+	A, B, C kdtree.Point
+}
+
+// Bounds returns the bounding box of the triangle.
+func (t Triangle) Bounds() *kdtree.Bounding { return bound(t.A, t.B, t.C) }
+
+// IntersectRay returns the parameter at which r strikes a three dimensional triangle,
+// using the Möller-Trumbore algorithm. Rays in the plane of the triangle and triangles
+// of other dimensionality are never struck.
+func (t Triangle) IntersectRay(r Ray, tmax float64) (float64, bool) {
+	if len(t.A) != 3 {
+		return 0, false
+	}
+	a, dir := vec(t.A), vec(r.Dir)
+	e1 := vec(t.B).sub(a)
+	e2 := vec(t.C).sub(a)
+	p := dir.cross(e2)
+	det := e1.dot(p)
+	if math.Abs(det) < epsilon {
+		return 0, false
+	}
+	inv := 1 / det
+	s := vec(r.Origin).sub(a)
+	u := s.dot(p) * inv
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+	q := s.cross(e1)
+	v := dir.dot(q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+	h := e2.dot(q) * inv
+	if h < 0 || h > tmax {
+		return 0, false
+	}
+	return h, true
+}
+
+// bound returns the bounding box of the points in p.
+func bound(p ...kdtree.Point) *kdtree.Bounding {
+	min := append(kdtree.Point(nil), p[0]...)
+	max := append(kdtree.Point(nil), p[0]...)
+	for _, e := range p[1:] {
+		for d, v := range e {
+			min[d] = math.Min(min[d], v)
+			max[d] = math.Max(max[d], v)
+		}
+	}
+	return &kdtree.Bounding{min, max}
+}
+
+type vec3 [3]float64
+
+func vec(p kdtree.Point) vec3 { return vec3{p[0], p[1], p[2]} }
+
+func (a vec3) sub(b vec3) vec3    { return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func (a vec3) dot(b vec3) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
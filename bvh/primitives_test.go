@@ -0,0 +1,45 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bvh
+
+import (
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestPrimitiveRays(c *check.C) {
+	for i, test := range []struct {
+		p    RayIntersector
+		r    Ray
+		want float64
+		ok   bool
+	}{
+		{Box{kdtree.Point{1, 1}, kdtree.Point{2, 2}}, Ray{kdtree.Point{0, 1.5}, kdtree.Point{1, 0}}, 1, true},
+		{Box{kdtree.Point{1, 1}, kdtree.Point{2, 2}}, Ray{kdtree.Point{1.5, 1.5}, kdtree.Point{1, 0}}, 0, true},
+		{Box{kdtree.Point{1, 1}, kdtree.Point{2, 2}}, Ray{kdtree.Point{0, 3}, kdtree.Point{1, 0}}, 0, false},
+		{Box{kdtree.Point{1, 1}, kdtree.Point{2, 2}}, Ray{kdtree.Point{3, 1.5}, kdtree.Point{1, 0}}, 0, false},
+
+		{Segment{kdtree.Point{2, -1}, kdtree.Point{2, 1}}, Ray{kdtree.Point{0, 0}, kdtree.Point{1, 0}}, 2, true},
+		{Segment{kdtree.Point{2, -1}, kdtree.Point{2, 1}}, Ray{kdtree.Point{0, 2}, kdtree.Point{1, 0}}, 0, false},
+		{Segment{kdtree.Point{2, 0}, kdtree.Point{4, 0}}, Ray{kdtree.Point{0, 0}, kdtree.Point{2, 0}}, 1, true},
+		{Segment{kdtree.Point{2, 1}, kdtree.Point{4, 1}}, Ray{kdtree.Point{0, 0}, kdtree.Point{1, 0}}, 0, false},
+		{Segment{kdtree.Point{0, 0, 0}, kdtree.Point{1, 1, 1}}, Ray{kdtree.Point{0, 0, 0}, kdtree.Point{1, 1, 1}}, 0, false},
+
+		{Triangle{kdtree.Point{0, 0, 5}, kdtree.Point{2, 0, 5}, kdtree.Point{0, 2, 5}}, Ray{kdtree.Point{0.5, 0.5, 0}, kdtree.Point{0, 0, 1}}, 5, true},
+		{Triangle{kdtree.Point{0, 0, 5}, kdtree.Point{2, 0, 5}, kdtree.Point{0, 2, 5}}, Ray{kdtree.Point{1.5, 1.5, 0}, kdtree.Point{0, 0, 1}}, 0, false},
+		{Triangle{kdtree.Point{0, 0, 5}, kdtree.Point{2, 0, 5}, kdtree.Point{0, 2, 5}}, Ray{kdtree.Point{0.5, 0.5, 0}, kdtree.Point{0, 0, -1}}, 0, false},
+		{Triangle{kdtree.Point{0, 0, 5}, kdtree.Point{2, 0, 5}, kdtree.Point{0, 2, 5}}, Ray{kdtree.Point{0.5, 0.5, 0}, kdtree.Point{1, 0, 0}}, 0, false},
+	} {
+		got, ok := test.p.IntersectRay(test.r, 10)
+		c.Check(ok, check.Equals, test.ok, check.Commentf("Test %d", i))
+		if ok {
+			c.Check(got, check.Equals, test.want, check.Commentf("Test %d", i))
+		}
+	}
+	// Hits beyond tmax are not reported.
+	_, ok := Segment{kdtree.Point{2, -1}, kdtree.Point{2, 1}}.IntersectRay(Ray{kdtree.Point{0, 0}, kdtree.Point{1, 0}}, 1)
+	c.Check(ok, check.Equals, false)
+	c.Check(Ray{kdtree.Point{1, 2}, kdtree.Point{1, -1}}.At(2), check.DeepEquals, kdtree.Point{3, 0})
+}
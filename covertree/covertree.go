@@ -0,0 +1,228 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package covertree implements cover trees for exact nearest neighbour search in metric
+// spaces, as described by Izbicki and Shelton in "Faster Cover Trees", Proceedings of the
+// 32nd International Conference on Machine Learning, 2015.
+//
+// Cover trees support insertion and removal of values without rebuilding. Values and
+// queries use the vptree Comparable, Keeper and ComparableDist types, so a cover tree may
+// be used in place of a vantage point tree when the indexed data change over time.
+package covertree
+
+import (
+	"math"
+	"sort"
+
+	"github.com/biogo/store/vptree"
+)
+
+var inf = math.Inf(1)
+
+// A Node holds a single value in a cover tree. The children of a node are within
+// 2^Level of its point, and all the values of the subtree rooted at the node are within
+// MaxDist of its point.
+type Node struct {
+	Point    vptree.Comparable
+	Level    int
+	MaxDist  float64
+	Children []*Node
+}
+
+// covdist returns the covering distance of n.
+func (n *Node) covdist() float64 { return math.Ldexp(1, n.Level) }
+
+// A Tree implements a cover tree.
+type Tree struct {
+	Root  *Node
+	Count int
+}
+
+// New returns a cover tree holding the values in p.
+func New(p []vptree.Comparable) *Tree {
+	t := &Tree{}
+	for _, c := range p {
+		t.Insert(c)
+	}
+	return t
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree) Len() int { return t.Count }
+
+// Insert adds a value to the tree.
+func (t *Tree) Insert(c vptree.Comparable) {
+	t.Count++
+	if t.Root == nil {
+		t.Root = &Node{Point: c}
+		return
+	}
+	d := t.Root.Point.Distance(c)
+	if d > t.Root.covdist() {
+		// Raise the root until it covers c. Raising a node's
+		// level preserves the covering of its children.
+		_, exp := math.Frexp(d)
+		t.Root.Level = exp
+	}
+	t.Root.insert(c, d)
+}
+
+// insert adds c, at distance d from its point, to the subtree rooted at n.
+func (n *Node) insert(c vptree.Comparable, d float64) {
+	for {
+		n.MaxDist = math.Max(n.MaxDist, d)
+		var next *Node
+		for _, ch := range n.Children {
+			if dc := ch.Point.Distance(c); dc <= ch.covdist() {
+				next, d = ch, dc
+				break
+			}
+		}
+		if next == nil {
+			n.Children = append(n.Children, &Node{Point: c, Level: n.Level - 1})
+			return
+		}
+		n = next
+	}
+}
+
+// Remove removes a single value from the tree that is at zero distance from c, returning
+// whether a value was removed. The descendants of the removed node are reinserted.
+func (t *Tree) Remove(c vptree.Comparable) bool {
+	if t.Root == nil {
+		return false
+	}
+	var orphans []vptree.Comparable
+	if t.Root.Point.Distance(c) == 0 {
+		for _, ch := range t.Root.Children {
+			orphans = ch.collect(orphans)
+		}
+		t.Root = nil
+	} else {
+		var ok bool
+		orphans, ok = t.Root.remove(c, t.Root.Point.Distance(c))
+		if !ok {
+			return false
+		}
+	}
+	t.Count -= len(orphans) + 1
+	for _, o := range orphans {
+		t.Insert(o)
+	}
+	return true
+}
+
+// remove removes c, at distance d from the point of n, from the descendants of n and
+// returns the orphaned values of the removed node's subtree.
+func (n *Node) remove(c vptree.Comparable, d float64) ([]vptree.Comparable, bool) {
+	if d > n.MaxDist {
+		return nil, false
+	}
+	for i, ch := range n.Children {
+		dc := ch.Point.Distance(c)
+		if dc == 0 {
+			var orphans []vptree.Comparable
+			for _, gc := range ch.Children {
+				orphans = gc.collect(orphans)
+			}
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return orphans, true
+		}
+		if orphans, ok := ch.remove(c, dc); ok {
+			return orphans, true
+		}
+	}
+	return nil, false
+}
+
+// collect appends the values of the subtree rooted at n to dst.
+func (n *Node) collect(dst []vptree.Comparable) []vptree.Comparable {
+	dst = append(dst, n.Point)
+	for _, ch := range n.Children {
+		dst = ch.collect(dst)
+	}
+	return dst
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Tree) Nearest(q vptree.Comparable) (vptree.Comparable, float64) {
+	if t.Root == nil {
+		return nil, inf
+	}
+	k := vptree.NewNKeeper(1)
+	t.Root.search(q, q.Distance(t.Root.Point), k)
+	return k.Heap[0].Comparable, k.Heap[0].Dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (t *Tree) NearestSet(k vptree.Keeper, q vptree.Comparable) {
+	if t.Root == nil {
+		return
+	}
+	t.Root.search(q, q.Distance(t.Root.Point), k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// search searches the subtree rooted at n, where d is the distance between q and the
+// point of n.
+func (n *Node) search(q vptree.Comparable, d float64, k vptree.Keeper) {
+	if d-n.MaxDist > k.Max().Dist {
+		return
+	}
+	k.Keep(vptree.ComparableDist{Comparable: n.Point, Dist: d})
+	if len(n.Children) == 0 {
+		return
+	}
+	children := make(byDist, len(n.Children))
+	for i, ch := range n.Children {
+		children[i] = nodeDist{ch, q.Distance(ch.Point)}
+	}
+	sort.Sort(children)
+	for _, ch := range children {
+		ch.search(q, ch.dist, k)
+	}
+}
+
+type nodeDist struct {
+	*Node
+	dist float64
+}
+
+type byDist []nodeDist
+
+func (b byDist) Len() int           { return len(b) }
+func (b byDist) Less(i, j int) bool { return b[i].dist < b[j].dist }
+func (b byDist) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// An Operation is a function that operates on a Comparable. The tree depth of the value
+// is also provided. If done is returned true, the Operation is indicating that no further
+// work needs to be done and so the Do function should traverse no further.
+type Operation func(c vptree.Comparable, depth int) (done bool)
+
+// Do performs fn on all values stored in the tree in pre-order. A boolean is returned
+// indicating whether the Do traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn Operation) bool {
+	if t.Root == nil {
+		return false
+	}
+	return t.Root.do(fn, 0)
+}
+
+func (n *Node) do(fn Operation, depth int) bool {
+	if fn(n.Point, depth) {
+		return true
+	}
+	for _, ch := range n.Children {
+		if ch.do(fn, depth+1) {
+			return true
+		}
+	}
+	return false
+}
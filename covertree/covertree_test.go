@@ -0,0 +1,147 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package covertree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/vptree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+// point is a Euclidean point.
+type point []float64
+
+func (p point) Distance(c vptree.Comparable) float64 {
+	q := c.(point)
+	var sum float64
+	for i := range p {
+		d := p[i] - q[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+func randPoints(n, dims int) []vptree.Comparable {
+	p := make([]vptree.Comparable, n)
+	for i := range p {
+		v := make(point, dims)
+		for j := range v {
+			v[j] = rand.Float64() * 100
+		}
+		p[i] = v
+	}
+	return p
+}
+
+// isCoverTree returns whether the covering and maximum distance invariants hold
+// throughout the subtree rooted at n.
+func (n *Node) isCoverTree() bool {
+	for _, ch := range n.Children {
+		if ch.Level >= n.Level || n.Point.Distance(ch.Point) > n.covdist() {
+			return false
+		}
+		if ch.do(func(c vptree.Comparable, _ int) bool { return n.Point.Distance(c) > n.MaxDist }, 0) {
+			return false
+		}
+		if !ch.isCoverTree() {
+			return false
+		}
+	}
+	return true
+}
+
+func nearest(q vptree.Comparable, p []vptree.Comparable) (vptree.Comparable, float64) {
+	best, d := p[0], q.Distance(p[0])
+	for _, e := range p[1:] {
+		if ed := q.Distance(e); ed < d {
+			best, d = e, ed
+		}
+	}
+	return best, d
+}
+
+func (s *S) TestNearest(c *check.C) {
+	_, d := (&Tree{}).Nearest(point{0})
+	c.Check(math.IsInf(d, 1), check.Equals, true)
+
+	p := randPoints(2000, 6)
+	t := New(p)
+	c.Check(t.Len(), check.Equals, len(p))
+	c.Check(t.Root.isCoverTree(), check.Equals, true)
+	var n int
+	t.Do(func(vptree.Comparable, int) bool { n++; return false })
+	c.Check(n, check.Equals, len(p))
+
+	for _, q := range randPoints(100, 6) {
+		got, d := t.Nearest(q)
+		want, wd := nearest(q, p)
+		c.Check(got, check.DeepEquals, want)
+		c.Check(d, check.Equals, wd)
+	}
+}
+
+func (s *S) TestNearestSet(c *check.C) {
+	p := randPoints(1000, 4)
+	t := New(p)
+	for _, q := range randPoints(20, 4) {
+		dists := make([]float64, len(p))
+		for i, e := range p {
+			dists[i] = q.Distance(e)
+		}
+		sort.Float64s(dists)
+
+		k := vptree.NewNKeeper(10)
+		t.NearestSet(k, q)
+		c.Assert(k.Len(), check.Equals, 10)
+		for i, cd := range k.Heap {
+			c.Check(cd.Dist, check.Equals, dists[i])
+		}
+
+		dk := vptree.NewDistKeeper(dists[25])
+		t.NearestSet(dk, q)
+		c.Check(dk.Len(), check.Equals, 27) // 26 values and the sentinel.
+	}
+}
+
+func (s *S) TestRemove(c *check.C) {
+	p := randPoints(500, 3)
+	t := New(p)
+	c.Check(t.Remove(point{-1, -1, -1}), check.Equals, false)
+
+	for i, j := range rand.Perm(len(p)) {
+		p[i], p[j] = p[j], p[i]
+	}
+	for len(p) > 0 {
+		r := p[len(p)-1]
+		p = p[:len(p)-1]
+		c.Assert(t.Remove(r), check.Equals, true)
+		c.Assert(t.Len(), check.Equals, len(p))
+		if len(p) == 0 {
+			c.Check(t.Root, check.IsNil)
+			break
+		}
+		if len(p)%50 == 0 {
+			c.Check(t.Root.isCoverTree(), check.Equals, true)
+			for _, q := range randPoints(10, 3) {
+				got, d := t.Nearest(q)
+				want, wd := nearest(q, p)
+				c.Check(got, check.DeepEquals, want)
+				c.Check(d, check.Equals, wd)
+			}
+		}
+	}
+	c.Check(t.Remove(point{0, 0, 0}), check.Equals, false)
+}
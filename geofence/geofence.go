@@ -0,0 +1,203 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package geofence provides a registry of named fences, regions against which points are
+// tested for entry.
+//
+// A Registry indexes the bounding rectangles of its fences in an R-tree, so evaluating a
+// point against all the registered fences tests only the fences whose bounds hold the
+// point. The inverse query, finding the points of a kdtree.Tree within a fence, uses the
+// fence's bounds to prune the tree.
+package geofence
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+	"github.com/biogo/store/rtree"
+)
+
+// ErrDims is returned when a fence does not have the dimensionality of the fences already
+// registered.
+var ErrDims = errors.New("geofence: dimension mismatch")
+
+// A Fence is a region of space.
+type Fence interface {
+	// Rect returns the bounding rectangle of the fence.
+	Rect() rtree.Rect
+
+	// Contains returns whether p lies within the fence.
+	Contains(p kdtree.Point) bool
+}
+
+// A Box is an axis aligned box fence with the corners Min and Max. The faces of the box
+// are within the fence.
+type Box struct {
+	Min, Max kdtree.Point
+}
+
+// Rect satisfies the Fence interface.
+func (b Box) Rect() rtree.Rect { return rtree.Rect{Min: b.Min, Max: b.Max} }
+
+// Contains satisfies the Fence interface.
+func (b Box) Contains(p kdtree.Point) bool {
+	for d, v := range p {
+		if v < b.Min[d] || v > b.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// A Circle is a fence holding the points within Euclidean distance Radius of Center. In
+// more than two dimensions a Circle is a sphere.
+type Circle struct {
+	Center kdtree.Point
+	Radius float64
+}
+
+// Rect satisfies the Fence interface.
+func (c Circle) Rect() rtree.Rect {
+	r := rtree.Rect{Min: make(kdtree.Point, len(c.Center)), Max: make(kdtree.Point, len(c.Center))}
+	for d, v := range c.Center {
+		r.Min[d] = v - c.Radius
+		r.Max[d] = v + c.Radius
+	}
+	return r
+}
+
+// Contains satisfies the Fence interface.
+func (c Circle) Contains(p kdtree.Point) bool { return c.Center.Distance(p) <= c.Radius*c.Radius }
+
+// A Polygon is a two-dimensional fence bounded by the closed path through its vertices.
+// Points within the polygon are determined by the even-odd rule.
+type Polygon []kdtree.Point
+
+// Rect satisfies the Fence interface.
+func (p Polygon) Rect() rtree.Rect {
+	r := rtree.Rect{Min: kdtree.Point{math.Inf(1), math.Inf(1)}, Max: kdtree.Point{math.Inf(-1), math.Inf(-1)}}
+	for _, v := range p {
+		for d := 0; d < 2; d++ {
+			r.Min[d] = math.Min(r.Min[d], v[d])
+			r.Max[d] = math.Max(r.Max[d], v[d])
+		}
+	}
+	return r
+}
+
+// Contains satisfies the Fence interface.
+func (p Polygon) Contains(q kdtree.Point) bool {
+	var in bool
+	for i, a := range p {
+		b := p[(i+1)%len(p)]
+		if (a[1] > q[1]) != (b[1] > q[1]) && q[0] < a[0]+(q[1]-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// A Registry holds a set of named fences.
+type Registry struct {
+	fences map[string]Fence
+	index  *rtree.Tree
+	dims   int
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{fences: make(map[string]Fence), index: rtree.New(0)}
+}
+
+// Len returns the number of fences in the registry.
+func (r *Registry) Len() int { return len(r.fences) }
+
+// Register adds the fence f to the registry with the given name, replacing any fence
+// already registered with that name. Register returns ErrDims if f does not have the
+// dimensionality of the fences already in the registry.
+func (r *Registry) Register(name string, f Fence) error {
+	rect := f.Rect()
+	if n := len(r.fences); n != 0 && len(rect.Min) != r.dims {
+		// A lone fence may be replaced by one of any dimensionality.
+		if _, ok := r.fences[name]; !ok || n != 1 {
+			return ErrDims
+		}
+	}
+	r.Unregister(name)
+	r.dims = len(rect.Min)
+	r.fences[name] = f
+	r.index.Insert(rect, name)
+	return nil
+}
+
+// Unregister removes the fence with the given name from the registry, returning whether a
+// fence was removed.
+func (r *Registry) Unregister(name string) bool {
+	f, ok := r.fences[name]
+	if !ok {
+		return false
+	}
+	delete(r.fences, name)
+	r.index.Remove(f.Rect(), func(v interface{}) bool { return v == name })
+	return true
+}
+
+// Fence returns the fence registered with the given name and whether it exists.
+func (r *Registry) Fence(name string) (Fence, bool) {
+	f, ok := r.fences[name]
+	return f, ok
+}
+
+// WhichFences returns the names of the fences containing p in sorted order.
+func (r *Registry) WhichFences(p kdtree.Point) []string {
+	if len(r.fences) == 0 || len(p) != r.dims {
+		return nil
+	}
+	var names []string
+	r.index.Intersecting(func(_ rtree.Rect, v interface{}) bool {
+		name := v.(string)
+		if r.fences[name].Contains(p) {
+			names = append(names, name)
+		}
+		return false
+	}, rtree.Point(p))
+	sort.Strings(names)
+	return names
+}
+
+// Inside returns the values of t within the fence registered with the given name, in the
+// order they are visited by t.DoBounded, and whether the fence exists. The values held by
+// t must be of a type accepted by kdtree.Point.Compare.
+func (r *Registry) Inside(t *kdtree.Tree, name string) ([]kdtree.Comparable, bool) {
+	f, ok := r.fences[name]
+	if !ok {
+		return nil, false
+	}
+	return Inside(t, f), true
+}
+
+// Inside returns the values of t within f, in the order they are visited by t.DoBounded.
+// The values held by t must be of a type accepted by kdtree.Point.Compare and must have
+// the dimensionality of f.
+func Inside(t *kdtree.Tree, f Fence) []kdtree.Comparable {
+	if t.Root == nil {
+		return nil
+	}
+	rect := f.Rect()
+	zero := make(kdtree.Point, len(rect.Min))
+	p := make(kdtree.Point, len(rect.Min))
+	var res []kdtree.Comparable
+	t.DoBounded(func(c kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+		for d := range p {
+			p[d] = c.Compare(zero, kdtree.Dim(d))
+		}
+		if f.Contains(p) {
+			res = append(res, c)
+		}
+		return false
+	}, rect.Bounding())
+	return res
+}
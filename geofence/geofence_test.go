@@ -0,0 +1,128 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geofence
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestFences(c *check.C) {
+	b := Box{Min: kdtree.Point{0, 0}, Max: kdtree.Point{2, 1}}
+	c.Check(b.Contains(kdtree.Point{2, 1}), check.Equals, true)
+	c.Check(b.Contains(kdtree.Point{2, 1.1}), check.Equals, false)
+
+	ci := Circle{Center: kdtree.Point{1, 1}, Radius: 1}
+	c.Check(ci.Rect(), check.DeepEquals, Box{Min: kdtree.Point{0, 0}, Max: kdtree.Point{2, 2}}.Rect())
+	c.Check(ci.Contains(kdtree.Point{1, 2}), check.Equals, true)
+	c.Check(ci.Contains(kdtree.Point{1.8, 1.8}), check.Equals, false)
+
+	// An L shaped polygon.
+	p := Polygon{{0, 0}, {3, 0}, {3, 1}, {1, 1}, {1, 3}, {0, 3}}
+	c.Check(p.Rect(), check.DeepEquals, Box{Min: kdtree.Point{0, 0}, Max: kdtree.Point{3, 3}}.Rect())
+	c.Check(p.Contains(kdtree.Point{0.5, 2.5}), check.Equals, true)
+	c.Check(p.Contains(kdtree.Point{2.5, 0.5}), check.Equals, true)
+	c.Check(p.Contains(kdtree.Point{2, 2}), check.Equals, false)
+	c.Check(p.Contains(kdtree.Point{4, 0.5}), check.Equals, false)
+}
+
+func (s *S) TestRegistry(c *check.C) {
+	r := NewRegistry()
+	c.Check(r.WhichFences(kdtree.Point{0, 0}), check.HasLen, 0)
+	c.Check(r.Register("box", Box{Min: kdtree.Point{0, 0}, Max: kdtree.Point{2, 1}}), check.IsNil)
+	c.Check(r.Register("circle", Circle{Center: kdtree.Point{1, 1}, Radius: 1}), check.IsNil)
+	c.Check(r.Register("ell", Polygon{{0, 0}, {3, 0}, {3, 1}, {1, 1}, {1, 3}, {0, 3}}), check.IsNil)
+	c.Check(r.Register("cube", Box{Min: kdtree.Point{0, 0, 0}, Max: kdtree.Point{1, 1, 1}}), check.Equals, ErrDims)
+	c.Check(r.Len(), check.Equals, 3)
+
+	c.Check(r.WhichFences(kdtree.Point{0.5, 0.5}), check.DeepEquals, []string{"box", "circle", "ell"})
+	c.Check(r.WhichFences(kdtree.Point{1.5, 1.5}), check.DeepEquals, []string{"circle"})
+	c.Check(r.WhichFences(kdtree.Point{0.5, 2.5}), check.DeepEquals, []string{"ell"})
+	c.Check(r.WhichFences(kdtree.Point{5, 5}), check.HasLen, 0)
+	c.Check(r.WhichFences(kdtree.Point{5}), check.HasLen, 0)
+
+	// Replacing a fence.
+	c.Check(r.Register("circle", Circle{Center: kdtree.Point{10, 10}, Radius: 1}), check.IsNil)
+	c.Check(r.Len(), check.Equals, 3)
+	c.Check(r.WhichFences(kdtree.Point{1.5, 1.5}), check.HasLen, 0)
+	c.Check(r.WhichFences(kdtree.Point{10, 10.5}), check.DeepEquals, []string{"circle"})
+
+	c.Check(r.Unregister("box"), check.Equals, true)
+	c.Check(r.Unregister("box"), check.Equals, false)
+	c.Check(r.WhichFences(kdtree.Point{0.5, 0.5}), check.DeepEquals, []string{"ell"})
+	_, ok := r.Fence("box")
+	c.Check(ok, check.Equals, false)
+	f, ok := r.Fence("ell")
+	c.Check(ok, check.Equals, true)
+	c.Check(f, check.FitsTypeOf, Polygon(nil))
+
+	// Many fences agree with a linear scan.
+	rnd := rand.New(rand.NewSource(1))
+	r = NewRegistry()
+	fences := make(map[string]Fence)
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprint("fence", i)
+		f := Circle{Center: kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100}, Radius: rnd.Float64() * 10}
+		fences[name] = f
+		c.Assert(r.Register(name, f), check.IsNil)
+	}
+	for i := 0; i < 100; i++ {
+		p := kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100}
+		var want []string
+		for name, f := range fences {
+			if f.Contains(p) {
+				want = append(want, name)
+			}
+		}
+		sort.Strings(want)
+		got := r.WhichFences(p)
+		if len(want) == 0 {
+			c.Check(got, check.HasLen, 0)
+		} else {
+			c.Check(got, check.DeepEquals, want)
+		}
+	}
+}
+
+func (s *S) TestInside(c *check.C) {
+	var p kdtree.Points
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			p = append(p, kdtree.Point{float64(x), float64(y)})
+		}
+	}
+	t := kdtree.New(p, false)
+	r := NewRegistry()
+	r.Register("ell", Polygon{{-0.5, -0.5}, {3.5, -0.5}, {3.5, 0.5}, {0.5, 0.5}, {0.5, 3.5}, {-0.5, 3.5}})
+	r.Register("circle", Circle{Center: kdtree.Point{2, 2}, Radius: 1})
+
+	res, ok := r.Inside(t, "ell")
+	c.Check(ok, check.Equals, true)
+	c.Check(res, check.HasLen, 7)
+	for _, v := range res {
+		pt := v.(kdtree.Point)
+		c.Check(pt[0] == 0 || pt[1] == 0, check.Equals, true)
+	}
+
+	res, _ = r.Inside(t, "circle")
+	c.Check(res, check.HasLen, 5)
+
+	_, ok = r.Inside(t, "missing")
+	c.Check(ok, check.Equals, false)
+	c.Check(Inside(&kdtree.Tree{}, Circle{Center: kdtree.Point{0, 0}, Radius: 1}), check.HasLen, 0)
+}
@@ -0,0 +1,392 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package grid implements a uniform grid, or cell list, spatial index.
+//
+// A Grid divides space into cubic cells of a single fixed size and holds each value in
+// the cell containing it. Insertion and removal are constant time operations and
+// queries examine only the cells near the query. For data of roughly uniform density
+// queried with a fixed radius, as in particle simulations, a Grid with a cell size close
+// to the query radius will generally outperform a k-d tree. For clustered data, or
+// queries with widely varying radii, a k-d tree is the better choice since cells become
+// either crowded or mostly empty.
+//
+// Grids hold kdtree.Comparable values whose Compare and Distance methods accept
+// kdtree.Point parameters, such as kdtree.Point and kdtree.Datum, and squared Euclidean
+// Distance methods are assumed by range queries. Queries follow the kdtree package's API.
+package grid
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// MaxDims is the maximum number of dimensions of a Grid.
+const MaxDims = 3
+
+var _ kdtree.SpatialIndex = (*Grid)(nil)
+
+var (
+	// ErrDims is returned when a grid is created with an invalid number of dimensions.
+	ErrDims = errors.New("grid: invalid dimensions")
+
+	// ErrSize is returned when a grid is created with a non-positive cell size.
+	ErrSize = errors.New("grid: invalid cell size")
+)
+
+// key is the index of a cell. Coordinates beyond the dimensions of the grid are zero.
+type key [MaxDims]int
+
+// A Grid is a uniform grid spatial index.
+type Grid struct {
+	dims  int
+	size  float64
+	zero  kdtree.Point
+	cells map[key][]kdtree.Comparable
+	count int
+}
+
+// New returns an empty grid of the given dimensionality with cubic cells of side size.
+// The cell size should be close to the radius of range queries performed on the grid,
+// or to the typical distance to near neighbours for nearest neighbour queries.
+func New(dims int, size float64) (*Grid, error) {
+	if dims < 1 || dims > MaxDims {
+		return nil, ErrDims
+	}
+	if !(size > 0) || math.IsInf(size, 1) {
+		return nil, ErrSize
+	}
+	return &Grid{
+		dims:  dims,
+		size:  size,
+		zero:  make(kdtree.Point, dims),
+		cells: make(map[key][]kdtree.Comparable),
+	}, nil
+}
+
+// Len returns the number of values stored in the grid.
+func (g *Grid) Len() int { return g.count }
+
+// Dims returns the dimensionality of the grid.
+func (g *Grid) Dims() int { return g.dims }
+
+// CellSize returns the side length of the grid's cells.
+func (g *Grid) CellSize() float64 { return g.size }
+
+// Cells returns the number of non-empty cells in the grid.
+func (g *Grid) Cells() int { return len(g.cells) }
+
+// coord returns the coordinate of c in dimension d.
+func (g *Grid) coord(c kdtree.Comparable, d int) float64 {
+	return c.Compare(g.zero, kdtree.Dim(d))
+}
+
+// keyOf returns the key of the cell holding c.
+func (g *Grid) keyOf(c kdtree.Comparable) key {
+	var k key
+	for d := 0; d < g.dims; d++ {
+		k[d] = int(math.Floor(g.coord(c, d) / g.size))
+	}
+	return k
+}
+
+// Insert adds c to the grid. Insert returns kdtree.ErrPointDims if c does not have the
+// dimensionality of the grid.
+func (g *Grid) Insert(c kdtree.Comparable) error {
+	if c.Dims() != g.dims {
+		return kdtree.ErrPointDims
+	}
+	k := g.keyOf(c)
+	g.cells[k] = append(g.cells[k], c)
+	g.count++
+	return nil
+}
+
+// Remove removes a single value from the grid that has the same coordinates as c,
+// returning whether a value was removed.
+func (g *Grid) Remove(c kdtree.Comparable) bool {
+	if c.Dims() != g.dims {
+		return false
+	}
+	k := g.keyOf(c)
+	cell := g.cells[k]
+	for i, p := range cell {
+		if same(p, c) {
+			if len(cell) == 1 {
+				delete(g.cells, k)
+			} else {
+				g.cells[k] = append(cell[:i], cell[i+1:]...)
+			}
+			g.count--
+			return true
+		}
+	}
+	return false
+}
+
+// same returns whether a and b have the same coordinates.
+func same(a, b kdtree.Comparable) bool {
+	for d := 0; d < a.Dims(); d++ {
+		if a.Compare(b, kdtree.Dim(d)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cellDist returns the squared distance from the point q to the nearest point of the
+// cell k.
+func (g *Grid) cellDist(q []float64, k key) float64 {
+	var sum float64
+	for d, v := range q {
+		var delta float64
+		if min := float64(k[d]) * g.size; v < min {
+			delta = min - v
+		} else if max := float64(k[d]+1) * g.size; v > max {
+			delta = v - max
+		}
+		sum += delta * delta
+	}
+	return sum
+}
+
+// chebyshev returns the Chebyshev distance between cells a and b.
+func chebyshev(a, b key) int {
+	var m int
+	for d := range a {
+		v := a[d] - b[d]
+		if v < 0 {
+			v = -v
+		}
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+// ring calls fn on each cell at Chebyshev distance r from the cell c.
+func (g *Grid) ring(c key, r int, fn func(key)) {
+	var o key
+	var walk func(d int, edge bool)
+	walk = func(d int, edge bool) {
+		if d == g.dims {
+			if edge {
+				var k key
+				for i := range k {
+					k[i] = c[i] + o[i]
+				}
+				fn(k)
+			}
+			return
+		}
+		for o[d] = -r; o[d] <= r; o[d]++ {
+			walk(d+1, edge || o[d] == -r || o[d] == r)
+		}
+	}
+	walk(0, r == 0)
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (g *Grid) Nearest(q kdtree.Comparable) (kdtree.Comparable, float64) {
+	k := kdtree.NewNKeeper(1)
+	g.search(q, k)
+	return k.Heap[0].Comparable, k.Heap[0].Dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (g *Grid) NearestSet(k kdtree.Keeper, q kdtree.Comparable) {
+	g.search(q, k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// NearestN returns the n nearest values to the query in min sorted order. If the grid holds
+// fewer than n values, all the values in the grid are returned.
+func (g *Grid) NearestN(q kdtree.Comparable, n int) []kdtree.ComparableDist {
+	if n > g.Len() {
+		n = g.Len()
+	}
+	if n <= 0 {
+		return nil
+	}
+	k := kdtree.NewNKeeper(n)
+	g.NearestSet(k, q)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// InRange returns the values within distance d of the query, as measured by the values'
+// Distance method, in order of increasing distance.
+func (g *Grid) InRange(q kdtree.Comparable, d float64) []kdtree.ComparableDist {
+	k := kdtree.NewDistKeeper(d)
+	g.NearestSet(k, q)
+	res := make([]kdtree.ComparableDist, 0, k.Len()-1)
+	for _, cd := range k.Heap {
+		if cd.Comparable != nil {
+			res = append(res, cd)
+		}
+	}
+	return res
+}
+
+func (g *Grid) search(q kdtree.Comparable, k kdtree.Keeper) {
+	if g.count == 0 {
+		return
+	}
+	qc := make([]float64, g.dims)
+	for d := range qc {
+		qc[d] = g.coord(q, d)
+	}
+	keep := func(c key) {
+		if g.cellDist(qc, c) > k.Max().Dist {
+			return
+		}
+		for _, p := range g.cells[c] {
+			k.Keep(kdtree.ComparableDist{Comparable: p, Dist: q.Distance(p)})
+		}
+	}
+
+	// Search rings of cells outwards from the query's cell while
+	// a ring holds no more cells than are occupied. Every cell beyond
+	// ring r is at least r cell sides from the query.
+	centre := g.keyOf(q)
+	r := 0
+	for ; ; r++ {
+		if r > 0 {
+			if lim := float64(r-1) * g.size; lim*lim > k.Max().Dist {
+				return
+			}
+		}
+		if ringCells(g.dims, r) > len(g.cells) {
+			break
+		}
+		g.ring(centre, r, keep)
+	}
+
+	// Otherwise visit the remaining occupied cells in order of distance.
+	rem := make(byDist, 0, len(g.cells))
+	for c := range g.cells {
+		if chebyshev(c, centre) >= r {
+			rem = append(rem, cellDist{c, g.cellDist(qc, c)})
+		}
+	}
+	sort.Sort(rem)
+	for _, c := range rem {
+		if c.dist > k.Max().Dist {
+			return
+		}
+		keep(c.key)
+	}
+}
+
+type cellDist struct {
+	key  key
+	dist float64
+}
+
+type byDist []cellDist
+
+func (c byDist) Len() int           { return len(c) }
+func (c byDist) Less(i, j int) bool { return c[i].dist < c[j].dist }
+func (c byDist) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// ringCells returns the number of cells at Chebyshev distance r from a cell in
+// dims dimensions.
+func ringCells(dims, r int) int {
+	if r == 0 {
+		return 1
+	}
+	outer, inner := 1, 1
+	for i := 0; i < dims; i++ {
+		outer *= 2*r + 1
+		inner *= 2*r - 1
+	}
+	return outer - inner
+}
+
+// Do performs fn on all values stored in the grid. The Bounding passed to fn is nil and
+// the depth is zero. A boolean is returned indicating whether the Do traversal was
+// interrupted by an Operation returning true.
+func (g *Grid) Do(fn kdtree.Operation) bool {
+	for _, cell := range g.cells {
+		for _, p := range cell {
+			if fn(p, nil, 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DoBounded performs fn on all values stored in the grid that are within the specified
+// bound. If b is nil, the result is the same as a Do. A boolean is returned indicating
+// whether the DoBounded traversal was interrupted by an Operation returning true.
+func (g *Grid) DoBounded(fn kdtree.Operation, b *kdtree.Bounding) bool {
+	if b == nil {
+		return g.Do(fn)
+	}
+	lo, hi := g.keyOf(b[0]), g.keyOf(b[1])
+	n := 1
+	for d := 0; d < g.dims; d++ {
+		if hi[d] < lo[d] {
+			return false
+		}
+		n *= hi[d] - lo[d] + 1
+		if n > len(g.cells) {
+			break
+		}
+	}
+	visit := func(cell []kdtree.Comparable) bool {
+		for _, p := range cell {
+			if b.Contains(p) && fn(p, nil, 0) {
+				return true
+			}
+		}
+		return false
+	}
+	if n > len(g.cells) {
+		for c, cell := range g.cells {
+			if in(c, lo, hi, g.dims) && visit(cell) {
+				return true
+			}
+		}
+		return false
+	}
+	var c key
+	var walk func(d int) bool
+	walk = func(d int) bool {
+		if d == g.dims {
+			return visit(g.cells[c])
+		}
+		for c[d] = lo[d]; c[d] <= hi[d]; c[d]++ {
+			if walk(d + 1) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(0)
+}
+
+// in returns whether the cell c is within the cells lo to hi inclusive.
+func in(c, lo, hi key, dims int) bool {
+	for d := 0; d < dims; d++ {
+		if c[d] < lo[d] || c[d] > hi[d] {
+			return false
+		}
+	}
+	return true
+}
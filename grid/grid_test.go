@@ -0,0 +1,181 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randPoints(rnd *rand.Rand, n, dims int) []kdtree.Point {
+	p := make([]kdtree.Point, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			p[i][d] = rnd.Float64()*200 - 100
+		}
+	}
+	return p
+}
+
+func (s *S) TestNew(c *check.C) {
+	_, err := New(0, 1)
+	c.Check(err, check.Equals, ErrDims)
+	_, err = New(MaxDims+1, 1)
+	c.Check(err, check.Equals, ErrDims)
+	_, err = New(2, 0)
+	c.Check(err, check.Equals, ErrSize)
+
+	g, err := New(2, 5)
+	c.Assert(err, check.Equals, nil)
+	c.Check(g.Dims(), check.Equals, 2)
+	c.Check(g.CellSize(), check.Equals, 5.)
+	c.Check(g.Insert(kdtree.Point{1}), check.Equals, kdtree.ErrPointDims)
+	p, _ := g.Nearest(kdtree.Point{1, 1})
+	c.Check(p, check.IsNil)
+}
+
+func (s *S) TestRingCells(c *check.C) {
+	for dims := 1; dims <= MaxDims; dims++ {
+		g, _ := New(dims, 1)
+		for r := 0; r < 4; r++ {
+			var n int
+			g.ring(key{}, r, func(k key) {
+				c.Check(chebyshev(k, key{}), check.Equals, r)
+				n++
+			})
+			c.Check(n, check.Equals, ringCells(dims, r), check.Commentf("dims=%d r=%d", dims, r))
+		}
+	}
+}
+
+func (s *S) TestNearest(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= MaxDims; dims++ {
+		for _, size := range []float64{0.5, 10, 1000} {
+			g, _ := New(dims, size)
+			pts := randPoints(rnd, 500, dims)
+			for _, p := range pts {
+				c.Assert(g.Insert(p), check.Equals, nil)
+			}
+			c.Check(g.Len(), check.Equals, len(pts))
+			for _, q := range randPoints(rnd, 50, dims) {
+				q[0] *= 2
+				want := pts[0]
+				for _, p := range pts[1:] {
+					if q.Distance(p) < q.Distance(want) {
+						want = p
+					}
+				}
+				got, d := g.Nearest(q)
+				c.Check(got, check.DeepEquals, want)
+				c.Check(d, check.Equals, q.Distance(want))
+
+				k := kdtree.NewNKeeper(5)
+				g.NearestSet(k, q)
+				c.Check(k.Len(), check.Equals, 5)
+				c.Check(sort.IsSorted(sort.Reverse(k)), check.Equals, true)
+				c.Check(k.Heap[0].Comparable, check.DeepEquals, want)
+			}
+		}
+	}
+}
+
+func (s *S) TestInRange(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	g, _ := New(3, 10)
+	pts := randPoints(rnd, 2000, 3)
+	for _, p := range pts {
+		g.Insert(p)
+	}
+	q := kdtree.Point{5, -3, 20}
+	const r = 400.
+	var want int
+	for _, p := range pts {
+		if q.Distance(p) <= r {
+			want++
+		}
+	}
+	got := g.InRange(q, r)
+	c.Check(len(got), check.Equals, want)
+	for i, cd := range got {
+		c.Check(cd.Dist <= r, check.Equals, true)
+		if i > 0 {
+			c.Check(got[i-1].Dist <= cd.Dist, check.Equals, true)
+		}
+	}
+}
+
+func (s *S) TestDoBounded(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	g, _ := New(2, 3)
+	pts := randPoints(rnd, 1000, 2)
+	for _, p := range pts {
+		g.Insert(p)
+	}
+	for _, b := range []*kdtree.Bounding{
+		{kdtree.Point{-20, 30}, kdtree.Point{15, 60}},
+		{kdtree.Point{-1e6, -1e6}, kdtree.Point{1e6, 1e6}},
+		{kdtree.Point{10, 10}, kdtree.Point{0, 0}},
+	} {
+		var want int
+		for _, p := range pts {
+			if b.Contains(p) {
+				want++
+			}
+		}
+		var got int
+		c.Check(g.DoBounded(func(p kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+			c.Check(b.Contains(p), check.Equals, true)
+			got++
+			return false
+		}, b), check.Equals, false)
+		c.Check(got, check.Equals, want)
+	}
+
+	var n int
+	c.Check(g.DoBounded(func(kdtree.Comparable, *kdtree.Bounding, int) bool { n++; return n == 10 }, nil), check.Equals, true)
+	c.Check(n, check.Equals, 10)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	g, _ := New(2, 5)
+	pts := randPoints(rnd, 500, 2)
+	for _, p := range pts {
+		g.Insert(p)
+	}
+	c.Check(g.Remove(kdtree.Point{1000, 1000}), check.Equals, false)
+	c.Check(g.Remove(kdtree.Point{1}), check.Equals, false)
+	for i, j := range rnd.Perm(len(pts)) {
+		c.Assert(g.Remove(pts[j]), check.Equals, true)
+		c.Check(g.Len(), check.Equals, len(pts)-i-1)
+	}
+	c.Check(g.Cells(), check.Equals, 0)
+}
+
+func BenchmarkInRange(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	g, _ := New(3, 5)
+	pts := randPoints(rnd, 1e5, 3)
+	for _, p := range pts {
+		g.Insert(p)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.InRange(pts[i%len(pts)], 25)
+	}
+}
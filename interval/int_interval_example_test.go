@@ -55,3 +55,23 @@ func Example_2() {
 	// Integer-specific interval tree:
 	// [[1,6)#2 [2,4)#1 [3,4)#3 [4,6)#5 [5,8)#6 [5,7)#8]
 }
+
+func Example_stab() {
+	t := &interval.IntTree{}
+	for i, iv := range intIvs {
+		iv.UID = uintptr(i)
+		err := t.Insert(iv, false)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	// With half-open intervals, the intervals containing
+	// the position p are those overlapping [p,p+1).
+	fmt.Println("Intervals containing 5:")
+	fmt.Println(t.Get(IntInterval{Start: 5, End: 6}))
+
+	// Output:
+	// Intervals containing 5:
+	// [[1,6)#2 [4,6)#5 [5,8)#6 [5,7)#8]
+}
@@ -4,6 +4,12 @@
 
 // Package interval implements an interval tree based on an augmented
 // Left-Leaning Red Black tree.
+//
+// Intervals overlapping a query range are found with Get and
+// DoMatching. Stabbing queries, finding the intervals that contain a
+// single position, are performed by querying with an interval of unit
+// length at that position for discrete coordinates, or of zero length
+// for continuous coordinates with closed interval semantics.
 package interval
 
 import (
@@ -0,0 +1,337 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	_ Interface = ImagePoints(nil)
+	_ Extender  = ImagePoint{}
+	_ Interface = Array2s(nil)
+	_ Extender  = Array2{}
+	_ Interface = Array3s(nil)
+	_ Extender  = Array3{}
+)
+
+// An ImagePoint is an image.Point that satisfies the Comparable and Extender interfaces.
+type ImagePoint image.Point
+
+func (p ImagePoint) coord(d Dim) int {
+	if d == 0 {
+		return p.X
+	}
+	return p.Y
+}
+
+// Compare satisfies the Comparable interface. c must be an ImagePoint.
+func (p ImagePoint) Compare(c Comparable, d Dim) float64 {
+	return float64(p.coord(d) - c.(ImagePoint).coord(d))
+}
+
+// Dims returns 2.
+func (p ImagePoint) Dims() int { return 2 }
+
+// Distance returns the squared Euclidean distance between p and c. c must be an ImagePoint.
+func (p ImagePoint) Distance(c Comparable) float64 {
+	q := c.(ImagePoint)
+	dx, dy := float64(p.X-q.X), float64(p.Y-q.Y)
+	return dx*dx + dy*dy
+}
+
+// Extend satisfies the Extender interface.
+func (p ImagePoint) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{p, p}
+	}
+	min, max := b[0].(ImagePoint), b[1].(ImagePoint)
+	if p.X < min.X {
+		min.X = p.X
+	}
+	if p.Y < min.Y {
+		min.Y = p.Y
+	}
+	if p.X > max.X {
+		max.X = p.X
+	}
+	if p.Y > max.Y {
+		max.Y = p.Y
+	}
+	*b = Bounding{min, max}
+	return b
+}
+
+// ImagePoints is a collection of ImagePoint values that satisfies the Interface.
+type ImagePoints []ImagePoint
+
+// Bounds returns the bounding volume of the points.
+func (p ImagePoints) Bounds() *Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p ImagePoints) Index(i int) Comparable         { return p[i] }
+func (p ImagePoints) Len() int                       { return len(p) }
+func (p ImagePoints) Pivot(d Dim) int                { return imagePlane{Dim: d, ImagePoints: p}.Pivot() }
+func (p ImagePoints) Slice(start, end int) Interface { return p[start:end] }
+
+type imagePlane struct {
+	Dim
+	ImagePoints
+}
+
+func (p imagePlane) Less(i, j int) bool {
+	return p.ImagePoints[i].coord(p.Dim) < p.ImagePoints[j].coord(p.Dim)
+}
+func (p imagePlane) Pivot() int { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p imagePlane) Slice(start, end int) SortSlicer {
+	p.ImagePoints = p.ImagePoints[start:end]
+	return p
+}
+func (p imagePlane) Swap(i, j int) {
+	p.ImagePoints[i], p.ImagePoints[j] = p.ImagePoints[j], p.ImagePoints[i]
+}
+
+// An Array2 is a two dimensional point that satisfies the Comparable and Extender interfaces.
+type Array2 [2]float64
+
+// Compare satisfies the Comparable interface. c must be an Array2.
+func (p Array2) Compare(c Comparable, d Dim) float64 { return p[d] - c.(Array2)[d] }
+
+// Dims returns 2.
+func (p Array2) Dims() int { return 2 }
+
+// Distance returns the squared Euclidean distance between p and c. c must be an Array2.
+func (p Array2) Distance(c Comparable) float64 {
+	q := c.(Array2)
+	dx, dy := p[0]-q[0], p[1]-q[1]
+	return dx*dx + dy*dy
+}
+
+// Extend satisfies the Extender interface.
+func (p Array2) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{p, p}
+	}
+	min, max := b[0].(Array2), b[1].(Array2)
+	for d, v := range p {
+		min[d] = math.Min(min[d], v)
+		max[d] = math.Max(max[d], v)
+	}
+	*b = Bounding{min, max}
+	return b
+}
+
+// Array2s is a collection of Array2 values that satisfies the Interface.
+type Array2s []Array2
+
+// Bounds returns the bounding volume of the points.
+func (p Array2s) Bounds() *Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p Array2s) Index(i int) Comparable         { return p[i] }
+func (p Array2s) Len() int                       { return len(p) }
+func (p Array2s) Pivot(d Dim) int                { return array2Plane{Dim: d, Array2s: p}.Pivot() }
+func (p Array2s) Slice(start, end int) Interface { return p[start:end] }
+
+type array2Plane struct {
+	Dim
+	Array2s
+}
+
+func (p array2Plane) Less(i, j int) bool              { return p.Array2s[i][p.Dim] < p.Array2s[j][p.Dim] }
+func (p array2Plane) Pivot() int                      { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p array2Plane) Slice(start, end int) SortSlicer { p.Array2s = p.Array2s[start:end]; return p }
+func (p array2Plane) Swap(i, j int)                   { p.Array2s[i], p.Array2s[j] = p.Array2s[j], p.Array2s[i] }
+
+// An Array3 is a three dimensional point that satisfies the Comparable and Extender interfaces.
+type Array3 [3]float64
+
+// Compare satisfies the Comparable interface. c must be an Array3.
+func (p Array3) Compare(c Comparable, d Dim) float64 { return p[d] - c.(Array3)[d] }
+
+// Dims returns 3.
+func (p Array3) Dims() int { return 3 }
+
+// Distance returns the squared Euclidean distance between p and c. c must be an Array3.
+func (p Array3) Distance(c Comparable) float64 {
+	q := c.(Array3)
+	dx, dy, dz := p[0]-q[0], p[1]-q[1], p[2]-q[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// Extend satisfies the Extender interface.
+func (p Array3) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{p, p}
+	}
+	min, max := b[0].(Array3), b[1].(Array3)
+	for d, v := range p {
+		min[d] = math.Min(min[d], v)
+		max[d] = math.Max(max[d], v)
+	}
+	*b = Bounding{min, max}
+	return b
+}
+
+// Array3s is a collection of Array3 values that satisfies the Interface.
+type Array3s []Array3
+
+// Bounds returns the bounding volume of the points.
+func (p Array3s) Bounds() *Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p Array3s) Index(i int) Comparable         { return p[i] }
+func (p Array3s) Len() int                       { return len(p) }
+func (p Array3s) Pivot(d Dim) int                { return array3Plane{Dim: d, Array3s: p}.Pivot() }
+func (p Array3s) Slice(start, end int) Interface { return p[start:end] }
+
+type array3Plane struct {
+	Dim
+	Array3s
+}
+
+func (p array3Plane) Less(i, j int) bool              { return p.Array3s[i][p.Dim] < p.Array3s[j][p.Dim] }
+func (p array3Plane) Pivot() int                      { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p array3Plane) Slice(start, end int) SortSlicer { p.Array3s = p.Array3s[start:end]; return p }
+func (p array3Plane) Swap(i, j int)                   { p.Array3s[i], p.Array3s[j] = p.Array3s[j], p.Array3s[i] }
+
+// structFields caches the coordinate field indices of struct types used by StructDatum.
+var structFields = struct {
+	sync.Mutex
+	m map[reflect.Type][]int
+}{m: make(map[reflect.Type][]int)}
+
+// StructDatum returns a Datum with coordinates taken from the fields of the struct v
+// that are tagged with a kdtree key holding the field's dimension, and with v as its
+// Value. v may be a struct or a pointer to a struct. Tagged fields must be of a floating
+// point or integer kind and the tagged dimensions must be 0 through n-1 for some n. For
+// example, the following type describes two dimensional Datum values.
+//
+//	type city struct {
+//		Name string
+//		Lon  float64 `kdtree:"0"`
+//		Lat  float64 `kdtree:"1"`
+//	}
+//
+// The coordinates are copied from v when StructDatum is called.
+func StructDatum(v interface{}) (Datum, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return Datum{}, fmt.Errorf("kdtree: nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return Datum{}, fmt.Errorf("kdtree: %T is not a struct", v)
+	}
+	fields, err := coordFields(rv.Type())
+	if err != nil {
+		return Datum{}, err
+	}
+	p := make(Point, len(fields))
+	for d, i := range fields {
+		f := rv.Field(i)
+		switch f.Kind() {
+		case reflect.Float32, reflect.Float64:
+			p[d] = f.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			p[d] = float64(f.Int())
+		default:
+			p[d] = float64(f.Uint())
+		}
+	}
+	return Datum{Point: p, Value: v}, nil
+}
+
+// StructData returns the Data obtained by calling StructDatum on each element of the
+// slice s.
+func StructData(s interface{}) (Data, error) {
+	rv := reflect.ValueOf(s)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("kdtree: %T is not a slice", s)
+	}
+	data := make(Data, rv.Len())
+	for i := range data {
+		var err error
+		data[i], err = StructDatum(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// coordFields returns the indices of the coordinate fields of the struct type t in
+// dimension order.
+func coordFields(t reflect.Type) ([]int, error) {
+	structFields.Lock()
+	defer structFields.Unlock()
+	if f, ok := structFields.m[t]; ok {
+		return f, nil
+	}
+	dims := make(map[int]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("kdtree")
+		if tag == "" {
+			continue
+		}
+		d, err := strconv.Atoi(tag)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("kdtree: invalid dimension tag %q on %s.%s", tag, t, f.Name)
+		}
+		switch f.Type.Kind() {
+		case reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return nil, fmt.Errorf("kdtree: coordinate field %s.%s is not numeric", t, f.Name)
+		}
+		if _, ok := dims[d]; ok {
+			return nil, fmt.Errorf("kdtree: duplicate dimension %d on %s.%s", d, t, f.Name)
+		}
+		dims[d] = i
+	}
+	if len(dims) == 0 {
+		return nil, fmt.Errorf("kdtree: %s has no coordinate fields", t)
+	}
+	fields := make([]int, len(dims))
+	for d := range fields {
+		i, ok := dims[d]
+		if !ok {
+			return nil, fmt.Errorf("kdtree: %s has no field for dimension %d", t, d)
+		}
+		fields[d] = i
+	}
+	structFields.m[t] = fields
+	return fields, nil
+}
@@ -0,0 +1,110 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"image"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestImagePoints(c *check.C) {
+	p := ImagePoints{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}
+	t := New(p, true)
+	c.Check(t.Root.Bounding, check.DeepEquals, &Bounding{ImagePoint{2, 1}, ImagePoint{9, 7}})
+	got, d := t.Nearest(ImagePoint(image.Pt(8, 7)))
+	c.Check(got, check.Equals, ImagePoint{9, 6})
+	c.Check(d, check.Equals, 2.)
+}
+
+func (s *S) TestArrays(c *check.C) {
+	p2 := Array2s{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}
+	t := New(p2, true)
+	c.Check(t.Root.Bounding, check.DeepEquals, &Bounding{Array2{2, 1}, Array2{9, 7}})
+	got, d := t.Nearest(Array2{8, 7})
+	c.Check(got, check.Equals, Array2{9, 6})
+	c.Check(d, check.Equals, 2.)
+
+	p3 := Array3s{{2, 3, 0}, {5, 4, 1}, {9, 6, 2}, {4, 7, 3}, {8, 1, 4}, {7, 2, 5}}
+	t = New(p3, true)
+	c.Check(t.Root.Bounding, check.DeepEquals, &Bounding{Array3{2, 1, 0}, Array3{9, 7, 5}})
+	got, d = t.Nearest(Array3{8, 7, 2})
+	c.Check(got, check.Equals, Array3{9, 6, 2})
+	c.Check(d, check.Equals, 2.)
+}
+
+type city struct {
+	Name string
+	Lon  float64 `kdtree:"0"`
+	Lat  float32 `kdtree:"1"`
+	Pop  int
+}
+
+func (s *S) TestStructData(c *check.C) {
+	cities := []city{
+		{"Adelaide", 138.6, -34.9, 1},
+		{"Brisbane", 153.0, -27.5, 2},
+		{"Darwin", 130.8, -12.5, 3},
+	}
+	data, err := StructData(cities)
+	c.Assert(err, check.Equals, nil)
+	c.Check(data[1].Point, check.DeepEquals, Point{153, float64(float32(-27.5))})
+	t := New(data, false)
+	got, _ := t.Nearest(Point{131, -13})
+	c.Check(got.(Datum).Value.(city).Name, check.Equals, "Darwin")
+
+	d, err := StructDatum(&cities[0])
+	c.Assert(err, check.Equals, nil)
+	c.Check(d.Value, check.Equals, &cities[0])
+
+	type grid struct {
+		Row uint8 `kdtree:"1"`
+		Col int   `kdtree:"0"`
+	}
+	d, err = StructDatum(grid{Row: 3, Col: -2})
+	c.Assert(err, check.Equals, nil)
+	c.Check(d.Point, check.DeepEquals, Point{-2, 3})
+}
+
+func (s *S) TestStructDatumErrors(c *check.C) {
+	type (
+		none struct{ X float64 }
+		gap  struct {
+			X float64
+			Y float64 `kdtree:"1"`
+		}
+		dup struct {
+			X, Y float64 `kdtree:"0"`
+		}
+		bad struct {
+			X float64 `kdtree:"x"`
+		}
+		nonNum struct {
+			X string `kdtree:"0"`
+		}
+		okay struct {
+			X float64 `kdtree:"0"`
+		}
+	)
+	for i, test := range []struct {
+		v   interface{}
+		err string
+	}{
+		{1, "kdtree: int is not a struct"},
+		{(*okay)(nil), `kdtree: nil \*kdtree.okay`},
+		{none{}, "kdtree: kdtree.none has no coordinate fields"},
+		{gap{}, "kdtree: kdtree.gap has no field for dimension 0"},
+		{dup{}, "kdtree: duplicate dimension 0 on kdtree.dup.Y"},
+		{bad{}, `kdtree: invalid dimension tag "x" on kdtree.bad.X`},
+		{nonNum{}, "kdtree: coordinate field kdtree.nonNum.X is not numeric"},
+	} {
+		_, err := StructDatum(test.v)
+		c.Check(err, check.ErrorMatches, test.err, check.Commentf("Test %d", i))
+	}
+	_, err := StructData(okay{})
+	c.Check(err, check.ErrorMatches, "kdtree: kdtree.okay is not a slice")
+	_, err = StructData([]interface{}{okay{}, 1})
+	c.Check(err, check.ErrorMatches, "kdtree: int is not a struct")
+}
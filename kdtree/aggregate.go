@@ -0,0 +1,153 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// An Aggregator accumulates the values passed to a call to Aggregate.
+type Aggregator interface {
+	// Add accumulates the value c.
+	Add(c Comparable)
+}
+
+// A SubtreeAggregator is an Aggregator that is able to accumulate the Summary of a whole
+// subtree computed by the tree's Augmenter.
+type SubtreeAggregator interface {
+	Aggregator
+
+	// AddSubtree accumulates the values of a subtree given its summary,
+	// returning false if the summary could not be used, in which case the
+	// values of the subtree are passed to Add.
+	AddSubtree(summary interface{}) bool
+}
+
+// Aggregate passes each value stored in the tree that is within the bound b to agg without
+// allocating a result set. If b is nil, all values are aggregated. If agg is a
+// SubtreeAggregator, the Summary of each subtree lying entirely within b is passed to
+// AddSubtree in place of its values. Bounding boxes of subtrees are taken from the nodes'
+// bounding volumes when these are present and are otherwise derived from the splitting planes
+// of the tree. Stored values and b must be of a type accepted by Point.Compare. Values are
+// not passed to agg in any particular order.
+func (t *Tree) Aggregate(b *Bounding, agg Aggregator) {
+	if t.Root == nil {
+		return
+	}
+	if b != nil {
+		t.mustMatch(b[0])
+		t.mustMatch(b[1])
+	}
+	sub, _ := agg.(SubtreeAggregator)
+	add := func(c Comparable, _ *Bounding, _ int) bool {
+		agg.Add(c)
+		return false
+	}
+	t.cover(b, func(n *Node) {
+		if sub == nil || n.Summary == nil || !sub.AddSubtree(n.Summary) {
+			n.do(add, 0)
+		}
+	}, func(n *Node) { agg.Add(n.Point) })
+}
+
+// cover calls whole with the root of each maximal subtree of t lying entirely within the
+// bound b, or all of t if b is nil, and single with each other node holding a value within b.
+func (t *Tree) cover(b *Bounding, whole, single func(*Node)) {
+	dims := t.Dims()
+	blo, bhi := make([]float64, dims), make([]float64, dims)
+	for d := range blo {
+		if b == nil {
+			blo[d], bhi[d] = -inf, inf
+			continue
+		}
+		blo[d], bhi[d] = at(b[0], Dim(d)), at(b[1], Dim(d))
+	}
+	lo, hi := make([]float64, dims), make([]float64, dims)
+	for d := range lo {
+		lo[d], hi[d] = -inf, inf
+	}
+	t.Root.cover(whole, single, blo, bhi, lo, hi)
+}
+
+func (n *Node) cover(whole, single func(*Node), blo, bhi, lo, hi []float64) {
+	clo, chi := lo, hi
+	if n.Bounding != nil {
+		clo, chi = make([]float64, len(lo)), make([]float64, len(hi))
+		for d := range clo {
+			clo[d], chi[d] = at(n.Bounding[0], Dim(d)), at(n.Bounding[1], Dim(d))
+		}
+	}
+	inside := true
+	for d := range blo {
+		if clo[d] > bhi[d] || chi[d] < blo[d] {
+			return
+		}
+		if clo[d] < blo[d] || chi[d] > bhi[d] {
+			inside = false
+		}
+	}
+	if inside {
+		whole(n)
+		return
+	}
+
+	split := at(n.Point, n.Plane)
+	if n.Left != nil && blo[n.Plane] <= split {
+		old := hi[n.Plane]
+		hi[n.Plane] = split
+		n.Left.cover(whole, single, blo, bhi, lo, hi)
+		hi[n.Plane] = old
+	}
+	if inBox(n.Point, blo, bhi) {
+		single(n)
+	}
+	if n.Right != nil && bhi[n.Plane] >= split {
+		old := lo[n.Plane]
+		lo[n.Plane] = split
+		n.Right.cover(whole, single, blo, bhi, lo, hi)
+		lo[n.Plane] = old
+	}
+}
+
+// inBox returns whether c lies within the box from lo to hi.
+func inBox(c Comparable, lo, hi []float64) bool {
+	for d := range lo {
+		v := at(c, Dim(d))
+		if v < lo[d] || v > hi[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// An Accumulator is an Aggregator computing the count, sum, minimum and maximum of a value
+// derived from each aggregated point.
+type Accumulator struct {
+	// Value returns the value of c to be
+	// accumulated.
+	Value func(c Comparable) float64
+
+	N             int
+	Sum, Min, Max float64
+}
+
+// Add accumulates the value of c.
+func (a *Accumulator) Add(c Comparable) {
+	v := a.Value(c)
+	if a.N == 0 || v < a.Min {
+		a.Min = v
+	}
+	if a.N == 0 || v > a.Max {
+		a.Max = v
+	}
+	a.N++
+	a.Sum += v
+}
+
+// Mean returns the mean of the accumulated values, or NaN if no values have been accumulated.
+func (a *Accumulator) Mean() float64 {
+	if a.N == 0 {
+		return math.NaN()
+	}
+	return a.Sum / float64(a.N)
+}
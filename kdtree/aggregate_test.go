@@ -0,0 +1,77 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// countAgg is a SubtreeAggregator counting values using counter summaries.
+type countAgg struct {
+	n, subtrees int
+}
+
+func (a *countAgg) Add(Comparable) { a.n++ }
+func (a *countAgg) AddSubtree(s interface{}) bool {
+	a.n += s.(int)
+	a.subtrees++
+	return true
+}
+
+func (s *S) TestAggregate(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 500)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64()}
+	}
+	sum := func(c Comparable) float64 { return c.(Point)[0] + c.(Point)[1] }
+	for _, bounding := range []bool{false, true} {
+		t := New(append(Points(nil), p...), bounding)
+		t.Augment(counter{})
+		var subtrees int
+		for i := 0; i < 50; i++ {
+			x, y := rnd.Float64(), rnd.Float64()
+			b := &Bounding{Point{x, y}, Point{x + rnd.Float64()/2, y + rnd.Float64()/2}}
+
+			want := Accumulator{Value: sum}
+			for _, v := range p {
+				if inBox(v, b[0].(Point), b[1].(Point)) {
+					want.Add(v)
+				}
+			}
+			got := Accumulator{Value: sum}
+			t.Aggregate(b, &got)
+			c.Check(got.N, check.Equals, want.N)
+			c.Check(math.Abs(got.Sum-want.Sum) < 1e-9, check.Equals, true)
+			c.Check(got.Min, check.Equals, want.Min)
+			c.Check(got.Max, check.Equals, want.Max)
+
+			var n countAgg
+			t.Aggregate(b, &n)
+			c.Check(n.n, check.Equals, want.N)
+			subtrees += n.subtrees
+		}
+		c.Check(subtrees > 0, check.Equals, true)
+
+		var n countAgg
+		t.Aggregate(nil, &n)
+		c.Check(n, check.Equals, countAgg{n: len(p), subtrees: 1})
+	}
+
+	var a Accumulator
+	(&Tree{}).Aggregate(nil, &a)
+	c.Check(a.N, check.Equals, 0)
+	c.Check(math.IsNaN(a.Mean()), check.Equals, true)
+
+	t := New(append(Points(nil), wpData...), false)
+	a = Accumulator{Value: func(c Comparable) float64 { return c.(Point)[1] }}
+	t.Aggregate(&Bounding{Point{4, 0}, Point{8, 10}}, &a)
+	c.Check([]float64{float64(a.N), a.Sum, a.Min, a.Max}, check.DeepEquals, []float64{4, 14, 1, 7})
+	c.Check(a.Mean(), check.Equals, 3.5)
+	c.Check(func() { t.Aggregate(&Bounding{Point{0}, Point{1}}, &a) }, check.Panics, ErrDimsMismatch)
+}
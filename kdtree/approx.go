@@ -0,0 +1,57 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// WithEpsilon returns a SearchOption that makes the search approximate by pruning any
+// subtree that cannot hold a value nearer than the current result by more than a factor of
+// 1+eps in Euclidean distance, so each value returned is within 1+eps of the distance of the
+// true result it stands for. A non-positive eps gives an exact search.
+func WithEpsilon(eps float64) SearchOption {
+	return func(c *searchConfig) {
+		if eps < 0 {
+			eps = 0
+		}
+		c.eps = eps
+	}
+}
+
+// WithChecks returns a SearchOption that limits the search to n distance evaluations,
+// returning the best values found when the budget is spent. A non-positive n places no limit
+// on the search.
+func WithChecks(n int) SearchOption {
+	return func(c *searchConfig) { c.checks = n }
+}
+
+// exhausted returns whether the distance evaluation budget of the search is spent.
+func (c *searchConfig) exhausted() bool {
+	return c != nil && c.checks > 0 && c.evaluated >= c.checks
+}
+
+// restart resets the distance evaluation budget for a new query.
+func (c *searchConfig) restart() {
+	if c != nil {
+		c.evaluated = 0
+	}
+}
+
+// reach returns whether the subtree on the far side of a splitting plane at signed distance
+// p from the query q may hold a value within dist of q, the search bound, allowing for the
+// approximation of the search.
+func (c *searchConfig) reach(q Comparable, p, dist float64) bool {
+	if c.exhausted() {
+		return false
+	}
+	d := planeDist(q, p)
+	if c != nil && c.eps != 0 {
+		// planeDist is squared except for EuclideanPoint
+		// queries, so the factor follows it.
+		f := 1 + c.eps
+		if _, ok := q.(EuclideanPoint); !ok {
+			f *= f
+		}
+		d *= f
+	}
+	return d <= dist
+}
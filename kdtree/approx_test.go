@@ -0,0 +1,77 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestWithEpsilon(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := New(randForestPoints(rnd, 1000, 3), false)
+	for _, q := range randForestPoints(rnd, 50, 3) {
+		var exact, loose SearchStats
+		want := t.NearestN(q, 5, WithStats(&exact))
+		c.Check(t.NearestN(q, 5, WithEpsilon(0)), check.DeepEquals, want)
+
+		const eps = 1.
+		got := t.NearestN(q, 5, WithEpsilon(eps), WithStats(&loose))
+		c.Assert(got, check.HasLen, 5)
+		c.Check(loose.Distances <= exact.Distances, check.Equals, true)
+		for i := range got {
+			c.Check(math.Sqrt(got[i].Dist) <= (1+eps)*math.Sqrt(want[i].Dist), check.Equals, true,
+				check.Commentf("%v: got %v want %v", q, got[i], want[i]))
+		}
+
+		p, d := t.Nearest(q, WithEpsilon(eps))
+		c.Check(q.Distance(p), check.Equals, d)
+		c.Check(math.Sqrt(d) <= (1+eps)*math.Sqrt(want[0].Dist), check.Equals, true)
+	}
+
+	e := New(Points{}, false)
+	for _, p := range randForestPoints(rnd, 200, 3) {
+		e.Insert(EuclideanPoint(p), false)
+	}
+	q := EuclideanPoint{0.5, 0.5, 0.5}
+	want := e.NearestN(q, 3)
+	got := e.NearestN(q, 3, WithEpsilon(0.5))
+	for i := range got {
+		c.Check(got[i].Dist <= 1.5*want[i].Dist, check.Equals, true)
+	}
+}
+
+func (s *S) TestWithChecks(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := New(randForestPoints(rnd, 1000, 3), false)
+	q := Point{0.5, 0.5, 0.5}
+	var st SearchStats
+	t.NearestN(q, 5, WithStats(&st))
+	exact := st.Distances
+	for _, n := range []int{1, 10, exact / 2, 2 * exact} {
+		st = SearchStats{}
+		got := t.NearestN(q, 5, WithChecks(n), WithStats(&st))
+		c.Check(st.Distances, check.Equals, min(n, exact))
+		c.Check(len(got), check.Equals, min(n, 5))
+	}
+	st = SearchStats{}
+	p, _ := t.Nearest(q, WithChecks(1), WithStats(&st))
+	c.Check(p, check.DeepEquals, t.Root.Point)
+	c.Check(st.Distances, check.Equals, 1)
+	c.Check(t.NearestN(q, 5, WithChecks(0)), check.DeepEquals, t.NearestN(q, 5))
+
+	// The budget applies to each query.
+	src := make([]Comparable, 10)
+	for i, p := range randForestPoints(rnd, len(src), 3) {
+		src[i] = p
+	}
+	st = SearchStats{}
+	for _, r := range t.Correspondences(src, inf, WithChecks(3), WithStats(&st)) {
+		c.Check(r.OK, check.Equals, true)
+	}
+	c.Check(st.Distances <= 4*len(src), check.Equals, true)
+}
@@ -0,0 +1,89 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// An Augmenter computes summaries of the values held by subtrees, such as counts, sums or
+// extents, allowing queries to use a subtree's summary in place of visiting its values.
+type Augmenter interface {
+	// Summarize returns the summary of a subtree whose root holds c given the
+	// summaries of its left and right subtrees, which are nil for empty subtrees.
+	Summarize(c Comparable, left, right interface{}) interface{}
+}
+
+// Augment sets the Augmenter of the tree to a and computes the Summary of every node. The
+// summaries are subsequently maintained by Insert, Remove, the Handle methods and Rebuild.
+// If a is nil, the summaries are cleared.
+func (t *Tree) Augment(a Augmenter) {
+	t.Augmenter = a
+	t.Root.augment(a)
+}
+
+func (n *Node) augment(a Augmenter) interface{} {
+	if n == nil {
+		return nil
+	}
+	l, r := n.Left.augment(a), n.Right.augment(a)
+	if a == nil {
+		n.Summary = nil
+	} else {
+		n.Summary = a.Summarize(n.Point, l, r)
+	}
+	return n.Summary
+}
+
+// summarize recalculates the summary of n from its point and the summaries of its children.
+func (n *Node) summarize(a Augmenter) {
+	var l, r interface{}
+	if n.Left != nil {
+		l = n.Left.Summary
+	}
+	if n.Right != nil {
+		r = n.Right.Summary
+	}
+	n.Summary = a.Summarize(n.Point, l, r)
+}
+
+// summarizePath recalculates the summaries of the nodes on the insertion path of c, which
+// must be the most recently inserted value.
+func (t *Tree) summarizePath(c Comparable) {
+	var path []*Node
+	for n := t.Root; n != nil; {
+		path = append(path, n)
+		if c.Compare(n.Point, n.Plane) <= 0 {
+			n = n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].summarize(t.Augmenter)
+	}
+}
+
+// fixup holds the state used to update the nodes on the path of a removal.
+type fixup struct {
+	// bounding is whether bounding volumes are being
+	// recalculated. It is set to false if this is not
+	// possible.
+	bounding bool
+
+	// aug is the Augmenter of the tree, if any.
+	aug Augmenter
+}
+
+// fixup returns the state used to update the nodes of t during a removal.
+func (t *Tree) fixup() *fixup {
+	return &fixup{bounding: t.Root.Bounding != nil, aug: t.Augmenter}
+}
+
+// update recalculates the bounding volume and summary of n.
+func (f *fixup) update(n *Node) {
+	if f.bounding {
+		f.bounding = n.rebound()
+	}
+	if f.aug != nil {
+		n.summarize(f.aug)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// counter is an Augmenter maintaining the number of values in each subtree.
+type counter struct{}
+
+func (counter) Summarize(_ Comparable, left, right interface{}) interface{} {
+	n := 1
+	for _, s := range [...]interface{}{left, right} {
+		if s != nil {
+			n += s.(int)
+		}
+	}
+	return n
+}
+
+// checkCounts checks that the summary of every node of t is the size of its subtree.
+func checkCounts(c *check.C, t *Tree) {
+	t.Walk(func(_ []*Node, n *Node) bool {
+		c.Check(n.Summary, check.Equals, n.count())
+		return false
+	})
+	if t.Root != nil {
+		c.Check(t.Root.Summary, check.Equals, t.Count)
+	}
+}
+
+func (s *S) TestAugment(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := New(append(Points(nil), bData...), false)
+	t.Augment(counter{})
+	checkCounts(c, t)
+
+	var handles []*Handle
+	for i := 0; i < 50; i++ {
+		handles = append(handles, t.InsertHandle(Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}, false))
+		t.Insert(bData[rnd.Intn(len(bData))], false)
+	}
+	checkCounts(c, t)
+	for _, p := range bData[:60] {
+		c.Assert(t.Remove(p), check.Equals, true)
+	}
+	checkCounts(c, t)
+	for i, h := range handles {
+		if i%2 == 0 {
+			c.Check(t.RemoveHandle(h), check.Equals, true)
+		} else {
+			c.Check(t.UpdateHandle(h, Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}), check.Equals, true)
+		}
+	}
+	checkCounts(c, t)
+	t.Rebuild(false)
+	checkCounts(c, t)
+
+	t.Augment(nil)
+	t.Walk(func(_ []*Node, n *Node) bool {
+		c.Check(n.Summary, check.IsNil)
+		return false
+	})
+
+	// Summaries are maintained from an empty tree.
+	t = &Tree{}
+	t.Augment(counter{})
+	for _, p := range wpData {
+		t.Insert(p, false)
+	}
+	checkCounts(c, t)
+	for _, p := range wpData {
+		t.Remove(p)
+		checkCounts(c, t)
+	}
+	c.Check(t.Root, check.IsNil)
+}
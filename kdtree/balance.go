@@ -0,0 +1,111 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"sort"
+)
+
+// skewSize is the least size of subtree included in
+// the MaxSkew of a BalanceReport. The skew of small
+// subtrees is necessarily large.
+const skewSize = 16
+
+// SubtreeBalance describes the balance of a subtree.
+type SubtreeBalance struct {
+	// Path is the route from the root of the tree to
+	// the root of the subtree as a sequence of 'L' and
+	// 'R' branches. The path of the root is empty.
+	Path string
+
+	// Size, Left and Right are the number of values in
+	// the subtree and in its left and right subtrees.
+	Size, Left, Right int
+
+	// Skew is |Left-Right|/Size, the fraction of the
+	// subtree's values in excess of a balanced split.
+	Skew float64
+}
+
+// Excess returns the difference between the sizes of the left and right subtrees of s.
+func (s SubtreeBalance) Excess() int {
+	if s.Left > s.Right {
+		return s.Left - s.Right
+	}
+	return s.Right - s.Left
+}
+
+// A BalanceReport describes the balance of a tree.
+type BalanceReport struct {
+	// Size and Height are the number of values in the
+	// tree and its height.
+	Size, Height int
+
+	// OptimalHeight is the height of a perfectly balanced
+	// tree holding Size values.
+	OptimalHeight int
+
+	// MaxSkew is the greatest Skew of any subtree holding
+	// at least 16 values.
+	MaxSkew float64
+
+	// Worst holds the subtrees with the greatest Excess,
+	// in descending order of Excess. Subtrees with equal
+	// Excess are ordered by path.
+	Worst []SubtreeBalance
+}
+
+// BalanceReport returns a report of the balance of the tree, including the n most
+// unbalanced subtrees. For long lived trees subject to insertions and removals, a Height
+// much greater than OptimalHeight or a large MaxSkew indicates that the tree should be
+// rebuilt.
+func (t *Tree) BalanceReport(n int) BalanceReport {
+	r := BalanceReport{Height: t.Height()}
+	var subtrees []SubtreeBalance
+	r.Size = t.Root.balance(nil, func(s SubtreeBalance) {
+		if s.Size >= skewSize && s.Skew > r.MaxSkew {
+			r.MaxSkew = s.Skew
+		}
+		if n > 0 {
+			subtrees = append(subtrees, s)
+		}
+	})
+	if r.Size != 0 {
+		r.OptimalHeight = int(math.Ceil(math.Log2(float64(r.Size + 1))))
+	}
+	sort.Sort(byExcess(subtrees))
+	if len(subtrees) > n {
+		subtrees = subtrees[:n]
+	}
+	r.Worst = subtrees
+	return r
+}
+
+// balance returns the size of the subtree rooted at n, calling fn with the balance of
+// each non-empty subtree.
+func (n *Node) balance(path []byte, fn func(SubtreeBalance)) int {
+	if n == nil {
+		return 0
+	}
+	l := n.Left.balance(append(path, 'L'), fn)
+	r := n.Right.balance(append(path, 'R'), fn)
+	s := SubtreeBalance{Path: string(path), Size: l + r + 1, Left: l, Right: r}
+	s.Skew = float64(s.Excess()) / float64(s.Size)
+	fn(s)
+	return s.Size
+}
+
+type byExcess []SubtreeBalance
+
+func (s byExcess) Len() int { return len(s) }
+func (s byExcess) Less(i, j int) bool {
+	ei, ej := s[i].Excess(), s[j].Excess()
+	if ei != ej {
+		return ei > ej
+	}
+	return s[i].Path < s[j].Path
+}
+func (s byExcess) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,39 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestBalanceReport(c *check.C) {
+	c.Check((&Tree{}).BalanceReport(3), check.DeepEquals, BalanceReport{})
+
+	t := New(append(Points(nil), wpData...), false)
+	r := t.BalanceReport(2)
+	c.Check(r.Size, check.Equals, 6)
+	c.Check(r.Height, check.Equals, 3)
+	c.Check(r.OptimalHeight, check.Equals, 3)
+	c.Check(r.MaxSkew, check.Equals, 0.)
+	c.Check(r.Worst, check.DeepEquals, []SubtreeBalance{
+		{Path: "", Size: 6, Left: 3, Right: 2, Skew: 1. / 6},
+		{Path: "R", Size: 2, Left: 1, Right: 0, Skew: 1. / 2},
+	})
+
+	// A balanced tree with a degenerate right subtree.
+	t = New(append(Points(nil), bData...), false)
+	for i := 0; i < 50; i++ {
+		t.Insert(Point{2 + float64(i), 2 + float64(i), 2 + float64(i)}, false)
+	}
+	r = t.BalanceReport(1)
+	c.Check(r.Size, check.Equals, len(bData)+50)
+	c.Check(r.Height > r.OptimalHeight+40, check.Equals, true)
+	c.Check(r.MaxSkew > 0.9, check.Equals, true)
+	c.Assert(r.Worst, check.HasLen, 1)
+	for _, b := range r.Worst[0].Path {
+		c.Check(b, check.Equals, 'R')
+	}
+	c.Check(r.Worst[0].Excess() >= 40, check.Equals, true)
+}
@@ -0,0 +1,112 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// A Mass is the summary of a subtree maintained by a MassAugmenter.
+type Mass struct {
+	// Weight is the total weight of the values in the subtree.
+	Weight float64
+
+	// Centroid is the weighted centroid of the values.
+	Centroid Point
+
+	// Min and Max are the corners of the bounding box
+	// of the values.
+	Min, Max Point
+}
+
+// A MassAugmenter is an Augmenter that maintains the Mass of each subtree, for use by
+// Barnes–Hut approximations with DoBarnesHut. Values must be of a type accepted by
+// Point.Compare.
+type MassAugmenter struct {
+	// Weight returns the weight of a value. If Weight
+	// is nil, each value has a weight of one.
+	Weight func(Comparable) float64
+}
+
+func (a MassAugmenter) weight(c Comparable) float64 {
+	if a.Weight == nil {
+		return 1
+	}
+	return a.Weight(c)
+}
+
+// Summarize satisfies the Augmenter interface, returning a *Mass.
+func (a MassAugmenter) Summarize(c Comparable, left, right interface{}) interface{} {
+	dims := c.Dims()
+	m := &Mass{Weight: a.weight(c), Centroid: make(Point, dims), Min: make(Point, dims), Max: make(Point, dims)}
+	for d := range m.Centroid {
+		v := at(c, Dim(d))
+		m.Centroid[d] = m.Weight * v
+		m.Min[d], m.Max[d] = v, v
+	}
+	for _, s := range [...]interface{}{left, right} {
+		if s == nil {
+			continue
+		}
+		sm := s.(*Mass)
+		m.Weight += sm.Weight
+		for d := range m.Centroid {
+			m.Centroid[d] += sm.Weight * sm.Centroid[d]
+			m.Min[d] = math.Min(m.Min[d], sm.Min[d])
+			m.Max[d] = math.Max(m.Max[d], sm.Max[d])
+		}
+	}
+	if m.Weight != 0 {
+		for d := range m.Centroid {
+			m.Centroid[d] /= m.Weight
+		}
+	}
+	return m
+}
+
+// DoBarnesHut performs fn on a coarse partition of the values in the tree as seen from q,
+// as used by Barnes–Hut approximations of N-body and force-directed layout computations. A
+// subtree is passed to fn as a single body at its weighted centroid with its total weight,
+// and a nil value, when the ratio of the greatest side of its bounding box to the Euclidean
+// distance from q to its centroid is less than theta. Otherwise the subtree is opened, its
+// root value is passed to fn with its coordinates and weight, and its child subtrees are
+// considered in turn. A theta of zero visits every value individually. A boolean is returned
+// indicating whether the traversal was interrupted by fn returning true.
+//
+// The tree must be augmented by a MassAugmenter. DoBarnesHut panics with ErrDimsMismatch if
+// q does not have the dimensionality of the values in the tree.
+func (t *Tree) DoBarnesHut(fn func(c Comparable, centroid Point, weight float64) (done bool), q Point, theta float64) bool {
+	if t.Root == nil {
+		return false
+	}
+	a, ok := t.Augmenter.(MassAugmenter)
+	if !ok {
+		panic("kdtree: tree not augmented by MassAugmenter")
+	}
+	t.mustMatch(q)
+	return t.Root.doBarnesHut(fn, q, theta, a)
+}
+
+func (n *Node) doBarnesHut(fn func(Comparable, Point, float64) bool, q Point, theta float64, a MassAugmenter) bool {
+	if n == nil {
+		return false
+	}
+	m := n.Summary.(*Mass)
+	if n.Left != nil || n.Right != nil {
+		var size float64
+		for d := range m.Min {
+			size = math.Max(size, m.Max[d]-m.Min[d])
+		}
+		if dist := math.Sqrt(q.Distance(m.Centroid)); size < theta*dist {
+			return fn(nil, m.Centroid, m.Weight)
+		}
+	}
+	p := make(Point, len(q))
+	for d := range p {
+		p[d] = at(n.Point, Dim(d))
+	}
+	if fn(n.Point, p, a.weight(n.Point)) {
+		return true
+	}
+	return n.Left.doBarnesHut(fn, q, theta, a) || n.Right.doBarnesHut(fn, q, theta, a)
+}
@@ -0,0 +1,74 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestMassAugmenter(c *check.C) {
+	weight := func(c Comparable) float64 { return c.(Point)[0] }
+	t := New(append(Points(nil), wpData...), false)
+	t.Augment(MassAugmenter{Weight: weight})
+	m := t.Root.Summary.(*Mass)
+	c.Check(m.Weight, check.Equals, 35.)
+	c.Check(m.Min, check.DeepEquals, Point{2, 1})
+	c.Check(m.Max, check.DeepEquals, Point{9, 7})
+	var cy float64
+	for _, p := range wpData {
+		cy += p[0] * p[1]
+	}
+	c.Check(m.Centroid[0], check.Equals, (4.+25+16+49+64+81)/35)
+	c.Check(m.Centroid[1], check.Equals, cy/35)
+
+	t.Remove(Point{9, 6})
+	m = t.Root.Summary.(*Mass)
+	c.Check(m.Weight, check.Equals, 26.)
+	c.Check(m.Max, check.DeepEquals, Point{8, 7})
+}
+
+func (s *S) TestDoBarnesHut(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 2000)
+	for i := range p {
+		p[i] = Point{rnd.NormFloat64(), rnd.NormFloat64()}
+	}
+	t := New(append(Points(nil), p...), false)
+	c.Check(func() { t.DoBarnesHut(nil, Point{0, 0}, 0.5) }, check.PanicMatches, "kdtree: tree not augmented by MassAugmenter")
+	t.Augment(MassAugmenter{})
+
+	// force returns the inverse square attraction at q and the number of bodies visited.
+	force := func(q Point, theta float64) (f Point, n int) {
+		f = Point{0, 0}
+		t.DoBarnesHut(func(_ Comparable, at Point, w float64) bool {
+			n++
+			d2 := q.Distance(at)
+			if d2 == 0 {
+				return false
+			}
+			for d := range f {
+				f[d] += w * (at[d] - q[d]) / (d2 * math.Sqrt(d2))
+			}
+			return false
+		}, q, theta)
+		return f, n
+	}
+	for i := 0; i < 10; i++ {
+		q := Point{rnd.NormFloat64() * 3, rnd.NormFloat64() * 3}
+		exact, n := force(q, 0)
+		c.Check(n, check.Equals, len(p))
+		approx, m := force(q, 0.5)
+		c.Check(m < n/4, check.Equals, true, check.Commentf("visited %d", m))
+		c.Check(math.Sqrt(exact.Distance(approx)) < 0.05*math.Hypot(exact[0], exact[1]), check.Equals, true)
+	}
+
+	var n int
+	c.Check(t.DoBarnesHut(func(Comparable, Point, float64) bool { n++; return true }, Point{0, 0}, 0.5), check.Equals, true)
+	c.Check(n, check.Equals, 1)
+	c.Check((&Tree{}).DoBarnesHut(nil, Point{0, 0}, 1), check.Equals, false)
+}
@@ -0,0 +1,152 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// A Bloom is a counting Bloom filter over the coordinates of values, used by a tree to
+// answer Has and Get for absent values without a search. A Bloom never reports that a value
+// held by the tree is absent, and reports that an absent value may be present with a false
+// positive rate determined at construction. Counters saturate rather than overflow, leaving
+// a saturated counter set until the filter is rebuilt.
+type Bloom struct {
+	counts []uint8
+	hashes int
+}
+
+// NewBloom returns an empty Bloom sized to hold n values with a false positive rate of p.
+func NewBloom(n int, p float64) *Bloom {
+	if n < 1 {
+		n = 1
+	}
+	if !(0 < p && p < 1) {
+		p = 0.01
+	}
+	m := int(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := int(math.Ceil(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &Bloom{counts: make([]uint8, m), hashes: k}
+}
+
+// UseBloom sets the Bloom filter of the tree to b and adds the values stored in the tree to
+// it, after clearing any values it held. If b is nil, the tree's filter is removed. The
+// filter is maintained as values are inserted into and removed from the tree, and stored
+// values must be of a type accepted by Point.Compare.
+func (t *Tree) UseBloom(b *Bloom) {
+	t.Bloom = b
+	if b == nil {
+		return
+	}
+	b.Reset()
+	if t.Root == nil {
+		return
+	}
+	t.Root.do(func(c Comparable, _ *Bounding, _ int) bool {
+		b.Add(c)
+		return false
+	}, 0)
+}
+
+// Reset clears all values from the filter.
+func (b *Bloom) Reset() {
+	for i := range b.counts {
+		b.counts[i] = 0
+	}
+}
+
+// Add adds the coordinates of c to the filter.
+func (b *Bloom) Add(c Comparable) {
+	b.each(c, func(i int) {
+		if b.counts[i] != math.MaxUint8 {
+			b.counts[i]++
+		}
+	})
+}
+
+// Remove removes the coordinates of c from the filter. c must have been added.
+func (b *Bloom) Remove(c Comparable) {
+	b.each(c, func(i int) {
+		if n := b.counts[i]; n != 0 && n != math.MaxUint8 {
+			b.counts[i]--
+		}
+	})
+}
+
+// MayContain returns whether a value with the coordinates of c may have been added to the
+// filter. If MayContain returns false, no such value has been added.
+func (b *Bloom) MayContain(c Comparable) bool {
+	ok := true
+	b.each(c, func(i int) { ok = ok && b.counts[i] != 0 })
+	return ok
+}
+
+// each calls fn with the index of each counter for the coordinates of c, using double
+// hashing of the FNV-1a hash of the coordinates.
+func (b *Bloom) each(c Comparable, fn func(int)) {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+	h := uint64(offset)
+	for d := 0; d < c.Dims(); d++ {
+		x := at(c, Dim(d))
+		if x == 0 {
+			// Equal coordinates must hash equally.
+			x = 0
+		}
+		bits := math.Float64bits(x)
+		for i := uint(0); i < 64; i += 8 {
+			h ^= (bits >> i) & 0xff
+			h *= prime
+		}
+	}
+	h1, h2 := h, h>>33|h<<31|1
+	m := uint64(len(b.counts))
+	for i := 0; i < b.hashes; i++ {
+		fn(int((h1 + uint64(i)*h2) % m))
+	}
+}
+
+// Has returns whether the tree holds a value with the same coordinates as c. If the tree has
+// a Bloom filter, values that the filter reports absent are rejected without a search.
+func (t *Tree) Has(c Comparable) bool {
+	_, ok := t.Get(c)
+	return ok
+}
+
+// Get returns a value stored in the tree with the same coordinates as c and whether one was
+// found. If the tree has a Bloom filter, values that the filter reports absent are rejected
+// without a search.
+func (t *Tree) Get(c Comparable) (Comparable, bool) {
+	if t.Root == nil || c.Dims() != t.Dims() {
+		return nil, false
+	}
+	if t.Bloom != nil && !t.Bloom.MayContain(c) {
+		return nil, false
+	}
+	path := t.Root.findPath(c, nil, nil)
+	if path == nil {
+		return nil, false
+	}
+	return path[len(path)-1].Point, true
+}
+
+// inserted notifies the Bloom filter and Observer of the tree of the insertion of c.
+func (t *Tree) inserted(c Comparable) {
+	if t.Bloom != nil {
+		t.Bloom.Add(c)
+	}
+	t.Observer.inserted(c)
+}
+
+// removed notifies the Bloom filter and Observer of the tree of the removal of c.
+func (t *Tree) removed(c Comparable) {
+	if t.Bloom != nil {
+		t.Bloom.Remove(c)
+	}
+	t.Observer.removed(c)
+}
@@ -0,0 +1,72 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHasGet(c *check.C) {
+	t := New(Data{
+		{Point: Point{2, 3}, Value: "a"},
+		{Point: Point{5, 4}, Value: "b"},
+		{Point: Point{9, 6}, Value: "c"},
+	}, false)
+	for _, b := range []*Bloom{nil, NewBloom(10, 0.01)} {
+		t.UseBloom(b)
+		v, ok := t.Get(Point{5, 4})
+		c.Check(ok, check.Equals, true)
+		c.Check(v.(Datum).Value, check.Equals, "b")
+		c.Check(t.Has(Point{math.Copysign(0, -1) + 9, 6}), check.Equals, true)
+		c.Check(t.Has(Point{5, 5}), check.Equals, false)
+		c.Check(t.Has(Point{5}), check.Equals, false)
+	}
+	c.Check((&Tree{}).Has(Point{0}), check.Equals, false)
+}
+
+func (s *S) TestBloom(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	const n = 1000
+	t := &Tree{}
+	t.UseBloom(NewBloom(n, 0.01))
+	var p Points
+	for i := 0; i < n/2; i++ {
+		p = append(p, Point{rnd.Float64(), rnd.Float64()})
+		t.Insert(p[len(p)-1], false)
+	}
+	for _, v := range p {
+		c.Check(t.Bloom.MayContain(v), check.Equals, true)
+	}
+
+	// Values held before the filter is set are added.
+	t = New(append(Points(nil), p...), false)
+	t.UseBloom(NewBloom(n, 0.01))
+	for i := 0; i < n/2; i++ {
+		p = append(p, Point{rnd.Float64(), rnd.Float64()})
+		t.InsertHandle(p[len(p)-1], false)
+	}
+	t.Rebuild(false)
+	for _, v := range p {
+		c.Check(t.Bloom.MayContain(v), check.Equals, true)
+		c.Check(t.Has(v), check.Equals, true)
+	}
+	var fp int
+	for i := 0; i < 10000; i++ {
+		if t.Bloom.MayContain(Point{rnd.Float64(), rnd.Float64()}) {
+			fp++
+		}
+	}
+	c.Check(fp < 300, check.Equals, true, check.Commentf("false positives: %d", fp))
+
+	for _, v := range p {
+		c.Check(t.Remove(v), check.Equals, true)
+	}
+	for _, v := range p {
+		c.Check(t.Bloom.MayContain(v), check.Equals, false)
+	}
+}
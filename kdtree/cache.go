@@ -0,0 +1,182 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"container/list"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+var _ SpatialIndex = (*Cache)(nil)
+
+// A Cache is a SpatialIndex that memoizes the results of Nearest, NearestN and InRange
+// queries on an underlying index in a least recently used cache, for workloads such as tile
+// servers where identical queries are repeated. Queries are keyed by their type, the
+// coordinates of the query and their parameters, so query values must be of a type accepted
+// by Point.Compare. The cache is cleared when a value is inserted into or removed from the
+// index through the Cache; mutations made directly to the underlying index must be followed
+// by a call to Invalidate. DoBounded traversals are not cached.
+//
+// The Cache is safe for concurrent queries if the underlying index is. Results returned from
+// NearestN and InRange are copies that may be altered by the caller.
+type Cache struct {
+	index SpatialIndex
+	size  int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+
+	// gen is incremented when the cache is
+	// invalidated so that results of queries
+	// racing with a mutation are not cached.
+	gen int
+}
+
+// cacheEntry is a memoized query result.
+type cacheEntry struct {
+	key     string
+	nearest ComparableDist
+	results []ComparableDist
+}
+
+// Query types of cache keys.
+const (
+	nearestQuery byte = iota
+	nearestNQuery
+	inRangeQuery
+)
+
+// NewCache returns a Cache holding up to size query results for index.
+func NewCache(index SpatialIndex, size int) *Cache {
+	return &Cache{
+		index:   index,
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Stats returns the number of queries answered from and not found in the cache.
+func (c *Cache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Invalidate clears the cache.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+	c.gen++
+	c.mu.Unlock()
+}
+
+// cacheKey returns the cache key of a query of type kind at q with the parameter p.
+func cacheKey(kind byte, q Comparable, p float64) string {
+	b := make([]byte, 1, 1+8*(q.Dims()+1))
+	b[0] = kind
+	var buf [8]byte
+	for d := 0; d < q.Dims(); d++ {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(at(q, Dim(d))))
+		b = append(b, buf[:]...)
+	}
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(p))
+	return string(append(b, buf[:]...))
+}
+
+// get returns the cached entry for k, if it exists, and the current generation of the cache.
+func (c *Cache) get(k string) (*cacheEntry, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	if !ok {
+		c.misses++
+		return nil, c.gen, false
+	}
+	c.hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry), c.gen, true
+}
+
+// put adds e, found in generation gen, to the cache, evicting the least recently used entry
+// if the cache is full.
+func (c *Cache) put(e *cacheEntry, gen int) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[e.key]; ok || gen != c.gen {
+		return
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	if c.lru.Len() > c.size {
+		old := c.lru.Remove(c.lru.Back()).(*cacheEntry)
+		delete(c.entries, old.key)
+	}
+}
+
+// Insert adds c to the underlying index, clearing the cache if it is added.
+func (c *Cache) Insert(v Comparable) error {
+	err := c.index.Insert(v)
+	if err == nil {
+		c.Invalidate()
+	}
+	return err
+}
+
+// Remove removes a single value from the underlying index that has the same coordinates as
+// v, clearing the cache if a value was removed.
+func (c *Cache) Remove(v Comparable) bool {
+	ok := c.index.Remove(v)
+	if ok {
+		c.Invalidate()
+	}
+	return ok
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (c *Cache) Nearest(q Comparable) (Comparable, float64) {
+	k := cacheKey(nearestQuery, q, 0)
+	e, gen, ok := c.get(k)
+	if ok {
+		return e.nearest.Comparable, e.nearest.Dist
+	}
+	v, d := c.index.Nearest(q)
+	c.put(&cacheEntry{key: k, nearest: ComparableDist{Comparable: v, Dist: d}}, gen)
+	return v, d
+}
+
+// NearestN returns the n nearest values to the query in min sorted order.
+func (c *Cache) NearestN(q Comparable, n int) []ComparableDist {
+	return c.results(cacheKey(nearestNQuery, q, float64(n)), func() []ComparableDist { return c.index.NearestN(q, n) })
+}
+
+// InRange returns the values within distance d of the query in min sorted order.
+func (c *Cache) InRange(q Comparable, d float64) []ComparableDist {
+	return c.results(cacheKey(inRangeQuery, q, d), func() []ComparableDist { return c.index.InRange(q, d) })
+}
+
+func (c *Cache) results(k string, query func() []ComparableDist) []ComparableDist {
+	e, gen, ok := c.get(k)
+	if ok {
+		return append([]ComparableDist(nil), e.results...)
+	}
+	r := query()
+	c.put(&cacheEntry{key: k, results: append([]ComparableDist(nil), r...)}, gen)
+	return r
+}
+
+// DoBounded performs fn on all values within the specified bound in the underlying index.
+func (c *Cache) DoBounded(fn Operation, b *Bounding) bool { return c.index.DoBounded(fn, b) }
+
+// Len returns the number of values in the underlying index.
+func (c *Cache) Len() int { return c.index.Len() }
@@ -0,0 +1,71 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCache(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	cache := NewCache(t.Index(false), 2)
+
+	nn, d := cache.Nearest(Point{8, 7})
+	c.Check(nn, check.DeepEquals, Point{9, 6})
+	c.Check(d, check.Equals, 2.)
+	nn, d = cache.Nearest(Point{8, 7})
+	c.Check(nn, check.DeepEquals, Point{9, 6})
+	c.Check(d, check.Equals, 2.)
+	hits, misses := cache.Stats()
+	c.Check([]int{hits, misses}, check.DeepEquals, []int{1, 1})
+
+	// Queries of different types and parameters are distinct.
+	r := cache.NearestN(Point{8, 7}, 2)
+	c.Check(r, check.HasLen, 2)
+	r[0].Dist = -1
+	c.Check(cache.NearestN(Point{8, 7}, 2)[0].Dist, check.Equals, 2.)
+	c.Check(cache.NearestN(Point{8, 7}, 3), check.HasLen, 3)
+	hits, misses = cache.Stats()
+	c.Check([]int{hits, misses}, check.DeepEquals, []int{2, 3})
+
+	// The least recently used entry is evicted.
+	cache.Nearest(Point{8, 7})
+	hits, misses = cache.Stats()
+	c.Check([]int{hits, misses}, check.DeepEquals, []int{2, 4})
+
+	c.Check(cache.InRange(Point{8, 1}, 2), check.HasLen, 2)
+	c.Check(cache.Insert(Point{8, 2}), check.IsNil)
+	c.Check(cache.InRange(Point{8, 1}, 2), check.HasLen, 3)
+	c.Check(cache.Len(), check.Equals, 7)
+	c.Check(cache.Remove(Point{8, 2}), check.Equals, true)
+	c.Check(cache.Remove(Point{8, 2}), check.Equals, false)
+	c.Check(cache.InRange(Point{8, 1}, 2), check.HasLen, 2)
+
+	// Direct mutations require invalidation.
+	t.Insert(Point{8, 2}, false)
+	c.Check(cache.InRange(Point{8, 1}, 2), check.HasLen, 2)
+	cache.Invalidate()
+	c.Check(cache.InRange(Point{8, 1}, 2), check.HasLen, 3)
+
+	var n int
+	cache.DoBounded(func(Comparable, *Bounding, int) bool { n++; return false }, &Bounding{Point{0, 0}, Point{5, 5}})
+	c.Check(n, check.Equals, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.NearestN(Point{float64(j % 10), float64(i)}, 3)
+			}
+		}(i)
+	}
+	wg.Wait()
+	hits, misses = cache.Stats()
+	c.Check(hits+misses > 800, check.Equals, true)
+}
@@ -0,0 +1,128 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cluster provides clustering of points using k-d trees.
+package cluster
+
+import (
+	"math/rand"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// Seed returns k initial centroids chosen from p by k-means++ seeding. The first centroid
+// is chosen uniformly at random and each subsequent centroid is chosen with probability
+// proportional to the squared distance of a point from its nearest chosen centroid. If p
+// holds fewer than k distinct points, fewer than k centroids are returned. The returned
+// centroids are copies of points in p.
+func Seed(p []kdtree.Point, k int, rnd *rand.Rand) []kdtree.Point {
+	if len(p) == 0 || k <= 0 {
+		return nil
+	}
+	centroids := []kdtree.Point{clone(p[rnd.Intn(len(p))])}
+	dist := make([]float64, len(p))
+	var sum float64
+	for i, v := range p {
+		dist[i] = v.Distance(centroids[0])
+		sum += dist[i]
+	}
+	for len(centroids) < k && sum > 0 {
+		r := rnd.Float64() * sum
+		next := -1
+		for i, d := range dist {
+			if d == 0 {
+				continue
+			}
+			next = i
+			if r -= d; r < 0 {
+				break
+			}
+		}
+		c := clone(p[next])
+		centroids = append(centroids, c)
+		sum = 0
+		for i, v := range p {
+			if d := v.Distance(c); d < dist[i] {
+				dist[i] = d
+			}
+			sum += dist[i]
+		}
+	}
+	return centroids
+}
+
+func clone(p kdtree.Point) kdtree.Point { return append(kdtree.Point(nil), p...) }
+
+// Assign returns the index of the nearest centroid to each point in p. Nearest centroids
+// are found by searching a k-d tree of the centroids, with ties resolved as described for
+// kdtree.Tree.Nearest.
+func Assign(p []kdtree.Point, centroids []kdtree.Point) []int {
+	if len(centroids) == 0 {
+		return nil
+	}
+	data := make(kdtree.Data, len(centroids))
+	for i, c := range centroids {
+		data[i] = kdtree.Datum{Point: c, Value: i}
+	}
+	t := kdtree.New(data, false)
+	assign := make([]int, len(p))
+	for i, v := range p {
+		c, _ := t.Nearest(v)
+		assign[i] = c.(kdtree.Datum).Value.(int)
+	}
+	return assign
+}
+
+// KMeans partitions p into at most k clusters using Lloyd's algorithm from centroids
+// chosen by Seed, performing at most maxIter iterations of assignment. It returns the
+// centroids of the clusters and the index of the cluster to which each point is assigned.
+// The centroid of a cluster that becomes empty is left unchanged.
+func KMeans(p []kdtree.Point, k, maxIter int, rnd *rand.Rand) (centroids []kdtree.Point, assign []int) {
+	centroids = Seed(p, k, rnd)
+	if len(centroids) == 0 {
+		return nil, nil
+	}
+	dims := len(p[0])
+	for iter := 0; iter < maxIter; iter++ {
+		next := Assign(p, centroids)
+		if assign != nil && equal(assign, next) {
+			break
+		}
+		assign = next
+		sums := make([]kdtree.Point, len(centroids))
+		counts := make([]int, len(centroids))
+		for i, v := range p {
+			c := assign[i]
+			if sums[c] == nil {
+				sums[c] = make(kdtree.Point, dims)
+			}
+			for d, x := range v {
+				sums[c][d] += x
+			}
+			counts[c]++
+		}
+		for c, s := range sums {
+			if counts[c] == 0 {
+				continue
+			}
+			for d := range s {
+				s[d] /= float64(counts[c])
+			}
+			centroids[c] = s
+		}
+	}
+	if assign == nil {
+		assign = Assign(p, centroids)
+	}
+	return centroids, assign
+}
+
+func equal(a, b []int) bool {
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
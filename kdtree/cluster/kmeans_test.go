@@ -0,0 +1,100 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+// blobs returns n points in each of the Gaussian clusters centred on centres.
+func blobs(rnd *rand.Rand, centres []kdtree.Point, n int, sd float64) (p []kdtree.Point, labels []int) {
+	for i := 0; i < n; i++ {
+		for c, m := range centres {
+			v := make(kdtree.Point, len(m))
+			for d := range v {
+				v[d] = m[d] + rnd.NormFloat64()*sd
+			}
+			p = append(p, v)
+			labels = append(labels, c)
+		}
+	}
+	return p, labels
+}
+
+var centres = []kdtree.Point{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+
+func (s *S) TestSeed(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	c.Check(Seed(nil, 3, rnd), check.HasLen, 0)
+	c.Check(Seed([]kdtree.Point{{1, 1}, {1, 1}}, 3, rnd), check.DeepEquals, []kdtree.Point{{1, 1}})
+
+	p, _ := blobs(rnd, centres, 100, 0.5)
+	// k-means++ almost always seeds one centroid in each well separated cluster.
+	var hits int
+	for i := 0; i < 20; i++ {
+		seeds := Seed(p, 4, rnd)
+		c.Assert(seeds, check.HasLen, 4)
+		seen := make(map[int]bool)
+		for _, a := range Assign(seeds, centres) {
+			seen[a] = true
+		}
+		if len(seen) == 4 {
+			hits++
+		}
+	}
+	c.Check(hits >= 15, check.Equals, true)
+}
+
+func (s *S) TestAssign(c *check.C) {
+	p := []kdtree.Point{{0, 1}, {9, 9}, {11, 1}, {5, 5}}
+	c.Check(Assign(p, centres), check.DeepEquals, []int{0, 3, 1, 0})
+	c.Check(Assign(p, nil), check.HasLen, 0)
+}
+
+func (s *S) TestKMeans(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p, labels := blobs(rnd, centres, 100, 1)
+	cents, assign := KMeans(p, 4, 100, rnd)
+	c.Assert(cents, check.HasLen, 4)
+	c.Assert(assign, check.HasLen, len(p))
+
+	// Each recovered centroid is near a true centre.
+	for _, m := range cents {
+		best := math.Inf(1)
+		for _, t := range centres {
+			best = math.Min(best, m.Distance(t))
+		}
+		c.Check(best < 0.5, check.Equals, true)
+	}
+	// Clusters agree with the generating labels up to renaming.
+	rename := make(map[int]int)
+	var agree int
+	for i, a := range assign {
+		if _, ok := rename[a]; !ok {
+			rename[a] = labels[i]
+		}
+		if rename[a] == labels[i] {
+			agree++
+		}
+	}
+	c.Check(float64(agree)/float64(len(p)) > 0.99, check.Equals, true)
+
+	cents, assign = KMeans(nil, 4, 10, rnd)
+	c.Check(cents, check.HasLen, 0)
+	c.Check(assign, check.HasLen, 0)
+}
@@ -0,0 +1,112 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"github.com/biogo/store/kdtree"
+)
+
+// A ShiftOption modifies the behaviour of MeanShift.
+type ShiftOption func(*shiftConfig)
+
+type shiftConfig struct {
+	kernel  func(dist float64) float64
+	maxIter int
+	tol     float64
+}
+
+// WithKernel specifies the kernel used to weight the contribution of each value within the
+// search radius to the mean, as a function of its distance from the current estimate of the
+// mode. The default kernel weights all values equally.
+func WithKernel(fn func(dist float64) float64) ShiftOption {
+	return func(c *shiftConfig) { c.kernel = fn }
+}
+
+// WithMaxIter specifies the maximum number of shifts made from each starting point. The
+// default is 300.
+func WithMaxIter(n int) ShiftOption {
+	return func(c *shiftConfig) { c.maxIter = n }
+}
+
+// WithTolerance specifies the shift distance below which a mode is considered to have
+// converged. The default is 1e-6 of the search radius.
+func WithTolerance(tol float64) ShiftOption {
+	return func(c *shiftConfig) { c.tol = tol }
+}
+
+// MeanShift finds the modes of the density of the values in t by mean-shift, starting from
+// each value and repeatedly moving to the kernel weighted mean of the values within radius
+// of the current position, found with t.InRange. Distances, including radius, are measured
+// by the values' Distance method and values must be comparable with kdtree.Point values.
+// Converged positions within radius of an earlier mode are merged into that mode.
+//
+// MeanShift returns the modes and, for each value in the order returned by t.Points, the
+// index of the mode to which it converged.
+func MeanShift(t *kdtree.Tree, radius float64, opts ...ShiftOption) (modes []kdtree.Point, assign []int) {
+	cfg := shiftConfig{maxIter: 300, tol: radius * 1e-6}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	values := t.Points()
+	if len(values) == 0 {
+		return nil, nil
+	}
+	dims := values[0].Dims()
+	zero := make(kdtree.Point, dims)
+	assign = make([]int, len(values))
+	for i, v := range values {
+		x := make(kdtree.Point, dims)
+		for d := range x {
+			x[d] = v.Compare(zero, kdtree.Dim(d))
+		}
+		x = shift(t, x, radius, cfg)
+		assign[i] = -1
+		for j, m := range modes {
+			if m.Distance(x) <= radius {
+				assign[i] = j
+				break
+			}
+		}
+		if assign[i] < 0 {
+			assign[i] = len(modes)
+			modes = append(modes, x)
+		}
+	}
+	return modes, assign
+}
+
+// shift returns the mode reached from x.
+func shift(t *kdtree.Tree, x kdtree.Point, radius float64, cfg shiftConfig) kdtree.Point {
+	zero := make(kdtree.Point, len(x))
+	next := make(kdtree.Point, len(x))
+	for iter := 0; iter < cfg.maxIter; iter++ {
+		for d := range next {
+			next[d] = 0
+		}
+		var sum float64
+		for _, nd := range t.InRange(x, radius) {
+			w := 1.
+			if cfg.kernel != nil {
+				w = cfg.kernel(nd.Dist)
+			}
+			for d := range next {
+				next[d] += w * nd.Comparable.Compare(zero, kdtree.Dim(d))
+			}
+			sum += w
+		}
+		if sum == 0 {
+			break
+		}
+		for d := range next {
+			next[d] /= sum
+		}
+		moved := next.Distance(x)
+		x, next = next, x
+		if moved <= cfg.tol {
+			break
+		}
+	}
+	return x
+}
@@ -0,0 +1,46 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestMeanShift(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p, _ := blobs(rnd, centres, 50, 0.5)
+	data := make(kdtree.Points, len(p))
+	copy(data, p)
+	t := kdtree.New(data, false)
+
+	for _, opts := range [][]ShiftOption{
+		nil,
+		{WithKernel(func(d float64) float64 { return math.Exp(-d / 2) })},
+	} {
+		modes, assign := MeanShift(t, 9, opts...)
+		c.Assert(modes, check.HasLen, 4)
+		values := t.Points()
+		c.Assert(assign, check.HasLen, len(values))
+		for _, m := range modes {
+			near := Assign([]kdtree.Point{m}, centres)[0]
+			c.Check(m.Distance(centres[near]) < 0.1, check.Equals, true)
+		}
+		for i, v := range values {
+			c.Check(Assign([]kdtree.Point{v.(kdtree.Point)}, modes)[0], check.Equals, assign[i])
+		}
+	}
+
+	modes, assign := MeanShift(&kdtree.Tree{}, 1)
+	c.Check(modes, check.HasLen, 0)
+	c.Check(assign, check.HasLen, 0)
+
+	modes, _ = MeanShift(kdtree.New(kdtree.Points{{0, 0}, {1, 0}}, false), 1, WithMaxIter(0))
+	c.Check(modes, check.DeepEquals, []kdtree.Point{{0, 0}})
+}
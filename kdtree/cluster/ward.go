@@ -0,0 +1,192 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Merge is a step of an agglomerative clustering, joining the clusters A and B. Clusters
+// with identifiers less than the number of clustered points are the single points with that
+// index, and the cluster formed by the ith merge of a dendrogram has the identifier i plus
+// the number of clustered points.
+type Merge struct {
+	A, B int
+
+	// Dist is the linkage distance between
+	// the merged clusters.
+	Dist float64
+
+	// Size is the number of points in the
+	// merged cluster.
+	Size int
+}
+
+// cluster is a cluster of points held by the centroid tree.
+type cluster struct {
+	centroid kdtree.Point
+	size     int
+
+	// rep is the least index of
+	// the points in the cluster.
+	rep int
+
+	h *kdtree.Handle
+}
+
+func centroidOf(c kdtree.Comparable) kdtree.Comparable {
+	if v, ok := c.(*cluster); ok {
+		return v.centroid
+	}
+	return c
+}
+
+func (c *cluster) Compare(v kdtree.Comparable, d kdtree.Dim) float64 {
+	return c.centroid.Compare(centroidOf(v), d)
+}
+func (c *cluster) Dims() int                            { return len(c.centroid) }
+func (c *cluster) Distance(v kdtree.Comparable) float64 { return c.centroid.Distance(centroidOf(v)) }
+
+// ward returns the Ward linkage distance between a and b.
+func ward(a, b *cluster) float64 {
+	na, nb := float64(a.size), float64(b.size)
+	return na * nb / (na + nb) * a.centroid.Distance(b.centroid)
+}
+
+// Ward returns the dendrogram of the agglomerative clustering of p using Ward's minimum
+// variance linkage, under which the distance between two clusters is the increase in the
+// total within-cluster sum of squared distances caused by merging them. Merges are returned
+// in order of increasing distance.
+//
+// The dendrogram is found by the nearest neighbour chain algorithm, with the centroids of
+// the current clusters held in a k-d tree that is updated as clusters are merged. The Ward
+// nearest neighbour of a cluster of size n is found among the centroids within squared
+// distance w*(n+1)/n of its centroid, where w is its Ward distance from the cluster with the
+// nearest centroid.
+func Ward(p []kdtree.Point) []Merge {
+	if len(p) < 2 {
+		return nil
+	}
+	t := &kdtree.Tree{}
+	active := make([]*cluster, len(p))
+	// Points are inserted in random order so that
+	// the tree is balanced in expectation.
+	for _, i := range rand.New(rand.NewSource(1)).Perm(len(p)) {
+		c := &cluster{centroid: clone(p[i]), size: 1, rep: i}
+		c.h = t.InsertHandle(c, false)
+		active[i] = c
+	}
+
+	steps := make([]step, 0, len(p)-1)
+	var chain []*cluster
+	for len(steps) < len(p)-1 {
+		if len(chain) == 0 {
+			for len(active) != 0 && !active[len(active)-1].h.Valid() {
+				active = active[:len(active)-1]
+			}
+			chain = append(chain, active[len(active)-1])
+		}
+		a := chain[len(chain)-1]
+		var prev *cluster
+		if len(chain) > 1 {
+			prev = chain[len(chain)-2]
+		}
+		b, dist := nearest(t, a, prev)
+		if b != prev {
+			chain = append(chain, b)
+			continue
+		}
+		chain = chain[:len(chain)-2]
+		t.RemoveHandle(a.h)
+		t.RemoveHandle(b.h)
+		m := &cluster{centroid: make(kdtree.Point, len(a.centroid)), size: a.size + b.size, rep: a.rep}
+		if b.rep < m.rep {
+			m.rep = b.rep
+		}
+		for d := range m.centroid {
+			m.centroid[d] = (float64(a.size)*a.centroid[d] + float64(b.size)*b.centroid[d]) / float64(m.size)
+		}
+		m.h = t.InsertHandle(m, false)
+		active = append(active, m)
+		steps = append(steps, step{a: a.rep, b: b.rep, dist: dist, size: m.size})
+	}
+
+	// Merges found by the chain are not in order of distance.
+	// Ward linkage is reducible, so the sorted merges form the
+	// same dendrogram with clusters relabelled in merge order.
+	sort.Stable(byDist(steps))
+	parent := make([]int, len(p))
+	label := make([]int, len(p))
+	for i := range parent {
+		parent[i], label[i] = i, i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	merges := make([]Merge, len(steps))
+	for i, s := range steps {
+		ra, rb := find(s.a), find(s.b)
+		a, b := label[ra], label[rb]
+		if b < a {
+			a, b = b, a
+		}
+		merges[i] = Merge{A: a, B: b, Dist: s.dist, Size: s.size}
+		parent[rb] = ra
+		label[ra] = len(p) + i
+	}
+	return merges
+}
+
+// step is a merge of the clusters with the representative points a and b.
+type step struct {
+	a, b int
+	dist float64
+	size int
+}
+
+type byDist []step
+
+func (s byDist) Len() int           { return len(s) }
+func (s byDist) Less(i, j int) bool { return s[i].dist < s[j].dist }
+func (s byDist) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// nearest returns the cluster in t other than a with the least Ward linkage distance from a,
+// and that distance. Ties are resolved in favour of prev, and then of the cluster with the
+// least representative point, so the nearest neighbour chain is guaranteed to terminate.
+func nearest(t *kdtree.Tree, a, prev *cluster) (*cluster, float64) {
+	var bound float64
+	for _, nd := range t.NearestN(a, 2) {
+		if nd.Comparable != a {
+			bound = ward(a, nd.Comparable.(*cluster))
+			break
+		}
+	}
+	// Clusters at no greater Ward distance than the nearest
+	// centroid are within the squared distance bound*(n+1)/n,
+	// which is widened to include ties despite rounding.
+	n := float64(a.size)
+	var (
+		best *cluster
+		dist float64
+	)
+	for _, nd := range t.InRange(a, bound*(n+1)/n*(1+1e-9)) {
+		c := nd.Comparable.(*cluster)
+		if c == a {
+			continue
+		}
+		d := ward(a, c)
+		if best == nil || d < dist || (d == dist && best != prev && (c == prev || c.rep < best.rep)) {
+			best, dist = c, d
+		}
+	}
+	return best, dist
+}
@@ -0,0 +1,92 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// naiveWard returns the Ward dendrogram of p by repeatedly merging the closest pair of
+// clusters.
+func naiveWard(p []kdtree.Point) []Merge {
+	var clusters []*cluster
+	for i, v := range p {
+		clusters = append(clusters, &cluster{centroid: clone(v), size: 1, rep: i})
+	}
+	var merges []Merge
+	for len(clusters) > 1 {
+		bi, bj, best := 0, 1, math.Inf(1)
+		for i, a := range clusters {
+			for j := i + 1; j < len(clusters); j++ {
+				if d := ward(a, clusters[j]); d < best {
+					bi, bj, best = i, j, d
+				}
+			}
+		}
+		a, b := clusters[bi], clusters[bj]
+		m := &cluster{centroid: make(kdtree.Point, len(a.centroid)), size: a.size + b.size, rep: len(p) + len(merges)}
+		for d := range m.centroid {
+			m.centroid[d] = (float64(a.size)*a.centroid[d] + float64(b.size)*b.centroid[d]) / float64(m.size)
+		}
+		ida, idb := a.rep, b.rep
+		if idb < ida {
+			ida, idb = idb, ida
+		}
+		merges = append(merges, Merge{A: ida, B: idb, Dist: best, Size: m.size})
+		clusters = append(clusters[:bj], clusters[bj+1:]...)
+		clusters[bi] = m
+	}
+	return merges
+}
+
+func (s *S) TestWard(c *check.C) {
+	c.Check(Ward(nil), check.HasLen, 0)
+	c.Check(Ward([]kdtree.Point{{1, 1}}), check.HasLen, 0)
+	c.Check(Ward([]kdtree.Point{{0}, {10}, {1}}), check.DeepEquals, []Merge{
+		{A: 0, B: 2, Dist: 0.5, Size: 2},
+		{A: 1, B: 3, Dist: 2. / 3 * 90.25, Size: 3},
+	})
+
+	rnd := rand.New(rand.NewSource(1))
+	p, _ := blobs(rnd, centres, 30, 2)
+	got, want := Ward(p), naiveWard(p)
+	c.Assert(got, check.HasLen, len(want))
+	for i := range got {
+		c.Check(got[i].A, check.Equals, want[i].A)
+		c.Check(got[i].B, check.Equals, want[i].B)
+		c.Check(got[i].Size, check.Equals, want[i].Size)
+		c.Check(math.Abs(got[i].Dist-want[i].Dist) <= 1e-9*want[i].Dist, check.Equals, true)
+	}
+	// The final merges join the four blobs.
+	for _, m := range got[len(got)-3:] {
+		c.Check(m.Dist > 100, check.Equals, true)
+	}
+
+	// Duplicated points are merged at zero distance.
+	p = nil
+	for i := 0; i < 200; i++ {
+		p = append(p, kdtree.Point{float64(rnd.Intn(5)), float64(rnd.Intn(5))})
+	}
+	got = Ward(p)
+	c.Assert(got, check.HasLen, len(p)-1)
+	used := make(map[int]bool)
+	for i, m := range got {
+		c.Check(m.A < m.B && m.B < len(p)+i, check.Equals, true)
+		c.Check(used[m.A] || used[m.B], check.Equals, false)
+		used[m.A], used[m.B] = true, true
+		if i > 0 {
+			c.Check(m.Dist >= got[i-1].Dist, check.Equals, true)
+		}
+		if i < len(p)-25 {
+			c.Check(m.Dist, check.Equals, 0.)
+		}
+	}
+	c.Check(got[len(got)-1].Size, check.Equals, len(p))
+}
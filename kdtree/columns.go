@@ -0,0 +1,196 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	_ Interface = Rows{}
+	_ Extender  = Row{}
+)
+
+// A Float64Array is a column of float64 values. It is satisfied by the *array.Float64
+// type of the Apache Arrow Go implementation, allowing columns of an Arrow record batch,
+// or of a Parquet file read into Arrow, to be used without copying.
+type Float64Array interface {
+	// Len returns the number of values in the column.
+	Len() int
+
+	// NullN returns the number of null values in the column.
+	NullN() int
+
+	// Float64Values returns the values of the column.
+	Float64Values() []float64
+}
+
+// Columns holds a set of points in columnar form, with the coordinates of each dimension
+// held in a separate slice. Trees built from the Rows of a Columns refer to the column
+// data rather than holding a copy of each point.
+type Columns struct {
+	cols [][]float64
+	n    int
+}
+
+// NewColumns returns a Columns holding the given column slices, one for each dimension.
+// The slices are retained and must not be altered while the Columns is in use. All the
+// slices must have the same length.
+func NewColumns(cols ...[]float64) (*Columns, error) {
+	if len(cols) == 0 {
+		return nil, errors.New("kdtree: no columns")
+	}
+	n := len(cols[0])
+	for i, c := range cols[1:] {
+		if len(c) != n {
+			return nil, fmt.Errorf("kdtree: column %d has length %d, want %d", i+1, len(c), n)
+		}
+	}
+	return &Columns{cols: cols, n: n}, nil
+}
+
+// FromArrow returns a Columns referring to the values of the given arrays, one for each
+// dimension. Typically these will be the float64 columns of an Arrow record batch, for
+// example
+//
+//	cols, err := kdtree.FromArrow(
+//		rec.Column(0).(*array.Float64),
+//		rec.Column(1).(*array.Float64),
+//	)
+//	...
+//	t := kdtree.New(cols.Rows(), false)
+//
+// The arrays must not be released while the Columns is in use. Columns holding null
+// values are rejected, since nulls have no position in the space.
+func FromArrow(cols ...Float64Array) (*Columns, error) {
+	vals := make([][]float64, len(cols))
+	for i, c := range cols {
+		if n := c.NullN(); n != 0 {
+			return nil, fmt.Errorf("kdtree: column %d has %d null values", i, n)
+		}
+		vals[i] = c.Float64Values()[:c.Len()]
+	}
+	return NewColumns(vals...)
+}
+
+// Len returns the number of points held by the Columns.
+func (c *Columns) Len() int { return c.n }
+
+// Dims returns the number of dimensions of the points held by the Columns.
+func (c *Columns) Dims() int { return len(c.cols) }
+
+// Row returns the ith point held by the Columns.
+func (c *Columns) Row(i int) Row {
+	if i < 0 || i >= c.n {
+		panic("kdtree: row index out of range")
+	}
+	return Row{c: c, i: i}
+}
+
+// Rows returns a Rows holding all the points of the Columns, suitable for passing to New.
+func (c *Columns) Rows() Rows {
+	idx := make([]int, c.n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return Rows{c: c, idx: idx}
+}
+
+// A Row is a point held by a Columns. A Row may be compared with Point values and with
+// other Row values.
+type Row struct {
+	c *Columns
+	i int
+}
+
+// Index returns the index of the row in its Columns. This may be used to look up
+// associated values held in other columns of the source data.
+func (r Row) Index() int { return r.i }
+
+// Point returns a copy of the coordinates of the row.
+func (r Row) Point() Point {
+	p := make(Point, len(r.c.cols))
+	for d, col := range r.c.cols {
+		p[d] = col[r.i]
+	}
+	return p
+}
+
+// at returns the coordinate of c in dimension d. c must be a Row or a type accepted
+// by Point.Compare.
+func at(c Comparable, d Dim) float64 {
+	if r, ok := c.(Row); ok {
+		return r.c.cols[d][r.i]
+	}
+	if a, ok := c.(coorder); ok {
+		return a.coord(d)
+	}
+	return coords(c)[d]
+}
+
+// Compare satisfies the Comparable interface.
+func (r Row) Compare(c Comparable, d Dim) float64 { return r.c.cols[d][r.i] - at(c, d) }
+
+// Dims satisfies the Comparable interface.
+func (r Row) Dims() int { return len(r.c.cols) }
+
+// Distance satisfies the Comparable interface.
+func (r Row) Distance(c Comparable) float64 {
+	var sum float64
+	for d, col := range r.c.cols {
+		v := col[r.i] - at(c, Dim(d))
+		sum += v * v
+	}
+	return sum
+}
+
+// Extend satisfies the Extender interface. The bounding volume is held as Point values.
+func (r Row) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{r.Point(), r.Point()}
+	}
+	min := b[0].(Point)
+	max := b[1].(Point)
+	for d, col := range r.c.cols {
+		min[d] = math.Min(min[d], col[r.i])
+		max[d] = math.Max(max[d], col[r.i])
+	}
+	return b
+}
+
+// Rows is a collection of rows of a Columns that satisfies the Interface. Reordering
+// of a Rows during tree construction permutes an index and does not alter the column data.
+type Rows struct {
+	c   *Columns
+	idx []int
+}
+
+// Bounds returns the bounding volume of the rows.
+func (r Rows) Bounds() *Bounding {
+	if r.Len() == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, i := range r.idx {
+		b = Row{c: r.c, i: i}.Extend(b)
+	}
+	return b
+}
+func (r Rows) Index(i int) Comparable         { return Row{c: r.c, i: r.idx[i]} }
+func (r Rows) Len() int                       { return len(r.idx) }
+func (r Rows) Pivot(d Dim) int                { return rowsPlane{Rows: r, col: r.c.cols[d]}.Pivot() }
+func (r Rows) Slice(start, end int) Interface { r.idx = r.idx[start:end]; return r }
+
+type rowsPlane struct {
+	Rows
+	col []float64
+}
+
+func (p rowsPlane) Less(i, j int) bool              { return p.col[p.idx[i]] < p.col[p.idx[j]] }
+func (p rowsPlane) Pivot() int                      { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p rowsPlane) Slice(start, end int) SortSlicer { p.idx = p.idx[start:end]; return p }
+func (p rowsPlane) Swap(i, j int)                   { p.idx[i], p.idx[j] = p.idx[j], p.idx[i] }
@@ -0,0 +1,83 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// float64Array is a minimal Float64Array in the style of an Arrow array.
+type float64Array struct {
+	vals  []float64
+	nulls int
+}
+
+func (a float64Array) Len() int                 { return len(a.vals) }
+func (a float64Array) NullN() int               { return a.nulls }
+func (a float64Array) Float64Values() []float64 { return a.vals }
+
+func (s *S) TestColumns(c *check.C) {
+	x := []float64{2, 5, 9, 4, 8, 7}
+	y := []float64{3, 4, 6, 7, 1, 2}
+	cols, err := FromArrow(float64Array{vals: x}, float64Array{vals: y})
+	c.Assert(err, check.Equals, nil)
+	c.Check(cols.Len(), check.Equals, 6)
+	c.Check(cols.Dims(), check.Equals, 2)
+
+	t := New(cols.Rows(), true)
+	c.Check(t.Count, check.Equals, 6)
+	c.Check(t.Root.isOrdered(), check.Equals, true)
+	c.Check(t.Root.Bounding, check.DeepEquals, wpBound)
+	c.Check(x, check.DeepEquals, []float64{2, 5, 9, 4, 8, 7}, check.Commentf("column data altered"))
+
+	for _, q := range wpData {
+		got, d := t.Nearest(q)
+		c.Check(d, check.Equals, 0.)
+		c.Check(got.(Row).Point(), check.DeepEquals, q)
+		c.Check(wpData[got.(Row).Index()], check.DeepEquals, q)
+	}
+
+	var n int
+	t.DoBounded(func(Comparable, *Bounding, int) bool { n++; return false }, &Bounding{Point{4, 1}, Point{8, 4}})
+	c.Check(n, check.Equals, 3)
+}
+
+func (s *S) TestColumnsRandom(c *check.C) {
+	const (
+		dims    = 3
+		setSize = 1000
+	)
+	vals := make([][]float64, dims)
+	for d := range vals {
+		vals[d] = make([]float64, setSize)
+		for i := range vals[d] {
+			vals[d][i] = rand.Float64()
+		}
+	}
+	cols, err := NewColumns(vals...)
+	c.Assert(err, check.Equals, nil)
+	var pts Points
+	for i := 0; i < setSize; i++ {
+		pts = append(pts, cols.Row(i).Point())
+	}
+	t := New(cols.Rows(), false)
+	for i := 0; i < 100; i++ {
+		q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+		got, _ := t.Nearest(q)
+		want, _ := nearest(q, pts)
+		c.Check(got.(Row).Point(), check.DeepEquals, want)
+	}
+}
+
+func (s *S) TestColumnsErrors(c *check.C) {
+	_, err := NewColumns()
+	c.Check(err, check.ErrorMatches, "kdtree: no columns")
+	_, err = NewColumns([]float64{1, 2}, []float64{1})
+	c.Check(err, check.ErrorMatches, "kdtree: column 1 has length 1, want 2")
+	_, err = FromArrow(float64Array{vals: []float64{1, 2}}, float64Array{vals: []float64{1, 2}, nulls: 1})
+	c.Check(err, check.ErrorMatches, "kdtree: column 1 has 1 null values")
+}
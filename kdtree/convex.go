@@ -0,0 +1,118 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A HalfSpace is the closed region of points x for which the dot product of Normal and x is
+// less than or equal to Offset.
+type HalfSpace struct {
+	Normal Point
+	Offset float64
+}
+
+// Contains returns whether c lies within h. c must be of a type accepted by Point.Compare.
+func (h HalfSpace) Contains(c Comparable) bool {
+	var dot float64
+	for d, v := range h.Normal {
+		dot += v * at(c, Dim(d))
+	}
+	return dot <= h.Offset
+}
+
+// DoConvex performs fn on all values stored in the tree that are within the convex region
+// formed by the intersection of the half-spaces in region, such as a camera view frustum.
+// Stored values must be of a type accepted by Point.Compare. Subtrees whose bounding boxes lie
+// entirely outside any half-space are pruned, and subtrees lying entirely within all the
+// half-spaces are visited without further tests. Bounding boxes are taken from the nodes'
+// bounding volumes when these are present and are otherwise derived from the splitting
+// planes of the tree. The Bounding passed to fn is that of the node holding the value. A
+// boolean is returned indicating whether the traversal was interrupted by an Operation
+// returning true. Values are visited in order. DoConvex panics with ErrDimsMismatch if a
+// normal does not have the dimensionality of the values in the tree.
+func (t *Tree) DoConvex(fn Operation, region []HalfSpace) bool {
+	if t.Root == nil {
+		return false
+	}
+	for _, h := range region {
+		t.mustMatch(h.Normal)
+	}
+	dims := t.Dims()
+	lo, hi := make([]float64, dims), make([]float64, dims)
+	for d := range lo {
+		lo[d], hi[d] = -inf, inf
+	}
+	return t.Root.doConvex(fn, region, lo, hi, 0)
+}
+
+func (n *Node) doConvex(fn Operation, region []HalfSpace, lo, hi []float64, depth int) bool {
+	blo, bhi := lo, hi
+	if n.Bounding != nil {
+		blo, bhi = make([]float64, len(lo)), make([]float64, len(hi))
+		for d := range blo {
+			blo[d], bhi[d] = at(n.Bounding[0], Dim(d)), at(n.Bounding[1], Dim(d))
+		}
+	}
+	var partial []HalfSpace
+	for _, h := range region {
+		min, max := h.extent(blo, bhi)
+		if min > h.Offset {
+			return false
+		}
+		if max > h.Offset {
+			partial = append(partial, h)
+		}
+	}
+	if len(partial) == 0 {
+		return n.do(fn, depth)
+	}
+
+	split := at(n.Point, n.Plane)
+	if n.Left != nil {
+		old := hi[n.Plane]
+		hi[n.Plane] = split
+		done := n.Left.doConvex(fn, partial, lo, hi, depth+1)
+		hi[n.Plane] = old
+		if done {
+			return true
+		}
+	}
+	if within(n.Point, partial) && fn(n.Point, n.Bounding, depth) {
+		return true
+	}
+	if n.Right != nil {
+		old := lo[n.Plane]
+		lo[n.Plane] = split
+		done := n.Right.doConvex(fn, partial, lo, hi, depth+1)
+		lo[n.Plane] = old
+		if done {
+			return true
+		}
+	}
+	return false
+}
+
+// extent returns the minimum and maximum of the dot product of h.Normal with points in the
+// box from lo to hi.
+func (h HalfSpace) extent(lo, hi []float64) (min, max float64) {
+	for d, v := range h.Normal {
+		switch {
+		case v > 0:
+			min += v * lo[d]
+			max += v * hi[d]
+		case v < 0:
+			min += v * hi[d]
+			max += v * lo[d]
+		}
+	}
+	return min, max
+}
+
+func within(c Comparable, region []HalfSpace) bool {
+	for _, h := range region {
+		if !h.Contains(c) {
+			return false
+		}
+	}
+	return true
+}
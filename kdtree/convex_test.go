@@ -0,0 +1,73 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHalfSpace(c *check.C) {
+	h := HalfSpace{Normal: Point{1, 1}, Offset: 2}
+	c.Check(h.Contains(Point{1, 1}), check.Equals, true)
+	c.Check(h.Contains(Point{0, 0}), check.Equals, true)
+	c.Check(h.Contains(Point{2, 1}), check.Equals, false)
+	c.Check(h.Contains(Datum{Point: Point{-5, 6}}), check.Equals, true)
+}
+
+func (s *S) TestDoConvex(c *check.C) {
+	// The triangle with vertices (0, 0), (8, 0) and (8, 8).
+	triangle := []HalfSpace{
+		{Normal: Point{0, -1}, Offset: 0},
+		{Normal: Point{1, 0}, Offset: 8},
+		{Normal: Point{-1, 1}, Offset: 0},
+	}
+	for _, bounding := range []bool{false, true} {
+		t := New(append(Points(nil), wpData...), bounding)
+		var got []Comparable
+		t.DoConvex(func(v Comparable, _ *Bounding, _ int) bool {
+			got = append(got, v)
+			return false
+		}, triangle)
+		c.Check(got, check.DeepEquals, []Comparable{Point{5, 4}, Point{7, 2}, Point{8, 1}})
+
+		var n int
+		c.Check(t.DoConvex(func(Comparable, *Bounding, int) bool { n++; return true }, triangle), check.Equals, true)
+		c.Check(n, check.Equals, 1)
+
+		n = 0
+		t.DoConvex(func(Comparable, *Bounding, int) bool { n++; return false }, nil)
+		c.Check(n, check.Equals, len(wpData))
+	}
+	c.Check((&Tree{}).DoConvex(func(Comparable, *Bounding, int) bool { return true }, triangle), check.Equals, false)
+	c.Check(func() {
+		New(append(Points(nil), wpData...), false).DoConvex(nil, []HalfSpace{{Normal: Point{1}}})
+	}, check.PanicMatches, ErrDimsMismatch.Error())
+
+	// A frustum-like slab region in three dimensions.
+	region := []HalfSpace{
+		{Normal: Point{1, 0.2, 0}, Offset: 0.7},
+		{Normal: Point{-1, 0.2, 0}, Offset: -0.2},
+		{Normal: Point{0, 1, -0.3}, Offset: 0.6},
+		{Normal: Point{0, -1, -0.3}, Offset: -0.1},
+		{Normal: Point{0, 0, 1}, Offset: 0.9},
+	}
+	for _, bounding := range []bool{false, true} {
+		t := New(append(Points(nil), bData...), bounding)
+		var want int
+		for _, v := range bData {
+			if within(v, region) {
+				want++
+			}
+		}
+		c.Assert(want > 0, check.Equals, true)
+		var got int
+		t.DoConvex(func(v Comparable, _ *Bounding, _ int) bool {
+			c.Check(within(v, region), check.Equals, true)
+			got++
+			return false
+		}, region)
+		c.Check(got, check.Equals, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// A Correspondence is the nearest value in a tree to a source value.
+type Correspondence struct {
+	// Target is the nearest value to the source
+	// and Dist is its distance from the source.
+	// Target is nil and Dist is infinite when the
+	// correspondence is rejected.
+	Target Comparable
+	Dist   float64
+
+	// OK is false if no value is within the
+	// maximum distance of the source.
+	OK bool
+}
+
+// Correspondences returns, for each value in src, the nearest value in the tree within
+// distance maxDist of it, as measured by the values' Distance method, as used by the
+// matching step of iterative closest point registration. Ties are resolved as for Nearest.
+// Sources are searched in order sharing a single search configuration, and each search is
+// bounded by the distance of the source from the preceding source's target if that is less
+// than maxDist, so runs of nearby sources, as found in scans, are cheap to match. Options
+// such as WithStats apply to the whole batch. Correspondences panics with ErrDimsMismatch
+// if a source does not have the dimensionality of the values in the tree.
+func (t *Tree) Correspondences(src []Comparable, maxDist float64, opts ...SearchOption) []Correspondence {
+	res := make([]Correspondence, len(src))
+	if t.Root == nil {
+		for i := range res {
+			res[i].Dist = inf
+		}
+		return res
+	}
+	for _, q := range src {
+		t.mustMatch(q)
+	}
+	sc := t.searchConfig(opts)
+	// search only finds values strictly nearer than its bound,
+	// so the bound is placed just beyond maxDist.
+	limit := math.Nextafter(maxDist, inf)
+	var prev *Node
+	for i, q := range src {
+		sc.restart()
+		bn, dist := (*Node)(nil), limit
+		if prev != nil {
+			if d := sc.distance(q, prev.Point); d <= maxDist {
+				bn, dist = prev, d
+			}
+		}
+		bn, dist = t.Root.search(q, bn, dist, sc)
+		if bn == nil {
+			res[i].Dist = inf
+			continue
+		}
+		res[i] = Correspondence{Target: bn.Point, Dist: dist, OK: true}
+		prev = bn
+	}
+	return res
+}
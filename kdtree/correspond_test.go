@@ -0,0 +1,50 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCorrespondences(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	got := t.Correspondences([]Comparable{Point{2, 2}, Point{0, 10}, Point{8, 2}}, 4)
+	c.Check(got, check.DeepEquals, []Correspondence{
+		{Target: Point{2, 3}, Dist: 1, OK: true},
+		{Target: nil, Dist: inf, OK: false},
+		// (7,2) and (8,1) tie and (7,2) is lexically lesser.
+		{Target: Point{7, 2}, Dist: 1, OK: true},
+	})
+	c.Check(t.Correspondences([]Comparable{Point{2, 1}}, 4), check.DeepEquals, []Correspondence{{Target: Point{2, 3}, Dist: 4, OK: true}})
+	c.Check((&Tree{}).Correspondences([]Comparable{Point{0, 0}}, 1), check.DeepEquals, []Correspondence{{Dist: inf}})
+	c.Check(func() { t.Correspondences([]Comparable{Point{0}}, 1) }, check.Panics, ErrDimsMismatch)
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 2000)
+	for i := range p {
+		p[i] = Point{float64(rnd.Intn(100)), float64(rnd.Intn(100)), float64(rnd.Intn(100))}
+	}
+	t = New(p, false)
+	// A scan-like sequence of sources.
+	var src []Comparable
+	q := Point{50, 50, 50}
+	for i := 0; i < 1000; i++ {
+		q = Point{q[0] + rnd.NormFloat64(), q[1] + rnd.NormFloat64(), q[2] + rnd.NormFloat64()}
+		src = append(src, q)
+	}
+	var batch, single SearchStats
+	got = t.Correspondences(src, 20, WithStats(&batch))
+	for i, q := range src {
+		nn, d := t.Nearest(q, WithStats(&single))
+		if d > 20 {
+			c.Check(got[i].OK, check.Equals, false)
+			continue
+		}
+		c.Check(got[i], check.DeepEquals, Correspondence{Target: nn, Dist: d, OK: true})
+	}
+	c.Check(batch.Visited < single.Visited, check.Equals, true)
+}
@@ -0,0 +1,217 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"sort"
+)
+
+// A Match is a pair of values from two trees and the squared Euclidean distance between
+// them.
+type Match struct {
+	A, B Comparable
+	Dist float64
+}
+
+// CrossMatch returns all the pairs of values from a and b that are within Euclidean
+// distance r of each other. Values must be of a type accepted by Point.Compare; to match
+// catalogs of unit vectors within an angle θ, use the chord length r = 2*sin(θ/2). Matches
+// are ordered by the position of their A value in the order returned by a.Points, then by
+// increasing distance, then lexically by the coordinates of their B value.
+//
+// Pairs are found by a dual-tree traversal that prunes pairs of subtrees whose bounding
+// boxes are separated by more than r. CrossMatch panics with ErrDimsMismatch if the trees
+// do not have the same dimensionality.
+func CrossMatch(a, b *Tree, r float64) []Match {
+	return matches(crossMatch(a, b, r))
+}
+
+// CrossMatchBest returns, for each value of a with a value of b within Euclidean distance
+// r, a Match holding the nearest such value of b, with ties broken lexically by coordinates.
+// Matches are ordered by the position of their A value in the order returned by a.Points.
+func CrossMatchBest(a, b *Tree, r float64) []Match {
+	all := crossMatch(a, b, r)
+	var best []nodeMatch
+	for i, m := range all {
+		// All the matches of a value of a are adjacent and the first is the best.
+		if i == 0 || m.a != all[i-1].a {
+			best = append(best, m)
+		}
+	}
+	return matches(best)
+}
+
+// crossMatch returns the matches between a and b in the order described for CrossMatch.
+func crossMatch(a, b *Tree, r float64) []nodeMatch {
+	if a.Root == nil || b.Root == nil {
+		return nil
+	}
+	b.mustMatch(a.Root.Point)
+	m := matcher{r2: r * r}
+	m.pair(a.Root, a.cell(), b.Root, b.cell())
+	sort.Sort(byMatch{matches: m.matches, index: a.nodeIndex()})
+	return m.matches
+}
+
+// nodeIndex returns the position of each node's value in the order returned by Points.
+func (t *Tree) nodeIndex() map[*Node]int {
+	index := make(map[*Node]int, t.Count)
+	t.Root.doNodes(func(n *Node) { index[n] = len(index) })
+	return index
+}
+
+func matches(nm []nodeMatch) []Match {
+	if len(nm) == 0 {
+		return nil
+	}
+	res := make([]Match, len(nm))
+	for i, m := range nm {
+		res[i] = Match{A: m.a.Point, B: m.b.Point, Dist: m.dist}
+	}
+	return res
+}
+
+// doNodes calls fn on each node of the subtree rooted at n in order.
+func (n *Node) doNodes(fn func(*Node)) {
+	if n.Left != nil {
+		n.Left.doNodes(fn)
+	}
+	fn(n)
+	if n.Right != nil {
+		n.Right.doNodes(fn)
+	}
+}
+
+// cell returns the bounding box of the values in t.
+func (t *Tree) cell() box {
+	dims := t.Dims()
+	c := box{lo: make([]float64, dims), hi: make([]float64, dims)}
+	for d := range c.lo {
+		c.lo[d], c.hi[d] = inf, -inf
+	}
+	t.Root.doNodes(func(n *Node) {
+		for d := range c.lo {
+			v := at(n.Point, Dim(d))
+			c.lo[d] = math.Min(c.lo[d], v)
+			c.hi[d] = math.Max(c.hi[d], v)
+		}
+	})
+	return c
+}
+
+// box is an axis aligned box.
+type box struct {
+	lo, hi []float64
+}
+
+// split returns the boxes of the left and right subtrees of n within b.
+func (b box) split(n *Node) (left, right box) {
+	v := at(n.Point, n.Plane)
+	left = box{lo: b.lo, hi: append([]float64(nil), b.hi...)}
+	left.hi[n.Plane] = math.Min(left.hi[n.Plane], v)
+	right = box{lo: append([]float64(nil), b.lo...), hi: b.hi}
+	right.lo[n.Plane] = math.Max(right.lo[n.Plane], v)
+	return left, right
+}
+
+// dist returns the squared distance between the nearest points of b and c.
+func (b box) dist(c box) float64 {
+	var sum float64
+	for d := range b.lo {
+		var delta float64
+		switch {
+		case b.hi[d] < c.lo[d]:
+			delta = c.lo[d] - b.hi[d]
+		case c.hi[d] < b.lo[d]:
+			delta = b.lo[d] - c.hi[d]
+		}
+		sum += delta * delta
+	}
+	return sum
+}
+
+// extent returns the greatest side length of b.
+func (b box) extent() float64 {
+	var max float64
+	for d := range b.lo {
+		max = math.Max(max, b.hi[d]-b.lo[d])
+	}
+	return max
+}
+
+// pointBox returns the degenerate box holding only c.
+func pointBox(c Comparable) box {
+	p := make([]float64, c.Dims())
+	for d := range p {
+		p[d] = at(c, Dim(d))
+	}
+	return box{lo: p, hi: p}
+}
+
+type nodeMatch struct {
+	a, b *Node
+	dist float64
+}
+
+type matcher struct {
+	r2      float64
+	matches []nodeMatch
+}
+
+// pair finds the matches between the subtrees rooted at a and b, with the boxes ab and bb.
+// The subtree with the larger box is divided into its root value and its child subtrees.
+func (m *matcher) pair(a *Node, ab box, b *Node, bb box) {
+	if a == nil || b == nil || ab.dist(bb) > m.r2 {
+		return
+	}
+	if ab.extent() >= bb.extent() {
+		m.single(a, pointBox(a.Point), b, bb, false)
+		l, r := ab.split(a)
+		m.pair(a.Left, l, b, bb)
+		m.pair(a.Right, r, b, bb)
+		return
+	}
+	m.single(b, pointBox(b.Point), a, ab, true)
+	l, r := bb.split(b)
+	m.pair(a, ab, b.Left, l)
+	m.pair(a, ab, b.Right, r)
+}
+
+// single finds the matches between the value of p and the subtree rooted at n with the box
+// nb. If swap is true, p is from the b tree.
+func (m *matcher) single(p *Node, pb box, n *Node, nb box, swap bool) {
+	if n == nil || pb.dist(nb) > m.r2 {
+		return
+	}
+	if d := pb.dist(pointBox(n.Point)); d <= m.r2 {
+		if swap {
+			m.matches = append(m.matches, nodeMatch{a: n, b: p, dist: d})
+		} else {
+			m.matches = append(m.matches, nodeMatch{a: p, b: n, dist: d})
+		}
+	}
+	l, r := nb.split(n)
+	m.single(p, pb, n.Left, l, swap)
+	m.single(p, pb, n.Right, r, swap)
+}
+
+type byMatch struct {
+	matches []nodeMatch
+	index   map[*Node]int
+}
+
+func (m byMatch) Len() int { return len(m.matches) }
+func (m byMatch) Less(i, j int) bool {
+	a, b := m.matches[i], m.matches[j]
+	if ia, ib := m.index[a.a], m.index[b.a]; ia != ib {
+		return ia < ib
+	}
+	if a.dist != b.dist {
+		return a.dist < b.dist
+	}
+	return lexLess(a.b.Point, b.b.Point)
+}
+func (m byMatch) Swap(i, j int) { m.matches[i], m.matches[j] = m.matches[j], m.matches[i] }
@@ -0,0 +1,65 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCrossMatch(c *check.C) {
+	a := New(Points{{0, 0}, {5, 5}, {10, 0}}, false)
+	b := New(Points{{0.5, 0}, {0, 0.5}, {5, 6}, {20, 20}}, false)
+	c.Check(CrossMatch(a, b, 0.6), check.DeepEquals, []Match{
+		{A: Point{0, 0}, B: Point{0, 0.5}, Dist: 0.25},
+		{A: Point{0, 0}, B: Point{0.5, 0}, Dist: 0.25},
+	})
+	c.Check(CrossMatch(a, b, 1), check.DeepEquals, []Match{
+		{A: Point{0, 0}, B: Point{0, 0.5}, Dist: 0.25},
+		{A: Point{0, 0}, B: Point{0.5, 0}, Dist: 0.25},
+		{A: Point{5, 5}, B: Point{5, 6}, Dist: 1},
+	})
+	c.Check(CrossMatchBest(a, b, 1), check.DeepEquals, []Match{
+		{A: Point{0, 0}, B: Point{0, 0.5}, Dist: 0.25},
+		{A: Point{5, 5}, B: Point{5, 6}, Dist: 1},
+	})
+	c.Check(CrossMatch(a, b, 0.1), check.HasLen, 0)
+	c.Check(CrossMatch(a, &Tree{}, 1), check.HasLen, 0)
+	c.Check(func() { CrossMatch(a, New(Points{{0}}, false), 1) }, check.PanicMatches, ErrDimsMismatch.Error())
+
+	rnd := rand.New(rand.NewSource(1))
+	random := func(n int) Points {
+		p := make(Points, n)
+		for i := range p {
+			p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+		}
+		return p
+	}
+	a = New(random(500), false)
+	b = New(random(700), false)
+	const r = 0.05
+	var want []Match
+	for _, p := range a.Points() {
+		for _, q := range b.InRange(p, r*r) {
+			want = append(want, Match{A: p, B: q.Comparable, Dist: q.Dist})
+		}
+	}
+	got := CrossMatch(a, b, r)
+	c.Assert(got, check.HasLen, len(want))
+	c.Check(got, check.DeepEquals, want)
+
+	best := CrossMatchBest(a, b, r)
+	var n int
+	for _, p := range a.Points() {
+		nn, d := b.Nearest(p)
+		if d > r*r {
+			continue
+		}
+		c.Check(best[n], check.DeepEquals, Match{A: p, B: nn, Dist: d})
+		n++
+	}
+	c.Check(best, check.HasLen, n)
+}
@@ -0,0 +1,124 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A CSVOption configures the behaviour of LoadCSV.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	comma    rune
+	comment  rune
+	header   bool
+	bounding bool
+}
+
+// CSVComma sets the field delimiter used by LoadCSV. The default is ','. Use '\t' to
+// read tab-separated values.
+func CSVComma(r rune) CSVOption {
+	return func(c *csvConfig) { c.comma = r }
+}
+
+// CSVComment sets the comment character used by LoadCSV. Lines beginning with r are
+// ignored.
+func CSVComment(r rune) CSVOption {
+	return func(c *csvConfig) { c.comment = r }
+}
+
+// CSVHeader specifies that the first record read by LoadCSV is a header and is skipped.
+func CSVHeader() CSVOption {
+	return func(c *csvConfig) { c.header = true }
+}
+
+// CSVBounding specifies that the tree built by LoadCSV holds bounding volumes.
+func CSVBounding() CSVOption {
+	return func(c *csvConfig) { c.bounding = true }
+}
+
+// LoadCSV reads delimited records from r and returns a tree built from them. The fields
+// at the indices given in cols are parsed as floating point values and used, in order, as
+// the coordinates of each point. The remaining fields of each record are retained in
+// record order as a []string Value of the Datum holding the point. Records with fewer
+// fields than required, or with unparseable coordinate fields, result in an error
+// identifying the offending record and column.
+func LoadCSV(r io.Reader, cols []int, opts ...CSVOption) (*Tree, error) {
+	if len(cols) == 0 {
+		return nil, errors.New("kdtree: no coordinate columns")
+	}
+	last := -1
+	coord := make(map[int]bool, len(cols))
+	for _, c := range cols {
+		if c < 0 || coord[c] {
+			return nil, fmt.Errorf("kdtree: invalid coordinate column %d", c)
+		}
+		coord[c] = true
+		if c > last {
+			last = c
+		}
+	}
+
+	cfg := csvConfig{comma: ','}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = cfg.comma
+	cr.Comment = cfg.comment
+	cr.FieldsPerRecord = -1
+	if cfg.comma == '\t' {
+		cr.LazyQuotes = true
+	}
+
+	var (
+		data Data
+		n    int
+	)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		n++
+		if n == 1 && cfg.header {
+			continue
+		}
+		if len(rec) <= last {
+			return nil, fmt.Errorf("kdtree: record %d: too few fields: have %d need %d", n, len(rec), last+1)
+		}
+		p := make(Point, len(cols))
+		for d, c := range cols {
+			p[d], err = strconv.ParseFloat(strings.TrimSpace(rec[c]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("kdtree: record %d column %d: %v", n, c, err)
+			}
+		}
+		var rest []string
+		if len(rec) > len(cols) {
+			rest = make([]string, 0, len(rec)-len(cols))
+			for i, f := range rec {
+				if !coord[i] {
+					rest = append(rest, f)
+				}
+			}
+		}
+		data = append(data, Datum{Point: p, Value: rest})
+	}
+
+	if len(data) == 0 {
+		return &Tree{}, nil
+	}
+	return New(data, cfg.bounding), nil
+}
@@ -0,0 +1,76 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestLoadCSV(c *check.C) {
+	const in = `name,x,note,y
+a,2,first,3
+b, 5 ,second,4
+c,9,third,6
+d,4,fourth,7
+e,8,fifth,1
+f,7,sixth,2
+`
+	t, err := LoadCSV(strings.NewReader(in), []int{1, 3}, CSVHeader(), CSVBounding())
+	c.Assert(err, check.Equals, nil)
+	c.Check(t.Count, check.Equals, 6)
+	c.Check(t.Root.Bounding, check.DeepEquals, &Bounding{Point{2, 1}, Point{9, 7}})
+	c.Check(t.Root.isOrdered(), check.Equals, true)
+
+	got, d := t.Nearest(Point{5, 4})
+	c.Check(d, check.Equals, 0.)
+	c.Check(got, check.DeepEquals, Datum{Point: Point{5, 4}, Value: []string{"b", "second"}})
+
+	var n int
+	t.DoBounded(func(c Comparable, _ *Bounding, _ int) bool { n++; return false }, &Bounding{Point{4, 1}, Point{8, 4}})
+	c.Check(n, check.Equals, 3)
+}
+
+func (s *S) TestLoadCSVTab(c *check.C) {
+	const in = "# comment\n1\t2\n3\t4\n"
+	t, err := LoadCSV(strings.NewReader(in), []int{1, 0}, CSVComma('\t'), CSVComment('#'))
+	c.Assert(err, check.Equals, nil)
+	c.Check(t.Count, check.Equals, 2)
+	got, _ := t.Nearest(Point{4, 3})
+	c.Check(got, check.DeepEquals, Datum{Point: Point{4, 3}, Value: []string(nil)})
+}
+
+func (s *S) TestLoadCSVErrors(c *check.C) {
+	for i, test := range []struct {
+		in   string
+		cols []int
+		err  string
+	}{
+		{"1,2\n", nil, "kdtree: no coordinate columns"},
+		{"1,2\n", []int{0, 0}, "kdtree: invalid coordinate column 0"},
+		{"1,2\n", []int{-1}, "kdtree: invalid coordinate column -1"},
+		{"1,2\n3\n", []int{0, 1}, "kdtree: record 2: too few fields: have 1 need 2"},
+		{"1,2\n3,x\n", []int{0, 1}, `kdtree: record 2 column 1: strconv.ParseFloat: parsing "x": invalid syntax`},
+	} {
+		t, err := LoadCSV(strings.NewReader(test.in), test.cols)
+		c.Check(t, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(err, check.ErrorMatches, test.err, check.Commentf("Test %d", i))
+	}
+
+	t, err := LoadCSV(strings.NewReader(""), []int{0})
+	c.Check(err, check.Equals, nil)
+	c.Check(t.Count, check.Equals, 0)
+	c.Check(t.Root, check.IsNil)
+}
+
+func (s *S) TestDatumCompare(c *check.C) {
+	d := Datum{Point: Point{1, 2}, Value: "v"}
+	c.Check(d.Compare(Point{0, 5}, 0), check.Equals, 1.)
+	c.Check(Point{0, 5}.Compare(d, 1), check.Equals, 3.)
+	c.Check(Point{0, 5}.Compare(&d, 1), check.Equals, 3.)
+	c.Check(Point{1, 1}.Distance(d), check.Equals, 1.)
+	c.Check(func() { Point{1, 1}.Distance(nbPoint{1, 1}) }, check.PanicMatches, "kdtree: cannot compare Point with kdtree.nbPoint")
+}
@@ -0,0 +1,114 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A Cursor is a resumable traversal of the values stored in a tree that are within a bound,
+// allowing the results of a large range query to be consumed in pages. Values are yielded in
+// the order they are visited by DoBounded, which depends only on the structure of the tree,
+// so a query over an unchanged tree may be resumed by a new Cursor skipping the number of
+// values already consumed, as given by Offset. A Cursor holds only the path to its current
+// position; it is invalidated by any modification of the tree.
+type Cursor struct {
+	tree   *Tree
+	lo, hi []float64
+
+	// stack holds the nodes whose values and
+	// right subtrees are yet to be visited, with
+	// the next node to be visited at the top.
+	stack  []*Node
+	offset int
+}
+
+// QueryBounded returns a Cursor over the values stored in the tree that are within the
+// bound b, or over all the values if b is nil. Stored values and b must be of a type accepted
+// by Point.Compare. QueryBounded panics with ErrDimsMismatch if b does not have the
+// dimensionality of the values in the tree.
+func (t *Tree) QueryBounded(b *Bounding) *Cursor {
+	c := &Cursor{tree: t}
+	if t.Root == nil {
+		return c
+	}
+	if b != nil {
+		t.mustMatch(b[0])
+		t.mustMatch(b[1])
+	}
+	dims := t.Dims()
+	c.lo, c.hi = make([]float64, dims), make([]float64, dims)
+	for d := range c.lo {
+		c.lo[d], c.hi[d] = -inf, inf
+		if b != nil {
+			c.lo[d], c.hi[d] = at(b[0], Dim(d)), at(b[1], Dim(d))
+		}
+	}
+	c.pushLeft(t.Root)
+	return c
+}
+
+// pushLeft pushes n and the path of left children from n that may hold values within the
+// bound of the cursor.
+func (c *Cursor) pushLeft(n *Node) {
+	for n != nil {
+		c.stack = append(c.stack, n)
+		if c.lo[n.Plane] > at(n.Point, n.Plane) {
+			break
+		}
+		n = n.Left
+	}
+}
+
+// step advances the cursor past the node at the top of its stack, returning the node.
+func (c *Cursor) step() *Node {
+	n := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	if n.Right != nil && c.hi[n.Plane] >= at(n.Point, n.Plane) {
+		c.pushLeft(n.Right)
+	}
+	return n
+}
+
+// Next returns up to n further values from the cursor. Next returns fewer than n values
+// only when the cursor is exhausted.
+func (c *Cursor) Next(n int) []Comparable {
+	var page []Comparable
+	for len(page) < n && len(c.stack) != 0 {
+		if v := c.step().Point; inBox(v, c.lo, c.hi) {
+			page = append(page, v)
+		}
+	}
+	c.offset += len(page)
+	return page
+}
+
+// Skip advances the cursor past up to n values without returning them, returning the number
+// of values skipped. If the tree has bounding volumes and is augmented by a Counter, subtrees
+// lying entirely within the bound of the cursor are skipped without visiting their values.
+func (c *Cursor) Skip(n int) int {
+	_, counted := c.tree.Augmenter.(Counter)
+	var skipped int
+	for skipped < n && len(c.stack) != 0 {
+		top := c.stack[len(c.stack)-1]
+		if counted && top.Right != nil && top.Right.Bounding != nil && inBox(top.Point, c.lo, c.hi) &&
+			inBox(top.Right.Bounding[0], c.lo, c.hi) && inBox(top.Right.Bounding[1], c.lo, c.hi) {
+			if size := 1 + top.Right.Summary.(int); skipped+size <= n {
+				// Skip top and its right subtree wholesale.
+				c.stack = c.stack[:len(c.stack)-1]
+				skipped += size
+				continue
+			}
+		}
+		if inBox(c.step().Point, c.lo, c.hi) {
+			skipped++
+		}
+	}
+	c.offset += skipped
+	return skipped
+}
+
+// Offset returns the number of values consumed from the cursor by Next and Skip.
+func (c *Cursor) Offset() int { return c.offset }
+
+// Done returns whether the cursor is exhausted. A cursor that is not done may yield no
+// further values if none of the remaining values are within its bound.
+func (c *Cursor) Done() bool { return len(c.stack) == 0 }
@@ -0,0 +1,67 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestCursor(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 5000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64()}
+	}
+	for _, bounding := range []bool{false, true} {
+		for _, aug := range []Augmenter{nil, Counter{}} {
+			t := New(append(Points(nil), p...), bounding)
+			t.Augment(aug)
+			for _, b := range []*Bounding{nil, {Point{0.2, 0.1}, Point{0.9, 0.6}}} {
+				var want []Comparable
+				t.DoBounded(func(v Comparable, _ *Bounding, _ int) bool {
+					want = append(want, v)
+					return false
+				}, b)
+
+				cur := t.QueryBounded(b)
+				var got []Comparable
+				for {
+					page := cur.Next(333)
+					got = append(got, page...)
+					if len(page) < 333 {
+						break
+					}
+				}
+				c.Check(got, check.DeepEquals, want)
+				c.Check(cur.Offset(), check.Equals, len(want))
+				c.Check(cur.Done(), check.Equals, true)
+				c.Check(cur.Next(10), check.HasLen, 0)
+
+				// A new cursor resumes a query from an offset.
+				for _, off := range []int{0, 1, 999, len(want) - 5, len(want) + 5} {
+					cur := t.QueryBounded(b)
+					n := cur.Skip(off)
+					if off > len(want) {
+						c.Check(n, check.Equals, len(want))
+						continue
+					}
+					c.Check(n, check.Equals, off)
+					c.Check(cur.Offset(), check.Equals, off)
+					end := off + 10
+					if end > len(want) {
+						end = len(want)
+					}
+					c.Check(cur.Next(10), check.DeepEquals, want[off:end])
+				}
+			}
+		}
+	}
+	cur := (&Tree{}).QueryBounded(nil)
+	c.Check(cur.Done(), check.Equals, true)
+	c.Check(cur.Next(10), check.HasLen, 0)
+	c.Check(func() { New(p, false).QueryBounded(&Bounding{Point{0}, Point{1}}) }, check.Panics, ErrDimsMismatch)
+}
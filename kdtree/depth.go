@@ -0,0 +1,78 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// Height returns the number of nodes on the longest path from the root of the tree to a
+// leaf. The height of an empty tree is zero.
+func (t *Tree) Height() int { return t.Root.height() }
+
+func (n *Node) height() int {
+	if n == nil {
+		return 0
+	}
+	l, r := n.Left.height(), n.Right.height()
+	if l > r {
+		return l + 1
+	}
+	return r + 1
+}
+
+// DepthStats holds summary statistics of the depths of the leaves of a tree, where a
+// leaf is a node without children and the root is at depth zero.
+type DepthStats struct {
+	// Leaves is the number of leaves.
+	Leaves int
+
+	// Min and Max are the least and greatest leaf depths.
+	Min, Max int
+
+	// Mean is the mean leaf depth.
+	Mean float64
+
+	// Histogram holds the number of leaves at each depth.
+	Histogram []int
+}
+
+// DepthStats returns statistics of the leaf depths of the tree. The leaf depths of a
+// tree built by New are all close to log₂ of the number of values; a wide spread
+// indicates that the tree has lost balance through insertions and removals and may
+// benefit from being rebuilt.
+func (t *Tree) DepthStats() DepthStats {
+	var s DepthStats
+	if t.Root == nil {
+		return s
+	}
+	var sum int
+	t.Root.leafDepths(0, func(depth int) {
+		for len(s.Histogram) <= depth {
+			s.Histogram = append(s.Histogram, 0)
+		}
+		s.Histogram[depth]++
+		if s.Leaves == 0 || depth < s.Min {
+			s.Min = depth
+		}
+		if depth > s.Max {
+			s.Max = depth
+		}
+		s.Leaves++
+		sum += depth
+	})
+	s.Mean = float64(sum) / float64(s.Leaves)
+	return s
+}
+
+// leafDepths calls fn with the depth of each leaf of the subtree rooted at n.
+func (n *Node) leafDepths(depth int, fn func(int)) {
+	if n.Left == nil && n.Right == nil {
+		fn(depth)
+		return
+	}
+	if n.Left != nil {
+		n.Left.leafDepths(depth+1, fn)
+	}
+	if n.Right != nil {
+		n.Right.leafDepths(depth+1, fn)
+	}
+}
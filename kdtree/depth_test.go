@@ -0,0 +1,47 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHeight(c *check.C) {
+	c.Check((&Tree{}).Height(), check.Equals, 0)
+	c.Check((&Tree{}).DepthStats(), check.DeepEquals, DepthStats{})
+
+	t := New(append(Points(nil), wpData...), false)
+	c.Check(t.Height(), check.Equals, 3)
+	c.Check(t.DepthStats(), check.DeepEquals, DepthStats{
+		Leaves:    3,
+		Min:       2,
+		Max:       2,
+		Mean:      2,
+		Histogram: []int{0, 0, 3},
+	})
+
+	// Inserting sorted values degrades the tree to a list.
+	t = &Tree{}
+	for i := 0; i < 10; i++ {
+		t.Insert(Point{float64(i), float64(i)}, false)
+	}
+	c.Check(t.Height(), check.Equals, 10)
+	ds := t.DepthStats()
+	c.Check(ds.Leaves, check.Equals, 1)
+	c.Check(ds.Min, check.Equals, 9)
+	c.Check(ds.Max, check.Equals, 9)
+	c.Check(ds.Histogram, check.HasLen, 10)
+
+	t = New(append(Points(nil), bData...), false)
+	ds = t.DepthStats()
+	c.Check(ds.Max-ds.Min <= 1, check.Equals, true)
+	c.Check(ds.Max+1, check.Equals, t.Height())
+	var n int
+	for _, h := range ds.Histogram {
+		n += h
+	}
+	c.Check(n, check.Equals, ds.Leaves)
+	c.Check(ds.Mean >= float64(ds.Min) && ds.Mean <= float64(ds.Max), check.Equals, true)
+}
@@ -0,0 +1,116 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// A DotOption modifies the output of Tree.DOT.
+type DotOption func(*dotConfig)
+
+type dotConfig struct {
+	name   string
+	bounds bool
+	label  func(Comparable) string
+}
+
+// DotName sets the name of the graph written by Tree.DOT. The default is "kdtree".
+func DotName(name string) DotOption {
+	return func(c *dotConfig) { c.name = name }
+}
+
+// DotBounds specifies that node labels written by Tree.DOT include bounding volumes.
+func DotBounds() DotOption {
+	return func(c *dotConfig) { c.bounds = true }
+}
+
+// DotLabel sets the function used to render points in node labels written by Tree.DOT.
+// The default renders points with the %v verb of the fmt package.
+func DotLabel(fn func(Comparable) string) DotOption {
+	return func(c *dotConfig) { c.label = fn }
+}
+
+// DOT writes a Graphviz DOT representation of the tree to w. Each node is labelled with
+// its point, its splitting dimension and, when the point is a Point, Datum or Row, the
+// pivot value on that dimension. Edges to left and right children are labelled "≤" and
+// ">". A child that is on the wrong side of its parent's splitting plane, as may result
+// from an incorrect Pivot implementation, is drawn in red.
+func (t *Tree) DOT(w io.Writer, opts ...DotOption) error {
+	cfg := dotConfig{
+		name:  "kdtree",
+		label: func(c Comparable) string { return fmt.Sprint(c) },
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n\tnode [shape=box];\n", strconv.Quote(cfg.name))
+	if t.Root != nil {
+		var id int
+		t.Root.dot(bw, &cfg, &id)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func (n *Node) dot(w io.Writer, cfg *dotConfig, id *int) int {
+	i := *id
+	*id++
+	label := fmt.Sprintf("%s\\nsplit %d", cfg.label(n.Point), n.Plane)
+	if v, ok := coordOf(n.Point, n.Plane); ok {
+		label += fmt.Sprintf(" at %v", v)
+	}
+	if cfg.bounds && n.Bounding != nil {
+		label += fmt.Sprintf("\\nbounds %s %s", cfg.label(n.Bounding[0]), cfg.label(n.Bounding[1]))
+	}
+	fmt.Fprintf(w, "\tn%d [label=%s];\n", i, dotQuote(label))
+	for _, e := range []struct {
+		c     *Node
+		label string
+		left  bool
+	}{
+		{n.Left, "≤", true},
+		{n.Right, ">", false},
+	} {
+		if e.c == nil {
+			continue
+		}
+		j := e.c.dot(w, cfg, id)
+		var attr string
+		if (e.c.Point.Compare(n.Point, n.Plane) <= 0) != e.left {
+			attr = ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(w, "\tn%d -> n%d [label=%q%s];\n", i, j, e.label, attr)
+	}
+	return i
+}
+
+// coordOf returns the coordinate of c in dimension d if c is of a type with known
+// coordinates.
+func coordOf(c Comparable, d Dim) (float64, bool) {
+	switch c.(type) {
+	case Point, Datum, *Datum, Row:
+		return at(c, d), true
+	}
+	return 0, false
+}
+
+// dotQuote returns s as a DOT quoted string. Backslash escapes in s are retained to
+// allow the use of DOT line breaks.
+func dotQuote(s string) string {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	return string(append(b, '"'))
+}
@@ -0,0 +1,47 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDOT(c *check.C) {
+	t := New(wpData, false)
+	var buf bytes.Buffer
+	c.Assert(t.DOT(&buf), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `digraph "kdtree" {
+	node [shape=box];
+	n0 [label="[7 2]\nsplit 0 at 7"];
+	n1 [label="[5 4]\nsplit 1 at 4"];
+	n2 [label="[2 3]\nsplit 0 at 2"];
+	n1 -> n2 [label="≤"];
+	n3 [label="[4 7]\nsplit 0 at 4"];
+	n1 -> n3 [label=">"];
+	n0 -> n1 [label="≤"];
+	n4 [label="[9 6]\nsplit 1 at 6"];
+	n5 [label="[8 1]\nsplit 0 at 8"];
+	n4 -> n5 [label="≤"];
+	n0 -> n4 [label=">"];
+}
+`)
+
+	buf.Reset()
+	t = New(wpData, true)
+	t.Root.Left, t.Root.Right = t.Root.Right, t.Root.Left
+	c.Assert(t.DOT(&buf, DotName("wp"), DotBounds()), check.Equals, nil)
+	out := buf.String()
+	c.Check(strings.HasPrefix(out, `digraph "wp" {`), check.Equals, true)
+	c.Check(strings.Contains(out, `n0 [label="[7 2]\nsplit 0 at 7\nbounds [2 1] [9 7]"];`), check.Equals, true)
+	c.Check(strings.Count(out, " color=red"), check.Equals, 2)
+
+	buf.Reset()
+	c.Assert((&Tree{}).DOT(&buf, DotLabel(func(Comparable) string { return `"` })), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, "digraph \"kdtree\" {\n\tnode [shape=box];\n}\n")
+	c.Check(dotQuote(`a"b\n`), check.Equals, `"a\"b\n"`)
+}
@@ -0,0 +1,91 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A DumpOption modifies the output of Tree.Dump.
+type DumpOption func(*dumpConfig)
+
+type dumpConfig struct {
+	depth  int
+	bounds bool
+	label  func(Comparable) string
+}
+
+// DumpDepth limits the output of Tree.Dump to nodes at depth at most d. Subtrees below
+// the limit are summarized by the number of values they hold. A negative depth, the
+// default, writes the whole tree.
+func DumpDepth(d int) DumpOption {
+	return func(c *dumpConfig) { c.depth = d }
+}
+
+// DumpBounds specifies that nodes written by Tree.Dump include bounding volumes.
+func DumpBounds() DumpOption {
+	return func(c *dumpConfig) { c.bounds = true }
+}
+
+// DumpLabel sets the function used to render points written by Tree.Dump. The default
+// renders points with the %v verb of the fmt package.
+func DumpLabel(fn func(Comparable) string) DumpOption {
+	return func(c *dumpConfig) { c.label = fn }
+}
+
+// Dump writes an indented textual representation of the tree to w, one node per line.
+// Each line holds the branch taken from the parent, L or R, the node's point and its
+// splitting dimension. For example, the output for a small tree is
+//
+//	[7 2] split 0
+//	  L [5 4] split 1
+//	    L [2 3] split 0
+//	    R [4 7] split 0
+//	  R [9 6] split 1
+//	    L [8 1] split 0
+func (t *Tree) Dump(w io.Writer, opts ...DumpOption) error {
+	cfg := dumpConfig{
+		depth: -1,
+		label: func(c Comparable) string { return fmt.Sprint(c) },
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	bw := bufio.NewWriter(w)
+	if t.Root != nil {
+		t.Root.dump(bw, &cfg, "", 0)
+	}
+	return bw.Flush()
+}
+
+func (n *Node) dump(w io.Writer, cfg *dumpConfig, branch string, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if cfg.depth >= 0 && depth > cfg.depth {
+		fmt.Fprintf(w, "%s%s... %d values\n", indent, branch, n.count())
+		return
+	}
+	fmt.Fprintf(w, "%s%s%s split %d", indent, branch, cfg.label(n.Point), n.Plane)
+	if cfg.bounds && n.Bounding != nil {
+		fmt.Fprintf(w, " bounds %s %s", cfg.label(n.Bounding[0]), cfg.label(n.Bounding[1]))
+	}
+	fmt.Fprintln(w)
+	if n.Left != nil {
+		n.Left.dump(w, cfg, "L ", depth+1)
+	}
+	if n.Right != nil {
+		n.Right.dump(w, cfg, "R ", depth+1)
+	}
+}
+
+// count returns the number of values in the subtree rooted at n.
+func (n *Node) count() int {
+	if n == nil {
+		return 0
+	}
+	return 1 + n.Left.count() + n.Right.count()
+}
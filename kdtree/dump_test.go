@@ -0,0 +1,48 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDump(c *check.C) {
+	t := New(append(Points(nil), wpData...), true)
+	var buf bytes.Buffer
+	c.Assert(t.Dump(&buf), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `[7 2] split 0
+  L [5 4] split 1
+    L [2 3] split 0
+    R [4 7] split 0
+  R [9 6] split 1
+    L [8 1] split 0
+`)
+
+	buf.Reset()
+	c.Assert(t.Dump(&buf, DumpDepth(1), DumpBounds()), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `[7 2] split 0 bounds [2 1] [9 7]
+  L [5 4] split 1 bounds [2 3] [5 7]
+    L ... 1 values
+    R ... 1 values
+  R [9 6] split 1 bounds [8 1] [9 6]
+    L ... 1 values
+`)
+
+	buf.Reset()
+	c.Assert(t.Dump(&buf, DumpDepth(0), DumpLabel(func(p Comparable) string {
+		return fmt.Sprintf("%.1f", p)
+	})), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, `[7.0 2.0] split 0
+  L ... 3 values
+  R ... 2 values
+`)
+
+	buf.Reset()
+	c.Assert((&Tree{}).Dump(&buf), check.Equals, nil)
+	c.Check(buf.String(), check.Equals, "")
+}
@@ -0,0 +1,219 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "sort"
+
+var _ SpatialIndex = (*Dynamic)(nil)
+
+// A Dynamic is a k-d tree index that supports efficient insertion using the logarithmic
+// method. Values are held in a set of balanced static trees, the ith of which holds at most
+// 2^i values. Inserting a value merges the full trees of consecutive sizes from the smallest
+// into a single new tree, so each value is rebuilt into a tree O(log n) times and the
+// amortized cost of insertion is O(log² n). Queries search each of the O(log n) trees.
+//
+// Unlike repeated calls to Tree.Insert, a Dynamic does not degrade in balance as values are
+// added.
+type Dynamic struct {
+	trees    []*Tree
+	count    int
+	dims     int
+	bounding bool
+}
+
+// NewDynamic returns an empty Dynamic. If bounding is true, bounding volumes are determined
+// for each node of the trees when the stored values are Extenders.
+func NewDynamic(bounding bool) *Dynamic {
+	return &Dynamic{bounding: bounding}
+}
+
+// Len returns the number of values in the index.
+func (t *Dynamic) Len() int { return t.count }
+
+// Trees returns the number of non-empty static trees held by the index.
+func (t *Dynamic) Trees() int {
+	var n int
+	for _, c := range t.trees {
+		if c != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Insert adds c to the index. Insert returns ErrPointDims if c does not have the
+// dimensionality of the values already in the index.
+func (t *Dynamic) Insert(c Comparable) error {
+	if t.count != 0 && c.Dims() != t.dims {
+		return ErrPointDims
+	}
+	t.dims = c.Dims()
+	t.count++
+	carry := comparables{c}
+	for i, tr := range t.trees {
+		if tr == nil {
+			t.trees[i] = New(carry, t.bounding)
+			return nil
+		}
+		carry = tr.Root.collect(carry)
+		t.trees[i] = nil
+	}
+	t.trees = append(t.trees, New(carry, t.bounding))
+	return nil
+}
+
+// Remove removes a single value from the index that has the same coordinates as c,
+// returning whether a value was removed.
+func (t *Dynamic) Remove(c Comparable) bool {
+	if t.count == 0 || c.Dims() != t.dims {
+		return false
+	}
+	for i, tr := range t.trees {
+		if tr != nil && tr.Remove(c) {
+			if tr.Count == 0 {
+				t.trees[i] = nil
+			}
+			t.count--
+			return true
+		}
+	}
+	return false
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Dynamic) Nearest(q Comparable) (Comparable, float64) {
+	var (
+		best *Node
+		dist = inf
+	)
+	for _, tr := range t.trees {
+		if tr != nil {
+			best, dist = tr.Root.search(q, best, dist, nil)
+		}
+	}
+	if best == nil {
+		return nil, inf
+	}
+	return best.Point, dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (t *Dynamic) NearestSet(k Keeper, q Comparable) {
+	for _, tr := range t.trees {
+		if tr != nil {
+			tr.Root.searchSet(q, k, nil)
+		}
+	}
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// NearestN returns the n nearest values to the query in min sorted order. If the index holds
+// fewer than n values, all the values in the index are returned.
+func (t *Dynamic) NearestN(q Comparable, n int) []ComparableDist {
+	if n > t.count {
+		n = t.count
+	}
+	if n <= 0 {
+		return nil
+	}
+	k := NewNKeeper(n)
+	t.NearestSet(k, q)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// InRange returns the values within distance d of the query, as measured by the values'
+// Distance method, in min sorted order.
+func (t *Dynamic) InRange(q Comparable, d float64) []ComparableDist {
+	k := NewDistKeeper(d)
+	t.NearestSet(k, q)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// Do performs fn on all values stored in the index. A boolean is returned indicating whether
+// the Do traversal was interrupted by an Operation returning true. The depth passed to fn is
+// the depth within the static tree holding the value.
+func (t *Dynamic) Do(fn Operation) bool {
+	for _, tr := range t.trees {
+		if tr != nil && tr.Do(fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// DoBounded performs fn on all values stored in the index that are within the specified
+// bound. If b is nil, the result is the same as a Do. A boolean is returned indicating
+// whether the DoBounded traversal was interrupted by an Operation returning true.
+func (t *Dynamic) DoBounded(fn Operation, b *Bounding) bool {
+	for _, tr := range t.trees {
+		if tr != nil && tr.DoBounded(fn, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// collect appends the points of the subtree rooted at n to dst.
+func (n *Node) collect(dst comparables) comparables {
+	if n == nil {
+		return dst
+	}
+	dst = append(dst, n.Point)
+	dst = n.Left.collect(dst)
+	return n.Right.collect(dst)
+}
+
+// comparables is a collection of arbitrary Comparable values that satisfies the Interface
+// and Bounder.
+type comparables []Comparable
+
+// Bounds returns the bounding volume of the values in p, or nil if the values are not all
+// Extenders.
+func (p comparables) Bounds() *Bounding {
+	var b *Bounding
+	for _, c := range p {
+		e, ok := c.(Extender)
+		if !ok {
+			return nil
+		}
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p comparables) Index(i int) Comparable         { return p[i] }
+func (p comparables) Len() int                       { return len(p) }
+func (p comparables) Pivot(d Dim) int                { return comparablePlane{comparables: p, Dim: d}.Pivot() }
+func (p comparables) Slice(start, end int) Interface { return p[start:end] }
+
+type comparablePlane struct {
+	Dim
+	comparables
+}
+
+func (p comparablePlane) Less(i, j int) bool {
+	return p.comparables[i].Compare(p.comparables[j], p.Dim) < 0
+}
+func (p comparablePlane) Pivot() int { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p comparablePlane) Slice(start, end int) SortSlicer {
+	p.comparables = p.comparables[start:end]
+	return p
+}
+func (p comparablePlane) Swap(i, j int) {
+	p.comparables[i], p.comparables[j] = p.comparables[j], p.comparables[i]
+}
@@ -0,0 +1,82 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDynamic(c *check.C) {
+	const n = 1000
+	rnd := rand.New(rand.NewSource(1))
+	t := NewDynamic(true)
+	var pts Points
+	for i := 0; i < n; i++ {
+		p := Point{rnd.Float64() * 100, rnd.Float64() * 100, rnd.Float64() * 100}
+		pts = append(pts, p)
+		c.Assert(t.Insert(p), check.Equals, nil)
+
+		// The trees present correspond to the set bits of the count.
+		var want int
+		for v := i + 1; v != 0; v &= v - 1 {
+			want++
+		}
+		c.Assert(t.Trees(), check.Equals, want)
+	}
+	c.Check(t.Insert(Point{1, 2}), check.Equals, ErrPointDims)
+	c.Check(t.Len(), check.Equals, n)
+	for _, tr := range t.trees {
+		if tr != nil {
+			c.Check(tr.Root.isKDTree(), check.Equals, true)
+			c.Check(tr.Root.Bounding, check.NotNil)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		q := Point{rnd.Float64() * 100, rnd.Float64() * 100, rnd.Float64() * 100}
+		ep, ed := nearest(q, pts)
+		p, d := t.Nearest(q)
+		c.Check(p, check.DeepEquals, ep)
+		c.Check(d, check.Equals, ed)
+
+		got := t.NearestN(q, 10)
+		want := nearestN(10, q, pts)
+		c.Check(got, check.DeepEquals, want)
+
+		var inRange, bounded int
+		b := &Bounding{Point{q[0] - 10, q[1] - 10, q[2] - 10}, Point{q[0] + 10, q[1] + 10, q[2] + 10}}
+		for _, p := range pts {
+			if q.Distance(p) <= 100 {
+				inRange++
+			}
+			if b.Contains(p) {
+				bounded++
+			}
+		}
+		c.Check(t.InRange(q, 100), check.HasLen, inRange)
+		var m int
+		t.DoBounded(func(Comparable, *Bounding, int) bool { m++; return false }, b)
+		c.Check(m, check.Equals, bounded)
+	}
+
+	for i, j := range rnd.Perm(n) {
+		c.Assert(t.Remove(pts[j]), check.Equals, true)
+		c.Check(t.Len(), check.Equals, n-i-1)
+	}
+	c.Check(t.Trees(), check.Equals, 0)
+	c.Check(t.Remove(pts[0]), check.Equals, false)
+	p, _ := t.Nearest(pts[0])
+	c.Check(p, check.IsNil)
+}
+
+func BenchmarkDynamicInsert(b *testing.B) {
+	t := NewDynamic(false)
+	for i := 0; i < b.N; i++ {
+		t.Insert(Point{rand.Float64(), rand.Float64(), rand.Float64()})
+	}
+}
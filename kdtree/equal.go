@@ -0,0 +1,99 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "sort"
+
+// Equal returns whether t and other have the same structure, with equal values held in
+// corresponding nodes splitting on the same dimensions. Values are compared with eq, or
+// by their coordinates if eq is nil. Bounding volumes are not compared.
+func (t *Tree) Equal(other *Tree, eq func(a, b Comparable) bool) bool {
+	if eq == nil {
+		eq = sameCoords
+	}
+	return t.Count == other.Count && t.Root.equal(other.Root, eq)
+}
+
+func (n *Node) equal(o *Node, eq func(a, b Comparable) bool) bool {
+	if n == nil || o == nil {
+		return n == o
+	}
+	return n.Plane == o.Plane &&
+		n.Point.Dims() == o.Point.Dims() &&
+		eq(n.Point, o.Point) &&
+		n.Left.equal(o.Left, eq) &&
+		n.Right.equal(o.Right, eq)
+}
+
+// EqualSet returns whether t and other hold the same values, irrespective of the structure
+// of the trees. Values are compared with eq, or by their coordinates if eq is nil. eq must
+// only report values with the same coordinates as being equal, and should be an equivalence
+// relation.
+func (t *Tree) EqualSet(other *Tree, eq func(a, b Comparable) bool) bool {
+	if t.Count != other.Count {
+		return false
+	}
+	a, b := t.Points(), other.Points()
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Sort(byCoords(a))
+	sort.Sort(byCoords(b))
+	for len(a) != 0 {
+		if a[0].Dims() != b[0].Dims() || !sameCoords(a[0], b[0]) {
+			return false
+		}
+		i, j := 1, 1
+		for ; i < len(a) && sameCoords(a[0], a[i]); i++ {
+		}
+		for ; j < len(b) && a[0].Dims() == b[j].Dims() && sameCoords(a[0], b[j]); j++ {
+		}
+		if i != j {
+			return false
+		}
+		if eq != nil && !matchAll(a[:i], b[:j], eq) {
+			return false
+		}
+		a, b = a[i:], b[j:]
+	}
+	return true
+}
+
+// matchAll returns whether each value in a is equal to a distinct value in b under eq.
+func matchAll(a, b []Comparable, eq func(a, b Comparable) bool) bool {
+	used := make([]bool, len(b))
+outer:
+	for _, x := range a {
+		for k, y := range b {
+			if !used[k] && eq(x, y) {
+				used[k] = true
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// byCoords sorts values lexically by their coordinates.
+type byCoords []Comparable
+
+func (p byCoords) Len() int           { return len(p) }
+func (p byCoords) Less(i, j int) bool { return lexLess(p[i], p[j]) }
+func (p byCoords) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// lexLess returns whether a is lexically less than b by its coordinates. Values with fewer
+// dimensions are less than values with more.
+func lexLess(a, b Comparable) bool {
+	if a.Dims() != b.Dims() {
+		return a.Dims() < b.Dims()
+	}
+	for d := Dim(0); d < Dim(a.Dims()); d++ {
+		if c := a.Compare(b, d); c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestEqual(c *check.C) {
+	a := New(append(Points(nil), wpData...), false)
+	b := New(append(Points(nil), wpData...), true)
+	c.Check(a.Equal(b, nil), check.Equals, true)
+	c.Check(a.EqualSet(b, nil), check.Equals, true)
+	c.Check((&Tree{}).Equal(&Tree{}, nil), check.Equals, true)
+	c.Check((&Tree{}).EqualSet(&Tree{}, nil), check.Equals, true)
+
+	// The same values inserted in a different order give a
+	// tree with a different structure.
+	d := &Tree{}
+	for _, p := range wpData {
+		d.Insert(p, false)
+	}
+	c.Check(a.Equal(d, nil), check.Equals, false)
+	c.Check(a.EqualSet(d, nil), check.Equals, true)
+
+	d.Insert(Point{1, 1}, false)
+	c.Check(a.EqualSet(d, nil), check.Equals, false)
+	a.Insert(Point{1, 2}, false)
+	c.Check(a.EqualSet(d, nil), check.Equals, false)
+
+	// Values with the same coordinates are distinguished by eq.
+	x := New(Data{{Point: Point{1, 1}, Value: 1}, {Point: Point{1, 1}, Value: 2}, {Point: Point{2, 0}, Value: 3}}, false)
+	y := New(Data{{Point: Point{2, 0}, Value: 3}, {Point: Point{1, 1}, Value: 2}, {Point: Point{1, 1}, Value: 1}}, false)
+	z := New(Data{{Point: Point{2, 0}, Value: 3}, {Point: Point{1, 1}, Value: 2}, {Point: Point{1, 1}, Value: 2}}, false)
+	value := func(a, b Comparable) bool { return a.(Datum).Value == b.(Datum).Value }
+	c.Check(x.EqualSet(y, value), check.Equals, true)
+	c.Check(x.EqualSet(z, nil), check.Equals, true)
+	c.Check(x.EqualSet(z, value), check.Equals, false)
+	c.Check(x.Equal(x, value), check.Equals, true)
+}
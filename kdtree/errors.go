@@ -0,0 +1,67 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "errors"
+
+var (
+	// ErrEmpty is returned when querying an empty tree.
+	ErrEmpty = errors.New("kdtree: empty tree")
+
+	// ErrNoBounds is returned when a bounding volume is required
+	// but has not been constructed for a tree.
+	ErrNoBounds = errors.New("kdtree: no bounding volume")
+
+	// ErrDimsMismatch is returned when a query does not have the
+	// dimensionality of the values held by a tree. It is the same
+	// error value as ErrPointDims.
+	ErrDimsMismatch = ErrPointDims
+)
+
+// check returns an error if the tree is empty or q does not have the dimensionality of the
+// values at the root of the tree.
+func (t *Tree) check(q Comparable) error {
+	if t.Root == nil {
+		return ErrEmpty
+	}
+	if q.Dims() != t.Root.Point.Dims() {
+		return ErrDimsMismatch
+	}
+	return nil
+}
+
+// NearestErr returns the nearest value to the query and the distance between them, as for
+// Nearest. NearestErr returns ErrEmpty if the tree is empty and ErrDimsMismatch if q does not
+// have the dimensionality of the values in the tree.
+func (t *Tree) NearestErr(q Comparable, opts ...SearchOption) (Comparable, float64, error) {
+	if err := t.check(q); err != nil {
+		return nil, inf, err
+	}
+	c, d := t.Nearest(q, opts...)
+	return c, d, nil
+}
+
+// NearestNErr returns the n nearest values to the query in min sorted order, as for NearestN.
+// NearestNErr returns ErrEmpty if the tree is empty and ErrDimsMismatch if q does not have the
+// dimensionality of the values in the tree.
+func (t *Tree) NearestNErr(q Comparable, n int, opts ...SearchOption) ([]ComparableDist, error) {
+	if err := t.check(q); err != nil {
+		return nil, err
+	}
+	return t.NearestN(q, n, opts...), nil
+}
+
+// ContainsErr returns whether a Comparable is in the bounds of the tree. ContainsErr returns
+// ErrEmpty if the tree is empty, ErrNoBounds if no bounding has been constructed and
+// ErrDimsMismatch if c does not have the dimensionality of the values in the tree.
+func (t *Tree) ContainsErr(c Comparable) (bool, error) {
+	if err := t.check(c); err != nil {
+		return false, err
+	}
+	if t.Root.Bounding == nil {
+		return false, ErrNoBounds
+	}
+	return t.Root.Contains(c), nil
+}
@@ -0,0 +1,44 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestErrors(c *check.C) {
+	empty := &Tree{}
+	_, _, err := empty.NearestErr(Point{0, 0})
+	c.Check(err, check.Equals, ErrEmpty)
+	_, err = empty.NearestNErr(Point{0, 0}, 2)
+	c.Check(err, check.Equals, ErrEmpty)
+	_, err = empty.ContainsErr(Point{0, 0})
+	c.Check(err, check.Equals, ErrEmpty)
+	c.Check(empty.Contains(Point{0, 0}), check.Equals, true)
+
+	t := New(append(Points(nil), wpData...), false)
+	_, _, err = t.NearestErr(Point{0, 0, 0})
+	c.Check(err, check.Equals, ErrDimsMismatch)
+	_, err = t.NearestNErr(Point{0}, 2)
+	c.Check(err, check.Equals, ErrDimsMismatch)
+	_, err = t.ContainsErr(Point{0, 0})
+	c.Check(err, check.Equals, ErrNoBounds)
+
+	p, d, err := t.NearestErr(Point{9, 5})
+	c.Check(err, check.Equals, nil)
+	c.Check(p, check.DeepEquals, Point{9, 6})
+	c.Check(d, check.Equals, 1.)
+	ns, err := t.NearestNErr(Point{9, 5}, 2)
+	c.Check(err, check.Equals, nil)
+	c.Check(ns, check.HasLen, 2)
+
+	t = New(append(Points(nil), wpData...), true)
+	ok, err := t.ContainsErr(Point{0, 0})
+	c.Check(err, check.Equals, nil)
+	c.Check(ok, check.Equals, false)
+	ok, err = t.ContainsErr(Point{3, 3})
+	c.Check(err, check.Equals, nil)
+	c.Check(ok, check.Equals, true)
+}
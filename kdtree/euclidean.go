@@ -0,0 +1,73 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+var _ coorder = EuclideanPoint{}
+
+// An EuclideanPoint is a point in a k-d space whose Distance method returns the Euclidean
+// distance rather than its square. The distance is computed with scaling, as for
+// math.Hypot, so it neither overflows nor underflows unless the distance itself is not
+// representable. Searches with an EuclideanPoint query prune on the distance rather than
+// the squared distance to splitting planes, so they remain exact for extreme coordinates
+// where the squared distances of Point values overflow to +Inf or underflow to zero and
+// cease to distinguish between values.
+//
+// The Euclidean distance is computed with a relative error of a few units in the last
+// place. Coordinates must be no greater in magnitude than half the greatest float64 so that
+// their differences do not overflow. EuclideanPoint values may be compared with the values
+// accepted by Point.Compare, so any tree of such values may be queried by EuclideanPoints.
+type EuclideanPoint []float64
+
+func (p EuclideanPoint) coord(d Dim) float64 { return p[d] }
+
+// Compare satisfies the Comparable interface. c must be a value accepted by Point.Compare
+// or an EuclideanPoint.
+func (p EuclideanPoint) Compare(c Comparable, d Dim) float64 { return p[d] - at(c, d) }
+
+// Dims returns the number of dimensions of p.
+func (p EuclideanPoint) Dims() int { return len(p) }
+
+// Distance returns the Euclidean distance between p and c. c must be a value accepted by
+// Point.Compare or an EuclideanPoint.
+func (p EuclideanPoint) Distance(c Comparable) float64 {
+	var scale float64
+	for d, v := range p {
+		scale = math.Max(scale, math.Abs(v-at(c, Dim(d))))
+	}
+	if scale == 0 || math.IsInf(scale, 1) || math.IsNaN(scale) {
+		return scale
+	}
+	var sum float64
+	for d, v := range p {
+		x := (v - at(c, Dim(d))) / scale
+		sum += x * x
+	}
+	return scale * math.Sqrt(sum)
+}
+
+// Extend satisfies the Extender interface. The bounding volume is held as EuclideanPoint
+// values.
+func (p EuclideanPoint) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{append(EuclideanPoint(nil), p...), append(EuclideanPoint(nil), p...)}
+	}
+	min, max := b[0].(EuclideanPoint), b[1].(EuclideanPoint)
+	for d, v := range p {
+		min[d] = math.Min(min[d], v)
+		max[d] = math.Max(max[d], v)
+	}
+	return b
+}
+
+// planeDist returns the distance in the metric of the query q to a splitting plane, given
+// the difference c between the coordinates of q and the plane.
+func planeDist(q Comparable, c float64) float64 {
+	if _, ok := q.(EuclideanPoint); ok {
+		return math.Abs(c)
+	}
+	return c * c
+}
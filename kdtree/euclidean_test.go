@@ -0,0 +1,67 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestEuclideanDistance(c *check.C) {
+	for _, test := range []struct {
+		p    EuclideanPoint
+		q    Comparable
+		want float64
+	}{
+		{p: EuclideanPoint{0, 0}, q: Point{3, 4}, want: 5},
+		{p: EuclideanPoint{3e200, 0}, q: Point{0, 4e200}, want: 5e200},
+		{p: EuclideanPoint{3e-200, 0}, q: EuclideanPoint{0, 4e-200}, want: 5e-200},
+		{p: EuclideanPoint{1, 1}, q: Point{1, 1}, want: 0},
+		{p: EuclideanPoint{math.Inf(1), 0}, q: Point{0, 0}, want: math.Inf(1)},
+	} {
+		got := test.p.Distance(test.q)
+		c.Check(got == test.want || math.Abs(got-test.want) <= 1e-15*test.want, check.Equals, true, check.Commentf("%v %v: got %v", test.p, test.q, got))
+	}
+	c.Check(math.IsNaN(EuclideanPoint{math.NaN()}.Distance(Point{0})), check.Equals, true)
+	c.Check(EuclideanPoint{1, 2}.Extend(nil), check.DeepEquals, &Bounding{EuclideanPoint{1, 2}, EuclideanPoint{1, 2}})
+}
+
+func (s *S) TestEuclideanSearch(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, scale := range []float64{1e200, 1e-200} {
+		p := make(Points, 200)
+		for i := range p {
+			p[i] = Point{rnd.NormFloat64() * scale, rnd.NormFloat64() * scale}
+		}
+		t := New(append(Points(nil), p...), true)
+		for i := 0; i < 20; i++ {
+			q := EuclideanPoint{rnd.NormFloat64() * scale, rnd.NormFloat64() * scale}
+			want := make([]ComparableDist, len(p))
+			for j, v := range p {
+				want[j] = ComparableDist{Comparable: v, Dist: q.Distance(v)}
+			}
+			sort.Sort(byDistance(want))
+
+			v, d := t.Nearest(q)
+			c.Check(v, check.DeepEquals, want[0].Comparable, check.Commentf("scale %v test %d", scale, i))
+			c.Check(d, check.Equals, want[0].Dist)
+			c.Check(t.NearestN(q, 5), check.DeepEquals, want[:5], check.Commentf("scale %v test %d", scale, i))
+
+			// Squared distances no longer distinguish the values.
+			pq := Point(q)
+			c.Check(pq.Distance(want[0].Comparable) == pq.Distance(want[1].Comparable), check.Equals, true)
+		}
+	}
+}
+
+// byDistance sorts ComparableDists by distance.
+type byDistance []ComparableDist
+
+func (s byDistance) Len() int           { return len(s) }
+func (s byDistance) Less(i, j int) bool { return s[i].Dist < s[j].Dist }
+func (s byDistance) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
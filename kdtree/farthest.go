@@ -0,0 +1,88 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "container/heap"
+
+// SampleFarthest returns k well spread values stored in the tree chosen by greedy farthest
+// point sampling. The first value returned by Points is taken as the first sample, and each
+// subsequent sample is the value farthest, as measured by the values' Distance method, from
+// its nearest preceding sample. Ties are broken in favour of the value earlier in the order
+// returned by Points. After each sample is chosen, only the values within the distance of
+// the sample from its own nearest preceding sample are examined, since no others can be
+// brought nearer to their nearest sample. If the tree holds no more than k values, all the
+// values are returned in sampling order.
+func (t *Tree) SampleFarthest(k int) []Comparable {
+	values := t.Points()
+	if k <= 0 || len(values) == 0 {
+		return nil
+	}
+	if k > len(values) {
+		k = len(values)
+	}
+	items := make(comparables, len(values))
+	for i, v := range values {
+		items[i] = indexed{Comparable: v, i: i}
+	}
+	it := New(items, false)
+
+	// dist holds the distance from each value to its
+	// nearest sample, or -1 for samples, and queue holds
+	// candidates keyed by their distance at the time they
+	// were queued.
+	dist := make([]float64, len(values))
+	for i := range dist {
+		dist[i] = inf
+	}
+	var queue farQueue
+	samples := make([]Comparable, 0, k)
+	for next := 0; ; {
+		r := dist[next]
+		dist[next] = -1
+		samples = append(samples, values[next])
+		if len(samples) == k {
+			return samples
+		}
+		for _, nd := range it.InRange(indexed{Comparable: values[next], i: next}, r) {
+			i := nd.Comparable.(indexed).i
+			if nd.Dist < dist[i] {
+				dist[i] = nd.Dist
+				heap.Push(&queue, farCandidate{i: i, dist: nd.Dist})
+			}
+		}
+		// Discard candidates whose distance has been reduced
+		// since they were queued.
+		for {
+			c := heap.Pop(&queue).(farCandidate)
+			if c.dist == dist[c.i] {
+				next = c.i
+				break
+			}
+		}
+	}
+}
+
+type farCandidate struct {
+	i    int
+	dist float64
+}
+
+// farQueue is a max-heap of candidates ordered by distance and then by index.
+type farQueue []farCandidate
+
+func (q farQueue) Len() int { return len(q) }
+func (q farQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist > q[j].dist
+	}
+	return q[i].i < q[j].i
+}
+func (q farQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *farQueue) Push(x interface{}) { *q = append(*q, x.(farCandidate)) }
+func (q *farQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
@@ -0,0 +1,60 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// naiveFarthest returns k values of p chosen by greedy farthest point sampling.
+func naiveFarthest(p []Comparable, k int) []Comparable {
+	dist := make([]float64, len(p))
+	for i := range dist {
+		dist[i] = inf
+	}
+	var samples []Comparable
+	for next := 0; len(samples) < k; {
+		samples = append(samples, p[next])
+		dist[next] = -1
+		best := -1
+		for i, v := range p {
+			if dist[i] < 0 {
+				continue
+			}
+			if d := v.Distance(p[next]); d < dist[i] {
+				dist[i] = d
+			}
+			if best < 0 || dist[i] > dist[best] {
+				best = i
+			}
+		}
+		next = best
+	}
+	return samples
+}
+
+func (s *S) TestSampleFarthest(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	first := t.Points()[0]
+	got := t.SampleFarthest(3)
+	c.Check(got, check.DeepEquals, naiveFarthest(t.Points(), 3))
+	c.Check(got[0], check.DeepEquals, first)
+	c.Check(t.SampleFarthest(10), check.HasLen, 6)
+	c.Check(t.SampleFarthest(0), check.HasLen, 0)
+	c.Check((&Tree{}).SampleFarthest(2), check.HasLen, 0)
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 2000)
+	for i := range p {
+		// Integer coordinates give many ties and duplicates.
+		p[i] = Point{float64(rnd.Intn(40)), float64(rnd.Intn(40)), float64(rnd.Intn(40))}
+	}
+	t = New(p, false)
+	c.Check(t.SampleFarthest(100), check.DeepEquals, naiveFarthest(t.Points(), 100))
+	all := t.SampleFarthest(len(p))
+	c.Check(all, check.DeepEquals, naiveFarthest(t.Points(), len(p)))
+}
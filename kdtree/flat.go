@@ -0,0 +1,564 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+// A FlatTree is a read-only k-d tree of Point values held in an index-based layout. The
+// layout is identical to the flat file format written by WriteTo, so a FlatTree may be
+// obtained from the bytes of a file without deserialisation.
+//
+// Nodes are stored in pre-order; the point of the ith node is held at coordinates
+// [i*dims, (i+1)*dims) of a single coordinate matrix, and if bounds are present, the
+// bounding volume of the ith node is held at [2*i*dims, 2*(i+1)*dims) of a bounds matrix,
+// minimum first.
+type FlatTree struct {
+	dims   int
+	count  int
+	nodes  []flatNode
+	coords []float64
+	bounds []float64
+
+	// data retains the storage that the tree is a view of.
+	data []byte
+
+	// sum is the checksum of data[:size] if checked is true.
+	sum     uint32
+	size    uint64
+	checked bool
+
+	// unmap releases a memory mapping holding data.
+	unmap func() error
+}
+
+// flatNode is a node record in a FlatTree. Left and Right are node indices, with -1
+// indicating no child.
+type flatNode struct {
+	Left, Right int32
+	Plane       uint32
+	_           uint32
+}
+
+const (
+	flatMagic      = "kdflat\x00\x00"
+	flatVersion1   = 1
+	flatVersion    = 2
+	flatHeaderSize = 64
+	flatNodeSize   = int(unsafe.Sizeof(flatNode{}))
+	flatHasBounds  = 1
+)
+
+// ErrFlatFormat is returned when data is not a valid flat tree.
+var ErrFlatFormat = errors.New("kdtree: invalid flat tree format")
+
+// Flatten returns a FlatTree holding the points and structure of t. The coordinates of
+// each stored point are obtained by calling coords; if coords is nil, the stored points
+// and bounding volumes must be Point values. All points must have the same number of
+// dimensions. Bounding volumes are retained if every node of t has one.
+func (t *Tree) Flatten(coords func(Comparable) []float64) (*FlatTree, error) {
+	if coords == nil {
+		coords = func(c Comparable) []float64 { return c.(Point) }
+	}
+	f := &FlatTree{count: t.Count}
+	if t.Root == nil {
+		return f, nil
+	}
+	f.dims = t.Root.Point.Dims()
+	bounded := true
+	t.Root.walk(func(n *Node) {
+		bounded = bounded && n.Bounding != nil
+	})
+	var err error
+	f.add(t.Root, coords, bounded, &err)
+	if err != nil {
+		return nil, err
+	}
+	if !bounded {
+		f.bounds = nil
+	}
+	return f, nil
+}
+
+// walk calls fn for each node of the subtree rooted at n in pre-order.
+func (n *Node) walk(fn func(*Node)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	n.Left.walk(fn)
+	n.Right.walk(fn)
+}
+
+func (f *FlatTree) add(n *Node, coords func(Comparable) []float64, bounded bool, err *error) int32 {
+	if n == nil || *err != nil {
+		return -1
+	}
+	i := int32(len(f.nodes))
+	f.nodes = append(f.nodes, flatNode{Plane: uint32(n.Plane)})
+	f.coords = append(f.coords, f.check(coords(n.Point), err)...)
+	if bounded {
+		f.bounds = append(f.bounds, f.check(coords(n.Bounding[0]), err)...)
+		f.bounds = append(f.bounds, f.check(coords(n.Bounding[1]), err)...)
+	}
+	l := f.add(n.Left, coords, bounded, err)
+	r := f.add(n.Right, coords, bounded, err)
+	f.nodes[i].Left, f.nodes[i].Right = l, r
+	return i
+}
+
+func (f *FlatTree) check(c []float64, err *error) []float64 {
+	if len(c) != f.dims && *err == nil {
+		*err = errors.New("kdtree: inconsistent point dimensions")
+	}
+	return c
+}
+
+// Len returns the number of points in the tree.
+func (f *FlatTree) Len() int { return f.count }
+
+// Dims returns the number of dimensions of the points in the tree.
+func (f *FlatTree) Dims() int { return f.dims }
+
+func (f *FlatTree) point(i int32) Point {
+	return Point(f.coords[int(i)*f.dims : (int(i)+1)*f.dims : (int(i)+1)*f.dims])
+}
+
+func (f *FlatTree) bounding(i int32) *Bounding {
+	if f.bounds == nil {
+		return nil
+	}
+	o := 2 * int(i) * f.dims
+	return &Bounding{
+		Point(f.bounds[o : o+f.dims : o+f.dims]),
+		Point(f.bounds[o+f.dims : o+2*f.dims : o+2*f.dims]),
+	}
+}
+
+// Nearest returns the nearest value to the query and the distance between them, choosing
+// between equally near values as described for Tree.Nearest. The returned Point shares
+// storage with the tree and must not be altered.
+func (f *FlatTree) Nearest(q Comparable) (Comparable, float64) {
+	if len(f.nodes) == 0 {
+		return nil, inf
+	}
+	i, dist := f.search(0, q, -1, inf)
+	if i < 0 {
+		return nil, inf
+	}
+	return f.point(i), dist
+}
+
+func (f *FlatTree) search(i int32, q Comparable, bn int32, dist float64) (int32, float64) {
+	if i < 0 {
+		return bn, dist
+	}
+	n := f.nodes[i]
+	p := f.point(i)
+	c := q.Compare(p, Dim(n.Plane))
+	d := q.Distance(p)
+	if d < dist || (d == dist && bn >= 0 && lexLess(p, f.point(bn))) {
+		bn, dist = i, d
+	}
+
+	near, far := n.Left, n.Right
+	if c > 0 {
+		near, far = far, near
+	}
+	bn, dist = f.search(near, q, bn, dist)
+	if planeDist(q, c) <= dist {
+		bn, dist = f.search(far, q, bn, dist)
+	}
+	return bn, dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k, as
+// described for Tree.NearestSet.
+func (f *FlatTree) NearestSet(k Keeper, q Comparable) {
+	if len(f.nodes) == 0 {
+		return
+	}
+	f.searchSet(0, q, k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+func (f *FlatTree) searchSet(i int32, q Comparable, k Keeper) {
+	if i < 0 {
+		return
+	}
+	n := f.nodes[i]
+	p := f.point(i)
+	c := q.Compare(p, Dim(n.Plane))
+	k.Keep(ComparableDist{Comparable: p, Dist: q.Distance(p)})
+	near, far := n.Left, n.Right
+	if c > 0 {
+		near, far = far, near
+	}
+	f.searchSet(near, q, k)
+	if planeDist(q, c) <= k.Max().Dist {
+		f.searchSet(far, q, k)
+	}
+}
+
+// Do performs fn on all values stored in the tree in order, as described for Tree.Do.
+func (f *FlatTree) Do(fn Operation) bool {
+	if len(f.nodes) == 0 {
+		return false
+	}
+	return f.do(0, fn, 0)
+}
+
+func (f *FlatTree) do(i int32, fn Operation, depth int) (done bool) {
+	n := f.nodes[i]
+	if n.Left >= 0 {
+		done = f.do(n.Left, fn, depth+1)
+		if done {
+			return
+		}
+	}
+	done = fn(f.point(i), f.bounding(i), depth)
+	if done {
+		return
+	}
+	if n.Right >= 0 {
+		done = f.do(n.Right, fn, depth+1)
+	}
+	return
+}
+
+// DoBounded performs fn on all values stored in the tree that are within the specified
+// bound, as described for Tree.DoBounded.
+func (f *FlatTree) DoBounded(fn Operation, b *Bounding) bool {
+	if len(f.nodes) == 0 {
+		return false
+	}
+	if b == nil {
+		return f.do(0, fn, 0)
+	}
+	return f.doBounded(0, fn, b, 0)
+}
+
+func (f *FlatTree) doBounded(i int32, fn Operation, b *Bounding, depth int) (done bool) {
+	n := f.nodes[i]
+	p := f.point(i)
+	lc, hc := b[0].Compare(p, Dim(n.Plane)), b[1].Compare(p, Dim(n.Plane))
+	if lc <= 0 && n.Left >= 0 {
+		done = f.doBounded(n.Left, fn, b, depth+1)
+		if done {
+			return
+		}
+	}
+	if b.Contains(p) {
+		done = fn(p, b, depth)
+		if done {
+			return
+		}
+	}
+	if hc >= 0 && n.Right >= 0 {
+		done = f.doBounded(n.Right, fn, b, depth+1)
+	}
+	return
+}
+
+// Tree returns a Tree holding the points and structure of f. The points of the returned
+// Tree share storage with f.
+func (f *FlatTree) Tree() *Tree {
+	t := &Tree{Count: f.count}
+	if len(f.nodes) == 0 {
+		return t
+	}
+	nodes := make([]Node, len(f.nodes))
+	for i, n := range f.nodes {
+		nodes[i] = Node{Point: f.point(int32(i)), Plane: Dim(n.Plane), Bounding: f.bounding(int32(i))}
+		if n.Left >= 0 {
+			nodes[i].Left = &nodes[n.Left]
+		}
+		if n.Right >= 0 {
+			nodes[i].Right = &nodes[n.Right]
+		}
+	}
+	t.Root = &nodes[0]
+	return t
+}
+
+// The flat file format is a 64 byte little-endian header followed by the node table,
+// the coordinate matrix and, optionally, the bounds matrix, each starting at an 8 byte
+// aligned offset, and the little-endian CRC-32 (Castagnoli) checksum of all the
+// preceding bytes. Version 1 of the format has no checksum.
+//
+//	offset  size  field
+//	 0      8     magic "kdflat\x00\x00"
+//	 8      4     format version
+//	12      4     dimensions
+//	16      8     number of points in the tree
+//	24      8     number of nodes
+//	32      8     flags; bit 0 indicates the presence of bounds
+//	40      8     offset of the node table
+//	48      8     offset of the coordinate matrix
+//	56      8     offset of the bounds matrix
+//
+// Each node table entry is 16 bytes: the int32 left and right child indices, the
+// uint32 splitting dimension and 4 bytes of padding.
+type flatHeader struct {
+	dims, count, nodes, flags  uint64
+	nodeOff, coordOff, bndsOff uint64
+}
+
+func (f *FlatTree) header() flatHeader {
+	h := flatHeader{
+		dims:  uint64(f.dims),
+		count: uint64(f.count),
+		nodes: uint64(len(f.nodes)),
+	}
+	h.nodeOff = flatHeaderSize
+	h.coordOff = h.nodeOff + h.nodes*uint64(flatNodeSize)
+	h.bndsOff = h.coordOff + 8*uint64(len(f.coords))
+	if f.bounds != nil {
+		h.flags |= flatHasBounds
+	}
+	return h
+}
+
+func (h flatHeader) size() uint64 {
+	if h.flags&flatHasBounds == 0 {
+		return h.bndsOff
+	}
+	return h.bndsOff + 16*h.nodes*h.dims
+}
+
+// WriteTo writes f to w in the flat file format. It satisfies the io.WriterTo interface.
+func (f *FlatTree) WriteTo(w io.Writer) (int64, error) {
+	sum := crc32.New(castagnoli)
+	dst := w
+	w = io.MultiWriter(dst, sum)
+	h := f.header()
+	b := make([]byte, flatHeaderSize, h.coordOff)
+	copy(b, flatMagic)
+	binary.LittleEndian.PutUint32(b[8:], flatVersion)
+	binary.LittleEndian.PutUint32(b[12:], uint32(h.dims))
+	for i, v := range []uint64{h.count, h.nodes, h.flags, h.nodeOff, h.coordOff, h.bndsOff} {
+		binary.LittleEndian.PutUint64(b[16+8*i:], v)
+	}
+	var rec [16]byte
+	for _, n := range f.nodes {
+		binary.LittleEndian.PutUint32(rec[0:], uint32(n.Left))
+		binary.LittleEndian.PutUint32(rec[4:], uint32(n.Right))
+		binary.LittleEndian.PutUint32(rec[8:], n.Plane)
+		b = append(b, rec[:]...)
+	}
+	n, err := w.Write(b)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, m := range [][]float64{f.coords, f.bounds} {
+		n, err = writeFloats(w, m)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	binary.LittleEndian.PutUint32(rec[:], sum.Sum32())
+	n, err = dst.Write(rec[:4])
+	return written + int64(n), err
+}
+
+func writeFloats(w io.Writer, v []float64) (int, error) {
+	if littleEndian {
+		return w.Write(floatBytes(v))
+	}
+	var (
+		written int
+		buf     = make([]byte, 0, 4096)
+	)
+	for i, f := range v {
+		buf = buf[:len(buf)+8]
+		binary.LittleEndian.PutUint64(buf[len(buf)-8:], math.Float64bits(f))
+		if len(buf) == cap(buf) || i == len(v)-1 {
+			n, err := w.Write(buf)
+			written += n
+			if err != nil {
+				return written, err
+			}
+			buf = buf[:0]
+		}
+	}
+	return written, nil
+}
+
+// ReadFlat reads a FlatTree in the flat file format from r.
+func ReadFlat(r io.Reader) (*FlatTree, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := FlatFrom(alignedCopy(b))
+	if err != nil {
+		return nil, err
+	}
+	err = f.Verify()
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FlatFrom returns a FlatTree that is a view of the flat file format data in b. If b is 8 byte
+// aligned and the host is little-endian, no copying is performed and b must not be altered
+// while the FlatTree is in use. The structure of the node table is validated, but
+// coordinates are not and the checksum is not verified; Verify may be used to check the
+// integrity of the data.
+func FlatFrom(b []byte) (*FlatTree, error) {
+	if len(b) < flatHeaderSize || string(b[:8]) != flatMagic {
+		return nil, ErrFlatFormat
+	}
+	version := binary.LittleEndian.Uint32(b[8:])
+	if version != flatVersion1 && version != flatVersion {
+		return nil, ErrVersion
+	}
+	h := flatHeader{dims: uint64(binary.LittleEndian.Uint32(b[12:]))}
+	for i, p := range []*uint64{&h.count, &h.nodes, &h.flags, &h.nodeOff, &h.coordOff, &h.bndsOff} {
+		*p = binary.LittleEndian.Uint64(b[16+8*i:])
+	}
+	const maxNodes = math.MaxInt32
+	if h.nodes > maxNodes || h.count != h.nodes || h.dims > maxDims || (h.nodes != 0 && h.dims == 0) ||
+		h.nodeOff != flatHeaderSize ||
+		h.coordOff != h.nodeOff+h.nodes*uint64(flatNodeSize) ||
+		h.bndsOff != h.coordOff+8*h.nodes*h.dims ||
+		h.size() > uint64(len(b)) {
+		return nil, ErrFlatFormat
+	}
+	var sum uint32
+	if version >= flatVersion {
+		if h.size()+4 > uint64(len(b)) {
+			return nil, ErrFlatFormat
+		}
+		sum = binary.LittleEndian.Uint32(b[h.size():])
+	}
+	if !littleEndian || uintptr(unsafe.Pointer(&b[0]))%8 != 0 {
+		b = alignedCopy(b)
+	}
+	f := &FlatTree{
+		dims:   int(h.dims),
+		count:  int(h.count),
+		nodes:  nodeView(b[h.nodeOff:h.coordOff], int(h.nodes)),
+		coords: floatView(b[h.coordOff:h.bndsOff]),
+		data:   b,
+
+		sum:     sum,
+		size:    h.size(),
+		checked: version >= flatVersion,
+	}
+	if h.flags&flatHasBounds != 0 {
+		f.bounds = floatView(b[h.bndsOff:h.size()])
+	}
+	if !preorder(len(f.nodes), func(i int) (int, int) { return int(f.nodes[i].Left), int(f.nodes[i].Right) }) {
+		return nil, ErrFlatFormat
+	}
+	for _, n := range f.nodes {
+		if uint64(n.Plane) >= h.dims {
+			return nil, ErrFlatFormat
+		}
+	}
+	return f, nil
+}
+
+// Verify returns ErrChecksum if the data that f is a view of does not match its checksum.
+// Verify returns nil for trees read from flat data without a checksum and for trees
+// created by Tree.Flatten.
+func (f *FlatTree) Verify() error {
+	if f.checked && crc32.Checksum(f.data[:f.size], castagnoli) != f.sum {
+		return ErrChecksum
+	}
+	return nil
+}
+
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// alignedCopy returns a copy of b with 8 byte aligned storage.
+func alignedCopy(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	a := make([]uint64, (len(b)+7)/8)
+	var c []byte
+	view(unsafe.Pointer(&c), unsafe.Pointer(&a[0]), len(b))
+	copy(c, b)
+	return c
+}
+
+func floatBytes(v []float64) []byte {
+	if len(v) == 0 {
+		return nil
+	}
+	var b []byte
+	view(unsafe.Pointer(&b), unsafe.Pointer(&v[0]), 8*len(v))
+	return b
+}
+
+// view sets the slice pointed to by s to have length and capacity n over the storage at
+// data.
+func view(s, data unsafe.Pointer, n int) {
+	h := (*reflect.SliceHeader)(s)
+	h.Data = uintptr(data)
+	h.Len = n
+	h.Cap = n
+}
+
+// floatView returns the 8 byte aligned little-endian data in b as a []float64. On big-endian
+// hosts the values are decoded into new storage.
+func floatView(b []byte) []float64 {
+	if len(b) == 0 {
+		return nil
+	}
+	if littleEndian {
+		var v []float64
+		view(unsafe.Pointer(&v), unsafe.Pointer(&b[0]), len(b)/8)
+		return v
+	}
+	v := make([]float64, len(b)/8)
+	for i := range v {
+		v[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return v
+}
+
+// nodeView returns the n node records in b as a []flatNode. On big-endian hosts the
+// records are decoded into new storage.
+func nodeView(b []byte, n int) []flatNode {
+	if n == 0 {
+		return nil
+	}
+	if littleEndian {
+		var v []flatNode
+		view(unsafe.Pointer(&v), unsafe.Pointer(&b[0]), n)
+		return v
+	}
+	v := make([]flatNode, n)
+	for i := range v {
+		r := b[i*flatNodeSize:]
+		v[i] = flatNode{
+			Left:  int32(binary.LittleEndian.Uint32(r[0:])),
+			Right: int32(binary.LittleEndian.Uint32(r[4:])),
+			Plane: binary.LittleEndian.Uint32(r[8:]),
+		}
+	}
+	return v
+}
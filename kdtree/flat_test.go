@@ -0,0 +1,150 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFlatten(c *check.C) {
+	for i, t := range []*Tree{
+		{},
+		New(wpData, false),
+		New(append(Points(nil), wpData...), true),
+		bTree,
+	} {
+		f, err := t.Flatten(nil)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(f.Len(), check.Equals, t.Len())
+		c.Check(f.Tree().Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+
+		var buf bytes.Buffer
+		n, err := f.WriteTo(&buf)
+		c.Assert(err, check.IsNil)
+		c.Check(n, check.Equals, int64(buf.Len()))
+
+		got, err := ReadFlat(bytes.NewReader(buf.Bytes()))
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Tree().Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+
+		// Misaligned data must be accepted.
+		b := make([]byte, buf.Len()+1)
+		copy(b[1:], buf.Bytes())
+		got, err = FlatFrom(b[1:])
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Tree().Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+	}
+}
+
+func (s *S) TestFlatQueries(c *check.C) {
+	f, err := bTree.Flatten(nil)
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 100; i++ {
+		q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+		p, d := f.Nearest(q)
+		ep, ed := bTree.Nearest(q)
+		c.Check(p, check.DeepEquals, ep, check.Commentf("Test %d", i))
+		c.Check(d, check.Equals, ed)
+
+		fk, tk := NewNKeeper(5), NewNKeeper(5)
+		f.NearestSet(fk, q)
+		bTree.NearestSet(tk, q)
+		c.Check(fk.Heap, check.DeepEquals, tk.Heap)
+	}
+
+	// Ties must be resolved as they are by the tree.
+	grid := Points{{0, 0}, {2, 0}, {0, 2}, {2, 2}, {1, 3}, {3, 1}}
+	for _, p := range []Points{grid, {{2, 2}, {0, 0}, {2, 0}, {0, 2}}} {
+		t := New(p, false)
+		f, err := t.Flatten(nil)
+		c.Assert(err, check.IsNil)
+		for _, q := range []Point{{1, 1}, {1, 2}, {2, 1}} {
+			p, d := f.Nearest(q)
+			ep, ed := t.Nearest(q)
+			c.Check(p, check.DeepEquals, ep, check.Commentf("Query %v", q))
+			c.Check(d, check.Equals, ed)
+		}
+	}
+
+	wf, _ := New(wpData, false).Flatten(nil)
+	var result Points
+	killed := wf.DoBounded(func(c Comparable, _ *Bounding, _ int) (done bool) {
+		result = append(result, c.(Point))
+		return
+	}, &Bounding{Point{3, 4}, Point{10, 10}})
+	c.Check(result, check.DeepEquals, Points{Point{5, 4}, Point{4, 7}, Point{9, 6}})
+	c.Check(killed, check.Equals, false)
+
+	result = nil
+	wf.Do(func(c Comparable, _ *Bounding, _ int) (done bool) {
+		result = append(result, c.(Point))
+		return
+	})
+	c.Check(result, check.DeepEquals, wpData)
+}
+
+func (s *S) TestFlatInvalid(c *check.C) {
+	f, _ := New(wpData, false).Flatten(nil)
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	b := buf.Bytes()
+
+	_, err := FlatFrom(b[:len(b)-1])
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	bad := append([]byte(nil), b...)
+	bad[0] = 'x'
+	_, err = FlatFrom(bad)
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	bad = append([]byte(nil), b...)
+	bad[flatHeaderSize] = 0 // Root's left child refers to itself.
+	_, err = FlatFrom(bad)
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	bad = append([]byte(nil), b...)
+	copy(bad[flatHeaderSize+4:], bad[flatHeaderSize:flatHeaderSize+4]) // Root's children are the same node.
+	_, err = FlatFrom(bad)
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	bad = append([]byte(nil), b...)
+	bad[16]++ // Count does not match the number of nodes.
+	_, err = FlatFrom(bad)
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	_, err = New(Points{{1, 2}, {1, 2, 3}}, false).Flatten(nil)
+	c.Check(err, check.NotNil)
+}
+
+func (s *S) TestFlatChecksum(c *check.C) {
+	f, _ := New(wpData, true).Flatten(nil)
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	b := buf.Bytes()
+
+	_, err := ReadFlat(bytes.NewReader(b))
+	c.Check(err, check.IsNil)
+
+	bad := append([]byte(nil), b...)
+	bad[len(bad)-8] ^= 1
+	_, err = ReadFlat(bytes.NewReader(bad))
+	c.Check(err, check.Equals, ErrChecksum)
+	g, err := FlatFrom(bad)
+	c.Assert(err, check.IsNil)
+	c.Check(g.Verify(), check.Equals, ErrChecksum)
+
+	_, err = FlatFrom(b[:len(b)-4])
+	c.Check(err, check.Equals, ErrFlatFormat)
+
+	v1 := append([]byte(nil), b[:len(b)-4]...)
+	v1[8] = flatVersion1
+	g, err = ReadFlat(bytes.NewReader(v1))
+	c.Assert(err, check.IsNil)
+	c.Check(g.Tree(), check.DeepEquals, f.Tree())
+	c.Check(f.Verify(), check.IsNil)
+}
@@ -0,0 +1,258 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"container/heap"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// forestDims is the number of highest variance dimensions from
+	// which the splitting dimension of a forest node is chosen.
+	forestDims = 5
+
+	// forestSample is the maximum number of points used to estimate
+	// the variance of each dimension when building a forest node.
+	forestSample = 100
+)
+
+// A Forest is a set of randomized k-d trees for approximate nearest neighbour search of
+// high dimensional data. Each tree splits each node on a dimension chosen at random from
+// the dimensions with the highest variance. Searches descend all trees, sharing a single
+// priority queue of unexplored branches across the forest, until a specified number of
+// points have been checked.
+//
+// Increasing the number of trees or the number of checks made by a search increases
+// the likelihood of finding the true nearest neighbours at the cost of memory and search
+// time respectively.
+type Forest struct {
+	points []Comparable
+	trees  []*forestNode
+}
+
+type forestNode struct {
+	point       int
+	plane       Dim
+	left, right *forestNode
+}
+
+// NewForest returns a Forest of n randomized k-d trees constructed from the values in p.
+// Random choices are made using rnd; if rnd is nil, a source with a fixed seed is used.
+// If n is less than one, a single tree is constructed.
+func NewForest(p Interface, n int, rnd *rand.Rand) *Forest {
+	if n < 1 {
+		n = 1
+	}
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(1))
+	}
+	f := &Forest{points: make([]Comparable, p.Len())}
+	for i := range f.points {
+		f.points[i] = p.Index(i)
+	}
+	if len(f.points) == 0 {
+		return f
+	}
+	f.trees = make([]*forestNode, n)
+	for i := range f.trees {
+		idx := make([]int, len(f.points))
+		for j := range idx {
+			idx[j] = j
+		}
+		f.trees[i] = f.build(idx, rnd)
+	}
+	return f
+}
+
+func (f *Forest) build(idx []int, rnd *rand.Rand) *forestNode {
+	if len(idx) == 0 {
+		return nil
+	}
+	if len(idx) == 1 {
+		return &forestNode{point: idx[0]}
+	}
+	plane := f.randomPlane(idx, rnd)
+	piv := len(idx) / 2
+	Select(forestPlane{points: f.points, idx: idx, dim: plane}, piv)
+	return &forestNode{
+		point: idx[piv],
+		plane: plane,
+		left:  f.build(idx[:piv], rnd),
+		right: f.build(idx[piv+1:], rnd),
+	}
+}
+
+// randomPlane returns a dimension chosen at random from the dimensions with the highest
+// variance over a sample of the points indexed by idx.
+func (f *Forest) randomPlane(idx []int, rnd *rand.Rand) Dim {
+	ref := f.points[idx[0]]
+	dims := ref.Dims()
+	n := len(idx)
+	if n > forestSample {
+		n = forestSample
+	}
+	vars := make([]dimVar, dims)
+	for d := range vars {
+		var sum, sumSq float64
+		for _, i := range idx[:n] {
+			v := f.points[i].Compare(ref, Dim(d))
+			sum += v
+			sumSq += v * v
+		}
+		vars[d] = dimVar{dim: Dim(d), v: sumSq/float64(n) - (sum/float64(n))*(sum/float64(n))}
+	}
+	sort.Sort(byVariance(vars))
+	k := forestDims
+	if k > dims {
+		k = dims
+	}
+	return vars[rnd.Intn(k)].dim
+}
+
+type dimVar struct {
+	dim Dim
+	v   float64
+}
+
+type byVariance []dimVar
+
+func (v byVariance) Len() int           { return len(v) }
+func (v byVariance) Less(i, j int) bool { return v[i].v > v[j].v }
+func (v byVariance) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+
+// forestPlane is a SortSlicer ordering the indexed points on a dimension.
+type forestPlane struct {
+	points []Comparable
+	idx    []int
+	dim    Dim
+}
+
+func (p forestPlane) Len() int { return len(p.idx) }
+func (p forestPlane) Less(i, j int) bool {
+	return p.points[p.idx[i]].Compare(p.points[p.idx[j]], p.dim) < 0
+}
+func (p forestPlane) Swap(i, j int) { p.idx[i], p.idx[j] = p.idx[j], p.idx[i] }
+func (p forestPlane) Slice(start, end int) SortSlicer {
+	return forestPlane{points: p.points, idx: p.idx[start:end], dim: p.dim}
+}
+
+// Len returns the number of values in the forest.
+func (f *Forest) Len() int { return len(f.points) }
+
+// Trees returns the number of trees in the forest.
+func (f *Forest) Trees() int { return len(f.trees) }
+
+// Nearest returns an approximate nearest value to the query and the distance between them,
+// checking at most checks values. If checks is less than one, the search is exact.
+func (f *Forest) Nearest(q Comparable, checks int) (Comparable, float64) {
+	k := NewNKeeper(1)
+	f.search(q, k, checks)
+	return k.Heap[0].Comparable, k.Heap[0].Dist
+}
+
+// NearestSet finds approximate nearest values to the query accepted by the provided Keeper, k,
+// checking at most checks values. If checks is less than one, the search is exact. k must be
+// able to return a ComparableDist specifying the maximum acceptable distance when Max() is
+// called, and retains the results of the search in min sorted order after the call to
+// NearestSet returns.
+func (f *Forest) NearestSet(k Keeper, q Comparable, checks int) {
+	f.search(q, k, checks)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// NearestN returns approximately the n nearest values to the query in min sorted order,
+// checking at most checks values. If checks is less than n, n values are checked. If the
+// forest holds fewer than n values, all the values in the forest are returned.
+func (f *Forest) NearestN(q Comparable, n, checks int) []ComparableDist {
+	if n > len(f.points) {
+		n = len(f.points)
+	}
+	if n <= 0 {
+		return nil
+	}
+	if checks > 0 && checks < n {
+		checks = n
+	}
+	k := NewNKeeper(n)
+	f.NearestSet(k, q, checks)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// branch is an unexplored subtree and the lower bound on the distance from the query to
+// the values it holds.
+type branch struct {
+	node *forestNode
+	dist float64
+}
+
+type branches []branch
+
+func (b branches) Len() int            { return len(b) }
+func (b branches) Less(i, j int) bool  { return b[i].dist < b[j].dist }
+func (b branches) Swap(i, j int)       { b[i], b[j] = b[j], b[i] }
+func (b *branches) Push(x interface{}) { *b = append(*b, x.(branch)) }
+func (b *branches) Pop() interface{} {
+	x := (*b)[len(*b)-1]
+	*b = (*b)[:len(*b)-1]
+	return x
+}
+
+func (f *Forest) search(q Comparable, k Keeper, checks int) {
+	if len(f.points) == 0 {
+		return
+	}
+	var (
+		queue   branches
+		checked = make(map[int]struct{})
+	)
+	// descend follows the path of q from n to a leaf, queueing
+	// the branches not taken. It returns false when the number
+	// of checks has been exhausted.
+	descend := func(n *forestNode) bool {
+		for n != nil {
+			p := f.points[n.point]
+			if _, ok := checked[n.point]; !ok {
+				checked[n.point] = struct{}{}
+				k.Keep(ComparableDist{Comparable: p, Dist: q.Distance(p)})
+				if checks > 0 && len(checked) >= checks {
+					return false
+				}
+			}
+			near, far := n.left, n.right
+			c := q.Compare(p, n.plane)
+			if c > 0 {
+				near, far = far, near
+			}
+			if far != nil {
+				heap.Push(&queue, branch{node: far, dist: planeDist(q, c)})
+			}
+			n = near
+		}
+		return true
+	}
+	for _, t := range f.trees {
+		if !descend(t) {
+			return
+		}
+	}
+	for queue.Len() != 0 {
+		b := heap.Pop(&queue).(branch)
+		if b.dist > k.Max().Dist {
+			return
+		}
+		if !descend(b.node) {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func randForestPoints(rnd *rand.Rand, n, dims int) Points {
+	p := make(Points, n)
+	for i := range p {
+		p[i] = make(Point, dims)
+		for d := range p[i] {
+			p[i][d] = rnd.Float64()
+		}
+	}
+	return p
+}
+
+// embeddedPoints returns n points lying close to a random three dimensional subspace
+// of a space with the given dimensionality.
+func embeddedPoints(rnd *rand.Rand, basis Points, n int) Points {
+	p := make(Points, n)
+	for i := range p {
+		p[i] = make(Point, len(basis[0]))
+		for _, b := range basis {
+			w := rnd.Float64()
+			for d := range p[i] {
+				p[i][d] += w * b[d]
+			}
+		}
+		for d := range p[i] {
+			p[i][d] += rnd.NormFloat64() * 1e-3
+		}
+	}
+	return p
+}
+
+func (s *S) TestForestExact(c *check.C) {
+	f := NewForest(append(Points(nil), wpData...), 4, nil)
+	c.Check(f.Len(), check.Equals, len(wpData))
+	c.Check(f.Trees(), check.Equals, 4)
+	for i, q := range append([]Point{{4, 6}, {7, 5}, {8, 7}, {6, -5}, {1e5, -1e5}}, wpData...) {
+		p, d := f.Nearest(q, 0)
+		ep, ed := nearest(q, wpData)
+		c.Check(q.Distance(p), check.Equals, ed, check.Commentf("Test %d: query %.3f expects %.3f", i, q, ep))
+		c.Check(d, check.Equals, ed)
+
+		got := f.NearestN(q, 3, 0)
+		want := nearestN(3, q, wpData)
+		c.Check(got, check.HasLen, 3)
+		for j := range got {
+			c.Check(got[j].Dist, check.Equals, want[j].Dist, check.Commentf("Test %d", i))
+		}
+	}
+
+	empty := NewForest(Points{}, 2, nil)
+	p, _ := empty.Nearest(Point{1, 1}, 10)
+	c.Check(p, check.IsNil)
+	c.Check(empty.NearestN(Point{1, 1}, 2, 10), check.HasLen, 0)
+}
+
+func (s *S) TestForestRandom(c *check.C) {
+	const (
+		dims    = 32
+		setSize = 5000
+		queries = 200
+	)
+	rnd := rand.New(rand.NewSource(1))
+	basis := randForestPoints(rnd, 3, dims)
+	data := embeddedPoints(rnd, basis, setSize)
+	ref := append(Points(nil), data...)
+	f := NewForest(data, 4, rand.New(rand.NewSource(1)))
+
+	qs := embeddedPoints(rnd, basis, queries)
+	recall := func(checks int) int {
+		var hits int
+		for _, q := range qs {
+			p, _ := f.Nearest(q, checks)
+			ep, _ := nearest(q, ref)
+			if q.Distance(p) == q.Distance(ep) {
+				hits++
+			}
+		}
+		return hits
+	}
+	low, high, exact := recall(8), recall(128), recall(0)
+	c.Check(low <= high, check.Equals, true, check.Commentf("recall(8)=%d recall(128)=%d", low, high))
+	c.Check(high > queries*9/10, check.Equals, true, check.Commentf("recall(128)=%d", high))
+	c.Check(exact, check.Equals, queries)
+}
+
+func BenchmarkForestNearest(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	data := randForestPoints(rnd, 1e5, 64)
+	f := NewForest(data, 8, nil)
+	qs := randForestPoints(rnd, 1000, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Nearest(qs[i%len(qs)], 256)
+	}
+}
@@ -0,0 +1,388 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "sort"
+
+// frozenBucket is the greatest number of values held by a leaf bucket of a FrozenTree
+// built by Freeze.
+const frozenBucket = 8
+
+// A FrozenTree is an immutable k-d tree optimized for query throughput, for data that is
+// built once and queried many times. The tree is a complete binary tree stored implicitly,
+// so that the children of the ith split are the (2i+1)th and (2i+2)th, and its leaves are
+// buckets of up to eight values, or the leaf size given to FreezeLeaf, whose coordinates
+// are held contiguously in a single coordinate matrix. Splits hold only their dimension and value; neither child pointers nor
+// bounding volumes are stored.
+//
+// Distances between query and stored values are the squared Euclidean distances between
+// their coordinates, as for Point.Distance, rather than those returned by the values'
+// Distance methods. If the stored values are Point values, they are not retained and the
+// Points returned by queries share storage with the tree and must not be altered, unless
+// the tree was built by FreezeQuantized.
+type FrozenTree struct {
+	dims, count int
+
+	// depth is the depth of the leaf buckets, and
+	// splits and planes hold the split value and
+	// dimension of the 2^depth-1 internal nodes.
+	depth  int
+	splits []float64
+	planes []uint32
+
+	// coords holds the coordinates of the ith value
+	// at [i*dims, (i+1)*dims), or fixed holds them
+	// as multiples of precision from origin if the
+	// tree is quantized. values holds the values
+	// themselves unless they are Points.
+	coords    []float64
+	fixed     []uint32
+	origin    []float64
+	precision float64
+	values    []Comparable
+}
+
+// Freeze returns a FrozenTree holding the values stored in the tree. The structure of the
+// FrozenTree is determined afresh from the values, splitting each cell through the median
+// of the dimension in which its values are most widely spread. Stored values must be of a
+// type accepted by Point.Compare. Later changes to the tree are not reflected in the
+// FrozenTree.
+func (t *Tree) Freeze() *FrozenTree {
+	f := t.thaw()
+	f.layout(frozenBucket, nil)
+	return f
+}
+
+// FreezeLeaf returns a FrozenTree holding the values stored in the tree as for Freeze, with
+// leaf buckets of up to leafSize values and each cell split through the median of the
+// dimension chosen by rule. Rules are given the values of the cell as Points. If leafSize
+// is less than one, buckets hold single values, and if rule is nil, cells are split as for
+// Freeze.
+func (t *Tree) FreezeLeaf(leafSize int, rule SplitRule) *FrozenTree {
+	f := t.thaw()
+	f.layout(leafSize, rule)
+	return f
+}
+
+// thaw returns a FrozenTree holding the coordinates and values of the tree, without
+// structure.
+func (t *Tree) thaw() *FrozenTree {
+	f := &FrozenTree{count: t.Count, values: t.Points()}
+	if t.Root == nil {
+		return f
+	}
+	f.dims = t.Dims()
+	f.coords = make([]float64, 0, f.count*f.dims)
+	points := true
+	for _, v := range f.values {
+		_, ok := v.(Point)
+		points = points && ok
+		for d := 0; d < f.dims; d++ {
+			f.coords = append(f.coords, at(v, Dim(d)))
+		}
+	}
+	if points {
+		f.values = nil
+	}
+	return f
+}
+
+// layout determines the structure of f from its values, with leaf buckets of up to bucket
+// values and splits chosen by rule, or by widest spread if rule is nil.
+func (f *FrozenTree) layout(bucket int, rule SplitRule) {
+	if f.count == 0 {
+		return
+	}
+	for n := f.count; n > bucket && n > 1; n = (n + 1) / 2 {
+		f.depth++
+	}
+	f.splits = make([]float64, 1<<uint(f.depth)-1)
+	f.planes = make([]uint32, len(f.splits))
+	f.build(0, 0, f.count, rule, -1)
+}
+
+// build partitions the values in [lo, hi) about the split of node i and its descendants.
+func (f *FrozenTree) build(i, lo, hi int, rule SplitRule, parent Dim) {
+	if i >= len(f.splits) {
+		return
+	}
+	if lo == hi {
+		// Cells are only empty when buckets hold a single
+		// value, and the splits within them are arbitrary.
+		return
+	}
+	var d int
+	if rule != nil {
+		d = int(rule(frozenList{f: f, lo: lo, hi: hi}, parent))
+	} else {
+		d = f.widest(lo, hi)
+	}
+	mid := lo + (hi-lo)/2
+	Select(frozenPlane{f: f, d: d, lo: lo, hi: hi}, mid-lo)
+	f.splits[i] = f.coords[mid*f.dims+d]
+	f.planes[i] = uint32(d)
+	f.build(2*i+1, lo, mid, rule, Dim(d))
+	f.build(2*i+2, mid, hi, rule, Dim(d))
+}
+
+// widest returns the dimension in which the values in [lo, hi) are most widely spread.
+func (f *FrozenTree) widest(lo, hi int) int {
+	var d int
+	spread := -1.
+	for k := 0; k < f.dims; k++ {
+		min, max := inf, -inf
+		for j := lo; j < hi; j++ {
+			x := f.coords[j*f.dims+k]
+			if x < min {
+				min = x
+			}
+			if x > max {
+				max = x
+			}
+		}
+		if max-min > spread {
+			d, spread = k, max-min
+		}
+	}
+	return d
+}
+
+// frozenList is an Interface over the values of a FrozenTree in [lo, hi) that presents
+// them to a SplitRule as Points.
+type frozenList struct {
+	f      *FrozenTree
+	lo, hi int
+}
+
+func (p frozenList) Index(i int) Comparable {
+	o := (p.lo + i) * p.f.dims
+	return Point(p.f.coords[o : o+p.f.dims : o+p.f.dims])
+}
+func (p frozenList) Len() int { return p.hi - p.lo }
+func (p frozenList) Pivot(d Dim) int {
+	return Select(frozenPlane{f: p.f, d: int(d), lo: p.lo, hi: p.hi}, p.Len()/2)
+}
+func (p frozenList) Slice(start, end int) Interface {
+	p.lo, p.hi = p.lo+start, p.lo+end
+	return p
+}
+
+// frozenPlane is a SortSlicer over the values of a FrozenTree in [lo, hi), ordered by
+// their coordinates in dimension d.
+type frozenPlane struct {
+	f      *FrozenTree
+	d      int
+	lo, hi int
+}
+
+func (p frozenPlane) Len() int { return p.hi - p.lo }
+func (p frozenPlane) Less(i, j int) bool {
+	return p.f.coords[(p.lo+i)*p.f.dims+p.d] < p.f.coords[(p.lo+j)*p.f.dims+p.d]
+}
+func (p frozenPlane) Slice(start, end int) SortSlicer {
+	p.lo, p.hi = p.lo+start, p.lo+end
+	return p
+}
+func (p frozenPlane) Swap(i, j int) {
+	f := p.f
+	i, j = p.lo+i, p.lo+j
+	if f.values != nil {
+		f.values[i], f.values[j] = f.values[j], f.values[i]
+	}
+	a, b := f.coords[i*f.dims:(i+1)*f.dims], f.coords[j*f.dims:(j+1)*f.dims]
+	for k := range a {
+		a[k], b[k] = b[k], a[k]
+	}
+	if f.fixed != nil {
+		a, b := f.fixed[i*f.dims:(i+1)*f.dims], f.fixed[j*f.dims:(j+1)*f.dims]
+		for k := range a {
+			a[k], b[k] = b[k], a[k]
+		}
+	}
+}
+
+// Len returns the number of values in the tree.
+func (f *FrozenTree) Len() int { return f.count }
+
+// Dims returns the dimensionality of the values in the tree, or zero if the tree is empty.
+func (f *FrozenTree) Dims() int { return f.dims }
+
+// query returns the coordinates of q, panicking with ErrDimsMismatch if q does not have
+// the dimensionality of the values in the tree.
+func (f *FrozenTree) query(q Comparable) []float64 {
+	if q.Dims() != f.dims {
+		panic(ErrDimsMismatch)
+	}
+	qc := make([]float64, f.dims)
+	for d := range qc {
+		qc[d] = at(q, Dim(d))
+	}
+	return qc
+}
+
+// coord returns the coordinate in dimension d of the ith value.
+func (f *FrozenTree) coord(i, d int) float64 {
+	if f.fixed != nil {
+		return f.origin[d] + float64(f.fixed[i*f.dims+d])*f.precision
+	}
+	return f.coords[i*f.dims+d]
+}
+
+// value returns the ith value.
+func (f *FrozenTree) value(i int) Comparable {
+	switch {
+	case f.values != nil:
+		return f.values[i]
+	case f.fixed != nil:
+		p := make(Point, f.dims)
+		for d := range p {
+			p[d] = f.coord(i, d)
+		}
+		return p
+	default:
+		return Point(f.coords[i*f.dims : (i+1)*f.dims : (i+1)*f.dims])
+	}
+}
+
+// dist returns the squared Euclidean distance between q and the ith value.
+func (f *FrozenTree) dist(q []float64, i int) float64 {
+	var s float64
+	for d, x := range q {
+		x -= f.coord(i, d)
+		s += x * x
+	}
+	return s
+}
+
+// within returns whether the ith value lies within the box from min to max.
+func (f *FrozenTree) within(i int, min, max []float64) bool {
+	for d := range min {
+		x := f.coord(i, d)
+		if x < min[d] || x > max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest returns the nearest value to the query and the distance between them. Nearest
+// panics with ErrDimsMismatch if q does not have the dimensionality of the values in the
+// tree.
+func (f *FrozenTree) Nearest(q Comparable) (Comparable, float64) {
+	if f.count == 0 {
+		return nil, inf
+	}
+	keep := NewNKeeper(1)
+	f.NearestSet(keep, q)
+	return keep.Heap[0].Comparable, keep.Heap[0].Dist
+}
+
+// NearestN returns the n nearest values to the query in min sorted order.
+func (f *FrozenTree) NearestN(q Comparable, n int) []ComparableDist {
+	if f.count == 0 || n <= 0 {
+		return nil
+	}
+	keep := NewNKeeper(n)
+	f.NearestSet(keep, q)
+	h := keep.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// InRange returns the values within distance d of the query in min sorted order.
+func (f *FrozenTree) InRange(q Comparable, d float64) []ComparableDist {
+	if f.count == 0 {
+		return nil
+	}
+	keep := NewDistKeeper(d)
+	f.NearestSet(keep, q)
+	h := keep.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k, as
+// described for Tree.NearestSet. NearestSet panics with ErrDimsMismatch if q does not have
+// the dimensionality of the values in the tree.
+func (f *FrozenTree) NearestSet(k Keeper, q Comparable) {
+	if f.count == 0 {
+		return
+	}
+	f.searchSet(0, 0, f.count, f.query(q), k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+func (f *FrozenTree) searchSet(i, lo, hi int, q []float64, k Keeper) {
+	if i >= len(f.splits) {
+		for j := lo; j < hi; j++ {
+			if d := f.dist(q, j); d <= k.Max().Dist {
+				k.Keep(ComparableDist{Comparable: f.value(j), Dist: d})
+			}
+		}
+		return
+	}
+	mid := lo + (hi-lo)/2
+	c := q[f.planes[i]] - f.splits[i]
+	if c <= 0 {
+		f.searchSet(2*i+1, lo, mid, q, k)
+		if c*c <= k.Max().Dist {
+			f.searchSet(2*i+2, mid, hi, q, k)
+		}
+		return
+	}
+	f.searchSet(2*i+2, mid, hi, q, k)
+	if c*c <= k.Max().Dist {
+		f.searchSet(2*i+1, lo, mid, q, k)
+	}
+}
+
+// Do performs fn on all values stored in the tree in bucket order, with a nil bound and
+// the depth of the leaf buckets, returning whether the traversal was interrupted.
+func (f *FrozenTree) Do(fn Operation) bool {
+	for i := 0; i < f.count; i++ {
+		if fn(f.value(i), nil, f.depth) {
+			return true
+		}
+	}
+	return false
+}
+
+// DoBounded performs fn on all values stored in the tree that are within the specified
+// bound in bucket order, with b and the depth of the leaf buckets, returning whether the
+// traversal was interrupted. If b is nil, DoBounded is equivalent to Do. DoBounded panics
+// with ErrDimsMismatch if b does not have the dimensionality of the values in the tree.
+func (f *FrozenTree) DoBounded(fn Operation, b *Bounding) bool {
+	if b == nil {
+		return f.Do(fn)
+	}
+	if f.count == 0 {
+		return false
+	}
+	return f.doBounded(0, 0, f.count, fn, f.query(b[0]), f.query(b[1]), b)
+}
+
+func (f *FrozenTree) doBounded(i, lo, hi int, fn Operation, min, max []float64, b *Bounding) bool {
+	if i >= len(f.splits) {
+		for j := lo; j < hi; j++ {
+			if f.within(j, min, max) && fn(f.value(j), b, f.depth) {
+				return true
+			}
+		}
+		return false
+	}
+	mid := lo + (hi-lo)/2
+	d, split := f.planes[i], f.splits[i]
+	if min[d] <= split && f.doBounded(2*i+1, lo, mid, fn, min, max, b) {
+		return true
+	}
+	return max[d] >= split && f.doBounded(2*i+2, mid, hi, fn, min, max, b)
+}
@@ -0,0 +1,88 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFreeze(c *check.C) {
+	f := bTree.Freeze()
+	c.Check(f.Len(), check.Equals, bTree.Len())
+	c.Check(f.Dims(), check.Equals, 3)
+	for i := 0; i < 100; i++ {
+		q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+		p, d := f.Nearest(q)
+		ep, ed := bTree.Nearest(q)
+		c.Check(p, check.DeepEquals, ep, check.Commentf("Test %d", i))
+		c.Check(d, check.Equals, ed)
+
+		c.Check(f.NearestN(q, 5), check.DeepEquals, bTree.NearestN(q, 5), check.Commentf("Test %d", i))
+		c.Check(f.InRange(q, 0.01), check.DeepEquals, bTree.InRange(q, 0.01), check.Commentf("Test %d", i))
+
+		lo := Point{rand.Float64() / 2, rand.Float64() / 2, rand.Float64() / 2}
+		b := &Bounding{lo, Point{lo[0] + 0.3, lo[1] + 0.3, lo[2] + 0.3}}
+		var got, want []Comparable
+		f.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			got = append(got, c)
+			return false
+		}, b)
+		bTree.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			want = append(want, c)
+			return false
+		}, b)
+		sort.Sort(lexOrder(got))
+		sort.Sort(lexOrder(want))
+		c.Check(got, check.DeepEquals, want, check.Commentf("Test %d", i))
+	}
+	var n int
+	c.Check(f.Do(func(Comparable, *Bounding, int) bool { n++; return n == 10 }), check.Equals, true)
+	c.Check(n, check.Equals, 10)
+	c.Check(func() { f.Nearest(Point{0, 0}) }, check.Panics, ErrDimsMismatch)
+
+	e := (&Tree{}).Freeze()
+	c.Check(e.Len(), check.Equals, 0)
+	p, _ := e.Nearest(Point{0, 0})
+	c.Check(p, check.IsNil)
+	c.Check(e.NearestN(Point{0, 0}, 2), check.HasLen, 0)
+	c.Check(e.DoBounded(func(Comparable, *Bounding, int) bool { return true }, &Bounding{Point{0}, Point{1}}), check.Equals, false)
+}
+
+func (s *S) TestFreezeLeaf(c *check.C) {
+	for _, size := range []int{0, 1, 2, 3, 8, 33} {
+		for j, rule := range []SplitRule{nil, Cycle, MaxVariance} {
+			f := bTree.FreezeLeaf(size, rule)
+			c.Check(f.Len(), check.Equals, bTree.Len())
+			for i := 0; i < 20; i++ {
+				q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+				c.Check(f.NearestN(q, 5), check.DeepEquals, bTree.NearestN(q, 5),
+					check.Commentf("Leaf size %d rule %d test %d", size, j, i))
+			}
+			var n int
+			f.DoBounded(func(Comparable, *Bounding, int) bool { n++; return false },
+				&Bounding{Point{0, 0, 0}, Point{1, 1, 1}})
+			c.Check(n, check.Equals, bTree.Len())
+		}
+	}
+	c.Check(New(Points{{1, 2}}, false).FreezeLeaf(1, nil).NearestN(Point{0, 0}, 2), check.HasLen, 1)
+}
+
+func (s *S) TestFreezeValues(c *check.C) {
+	w := New(Data{
+		{Point: Point{2, 3}, Value: "a"},
+		{Point: Point{5, 4}, Value: "b"},
+		{Point: Point{9, 6}, Value: "c"},
+	}, false).Freeze()
+	p, d := w.Nearest(Point{8, 7})
+	c.Check(p.(Datum).Value, check.Equals, "c")
+	c.Check(d, check.Equals, 2.)
+	r := w.InRange(Point{4, 4}, 5)
+	c.Assert(r, check.HasLen, 2)
+	c.Check(r[0].Comparable.(Datum).Value, check.Equals, "b")
+	c.Check(r[1].Comparable.(Datum).Value, check.Equals, "a")
+}
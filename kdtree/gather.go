@@ -0,0 +1,34 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// GatherN returns up to n nearest values to the query that are within distance maxRadius of
+// it, as measured by the values' Distance method, in min sorted order, and the radius of the
+// gather. If n values were gathered, the radius is the distance of the nth value; otherwise
+// it is maxRadius. Ties are resolved as described for NearestN. The search is pruned by the
+// lesser of maxRadius and the distance of the nth nearest value found so far.
+func (t *Tree) GatherN(q Comparable, n int, maxRadius float64, opts ...SearchOption) ([]ComparableDist, float64) {
+	if n > t.Count {
+		n = t.Count
+	}
+	if n <= 0 || t.Root == nil {
+		return nil, maxRadius
+	}
+	k := NewNKeeper(n)
+	// NKeeper only accepts values strictly nearer than its max,
+	// so the sentinel is placed just beyond maxRadius.
+	k.Heap[0].Dist = math.Nextafter(maxRadius, inf)
+	t.NearestSet(k, q, opts...)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	if len(h) < n {
+		return h, maxRadius
+	}
+	return h, h[len(h)-1].Dist
+}
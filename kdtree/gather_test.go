@@ -0,0 +1,49 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestGatherN(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	for _, test := range []struct {
+		q         Point
+		n         int
+		maxRadius float64
+		want      []ComparableDist
+		radius    float64
+	}{
+		{Point{5, 4}, 2, 100, []ComparableDist{{Point{5, 4}, 0}, {Point{7, 2}, 8}}, 8},
+		{Point{5, 4}, 5, 10, []ComparableDist{{Point{5, 4}, 0}, {Point{7, 2}, 8}, {Point{2, 3}, 10}, {Point{4, 7}, 10}}, 10},
+		{Point{5, 4}, 3, 10, []ComparableDist{{Point{5, 4}, 0}, {Point{7, 2}, 8}, {Point{2, 3}, 10}}, 10},
+		{Point{5, 4}, 3, 9.5, []ComparableDist{{Point{5, 4}, 0}, {Point{7, 2}, 8}}, 9.5},
+		{Point{5, 4}, 0, 100, nil, 100},
+		{Point{0, 0}, 3, 1, []ComparableDist{}, 1},
+	} {
+		got, radius := t.GatherN(test.q, test.n, test.maxRadius)
+		if len(test.want) == 0 {
+			c.Check(got, check.HasLen, 0)
+		} else {
+			c.Check(got, check.DeepEquals, test.want)
+		}
+		c.Check(radius, check.Equals, test.radius)
+	}
+	got, radius := (&Tree{}).GatherN(Point{0, 0}, 3, 5)
+	c.Check(got, check.HasLen, 0)
+	c.Check(radius, check.Equals, 5.)
+
+	// The result is the intersection of NearestN and InRange.
+	t = New(append(Points(nil), bData...), false)
+	for _, q := range bData[:10] {
+		got, _ := t.GatherN(q, 10, 0.05)
+		in := t.InRange(q, 0.05)
+		if len(in) > 10 {
+			in = t.NearestN(q, 10)
+		}
+		c.Check(got, check.DeepEquals, in)
+	}
+}
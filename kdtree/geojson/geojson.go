@@ -0,0 +1,200 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package geojson provides loading of GeoJSON Point features into k-d trees and export
+// of query results as GeoJSON.
+//
+// Features are stored in two dimensional trees using their longitude and latitude as
+// coordinates. Distances are squared planar distances in coordinate units, as required
+// by kdtree.Comparable, and so are not geodesic distances.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+var (
+	_ kdtree.Interface = Features(nil)
+	_ kdtree.Bounder   = Features(nil)
+	_ kdtree.Extender  = (*Feature)(nil)
+)
+
+// A Feature is a GeoJSON Point feature that satisfies the kdtree.Comparable and
+// kdtree.Extender interfaces. Queries of trees holding Features must be Features;
+// Query returns a Feature suitable for this.
+type Feature struct {
+	// Lon and Lat are the coordinates of the point. Any
+	// altitude coordinate is discarded.
+	Lon, Lat float64
+
+	// ID is the feature identifier, if present.
+	ID interface{}
+
+	// Properties holds the feature properties.
+	Properties map[string]interface{}
+}
+
+// Query returns a Feature at the given location for use as a query.
+func Query(lon, lat float64) *Feature { return &Feature{Lon: lon, Lat: lat} }
+
+func (f *Feature) coord(d kdtree.Dim) float64 {
+	if d == 0 {
+		return f.Lon
+	}
+	return f.Lat
+}
+
+// Compare satisfies the kdtree.Comparable interface. c must be a *Feature.
+func (f *Feature) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return f.coord(d) - c.(*Feature).coord(d)
+}
+
+// Dims returns 2.
+func (f *Feature) Dims() int { return 2 }
+
+// Distance returns the squared planar distance between f and c. c must be a *Feature.
+func (f *Feature) Distance(c kdtree.Comparable) float64 {
+	g := c.(*Feature)
+	dx, dy := f.Lon-g.Lon, f.Lat-g.Lat
+	return dx*dx + dy*dy
+}
+
+// Extend satisfies the kdtree.Extender interface. The corners of the bounding box
+// are Features without properties.
+func (f *Feature) Extend(b *kdtree.Bounding) *kdtree.Bounding {
+	if b == nil {
+		return &kdtree.Bounding{Query(f.Lon, f.Lat), Query(f.Lon, f.Lat)}
+	}
+	min, max := b[0].(*Feature), b[1].(*Feature)
+	*b = kdtree.Bounding{
+		Query(math.Min(min.Lon, f.Lon), math.Min(min.Lat, f.Lat)),
+		Query(math.Max(max.Lon, f.Lon), math.Max(max.Lat, f.Lat)),
+	}
+	return b
+}
+
+// Features is a collection of Feature values that satisfies the kdtree.Interface.
+type Features []*Feature
+
+func (f Features) Index(i int) kdtree.Comparable         { return f[i] }
+func (f Features) Len() int                              { return len(f) }
+func (f Features) Slice(start, end int) kdtree.Interface { return f[start:end] }
+func (f Features) Pivot(d kdtree.Dim) int {
+	p := plane{Dim: d, Features: f}
+	return kdtree.Partition(p, kdtree.MedianOfRandoms(p, kdtree.Randoms))
+}
+
+// Bounds returns the bounding box of the features.
+func (f Features) Bounds() *kdtree.Bounding {
+	if len(f) == 0 {
+		return nil
+	}
+	var b *kdtree.Bounding
+	for _, e := range f {
+		b = e.Extend(b)
+	}
+	return b
+}
+
+type plane struct {
+	kdtree.Dim
+	Features
+}
+
+func (p plane) Less(i, j int) bool { return p.Features[i].coord(p.Dim) < p.Features[j].coord(p.Dim) }
+func (p plane) Swap(i, j int)      { p.Features[i], p.Features[j] = p.Features[j], p.Features[i] }
+func (p plane) Slice(start, end int) kdtree.SortSlicer {
+	p.Features = p.Features[start:end]
+	return p
+}
+
+type geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type feature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   *geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type collection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+// Decode reads a GeoJSON FeatureCollection from r and returns its features. All features
+// must have Point geometries; features with null geometry are skipped.
+func Decode(r io.Reader) (Features, error) {
+	var fc collection
+	err := json.NewDecoder(r).Decode(&fc)
+	if err != nil {
+		return nil, err
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("geojson: unexpected object type %q", fc.Type)
+	}
+	f := make(Features, 0, len(fc.Features))
+	for i, e := range fc.Features {
+		if e.Geometry == nil {
+			continue
+		}
+		if e.Geometry.Type != "Point" {
+			return nil, fmt.Errorf("geojson: feature %d has unsupported geometry type %q", i, e.Geometry.Type)
+		}
+		if len(e.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("geojson: feature %d has invalid coordinates", i)
+		}
+		f = append(f, &Feature{
+			Lon:        e.Geometry.Coordinates[0],
+			Lat:        e.Geometry.Coordinates[1],
+			ID:         e.ID,
+			Properties: e.Properties,
+		})
+	}
+	return f, nil
+}
+
+// Load reads a GeoJSON FeatureCollection of Point features from r and returns a bounded
+// k-d tree holding them.
+func Load(r io.Reader) (*kdtree.Tree, error) {
+	f, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return kdtree.New(f, true), nil
+}
+
+// Collect returns the Features held in the results of a kdtree.Keeper, skipping any
+// values that are not Features.
+func Collect(results []kdtree.ComparableDist) Features {
+	var f Features
+	for _, r := range results {
+		if e, ok := r.Comparable.(*Feature); ok {
+			f = append(f, e)
+		}
+	}
+	return f
+}
+
+// Encode writes f to w as a GeoJSON FeatureCollection.
+func Encode(w io.Writer, f Features) error {
+	fc := collection{Type: "FeatureCollection", Features: make([]feature, len(f))}
+	for i, e := range f {
+		fc.Features[i] = feature{
+			Type:       "Feature",
+			ID:         e.ID,
+			Geometry:   &geometry{Type: "Point", Coordinates: []float64{e.Lon, e.Lat}},
+			Properties: e.Properties,
+		}
+	}
+	return json.NewEncoder(w).Encode(fc)
+}
@@ -0,0 +1,72 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geojson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+const pois = `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "id": 1, "geometry": {"type": "Point", "coordinates": [2, 3]}, "properties": {"name": "a"}},
+    {"type": "Feature", "id": 2, "geometry": {"type": "Point", "coordinates": [5, 4, 10]}, "properties": {"name": "b"}},
+    {"type": "Feature", "id": 3, "geometry": {"type": "Point", "coordinates": [9, 6]}, "properties": {"name": "c"}},
+    {"type": "Feature", "id": 4, "geometry": null, "properties": {"name": "unlocated"}},
+    {"type": "Feature", "id": 5, "geometry": {"type": "Point", "coordinates": [4, 7]}, "properties": {"name": "d"}},
+    {"type": "Feature", "id": 6, "geometry": {"type": "Point", "coordinates": [8, 1]}, "properties": {"name": "e"}},
+    {"type": "Feature", "id": 7, "geometry": {"type": "Point", "coordinates": [7, 2]}, "properties": {"name": "f"}}
+  ]
+}`
+
+func (s *S) TestLoad(c *check.C) {
+	t, err := Load(strings.NewReader(pois))
+	c.Assert(err, check.IsNil)
+	c.Check(t.Len(), check.Equals, 6)
+	c.Check(t.Root.Bounding, check.DeepEquals, &kdtree.Bounding{Query(2, 1), Query(9, 7)})
+
+	p, d := t.Nearest(Query(8, 2))
+	c.Check(p.(*Feature).Properties["name"], check.Equals, "f")
+	c.Check(d, check.Equals, 1.)
+
+	k := kdtree.NewNKeeper(2)
+	t.NearestSet(k, Query(8.5, 1))
+	var buf bytes.Buffer
+	c.Assert(Encode(&buf, Collect(k.Heap)), check.IsNil)
+	c.Check(buf.String(), check.Equals, `{"type":"FeatureCollection","features":[`+
+		`{"type":"Feature","id":6,"geometry":{"type":"Point","coordinates":[8,1]},"properties":{"name":"e"}},`+
+		`{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[7,2]},"properties":{"name":"f"}}]}`+"\n")
+
+	var inBox []string
+	t.DoBounded(func(p kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+		inBox = append(inBox, p.(*Feature).Properties["name"].(string))
+		return false
+	}, &kdtree.Bounding{Query(3, 3), Query(10, 10)})
+	c.Check(inBox, check.HasLen, 3)
+}
+
+func (s *S) TestDecodeErrors(c *check.C) {
+	for _, in := range []string{
+		`{"type": "Feature"}`,
+		`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}}]}`,
+		`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Point", "coordinates": [0]}}]}`,
+		`not json`,
+	} {
+		_, err := Decode(strings.NewReader(in))
+		c.Check(err, check.NotNil, check.Commentf("%s", in))
+	}
+}
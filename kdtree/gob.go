@@ -0,0 +1,125 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+func init() {
+	RegisterGob(Point(nil))
+}
+
+// RegisterGob registers the concrete type of c for gob encoding of trees holding values
+// of that type. Point is registered by the kdtree package.
+func RegisterGob(c Comparable) {
+	gob.Register(c)
+}
+
+// gobTree is the serialised form of a Tree. Nodes are stored in pre-order with child
+// links held as indices into Nodes. A negative index indicates a nil child.
+type gobTree struct {
+	Count int
+	Nodes []gobNode
+}
+
+type gobNode struct {
+	Point       Comparable
+	Plane       Dim
+	Left, Right int
+	Bounding    *Bounding
+}
+
+// GobEncode satisfies the gob.GobEncoder interface. The concrete types of the stored
+// points must have been registered with RegisterGob. As for Marshal, the Tracer,
+// Observer, Augmenter, Bloom and node Summary values are not encoded and must be
+// restored after decoding.
+func (t *Tree) GobEncode() ([]byte, error) {
+	g := gobTree{Count: t.Count}
+	g.add(t.Root)
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(g)
+	return buf.Bytes(), err
+}
+
+func (g *gobTree) add(n *Node) int {
+	if n == nil {
+		return -1
+	}
+	i := len(g.Nodes)
+	g.Nodes = append(g.Nodes, gobNode{Point: n.Point, Plane: n.Plane, Bounding: n.Bounding})
+	l := g.add(n.Left)
+	r := g.add(n.Right)
+	g.Nodes[i].Left, g.Nodes[i].Right = l, r
+	return i
+}
+
+// GobDecode satisfies the gob.GobDecoder interface. GobDecode returns ErrFormat if a node
+// splits on a dimension its point does not have and ErrCount if the count of the encoded
+// tree does not match its number of nodes.
+func (t *Tree) GobDecode(b []byte) error {
+	var g gobTree
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	nodes := make([]Node, len(g.Nodes))
+	if !preorder(len(nodes), func(i int) (int, int) { return g.Nodes[i].Left, g.Nodes[i].Right }) {
+		return errors.New("kdtree: invalid node index")
+	}
+	for i, gn := range g.Nodes {
+		if gn.Point == nil || gn.Plane < 0 || int(gn.Plane) >= gn.Point.Dims() {
+			return ErrFormat
+		}
+		nodes[i] = Node{Point: gn.Point, Plane: gn.Plane, Bounding: gn.Bounding}
+		if gn.Left >= 0 {
+			nodes[i].Left = &nodes[gn.Left]
+		}
+		if gn.Right >= 0 {
+			nodes[i].Right = &nodes[gn.Right]
+		}
+	}
+	if g.Count != len(nodes) {
+		return ErrCount
+	}
+	t.Count = g.Count
+	t.Root = nil
+	if len(nodes) != 0 {
+		t.Root = &nodes[0]
+	}
+	return nil
+}
+
+// preorder returns whether the n nodes whose child indices are returned by children form
+// a single tree laid out in pre-order, with the root at index zero, so that each node is
+// the child of exactly one other node. A negative index indicates no child.
+func preorder(n int, children func(i int) (left, right int)) bool {
+	if n == 0 {
+		return true
+	}
+	next := 0
+	stack := []int{0}
+	for len(stack) != 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i != next {
+			return false
+		}
+		next++
+		l, r := children(i)
+		if r >= n || l >= n {
+			return false
+		}
+		if r >= 0 {
+			stack = append(stack, r)
+		}
+		if l >= 0 {
+			stack = append(stack, l)
+		}
+	}
+	return next == n
+}
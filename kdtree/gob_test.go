@@ -0,0 +1,67 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestGob(c *check.C) {
+	for i, t := range []*Tree{
+		{},
+		New(wpData, false),
+		New(append(Points(nil), wpData...), true),
+		bTree,
+	} {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(t)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+
+		var got Tree
+		err = gob.NewDecoder(&buf).Decode(&got)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Count, check.Equals, t.Count)
+		c.Check(got.Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+		c.Check(got.Root.isKDTree(), check.Equals, true)
+	}
+}
+
+func (s *S) TestGobInvalid(c *check.C) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobTree{
+		Count: 2,
+		Nodes: []gobNode{{Point: Point{0}, Left: 1, Right: -1}, {Point: Point{0}, Left: 0, Right: -1}},
+	})
+	c.Assert(err, check.IsNil)
+	var t Tree
+	c.Check(t.GobDecode(buf.Bytes()), check.NotNil)
+
+	// Nodes must each be the child of exactly one other node.
+	for _, nodes := range [][]gobNode{
+		{{Point: Point{0}, Left: 1, Right: 1}, {Point: Point{0}, Left: -1, Right: -1}},
+		{{Point: Point{0}, Left: 1, Right: 2}, {Point: Point{0}, Left: 2, Right: -1}, {Point: Point{0}, Left: -1, Right: -1}},
+		{{Point: Point{0}, Left: -1, Right: -1}, {Point: Point{0}, Left: -1, Right: -1}},
+	} {
+		buf.Reset()
+		c.Assert(gob.NewEncoder(&buf).Encode(gobTree{Count: len(nodes), Nodes: nodes}), check.IsNil)
+		c.Check(t.GobDecode(buf.Bytes()), check.NotNil)
+	}
+
+	for _, test := range []struct {
+		g   gobTree
+		err error
+	}{
+		{g: gobTree{Count: 1, Nodes: []gobNode{{Point: Point{0, 1}, Plane: 2, Left: -1, Right: -1}}}, err: ErrFormat},
+		{g: gobTree{Count: 1, Nodes: []gobNode{{Point: Point{0, 1}, Plane: -1, Left: -1, Right: -1}}}, err: ErrFormat},
+		{g: gobTree{Count: 2, Nodes: []gobNode{{Point: Point{0, 1}, Left: -1, Right: -1}}}, err: ErrCount},
+	} {
+		buf.Reset()
+		c.Assert(gob.NewEncoder(&buf).Encode(test.g), check.IsNil)
+		c.Check(t.GobDecode(buf.Bytes()), check.Equals, test.err)
+	}
+}
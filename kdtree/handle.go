@@ -0,0 +1,97 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A Handle refers to a value inserted into a Tree by InsertHandle. A Handle remains valid
+// until its value is removed from the tree, by RemoveHandle or by Remove, or until the tree
+// is rebuilt by Rebuild.
+type Handle struct {
+	tree *Tree
+	node *Node
+}
+
+// Valid returns whether the value referred to by h is held by its tree.
+func (h *Handle) Valid() bool { return h != nil && h.node != nil }
+
+// Point returns the value referred to by h, or nil if h is not valid.
+func (h *Handle) Point() Comparable {
+	if !h.Valid() {
+		return nil
+	}
+	return h.node.Point
+}
+
+// InsertHandle adds a point to the tree as described for Insert, returning a Handle that
+// refers to the stored point.
+func (t *Tree) InsertHandle(c Comparable, bounding bool) *Handle {
+	t.Insert(c, bounding)
+	h := &Handle{tree: t}
+	t.attach(h, c)
+	return h
+}
+
+// attach associates h with the most recently inserted node holding c, which is the last
+// node on the insertion path of c.
+func (t *Tree) attach(h *Handle, c Comparable) {
+	n := t.Root
+	for {
+		next := n.Right
+		if c.Compare(n.Point, n.Plane) <= 0 {
+			next = n.Left
+		}
+		if next == nil {
+			break
+		}
+		n = next
+	}
+	n.handle, h.node = h, n
+}
+
+// RemoveHandle removes the value referred to by h from the tree without a search for
+// equal values, returning whether a value was removed. RemoveHandle returns false if h is
+// not valid or does not refer to a value in t. Bounding volumes are updated as described
+// for Remove.
+func (t *Tree) RemoveHandle(h *Handle) bool {
+	if !h.Valid() || h.tree != t {
+		return false
+	}
+	return t.detach(h.node)
+}
+
+// detach removes the node target from the tree, returning whether it was found.
+// Bounding volumes are updated as described for Remove.
+func (t *Tree) detach(target *Node) bool {
+	removed := target.Point
+	f := t.fixup()
+	var ok bool
+	t.Root, ok = t.Root.removeTarget(target, f)
+	if !ok {
+		return false
+	}
+	t.Count--
+	if !f.bounding && t.Root != nil {
+		t.Root.Bounding = nil
+	}
+	t.removed(removed)
+	return true
+}
+
+// UpdateHandle replaces the value referred to by h with c, moving it within the tree,
+// and returns whether h was valid and referred to a value in t. h remains valid and
+// refers to c. UpdateHandle panics with ErrDimsMismatch if c does not have the
+// dimensionality of the values in the tree.
+func (t *Tree) UpdateHandle(h *Handle, c Comparable) bool {
+	if !h.Valid() || h.tree != t {
+		return false
+	}
+	t.mustMatch(c)
+	bounding := t.Root.Bounding != nil
+	if !t.RemoveHandle(h) {
+		return false
+	}
+	t.Insert(c, bounding)
+	t.attach(h, c)
+	return true
+}
@@ -0,0 +1,74 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHandle(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := &Tree{}
+	var handles []*Handle
+	for i := 0; i < 500; i++ {
+		// Coarse coordinates give many points on splitting planes.
+		d := Datum{Point: Point{float64(rnd.Intn(10)), float64(rnd.Intn(10))}, Value: i}
+		handles = append(handles, t.InsertHandle(d, true))
+	}
+	verify := func() {
+		var n int
+		for i, h := range handles {
+			if !h.Valid() {
+				c.Check(h.Point(), check.IsNil)
+				continue
+			}
+			n++
+			c.Assert(h.Point().(Datum).Value, check.Equals, i)
+		}
+		c.Assert(t.Len(), check.Equals, n)
+		var stored int
+		t.Do(func(Comparable, *Bounding, int) bool { stored++; return false })
+		c.Assert(stored, check.Equals, n)
+	}
+	verify()
+
+	for i := 0; i < 200; i++ {
+		h := handles[rnd.Intn(len(handles))]
+		switch rnd.Intn(3) {
+		case 0:
+			valid := h.Valid()
+			c.Check(t.RemoveHandle(h), check.Equals, valid)
+			c.Check(h.Valid(), check.Equals, false)
+		case 1:
+			if h.Valid() {
+				id := h.Point().(Datum).Value
+				p := Datum{Point: Point{float64(rnd.Intn(10)), float64(rnd.Intn(10))}, Value: id}
+				c.Check(t.UpdateHandle(h, p), check.Equals, true)
+				c.Check(h.Point(), check.DeepEquals, p)
+			} else {
+				c.Check(t.UpdateHandle(h, Point{0, 0}), check.Equals, false)
+			}
+		case 2:
+			t.Remove(Point{float64(rnd.Intn(10)), float64(rnd.Intn(10))})
+		}
+		verify()
+	}
+	c.Check(t.Root.Bounding, check.NotNil)
+
+	other := &Tree{}
+	h := other.InsertHandle(Point{1, 1}, false)
+	c.Check(t.RemoveHandle(h), check.Equals, false)
+	c.Check(h.Valid(), check.Equals, true)
+	c.Check(other.RemoveHandle(h), check.Equals, true)
+	c.Check(other.Len(), check.Equals, 0)
+	c.Check(other.RemoveHandle(h), check.Equals, false)
+	c.Check(other.RemoveHandle(nil), check.Equals, false)
+
+	h = other.InsertHandle(Point{1, 1}, false)
+	other.Rebuild(false)
+	c.Check(h.Valid(), check.Equals, false)
+}
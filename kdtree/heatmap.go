@@ -0,0 +1,88 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A HeatmapOption modifies the behaviour of Heatmap.
+type HeatmapOption func(*heatmapConfig)
+
+type heatmapConfig struct {
+	weight func(Comparable) float64
+}
+
+// WithWeight returns a HeatmapOption that sums the weight of the values in each cell given by
+// fn in place of counting them.
+func WithWeight(fn func(Comparable) float64) HeatmapOption {
+	return func(cfg *heatmapConfig) { cfg.weight = fn }
+}
+
+// Heatmap returns the number of values stored in the tree in each cell of an nx by ny grid
+// over the first two dimensions of the bound b; values outside b in further dimensions are
+// not counted. The returned raster is indexed by row, along the second dimension, and then
+// by column, along the first. Cells are closed below and open above except for the final row
+// and column, which are closed at the limits of b, so each value within b is counted once.
+// Each row of the raster is filled by a single bounded traversal. Stored values and b must be
+// of a type accepted by Point.Compare. Heatmap panics if nx or ny is less than one or if the
+// tree holds values with fewer than two dimensions.
+func (t *Tree) Heatmap(b *Bounding, nx, ny int, opts ...HeatmapOption) [][]float64 {
+	if nx < 1 || ny < 1 {
+		panic("kdtree: invalid heatmap size")
+	}
+	var cfg heatmapConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	raster := make([][]float64, ny)
+	cells := make([]float64, nx*ny)
+	for j := range raster {
+		raster[j] = cells[j*nx : (j+1)*nx]
+	}
+	if t.Root == nil {
+		return raster
+	}
+	t.mustMatch(b[0])
+	t.mustMatch(b[1])
+	if t.Dims() < 2 {
+		panic("kdtree: heatmap of one-dimensional tree")
+	}
+
+	x0, x1 := at(b[0], 0), at(b[1], 0)
+	y0, y1 := at(b[0], 1), at(b[1], 1)
+	lo, hi := clonePoint(b[0]), clonePoint(b[1])
+	for j, row := range raster {
+		lo[1] = y0 + (y1-y0)*float64(j)/float64(ny)
+		var open []DoOption
+		if j < ny-1 {
+			hi[1] = y0 + (y1-y0)*float64(j+1)/float64(ny)
+			open = []DoOption{WithOpenUpper(1)}
+		} else {
+			hi[1] = y1
+		}
+		t.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			i := nx - 1
+			if x1 > x0 {
+				i = int(float64(nx) * (at(c, 0) - x0) / (x1 - x0))
+				if i >= nx {
+					i = nx - 1
+				}
+			}
+			w := 1.
+			if cfg.weight != nil {
+				w = cfg.weight(c)
+			}
+			row[i] += w
+			return false
+		}, &Bounding{lo, hi}, open...)
+	}
+	return raster
+}
+
+// clonePoint returns a Point holding the coordinates of c.
+func clonePoint(c Comparable) Point {
+	p := make(Point, c.Dims())
+	for d := range p {
+		p[d] = at(c, Dim(d))
+	}
+	return p
+}
@@ -0,0 +1,52 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHeatmap(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	b := &Bounding{Point{0, 0}, Point{10, 10}}
+	c.Check(t.Heatmap(b, 2, 2), check.DeepEquals, [][]float64{{1, 3}, {1, 1}})
+	c.Check(t.Heatmap(b, 2, 2, WithWeight(func(c Comparable) float64 { return c.(Point)[0] })),
+		check.DeepEquals, [][]float64{{2, 20}, {4, 9}})
+	c.Check(t.Heatmap(&Bounding{Point{0, 0}, Point{4, 4}}, 1, 3), check.DeepEquals, [][]float64{{0}, {0}, {1}})
+
+	// Values on cell borders are counted once.
+	t.Insert(Point{5, 5}, false)
+	t.Insert(Point{10, 10}, false)
+	t.Insert(Point{0, 10}, false)
+	c.Check(t.Heatmap(b, 2, 2), check.DeepEquals, [][]float64{{1, 3}, {2, 3}})
+
+	c.Check((&Tree{}).Heatmap(b, 3, 1), check.DeepEquals, [][]float64{{0, 0, 0}})
+	c.Check(func() { t.Heatmap(b, 0, 1) }, check.Panics, "kdtree: invalid heatmap size")
+	c.Check(func() { t.Heatmap(&Bounding{Point{0}, Point{1}}, 1, 1) }, check.Panics, ErrDimsMismatch)
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	t = New(p, true)
+	b = &Bounding{Point{0.1, 0.2, 0}, Point{0.9, 0.7, 0.5}}
+	const nx, ny = 7, 5
+	want := make([][]float64, ny)
+	for j := range want {
+		want[j] = make([]float64, nx)
+	}
+	for _, v := range p {
+		if !b.Contains(v) {
+			continue
+		}
+		i := int(nx * (v[0] - 0.1) / 0.8)
+		j := int(ny * (v[1] - 0.2) / 0.5)
+		want[j][i]++
+	}
+	c.Check(t.Heatmap(b, nx, ny), check.DeepEquals, want)
+}
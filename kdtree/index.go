@@ -0,0 +1,88 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "errors"
+
+var _ SpatialIndex = treeIndex{}
+
+// ErrPointDims is returned when inserting a point into an index holding points of a
+// different dimensionality.
+var ErrPointDims = errors.New("kdtree: point dimensions do not match index")
+
+// A SpatialIndex is a dynamic index of points supporting nearest neighbour and range
+// queries. SpatialIndex is implemented by the values returned by Tree.Index and by the
+// index types of the quadtree and grid packages, allowing the index used by an
+// application to be chosen at run time.
+type SpatialIndex interface {
+	// Insert adds c to the index.
+	Insert(c Comparable) error
+
+	// Remove removes a single value from the index that has the
+	// same coordinates as c, returning whether a value was removed.
+	Remove(c Comparable) bool
+
+	// Nearest returns the nearest value to the query and the
+	// distance between them.
+	Nearest(q Comparable) (Comparable, float64)
+
+	// NearestN returns the n nearest values to the query in
+	// min sorted order.
+	NearestN(q Comparable, n int) []ComparableDist
+
+	// InRange returns the values within distance d of the query
+	// in min sorted order.
+	InRange(q Comparable, d float64) []ComparableDist
+
+	// DoBounded performs fn on all values within the specified
+	// bound, returning whether the traversal was interrupted.
+	DoBounded(fn Operation, b *Bounding) bool
+
+	// Len returns the number of values in the index.
+	Len() int
+}
+
+// InRange returns the values within distance d of the query, as measured by the values'
+// Distance method, in min sorted order.
+func (t *Tree) InRange(q Comparable, d float64, opts ...SearchOption) []ComparableDist {
+	if t.Root == nil {
+		return nil
+	}
+	k := NewDistKeeper(d)
+	t.NearestSet(k, q, opts...)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// Index returns a SpatialIndex backed by t. Values inserted through the index update the
+// tree's bounding volumes according to bounding, as described for Insert.
+func (t *Tree) Index(bounding bool) SpatialIndex {
+	return treeIndex{t: t, bounding: bounding}
+}
+
+type treeIndex struct {
+	t        *Tree
+	bounding bool
+}
+
+func (i treeIndex) Insert(c Comparable) error {
+	if i.t.Root != nil && i.t.Dims() != c.Dims() {
+		return ErrPointDims
+	}
+	i.t.Insert(c, i.bounding)
+	return nil
+}
+
+func (i treeIndex) Remove(c Comparable) bool                      { return i.t.Remove(c) }
+func (i treeIndex) Nearest(q Comparable) (Comparable, float64)    { return i.t.Nearest(q) }
+func (i treeIndex) NearestN(q Comparable, n int) []ComparableDist { return i.t.NearestN(q, n) }
+func (i treeIndex) InRange(q Comparable, d float64) []ComparableDist {
+	return i.t.InRange(q, d)
+}
+func (i treeIndex) DoBounded(fn Operation, b *Bounding) bool { return i.t.DoBounded(fn, b) }
+func (i treeIndex) Len() int                                 { return i.t.Len() }
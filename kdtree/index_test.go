@@ -0,0 +1,44 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestInRange(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	q := Point{7, 5}
+	got := t.InRange(q, 5)
+	var want int
+	for _, p := range wpData {
+		if q.Distance(p) <= 5 {
+			want++
+		}
+	}
+	c.Check(got, check.HasLen, want)
+	for i := 1; i < len(got); i++ {
+		c.Check(got[i-1].Dist <= got[i].Dist, check.Equals, true)
+	}
+	c.Check((&Tree{}).InRange(q, 5), check.HasLen, 0)
+}
+
+func (s *S) TestIndex(c *check.C) {
+	t := &Tree{}
+	idx := t.Index(true)
+	for _, p := range wpData {
+		c.Assert(idx.Insert(p), check.Equals, nil)
+	}
+	c.Check(idx.Insert(Point{1, 2, 3}), check.Equals, ErrPointDims)
+	c.Check(idx.Len(), check.Equals, len(wpData))
+	c.Check(t.Root.Bounding, check.NotNil)
+
+	p, d := idx.Nearest(Point{8, 7})
+	c.Check(p, check.DeepEquals, Point{9, 6})
+	c.Check(d, check.Equals, 2.)
+	c.Check(idx.NearestN(Point{8, 7}, 2), check.HasLen, 2)
+	c.Check(idx.Remove(Point{9, 6}), check.Equals, true)
+	c.Check(idx.Len(), check.Equals, len(wpData)-1)
+}
@@ -0,0 +1,66 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// An Iterator steps through the values stored in a Tree in the order of an in-order
+// traversal, as performed by Do. The behaviour of an Iterator is undefined if the tree
+// is altered during iteration.
+type Iterator struct {
+	stack []iterFrame
+	cur   iterFrame
+}
+
+type iterFrame struct {
+	n     *Node
+	depth int
+}
+
+// Iter returns an Iterator over the values stored in the tree. Next must be called
+// before the first value is available.
+func (t *Tree) Iter() *Iterator {
+	it := &Iterator{}
+	it.pushLeft(t.Root, 0)
+	return it
+}
+
+// pushLeft pushes n and its chain of left descendants onto the iterator's stack.
+func (it *Iterator) pushLeft(n *Node, depth int) {
+	for ; n != nil; n, depth = n.Left, depth+1 {
+		it.stack = append(it.stack, iterFrame{n: n, depth: depth})
+	}
+}
+
+// Next advances the iterator to the next value, returning false when no values remain.
+func (it *Iterator) Next() bool {
+	if len(it.stack) == 0 {
+		it.cur = iterFrame{}
+		return false
+	}
+	it.cur = it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	it.pushLeft(it.cur.n.Right, it.cur.depth+1)
+	return true
+}
+
+// Point returns the current value. Point returns nil if Next has not been called or
+// has returned false.
+func (it *Iterator) Point() Comparable {
+	if it.cur.n == nil {
+		return nil
+	}
+	return it.cur.n.Point
+}
+
+// Bounding returns the bounding volume of the node holding the current value, as
+// passed to an Operation by Do.
+func (it *Iterator) Bounding() *Bounding {
+	if it.cur.n == nil {
+		return nil
+	}
+	return it.cur.n.Bounding
+}
+
+// Depth returns the depth in the tree of the current value.
+func (it *Iterator) Depth() int { return it.cur.depth }
@@ -0,0 +1,22 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package kdtree
+
+import "iter"
+
+// All returns an iterator over the values stored in the tree in the order of an
+// in-order traversal, as performed by Do.
+func (t *Tree) All() iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		for it := t.Iter(); it.Next(); {
+			if !yield(it.Point()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+// +build go1.23
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestAll(c *check.C) {
+	t := New(bData, false)
+	var want, got []Comparable
+	t.Do(func(p Comparable, _ *Bounding, _ int) bool {
+		want = append(want, p)
+		return false
+	})
+	for p := range t.All() {
+		got = append(got, p)
+	}
+	c.Check(got, check.DeepEquals, want)
+
+	got = got[:0]
+	for p := range t.All() {
+		if len(got) == 10 {
+			break
+		}
+		got = append(got, p)
+	}
+	c.Check(got, check.DeepEquals, want[:10])
+}
@@ -0,0 +1,46 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestIter(c *check.C) {
+	for i, t := range []*Tree{
+		New(wpData, true),
+		New(bData, true),
+		New(Points{}, false),
+		{},
+	} {
+		type visit struct {
+			p     Comparable
+			b     *Bounding
+			depth int
+		}
+		var want, got []visit
+		t.Do(func(p Comparable, b *Bounding, depth int) bool {
+			want = append(want, visit{p, b, depth})
+			return false
+		})
+		it := t.Iter()
+		c.Check(it.Point(), check.IsNil)
+		for it.Next() {
+			got = append(got, visit{it.Point(), it.Bounding(), it.Depth()})
+		}
+		c.Check(got, check.DeepEquals, want, check.Commentf("Test %d", i))
+		c.Check(it.Next(), check.Equals, false)
+		c.Check(it.Point(), check.IsNil)
+	}
+
+	// Iteration may be abandoned early.
+	var n int
+	for it := New(wpData, false).Iter(); it.Next(); n++ {
+		if n == 2 {
+			break
+		}
+	}
+	c.Check(n, check.Equals, 2)
+}
@@ -0,0 +1,98 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// UnmarshalPoint is used to decode the JSON representation of points held in Tree, Node
+// and Bounding values. Points are encoded using their own JSON encoding. The default
+// decodes Point values. Clients storing other Comparable types must set UnmarshalPoint
+// before decoding JSON trees.
+var UnmarshalPoint = func(data []byte) (Comparable, error) {
+	var p Point
+	err := json.Unmarshal(data, &p)
+	if p == nil && err == nil {
+		return nil, errors.New("kdtree: null point")
+	}
+	return p, err
+}
+
+type jsonTree struct {
+	Count int   `json:"count"`
+	Root  *Node `json:"root"`
+}
+
+// MarshalJSON satisfies the json.Marshaler interface. The Tracer is not encoded.
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTree{Count: t.Count, Root: t.Root})
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (t *Tree) UnmarshalJSON(data []byte) error {
+	var v jsonTree
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	t.Count, t.Root = v.Count, v.Root
+	return nil
+}
+
+type jsonNode struct {
+	Point  json.RawMessage `json:"point"`
+	Plane  Dim             `json:"plane"`
+	Left   *Node           `json:"left,omitempty"`
+	Right  *Node           `json:"right,omitempty"`
+	Bounds *Bounding       `json:"bounds,omitempty"`
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	p, err := json.Marshal(n.Point)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonNode{Point: p, Plane: n.Plane, Left: n.Left, Right: n.Right, Bounds: n.Bounding})
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. Points are decoded by UnmarshalPoint.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	var v jsonNode
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	p, err := UnmarshalPoint(v.Point)
+	if err != nil {
+		return err
+	}
+	*n = Node{Point: p, Plane: v.Plane, Left: v.Left, Right: v.Right, Bounding: v.Bounds}
+	return nil
+}
+
+// MarshalJSON satisfies the json.Marshaler interface. The Bounding is encoded as a
+// two element array of the minimum and maximum points.
+func (b *Bounding) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]Comparable(*b))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. Points are decoded by UnmarshalPoint.
+func (b *Bounding) UnmarshalJSON(data []byte) error {
+	var v [2]json.RawMessage
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	for i, p := range v {
+		b[i], err = UnmarshalPoint(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
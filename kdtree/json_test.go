@@ -0,0 +1,52 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/json"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestJSON(c *check.C) {
+	for i, t := range []*Tree{
+		{},
+		New(wpData, false),
+		New(append(Points(nil), wpData...), true),
+		bTree,
+	} {
+		b, err := json.Marshal(t)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+
+		var got Tree
+		err = json.Unmarshal(b, &got)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Count, check.Equals, t.Count)
+		c.Check(got.Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+	}
+}
+
+func (s *S) TestJSONNode(c *check.C) {
+	n := &Node{
+		Point:    Point{2, 3},
+		Plane:    1,
+		Left:     &Node{Point: Point{1, 1}},
+		Bounding: &Bounding{Point{1, 1}, Point{2, 3}},
+	}
+	b, err := json.Marshal(n)
+	c.Assert(err, check.IsNil)
+	c.Check(string(b), check.Equals,
+		`{"point":[2,3],"plane":1,"left":{"point":[1,1],"plane":0},"bounds":[[1,1],[2,3]]}`)
+
+	var got Node
+	c.Assert(json.Unmarshal(b, &got), check.IsNil)
+	c.Check(&got, check.DeepEquals, n)
+
+	var bnd Bounding
+	c.Assert(json.Unmarshal([]byte(`[[0,0],[1,2]]`), &bnd), check.IsNil)
+	c.Check(bnd, check.DeepEquals, Bounding{Point{0, 0}, Point{1, 2}})
+
+	c.Check(json.Unmarshal([]byte(`{"plane":0}`), &got), check.NotNil)
+}
@@ -0,0 +1,346 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdbtree
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Source is a sequence of points to be bulk loaded by CreateFrom.
+type Source interface {
+	// Next returns the next point of the sequence,
+	// or io.EOF when no points remain.
+	Next() (kdtree.Point, error)
+}
+
+// NewReaderSource returns a Source that reads points with the given number of dimensions
+// from r, each point being encoded as a sequence of little-endian IEEE 754 float64
+// coordinates.
+func NewReaderSource(r io.Reader, dims int) Source {
+	return &readerSource{r: bufio.NewReader(r), buf: make([]byte, 8*dims)}
+}
+
+type readerSource struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (s *readerSource) Next() (kdtree.Point, error) {
+	if len(s.buf) == 0 {
+		return nil, ErrDims
+	}
+	_, err := io.ReadFull(s.r, s.buf)
+	if err != nil {
+		return nil, err
+	}
+	return kdtree.Point(getFloats(s.buf, len(s.buf)/8)), nil
+}
+
+// CreateFrom bulk loads the points read from src into a new tree file at path, replacing
+// any existing file. All points must have the same dimensionality. No more than
+// Options.MemoryPoints points are held in memory; the points are spooled to a temporary
+// file and partitioned into the groups held by each page by external merge sorts along the
+// dimension of widest spread until each group fits in memory.
+func CreateFrom(path string, src Source, opts *Options) (*Tree, error) {
+	work, err := ioutil.TempFile(opts.tempDir(), "kdbtree")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(work)
+	scratch, err := ioutil.TempFile(opts.tempDir(), "kdbtree")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(scratch)
+
+	e := &external{work: work, scratch: scratch, mem: opts.memoryPoints()}
+	n, err := e.spool(src)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New("kdbtree: no points")
+	}
+	return create(path, e.dims, n, opts, func(b *builder) uint64 {
+		e.b = b
+		root, _ := e.build(segment{n: n}, b.height(n))
+		return root
+	})
+}
+
+func removeTemp(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// external builds a tree from points held in a work file of fixed size records, using a
+// scratch file of the same size to hold sorted runs.
+type external struct {
+	b *builder
+
+	work, scratch *os.File
+	dims          int
+	mem           int
+}
+
+// segment is a range of records in the work file.
+type segment struct {
+	off, n int
+}
+
+// spool writes the points read from src to the work file, returning the number of points.
+func (e *external) spool(src Source) (int, error) {
+	w := bufio.NewWriter(e.work)
+	var (
+		n   int
+		rec []byte
+	)
+	for {
+		p, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if n == 0 {
+			e.dims = len(p)
+			rec = make([]byte, 8*e.dims)
+		}
+		if len(p) != e.dims {
+			return n, ErrDims
+		}
+		putFloats(rec, 0, p)
+		_, err = w.Write(rec)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, w.Flush()
+}
+
+// build writes the subtree of height h holding the points of s, returning its page number
+// and bounds.
+func (e *external) build(s segment, h int) (uint64, *kdtree.Bounding) {
+	if e.b.err != nil {
+		return 0, nil
+	}
+	if s.n <= e.mem {
+		p := e.read(e.work, s)
+		if e.b.err != nil {
+			return 0, nil
+		}
+		return e.b.build(p, h)
+	}
+
+	groups := e.split(s, e.b.groups(s.n, h), nil)
+	children := make([]uint64, len(groups))
+	boxes := make([]*kdtree.Bounding, len(groups))
+	for i, g := range groups {
+		children[i], boxes[i] = e.build(g, h-1)
+		if e.b.err != nil {
+			return 0, nil
+		}
+	}
+	return e.b.region(children, boxes), union(boxes)
+}
+
+// split partitions s into m groups of near equal size by recursive median splits on the
+// dimension of widest spread, appending the groups to dst, as described for builder.split.
+func (e *external) split(s segment, m int, dst []segment) []segment {
+	if m <= 1 || e.b.err != nil {
+		return append(dst, s)
+	}
+	lm := m / 2
+	k := s.n * lm / m
+	e.sort(s, e.widest(s))
+	dst = e.split(segment{off: s.off, n: k}, lm, dst)
+	return e.split(segment{off: s.off + k, n: s.n - k}, m-lm, dst)
+}
+
+// widest returns the dimension of widest spread of the points in s.
+func (e *external) widest(s segment) kdtree.Dim {
+	min := make([]float64, e.dims)
+	max := make([]float64, e.dims)
+	for d := range min {
+		min[d], max[d] = math.Inf(1), math.Inf(-1)
+	}
+	e.scan(e.work, s, func(p kdtree.Point) {
+		for d, v := range p {
+			min[d] = math.Min(min[d], v)
+			max[d] = math.Max(max[d], v)
+		}
+	})
+	var widest kdtree.Dim
+	for d := range min {
+		if max[d]-min[d] > max[widest]-min[widest] {
+			widest = kdtree.Dim(d)
+		}
+	}
+	return widest
+}
+
+// sort sorts the points of s by their coordinate in dimension d, writing sorted runs of
+// up to e.mem points to the scratch file and merging them back into the work file.
+func (e *external) sort(s segment, d kdtree.Dim) {
+	var runs []segment
+	for off := 0; off < s.n; off += e.mem {
+		r := segment{off: s.off + off, n: s.n - off}
+		if r.n > e.mem {
+			r.n = e.mem
+		}
+		p := e.read(e.work, r)
+		sort.Sort(kdtree.Plane{Dim: d, Points: p})
+		if r.n == s.n {
+			e.write(e.work, r, p)
+			return
+		}
+		e.write(e.scratch, r, p)
+		runs = append(runs, r)
+	}
+	if e.b.err != nil {
+		return
+	}
+
+	q := runQueue{dim: d}
+	for _, r := range runs {
+		h := &run{r: e.reader(e.scratch, r), rec: make([]byte, 8*e.dims), n: r.n}
+		if h.next(e) {
+			q.runs = append(q.runs, h)
+		}
+	}
+	heap.Init(&q)
+	w := e.writer(e.work, s)
+	rec := make([]byte, 8*e.dims)
+	for len(q.runs) != 0 && e.b.err == nil {
+		h := q.runs[0]
+		putFloats(rec, 0, h.p)
+		_, e.b.err = w.Write(rec)
+		if h.next(e) {
+			heap.Fix(&q, 0)
+		} else {
+			heap.Pop(&q)
+		}
+	}
+	if e.b.err == nil {
+		e.b.err = w.Flush()
+	}
+}
+
+// run is a sorted run being merged.
+type run struct {
+	r   io.Reader
+	rec []byte
+	n   int
+	p   kdtree.Point
+}
+
+// next reads the next point of the run, returning whether a point was read.
+func (r *run) next(e *external) bool {
+	if r.n == 0 || e.b.err != nil {
+		return false
+	}
+	r.n--
+	_, e.b.err = io.ReadFull(r.r, r.rec)
+	r.p = kdtree.Point(getFloats(r.rec, e.dims))
+	return e.b.err == nil
+}
+
+// runQueue is a heap of runs ordered by the coordinate of their current point in dim.
+type runQueue struct {
+	dim  kdtree.Dim
+	runs []*run
+}
+
+func (q runQueue) Len() int            { return len(q.runs) }
+func (q runQueue) Less(i, j int) bool  { return q.runs[i].p[q.dim] < q.runs[j].p[q.dim] }
+func (q runQueue) Swap(i, j int)       { q.runs[i], q.runs[j] = q.runs[j], q.runs[i] }
+func (q *runQueue) Push(x interface{}) { q.runs = append(q.runs, x.(*run)) }
+func (q *runQueue) Pop() (i interface{}) {
+	i, q.runs = q.runs[len(q.runs)-1], q.runs[:len(q.runs)-1]
+	return i
+}
+
+// read returns the points of s held in f.
+func (e *external) read(f *os.File, s segment) kdtree.Points {
+	p := make(kdtree.Points, 0, s.n)
+	e.scan(f, s, func(q kdtree.Point) { p = append(p, q) })
+	return p
+}
+
+// scan calls fn for each point of s held in f.
+func (e *external) scan(f *os.File, s segment, fn func(kdtree.Point)) {
+	r := e.reader(f, s)
+	rec := make([]byte, 8*e.dims)
+	for i := 0; i < s.n && e.b.err == nil; i++ {
+		_, e.b.err = io.ReadFull(r, rec)
+		if e.b.err == nil {
+			fn(kdtree.Point(getFloats(rec, e.dims)))
+		}
+	}
+}
+
+// write writes the points in p to the records of s in f.
+func (e *external) write(f *os.File, s segment, p kdtree.Points) {
+	if e.b.err != nil {
+		return
+	}
+	w := e.writer(f, s)
+	rec := make([]byte, 8*e.dims)
+	for _, q := range p {
+		putFloats(rec, 0, q)
+		_, e.b.err = w.Write(rec)
+		if e.b.err != nil {
+			return
+		}
+	}
+	e.b.err = w.Flush()
+}
+
+func (e *external) reader(f *os.File, s segment) *bufio.Reader {
+	size := int64(8 * e.dims)
+	return bufio.NewReader(io.NewSectionReader(f, int64(s.off)*size, int64(s.n)*size))
+}
+
+func (e *external) writer(f *os.File, s segment) *bufio.Writer {
+	return bufio.NewWriter(&offsetWriter{w: f, off: int64(s.off) * int64(8*e.dims)})
+}
+
+// offsetWriter writes sequentially from an offset of an io.WriterAt.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (w *offsetWriter) Write(b []byte) (int, error) {
+	n, err := w.w.WriteAt(b, w.off)
+	w.off += int64(n)
+	return n, err
+}
+
+// union returns the bounding box of the boxes in b.
+func union(b []*kdtree.Bounding) *kdtree.Bounding {
+	min := append(kdtree.Point(nil), b[0][0].(kdtree.Point)...)
+	max := append(kdtree.Point(nil), b[0][1].(kdtree.Point)...)
+	for _, e := range b[1:] {
+		for d, v := range e[0].(kdtree.Point) {
+			min[d] = math.Min(min[d], v)
+		}
+		for d, v := range e[1].(kdtree.Point) {
+			max[d] = math.Max(max[d], v)
+		}
+	}
+	return &kdtree.Bounding{min, max}
+}
@@ -0,0 +1,582 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdbtree implements a disk-backed, paged k-d-B tree for point sets that are
+// larger than available memory.
+//
+// The tree is held in a file of fixed size pages. Leaf pages hold the coordinates of
+// points and region pages hold the page numbers and bounding boxes of their children.
+// Only pages touched by a query are read, and recently used pages are held in a cache
+// of bounded size. Trees are constructed by bulk loading, either from points held in
+// memory by Create or from a Source of points by CreateFrom, which partitions the points
+// in temporary files so that the point set need not fit in memory. Incremental update is
+// not supported.
+package kdbtree
+
+import (
+	"container/heap"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/biogo/store/kdtree"
+)
+
+const (
+	magic   = "kdbtree\x00"
+	version = 1
+
+	// DefaultPageSize is the page size used when Options.PageSize is zero.
+	DefaultPageSize = 4096
+
+	// DefaultCachePages is the cache size used when Options.CachePages is zero.
+	DefaultCachePages = 1024
+
+	// DefaultMemoryPoints is the number of points held in memory by CreateFrom when
+	// Options.MemoryPoints is zero.
+	DefaultMemoryPoints = 1 << 20
+
+	// pageHeader is the size of the header of each node page: a page type byte,
+	// three bytes of padding, and a uint32 entry count.
+	pageHeader = 8
+)
+
+const (
+	leafPage byte = iota + 1
+	regionPage
+)
+
+var (
+	// ErrFormat is returned when a file is not a valid k-d-B tree.
+	ErrFormat = errors.New("kdbtree: invalid file format")
+
+	// ErrPageSize is returned when the page size is too small to hold at least
+	// two entries in each page.
+	ErrPageSize = errors.New("kdbtree: page size too small for dimensions")
+
+	// ErrDims is returned when points do not have the dimensionality of the tree.
+	ErrDims = errors.New("kdbtree: dimension mismatch")
+)
+
+// Options specifies parameters for creating and opening trees.
+type Options struct {
+	// PageSize is the size of each page in bytes. It is only used
+	// when creating a tree. If zero, DefaultPageSize is used.
+	PageSize int
+
+	// CachePages is the maximum number of pages held in the page
+	// cache. If zero, DefaultCachePages is used.
+	CachePages int
+
+	// MemoryPoints is the maximum number of points held in memory
+	// by CreateFrom. If zero, DefaultMemoryPoints is used.
+	MemoryPoints int
+
+	// TempDir is the directory in which CreateFrom places its
+	// temporary files. If empty, the default directory for
+	// temporary files is used.
+	TempDir string
+}
+
+func (o *Options) pageSize() int {
+	if o == nil || o.PageSize == 0 {
+		return DefaultPageSize
+	}
+	return o.PageSize
+}
+
+func (o *Options) cachePages() int {
+	if o == nil || o.CachePages == 0 {
+		return DefaultCachePages
+	}
+	return o.CachePages
+}
+
+func (o *Options) memoryPoints() int {
+	if o == nil || o.MemoryPoints == 0 {
+		return DefaultMemoryPoints
+	}
+	return o.MemoryPoints
+}
+
+func (o *Options) tempDir() string {
+	if o == nil {
+		return ""
+	}
+	return o.TempDir
+}
+
+// A Tree is a disk-backed k-d-B tree of kdtree.Point values. Queries may be any
+// kdtree.Comparable that may be compared with kdtree.Point values. A Tree is safe for
+// concurrent use by multiple goroutines.
+type Tree struct {
+	f        *os.File
+	dims     int
+	pageSize int
+	count    int
+	root     uint64
+
+	cache *cache
+}
+
+// leafCap and regionCap return the number of entries that fit in a page.
+func leafCap(pageSize, dims int) int   { return (pageSize - pageHeader) / (8 * dims) }
+func regionCap(pageSize, dims int) int { return (pageSize - pageHeader) / (8 + 16*dims) }
+
+// Create bulk loads the points in p into a new tree file at path, replacing any existing
+// file. All points must have the same dimensionality. The order of elements in p is altered.
+func Create(path string, p kdtree.Points, opts *Options) (*Tree, error) {
+	if len(p) == 0 {
+		return nil, errors.New("kdbtree: no points")
+	}
+	dims := len(p[0])
+	for _, e := range p {
+		if len(e) != dims {
+			return nil, ErrDims
+		}
+	}
+	return create(path, dims, len(p), opts, func(b *builder) uint64 {
+		root, _ := b.build(p, b.height(len(p)))
+		return root
+	})
+}
+
+// create writes a new tree file at path holding n points of the given dimensionality,
+// with the node pages written by build, which returns the root page number.
+func create(path string, dims, n int, opts *Options, build func(*builder) uint64) (*Tree, error) {
+	size := opts.pageSize()
+	if dims == 0 || leafCap(size, dims) < 2 || regionCap(size, dims) < 2 {
+		return nil, ErrPageSize
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	b := builder{
+		w:        f,
+		dims:     dims,
+		pageSize: size,
+		leafCap:  leafCap(size, dims),
+		fanout:   regionCap(size, dims),
+		next:     1, // Page 0 is the file header.
+		buf:      make([]byte, size),
+	}
+	root := build(&b)
+	if b.err == nil {
+		b.header(n, root)
+	}
+	err = b.err
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Tree{
+		f:        f,
+		dims:     dims,
+		pageSize: size,
+		count:    n,
+		root:     root,
+		cache:    newCache(opts.cachePages()),
+	}, nil
+}
+
+// Open opens the tree file at path.
+func Open(path string, opts *Options) (*Tree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	var h [40]byte
+	_, err = f.ReadAt(h[:], 0)
+	if err != nil {
+		f.Close()
+		if err == io.EOF {
+			err = ErrFormat
+		}
+		return nil, err
+	}
+	t := &Tree{
+		f:        f,
+		dims:     int(binary.LittleEndian.Uint32(h[12:])),
+		pageSize: int(binary.LittleEndian.Uint32(h[16:])),
+		count:    int(binary.LittleEndian.Uint64(h[24:])),
+		root:     binary.LittleEndian.Uint64(h[32:]),
+		cache:    newCache(opts.cachePages()),
+	}
+	if string(h[:8]) != magic || binary.LittleEndian.Uint32(h[8:]) != version ||
+		t.dims == 0 || t.pageSize < pageHeader || t.root == 0 ||
+		leafCap(t.pageSize, t.dims) < 2 || regionCap(t.pageSize, t.dims) < 2 {
+		f.Close()
+		return nil, ErrFormat
+	}
+	return t, nil
+}
+
+// Close closes the tree file.
+func (t *Tree) Close() error { return t.f.Close() }
+
+// Len returns the number of points in the tree.
+func (t *Tree) Len() int { return t.count }
+
+// Dims returns the dimensionality of the points in the tree.
+func (t *Tree) Dims() int { return t.dims }
+
+type builder struct {
+	w        io.WriterAt
+	dims     int
+	pageSize int
+	leafCap  int
+	fanout   int
+	next     uint64
+	buf      []byte
+	err      error
+}
+
+// height returns the number of levels required to hold n points.
+func (b *builder) height(n int) int {
+	h := 1
+	for c := b.leafCap; c < n; c *= b.fanout {
+		h++
+	}
+	return h
+}
+
+// build writes the subtree of height h holding p, returning its page number and bounds.
+func (b *builder) build(p kdtree.Points, h int) (uint64, *kdtree.Bounding) {
+	if b.err != nil {
+		return 0, nil
+	}
+	bounds := p.Bounds()
+	if h == 1 {
+		b.page(leafPage, len(p))
+		o := pageHeader
+		for _, e := range p {
+			o = putFloats(b.buf, o, e)
+		}
+		return b.write(), bounds
+	}
+
+	groups := b.split(p, b.groups(len(p), h), nil)
+	children := make([]uint64, len(groups))
+	boxes := make([]*kdtree.Bounding, len(groups))
+	for i, g := range groups {
+		children[i], boxes[i] = b.build(g, h-1)
+	}
+	return b.region(children, boxes), bounds
+}
+
+// groups returns the number of children of a region page of height h holding n points.
+func (b *builder) groups(n, h int) int {
+	capacity := b.leafCap
+	for i := 2; i < h; i++ {
+		capacity *= b.fanout
+	}
+	return (n + capacity - 1) / capacity
+}
+
+// region writes a region page holding the given children and their bounding boxes,
+// returning its page number.
+func (b *builder) region(children []uint64, boxes []*kdtree.Bounding) uint64 {
+	b.page(regionPage, len(children))
+	o := pageHeader
+	for i, c := range children {
+		binary.LittleEndian.PutUint64(b.buf[o:], c)
+		o = putFloats(b.buf, o+8, boxes[i][0].(kdtree.Point))
+		o = putFloats(b.buf, o, boxes[i][1].(kdtree.Point))
+	}
+	return b.write()
+}
+
+// split partitions p into m groups of near equal size by recursive median splits on
+// the dimension of widest spread, appending the groups to dst.
+func (b *builder) split(p kdtree.Points, m int, dst []kdtree.Points) []kdtree.Points {
+	if m <= 1 {
+		return append(dst, p)
+	}
+	lm := m / 2
+	k := len(p) * lm / m
+	d := kdtree.WidestSpread(p, -1)
+	kdtree.Select(kdtree.Plane{Dim: d, Points: p}, k)
+	dst = b.split(p[:k], lm, dst)
+	return b.split(p[k:], m-lm, dst)
+}
+
+func (b *builder) page(typ byte, n int) {
+	for i := range b.buf {
+		b.buf[i] = 0
+	}
+	b.buf[0] = typ
+	binary.LittleEndian.PutUint32(b.buf[4:], uint32(n))
+}
+
+func (b *builder) write() uint64 {
+	id := b.next
+	b.next++
+	if b.err == nil {
+		_, b.err = b.w.WriteAt(b.buf, int64(id)*int64(b.pageSize))
+	}
+	return id
+}
+
+// header writes the file header page.
+func (b *builder) header(count int, root uint64) {
+	for i := range b.buf {
+		b.buf[i] = 0
+	}
+	copy(b.buf, magic)
+	binary.LittleEndian.PutUint32(b.buf[8:], version)
+	binary.LittleEndian.PutUint32(b.buf[12:], uint32(b.dims))
+	binary.LittleEndian.PutUint32(b.buf[16:], uint32(b.pageSize))
+	binary.LittleEndian.PutUint64(b.buf[24:], uint64(count))
+	binary.LittleEndian.PutUint64(b.buf[32:], root)
+	_, b.err = b.w.WriteAt(b.buf, 0)
+}
+
+func putFloats(b []byte, o int, v []float64) int {
+	for _, f := range v {
+		binary.LittleEndian.PutUint64(b[o:], math.Float64bits(f))
+		o += 8
+	}
+	return o
+}
+
+// page is a decoded node page.
+type page struct {
+	leaf bool
+
+	// points holds the points of a leaf page.
+	points []kdtree.Point
+
+	// children and boxes hold the child page numbers and their
+	// bounding boxes for a region page.
+	children []uint64
+	boxes    []kdtree.Bounding
+}
+
+func (t *Tree) page(id uint64) (*page, error) {
+	if p, ok := t.cache.get(id); ok {
+		return p, nil
+	}
+	buf := make([]byte, t.pageSize)
+	_, err := t.f.ReadAt(buf, int64(id)*int64(t.pageSize))
+	if err != nil {
+		if err == io.EOF {
+			err = ErrFormat
+		}
+		return nil, err
+	}
+	n := int(binary.LittleEndian.Uint32(buf[4:]))
+	p := &page{leaf: buf[0] == leafPage}
+	switch buf[0] {
+	case leafPage:
+		if n > leafCap(t.pageSize, t.dims) {
+			return nil, ErrFormat
+		}
+		coords := getFloats(buf[pageHeader:], n*t.dims)
+		p.points = make([]kdtree.Point, n)
+		for i := range p.points {
+			p.points[i] = kdtree.Point(coords[i*t.dims : (i+1)*t.dims : (i+1)*t.dims])
+		}
+	case regionPage:
+		if n > regionCap(t.pageSize, t.dims) {
+			return nil, ErrFormat
+		}
+		p.children = make([]uint64, n)
+		p.boxes = make([]kdtree.Bounding, n)
+		o := pageHeader
+		for i := range p.children {
+			p.children[i] = binary.LittleEndian.Uint64(buf[o:])
+			if p.children[i] == 0 || p.children[i] >= id {
+				// Children are always written before their parents.
+				return nil, ErrFormat
+			}
+			p.boxes[i] = kdtree.Bounding{
+				kdtree.Point(getFloats(buf[o+8:], t.dims)),
+				kdtree.Point(getFloats(buf[o+8+8*t.dims:], t.dims)),
+			}
+			o += 8 + 16*t.dims
+		}
+	default:
+		return nil, ErrFormat
+	}
+	t.cache.put(id, p)
+	return p, nil
+}
+
+func getFloats(b []byte, n int) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return v
+}
+
+// minDist returns the squared distance from q to the nearest point of b.
+func minDist(q kdtree.Comparable, b *kdtree.Bounding) float64 {
+	var sum float64
+	for d := kdtree.Dim(0); d < kdtree.Dim(q.Dims()); d++ {
+		if c := q.Compare(b[0], d); c < 0 {
+			sum += c * c
+		} else if c := q.Compare(b[1], d); c > 0 {
+			sum += c * c
+		}
+	}
+	return sum
+}
+
+// intersects returns whether a and b overlap.
+func intersects(a, b *kdtree.Bounding) bool {
+	for d := kdtree.Dim(0); d < kdtree.Dim(a[0].Dims()); d++ {
+		if a[0].Compare(b[1], d) > 0 || a[1].Compare(b[0], d) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest returns the nearest point to the query and the distance between them, as
+// measured by the query's Distance method.
+func (t *Tree) Nearest(q kdtree.Comparable) (kdtree.Comparable, float64, error) {
+	k := kdtree.NewNKeeper(1)
+	err := t.NearestSet(k, q)
+	if err != nil {
+		return nil, math.Inf(1), err
+	}
+	return k.Heap[0].Comparable, k.Heap[0].Dist, nil
+}
+
+// NearestSet finds the nearest points to the query accepted by the provided Keeper, k,
+// as described for kdtree.Tree.NearestSet. Pages are visited in order of their distance
+// from q, and pages further than the maximum distance held by k are not read.
+func (t *Tree) NearestSet(k kdtree.Keeper, q kdtree.Comparable) error {
+	if q.Dims() != t.dims {
+		return ErrDims
+	}
+	queue := pageQueue{{id: t.root}}
+	for len(queue) != 0 {
+		e := heap.Pop(&queue).(pageDist)
+		if e.dist > k.Max().Dist {
+			break
+		}
+		p, err := t.page(e.id)
+		if err != nil {
+			return err
+		}
+		if p.leaf {
+			for _, pt := range p.points {
+				k.Keep(kdtree.ComparableDist{Comparable: pt, Dist: q.Distance(pt)})
+			}
+			continue
+		}
+		for i, c := range p.children {
+			if d := minDist(q, &p.boxes[i]); d <= k.Max().Dist {
+				heap.Push(&queue, pageDist{id: c, dist: d})
+			}
+		}
+	}
+	if k.Len() != 1 {
+		sort.Sort(sort.Reverse(k))
+	}
+	return nil
+}
+
+type pageDist struct {
+	id   uint64
+	dist float64
+}
+
+type pageQueue []pageDist
+
+func (q pageQueue) Len() int              { return len(q) }
+func (q pageQueue) Less(i, j int) bool    { return q[i].dist < q[j].dist }
+func (q pageQueue) Swap(i, j int)         { q[i], q[j] = q[j], q[i] }
+func (q *pageQueue) Push(x interface{})   { *q = append(*q, x.(pageDist)) }
+func (q *pageQueue) Pop() (i interface{}) { i, *q = (*q)[len(*q)-1], (*q)[:len(*q)-1]; return i }
+
+// DoBounded performs fn on all points stored in the tree that are within the specified
+// bound. If b is nil, fn is performed on all points. A boolean is returned indicating
+// whether the traversal was interrupted by an Operation returning true. The depth passed
+// to fn is the depth of the leaf page holding the point.
+func (t *Tree) DoBounded(fn kdtree.Operation, b *kdtree.Bounding) (bool, error) {
+	if b != nil && (b[0].Dims() != t.dims || b[1].Dims() != t.dims) {
+		return false, ErrDims
+	}
+	return t.doBounded(t.root, fn, b, 0)
+}
+
+func (t *Tree) doBounded(id uint64, fn kdtree.Operation, b *kdtree.Bounding, depth int) (bool, error) {
+	p, err := t.page(id)
+	if err != nil {
+		return false, err
+	}
+	if p.leaf {
+		for _, pt := range p.points {
+			if b.Contains(pt) && fn(pt, b, depth) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for i, c := range p.children {
+		if b != nil && !intersects(&p.boxes[i], b) {
+			continue
+		}
+		done, err := t.doBounded(c, fn, b, depth+1)
+		if done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
+// cache is a least recently used cache of decoded pages.
+type cache struct {
+	mu    sync.Mutex
+	max   int
+	pages map[uint64]*list.Element
+	used  *list.List // Elements hold *entry, most recently used first.
+}
+
+type entry struct {
+	id   uint64
+	page *page
+}
+
+func newCache(max int) *cache {
+	return &cache{max: max, pages: make(map[uint64]*list.Element), used: list.New()}
+}
+
+func (c *cache) get(id uint64) (*page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.pages[id]
+	if !ok {
+		return nil, false
+	}
+	c.used.MoveToFront(e)
+	return e.Value.(*entry).page, true
+}
+
+func (c *cache) put(id uint64, p *page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pages[id]; ok {
+		return
+	}
+	c.pages[id] = c.used.PushFront(&entry{id: id, page: p})
+	for c.used.Len() > c.max {
+		last := c.used.Back()
+		c.used.Remove(last)
+		delete(c.pages, last.Value.(*entry).id)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdbtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randPoints(n, dims int) kdtree.Points {
+	p := make(kdtree.Points, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			p[i][d] = rand.Float64()
+		}
+	}
+	return p
+}
+
+func (s *S) TestQueries(c *check.C) {
+	const dims = 3
+	data := randPoints(5000, dims)
+	path := filepath.Join(c.MkDir(), "tree.kdb")
+	opts := &Options{PageSize: 256, CachePages: 8}
+	t, err := Create(path, append(kdtree.Points(nil), data...), opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Close(), check.IsNil)
+
+	t, err = Open(path, opts)
+	c.Assert(err, check.IsNil)
+	defer t.Close()
+	checkQueries(c, t, data)
+}
+
+func (s *S) TestCreateFrom(c *check.C) {
+	const dims = 3
+	data := randPoints(5000, dims)
+	var buf bytes.Buffer
+	rec := make([]byte, 8)
+	for _, p := range data {
+		for _, v := range p {
+			binary.LittleEndian.PutUint64(rec, math.Float64bits(v))
+			buf.Write(rec)
+		}
+	}
+	dir := c.MkDir()
+	path := filepath.Join(dir, "tree.kdb")
+	for _, mem := range []int{10000, 700, 100} {
+		opts := &Options{PageSize: 256, CachePages: 8, MemoryPoints: mem, TempDir: dir}
+		t, err := CreateFrom(path, NewReaderSource(bytes.NewReader(buf.Bytes()), dims), opts)
+		c.Assert(err, check.IsNil, check.Commentf("MemoryPoints=%d", mem))
+		checkQueries(c, t, data)
+		c.Assert(t.Close(), check.IsNil)
+	}
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, check.IsNil)
+	c.Check(files, check.HasLen, 1, check.Commentf("Temporary files not removed"))
+
+	_, err = CreateFrom(path, NewReaderSource(bytes.NewReader(buf.Bytes()[:8*dims+1]), dims), nil)
+	c.Check(err, check.Equals, io.ErrUnexpectedEOF)
+	_, err = CreateFrom(path, NewReaderSource(bytes.NewReader(nil), dims), nil)
+	c.Check(err, check.NotNil)
+}
+
+func checkQueries(c *check.C, t *Tree, data kdtree.Points) {
+	dims := len(data[0])
+	c.Check(t.Len(), check.Equals, len(data))
+	c.Check(t.Dims(), check.Equals, dims)
+
+	for i := 0; i < 100; i++ {
+		q := randPoints(1, dims)[0]
+		var want []float64
+		for _, p := range data {
+			want = append(want, q.Distance(p))
+		}
+		sort.Float64s(want)
+
+		_, d, err := t.Nearest(q)
+		c.Assert(err, check.IsNil)
+		c.Check(d, check.Equals, want[0], check.Commentf("Test %d", i))
+
+		k := kdtree.NewNKeeper(10)
+		c.Assert(t.NearestSet(k, q), check.IsNil)
+		c.Assert(k.Heap, check.HasLen, 10)
+		for j, cd := range k.Heap {
+			c.Check(cd.Dist, check.Equals, want[j], check.Commentf("Test %d: result %d", i, j))
+		}
+
+		// Queries need not be kdtree.Point values.
+		q32 := kdtree.Point32{float32(q[0]), float32(q[1]), float32(q[2])}
+		want = want[:0]
+		for _, p := range data {
+			want = append(want, q32.Distance(p))
+		}
+		sort.Float64s(want)
+		_, d, err = t.Nearest(q32)
+		c.Assert(err, check.IsNil)
+		c.Check(d, check.Equals, want[0], check.Commentf("Test %d", i))
+	}
+
+	b := &kdtree.Bounding{kdtree.Point{0.2, 0.3, 0.1}, kdtree.Point{0.5, 0.9, 0.4}}
+	var want int
+	for _, p := range data {
+		if b.Contains(p) {
+			want++
+		}
+	}
+	var got int
+	done, err := t.DoBounded(func(p kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+		c.Check(b.Contains(p), check.Equals, true)
+		got++
+		return false
+	}, b)
+	c.Assert(err, check.IsNil)
+	c.Check(done, check.Equals, false)
+	c.Check(got, check.Equals, want)
+
+	got = 0
+	done, err = t.DoBounded(func(kdtree.Comparable, *kdtree.Bounding, int) bool { got++; return got == 10 }, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(done, check.Equals, true)
+	c.Check(got, check.Equals, 10)
+
+	_, _, err = t.Nearest(kdtree.Point{0})
+	c.Check(err, check.Equals, ErrDims)
+}
+
+func (s *S) TestCreateErrors(c *check.C) {
+	dir := c.MkDir()
+	_, err := Create(filepath.Join(dir, "a"), kdtree.Points{{1, 2}, {1}}, nil)
+	c.Check(err, check.Equals, ErrDims)
+	_, err = Create(filepath.Join(dir, "b"), randPoints(10, 100), &Options{PageSize: 256})
+	c.Check(err, check.Equals, ErrPageSize)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "c"), []byte("not a tree"), 0644), check.IsNil)
+	_, err = Open(filepath.Join(dir, "c"), nil)
+	c.Check(err, check.Equals, ErrFormat)
+}
+
+func (s *S) TestCache(c *check.C) {
+	cache := newCache(2)
+	for id := uint64(1); id <= 3; id++ {
+		cache.put(id, &page{})
+	}
+	_, ok := cache.get(1)
+	c.Check(ok, check.Equals, false)
+	_, ok = cache.get(2)
+	c.Check(ok, check.Equals, true)
+	cache.put(4, &page{})
+	_, ok = cache.get(3)
+	c.Check(ok, check.Equals, false)
+	_, ok = cache.get(2)
+	c.Check(ok, check.Equals, true)
+}
@@ -0,0 +1,210 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdgonum provides adapters between k-d trees and gonum types.
+//
+// Vec2 and Vec3 wrap the r2.Vec and r3.Vec types of gonum's spatial packages as
+// kdtree.Comparable values, and Rows and Matrix convert between kdtree.Points and
+// the rows of a mat.Matrix.
+package kdgonum
+
+import (
+	"math"
+
+	"github.com/biogo/store/kdtree"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/spatial/r2"
+	"gonum.org/v1/gonum/spatial/r3"
+)
+
+var (
+	_ kdtree.Interface = Vec2s(nil)
+	_ kdtree.Bounder   = Vec2s(nil)
+	_ kdtree.Extender  = Vec2{}
+	_ kdtree.Interface = Vec3s(nil)
+	_ kdtree.Bounder   = Vec3s(nil)
+	_ kdtree.Extender  = Vec3{}
+)
+
+// A Vec2 is an r2.Vec that satisfies the kdtree.Comparable and kdtree.Extender interfaces.
+type Vec2 r2.Vec
+
+func (v Vec2) coord(d kdtree.Dim) float64 {
+	if d == 0 {
+		return v.X
+	}
+	return v.Y
+}
+
+// Compare satisfies the kdtree.Comparable interface. c must be a Vec2.
+func (v Vec2) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return v.coord(d) - c.(Vec2).coord(d)
+}
+
+// Dims returns 2.
+func (v Vec2) Dims() int { return 2 }
+
+// Distance returns the squared Euclidean distance between v and c. c must be a Vec2.
+func (v Vec2) Distance(c kdtree.Comparable) float64 {
+	return r2.Norm2(r2.Sub(r2.Vec(v), r2.Vec(c.(Vec2))))
+}
+
+// Extend satisfies the kdtree.Extender interface.
+func (v Vec2) Extend(b *kdtree.Bounding) *kdtree.Bounding {
+	if b == nil {
+		return &kdtree.Bounding{v, v}
+	}
+	min, max := b[0].(Vec2), b[1].(Vec2)
+	*b = kdtree.Bounding{
+		Vec2{X: math.Min(min.X, v.X), Y: math.Min(min.Y, v.Y)},
+		Vec2{X: math.Max(max.X, v.X), Y: math.Max(max.Y, v.Y)},
+	}
+	return b
+}
+
+// Vec2s is a collection of Vec2 values that satisfies the kdtree.Interface.
+type Vec2s []Vec2
+
+func (p Vec2s) Index(i int) kdtree.Comparable         { return p[i] }
+func (p Vec2s) Len() int                              { return len(p) }
+func (p Vec2s) Slice(start, end int) kdtree.Interface { return p[start:end] }
+func (p Vec2s) Pivot(d kdtree.Dim) int {
+	pl := plane2{Dim: d, Vec2s: p}
+	return kdtree.Partition(pl, kdtree.MedianOfRandoms(pl, kdtree.Randoms))
+}
+
+// Bounds returns the bounding box of the vectors.
+func (p Vec2s) Bounds() *kdtree.Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *kdtree.Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+
+type plane2 struct {
+	kdtree.Dim
+	Vec2s
+}
+
+func (p plane2) Less(i, j int) bool { return p.Vec2s[i].coord(p.Dim) < p.Vec2s[j].coord(p.Dim) }
+func (p plane2) Swap(i, j int)      { p.Vec2s[i], p.Vec2s[j] = p.Vec2s[j], p.Vec2s[i] }
+func (p plane2) Slice(start, end int) kdtree.SortSlicer {
+	p.Vec2s = p.Vec2s[start:end]
+	return p
+}
+
+// A Vec3 is an r3.Vec that satisfies the kdtree.Comparable and kdtree.Extender interfaces.
+type Vec3 r3.Vec
+
+func (v Vec3) coord(d kdtree.Dim) float64 {
+	switch d {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	}
+	return v.Z
+}
+
+// Compare satisfies the kdtree.Comparable interface. c must be a Vec3.
+func (v Vec3) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return v.coord(d) - c.(Vec3).coord(d)
+}
+
+// Dims returns 3.
+func (v Vec3) Dims() int { return 3 }
+
+// Distance returns the squared Euclidean distance between v and c. c must be a Vec3.
+func (v Vec3) Distance(c kdtree.Comparable) float64 {
+	return r3.Norm2(r3.Sub(r3.Vec(v), r3.Vec(c.(Vec3))))
+}
+
+// Extend satisfies the kdtree.Extender interface.
+func (v Vec3) Extend(b *kdtree.Bounding) *kdtree.Bounding {
+	if b == nil {
+		return &kdtree.Bounding{v, v}
+	}
+	min, max := b[0].(Vec3), b[1].(Vec3)
+	*b = kdtree.Bounding{
+		Vec3{X: math.Min(min.X, v.X), Y: math.Min(min.Y, v.Y), Z: math.Min(min.Z, v.Z)},
+		Vec3{X: math.Max(max.X, v.X), Y: math.Max(max.Y, v.Y), Z: math.Max(max.Z, v.Z)},
+	}
+	return b
+}
+
+// Vec3s is a collection of Vec3 values that satisfies the kdtree.Interface.
+type Vec3s []Vec3
+
+func (p Vec3s) Index(i int) kdtree.Comparable         { return p[i] }
+func (p Vec3s) Len() int                              { return len(p) }
+func (p Vec3s) Slice(start, end int) kdtree.Interface { return p[start:end] }
+func (p Vec3s) Pivot(d kdtree.Dim) int {
+	pl := plane3{Dim: d, Vec3s: p}
+	return kdtree.Partition(pl, kdtree.MedianOfRandoms(pl, kdtree.Randoms))
+}
+
+// Bounds returns the bounding box of the vectors.
+func (p Vec3s) Bounds() *kdtree.Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *kdtree.Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+
+type plane3 struct {
+	kdtree.Dim
+	Vec3s
+}
+
+func (p plane3) Less(i, j int) bool { return p.Vec3s[i].coord(p.Dim) < p.Vec3s[j].coord(p.Dim) }
+func (p plane3) Swap(i, j int)      { p.Vec3s[i], p.Vec3s[j] = p.Vec3s[j], p.Vec3s[i] }
+func (p plane3) Slice(start, end int) kdtree.SortSlicer {
+	p.Vec3s = p.Vec3s[start:end]
+	return p
+}
+
+// Rows returns the rows of m as kdtree.Points. If m is a *mat.Dense the returned points
+// share the backing data of m, so alterations to m are visible in the points and trees
+// built from them; otherwise the rows are copied.
+func Rows(m mat.Matrix) kdtree.Points {
+	r, c := m.Dims()
+	p := make(kdtree.Points, r)
+	if d, ok := m.(*mat.Dense); ok {
+		for i := range p {
+			row := d.RawRowView(i)
+			p[i] = kdtree.Point(row[:c:c])
+		}
+		return p
+	}
+	for i := range p {
+		p[i] = kdtree.Point(mat.Row(make([]float64, c), i, m))
+	}
+	return p
+}
+
+// Matrix returns a dense matrix with the points of p as its rows. All the points of p
+// must have the same dimensionality. Matrix returns nil if p is empty.
+func Matrix(p kdtree.Points) *mat.Dense {
+	if len(p) == 0 {
+		return nil
+	}
+	c := len(p[0])
+	data := make([]float64, 0, len(p)*c)
+	for _, e := range p {
+		if len(e) != c {
+			panic("kdgonum: dimension mismatch")
+		}
+		data = append(data, e...)
+	}
+	return mat.NewDense(len(p), c, data)
+}
@@ -0,0 +1,69 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdgonum
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/spatial/r2"
+	"gonum.org/v1/gonum/spatial/r3"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestVec2(c *check.C) {
+	p := Vec2s{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}
+	t := kdtree.New(p, true)
+	c.Check(t.Count, check.Equals, 6)
+	c.Check(t.Root.Bounding, check.DeepEquals, &kdtree.Bounding{Vec2{2, 1}, Vec2{9, 7}})
+	got, d := t.Nearest(Vec2(r2.Vec{X: 8, Y: 7}))
+	c.Check(got, check.Equals, Vec2{9, 6})
+	c.Check(d, check.Equals, 2.)
+}
+
+func (s *S) TestVec3(c *check.C) {
+	p := make(Vec3s, 500)
+	for i := range p {
+		p[i] = Vec3{X: rand.Float64(), Y: rand.Float64(), Z: rand.Float64()}
+	}
+	t := kdtree.New(p, true)
+	for i := 0; i < 50; i++ {
+		q := Vec3(r3.Vec{X: rand.Float64(), Y: rand.Float64(), Z: rand.Float64()})
+		got, d := t.Nearest(q)
+		want := p[0]
+		for _, v := range p[1:] {
+			if q.Distance(v) < q.Distance(want) {
+				want = v
+			}
+		}
+		c.Check(got, check.Equals, want)
+		c.Check(d, check.Equals, q.Distance(want))
+	}
+}
+
+func (s *S) TestRows(c *check.C) {
+	m := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	p := Rows(m)
+	c.Check(p, check.DeepEquals, kdtree.Points{{1, 2}, {3, 4}, {5, 6}})
+	m.Set(1, 0, 10)
+	c.Check(p[1], check.DeepEquals, kdtree.Point{10, 4})
+	c.Check(cap(p[0]), check.Equals, 2)
+
+	t := Rows(m.T())
+	c.Check(t, check.DeepEquals, kdtree.Points{{1, 10, 5}, {2, 4, 6}})
+
+	c.Check(mat.Equal(Matrix(p), m), check.Equals, true)
+	c.Check(Matrix(nil), check.IsNil)
+	c.Check(func() { Matrix(kdtree.Points{{1}, {1, 2}}) }, check.PanicMatches, "kdgonum: dimension mismatch")
+}
@@ -0,0 +1,300 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdhttp provides an HTTP/JSON interface to a k-d tree.
+//
+// A Server handles the following requests, relative to the path at which it is mounted.
+// Query points are given as comma separated finite coordinates, for example q=1.5,2.
+//
+//	GET  /nearest?q=x,y,...          the nearest point and its distance
+//	GET  /knn?q=x,y,...&k=n          the n nearest points and their distances
+//	GET  /range?min=x,y,...&max=...  all points within the bounding box
+//	POST /insert                     insert a JSON array of points
+//
+// Responses to nearest and knn requests are JSON objects of the form
+// {"point":[x,y],"dist":d}, the knn response being an array of these. Range responses
+// are streamed as newline delimited JSON, one point per line. Insert responses report
+// the number of points inserted as {"inserted":n}.
+//
+// Trees served by a Server must hold values that may be compared with kdtree.Point
+// values and that may be encoded as JSON. Points added by insert requests are
+// kdtree.Point values.
+package kdhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/biogo/store/kdtree"
+)
+
+const (
+	// flushEvery is the number of range results written between flushes of the response.
+	flushEvery = 256
+
+	// defaultMaxInsertBytes is the default limit on the size of an insert request body.
+	defaultMaxInsertBytes = 32 << 20
+)
+
+// A Server is an http.Handler that serves queries of a k-d tree. A Server is safe for
+// concurrent use.
+type Server struct {
+	// Bounding specifies whether inserted points
+	// update the bounding volumes of the tree.
+	Bounding bool
+
+	// MaxK is the largest number of neighbours
+	// returned by a knn request. If MaxK is zero
+	// there is no limit.
+	MaxK int
+
+	// MaxInsertBytes is the largest accepted
+	// insert request body. If MaxInsertBytes is
+	// zero, the limit is 32MiB.
+	MaxInsertBytes int64
+
+	mu   sync.RWMutex
+	tree *kdtree.Tree
+	mux  *http.ServeMux
+}
+
+// NewServer returns a Server serving queries of t. The tree must not be altered
+// except through the Server while the Server is in use.
+func NewServer(t *kdtree.Tree) *Server {
+	s := &Server{tree: t, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/nearest", s.nearest)
+	s.mux.HandleFunc("/knn", s.knn)
+	s.mux.HandleFunc("/range", s.bounded)
+	s.mux.HandleFunc("/insert", s.insert)
+	return s
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Result is a point and its distance from a query.
+type Result struct {
+	Point kdtree.Comparable `json:"point"`
+	Dist  float64           `json:"dist"`
+}
+
+func (s *Server) nearest(w http.ResponseWriter, r *http.Request) {
+	if !method(w, r, "GET") {
+		return
+	}
+	q, err := point(r, "q")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.RLock()
+	if !s.dims(w, q) {
+		s.mu.RUnlock()
+		return
+	}
+	p, d := s.tree.Nearest(q)
+	s.mu.RUnlock()
+	if p == nil {
+		http.Error(w, "kdhttp: empty tree", http.StatusNotFound)
+		return
+	}
+	reply(w, Result{Point: p, Dist: d})
+}
+
+func (s *Server) knn(w http.ResponseWriter, r *http.Request) {
+	if !method(w, r, "GET") {
+		return
+	}
+	q, err := point(r, "q")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	k, err := strconv.Atoi(r.FormValue("k"))
+	if err != nil || k < 1 {
+		http.Error(w, "kdhttp: invalid k", http.StatusBadRequest)
+		return
+	}
+	if s.MaxK > 0 && k > s.MaxK {
+		k = s.MaxK
+	}
+	s.mu.RLock()
+	if !s.dims(w, q) {
+		s.mu.RUnlock()
+		return
+	}
+	cds := s.tree.NearestN(q, k)
+	s.mu.RUnlock()
+	res := make([]Result, len(cds))
+	for i, cd := range cds {
+		res[i] = Result{Point: cd.Comparable, Dist: cd.Dist}
+	}
+	reply(w, res)
+}
+
+func (s *Server) bounded(w http.ResponseWriter, r *http.Request) {
+	if !method(w, r, "GET") {
+		return
+	}
+	min, err := point(r, "min")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	max, err := point(r, "max")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(min) != len(max) {
+		http.Error(w, "kdhttp: dimension mismatch", http.StatusBadRequest)
+		return
+	}
+
+	// Results are passed to the writer in batches through a
+	// channel, so no more than a few batches are held while a
+	// slow client is written to. The read lock is held until
+	// the traversal completes or the request ends.
+	s.mu.RLock()
+	if !s.dims(w, min) {
+		s.mu.RUnlock()
+		return
+	}
+	batches := make(chan []kdtree.Comparable, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(batches)
+		send := func(b []kdtree.Comparable) bool {
+			select {
+			case batches <- b:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		batch := make([]kdtree.Comparable, 0, flushEvery)
+		stopped := s.tree.DoBounded(func(c kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+			batch = append(batch, c)
+			if len(batch) < flushEvery {
+				return false
+			}
+			if !send(batch) {
+				return true
+			}
+			batch = make([]kdtree.Comparable, 0, flushEvery)
+			return false
+		}, &kdtree.Bounding{min, max})
+		s.mu.RUnlock()
+		if !stopped && len(batch) != 0 {
+			send(batch)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	f, _ := w.(http.Flusher)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for batch := range batches {
+		for _, c := range batch {
+			err = enc.Encode(c)
+			if err != nil {
+				return
+			}
+		}
+		err = bw.Flush()
+		if err != nil {
+			return
+		}
+		if f != nil {
+			f.Flush()
+		}
+	}
+}
+
+func (s *Server) insert(w http.ResponseWriter, r *http.Request) {
+	if !method(w, r, "POST") {
+		return
+	}
+	limit := s.MaxInsertBytes
+	if limit == 0 {
+		limit = defaultMaxInsertBytes
+	}
+	var p []kdtree.Point
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&p)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("kdhttp: invalid points: %v", err), http.StatusBadRequest)
+		return
+	}
+	for _, e := range p {
+		if len(e) == 0 || len(e) != len(p[0]) {
+			http.Error(w, "kdhttp: dimension mismatch", http.StatusBadRequest)
+			return
+		}
+	}
+	s.mu.Lock()
+	if len(p) != 0 && !s.dims(w, p[0]) {
+		s.mu.Unlock()
+		return
+	}
+	for _, e := range p {
+		s.tree.Insert(e, s.Bounding)
+	}
+	s.mu.Unlock()
+	reply(w, struct {
+		Inserted int `json:"inserted"`
+	}{len(p)})
+}
+
+// dims returns whether p has the dimensionality of the points in the tree, replying
+// with an error if it does not. dims must be called with s.mu held.
+func (s *Server) dims(w http.ResponseWriter, p kdtree.Point) bool {
+	if s.tree.Root == nil || s.tree.Root.Point.Dims() == len(p) {
+		return true
+	}
+	http.Error(w, "kdhttp: dimension mismatch", http.StatusBadRequest)
+	return false
+}
+
+// method returns whether the request method is m, replying with an error if it is not.
+func method(w http.ResponseWriter, r *http.Request, m string) bool {
+	if r.Method == m {
+		return true
+	}
+	w.Header().Set("Allow", m)
+	http.Error(w, "kdhttp: method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// point returns the point held in the form value key.
+func point(r *http.Request, key string) (kdtree.Point, error) {
+	v := r.FormValue(key)
+	if v == "" {
+		return nil, fmt.Errorf("kdhttp: missing %s", key)
+	}
+	f := strings.Split(v, ",")
+	p := make(kdtree.Point, len(f))
+	for i, s := range f {
+		var err error
+		p[i], err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || math.IsNaN(p[i]) || math.IsInf(p[i], 0) {
+			return nil, errors.New("kdhttp: invalid " + key)
+		}
+	}
+	return p, nil
+}
+
+func reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,149 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var wpData = kdtree.Points{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}
+
+type result struct {
+	Point kdtree.Point `json:"point"`
+	Dist  float64      `json:"dist"`
+}
+
+func get(c *check.C, srv *httptest.Server, path string, v interface{}) int {
+	resp, err := http.Get(srv.URL + path)
+	c.Assert(err, check.Equals, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && v != nil {
+		c.Assert(json.NewDecoder(resp.Body).Decode(v), check.Equals, nil)
+	}
+	return resp.StatusCode
+}
+
+func (s *S) TestQueries(c *check.C) {
+	srv := httptest.NewServer(NewServer(kdtree.New(append(kdtree.Points(nil), wpData...), false)))
+	defer srv.Close()
+
+	var r result
+	c.Check(get(c, srv, "/nearest?q=8,7", &r), check.Equals, http.StatusOK)
+	c.Check(r, check.DeepEquals, result{kdtree.Point{9, 6}, 2})
+
+	var rs []result
+	c.Check(get(c, srv, "/knn?q=8,7&k=2", &rs), check.Equals, http.StatusOK)
+	c.Check(rs, check.DeepEquals, []result{{kdtree.Point{9, 6}, 2}, {kdtree.Point{4, 7}, 16}})
+
+	resp, err := http.Get(srv.URL + "/range?min=4,1&max=8,4")
+	c.Assert(err, check.Equals, nil)
+	c.Check(resp.Header.Get("Content-Type"), check.Equals, "application/x-ndjson")
+	var got []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	resp.Body.Close()
+	sort.Strings(got)
+	c.Check(got, check.DeepEquals, []string{"[5,4]", "[7,2]", "[8,1]"})
+
+	for _, path := range []string{
+		"/nearest",
+		"/nearest?q=1,x",
+		"/nearest?q=1,NaN",
+		"/knn?q=Inf,2&k=1",
+		"/range?min=-Inf,1&max=3,4",
+		"/nearest?q=1,2,3",
+		"/knn?q=1,2",
+		"/knn?q=1,2&k=0",
+		"/range?min=1,2",
+		"/range?min=1,2&max=3",
+	} {
+		c.Check(get(c, srv, path, nil), check.Equals, http.StatusBadRequest, check.Commentf("%s", path))
+	}
+	resp, err = http.Post(srv.URL+"/nearest?q=1,2", "", nil)
+	c.Assert(err, check.Equals, nil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, check.Equals, http.StatusMethodNotAllowed)
+}
+
+func (s *S) TestInsert(c *check.C) {
+	t := &kdtree.Tree{}
+	h := NewServer(t)
+	h.MaxK = 3
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	c.Check(get(c, srv, "/nearest?q=1,2", nil), check.Equals, http.StatusNotFound)
+
+	body, _ := json.Marshal(wpData)
+	resp, err := http.Post(srv.URL+"/insert", "application/json", strings.NewReader(string(body)))
+	c.Assert(err, check.Equals, nil)
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Check(strings.TrimSpace(string(b)), check.Equals, `{"inserted":6}`)
+	c.Check(t.Count, check.Equals, 6)
+
+	var rs []result
+	c.Check(get(c, srv, "/knn?q=8,7&k=10", &rs), check.Equals, http.StatusOK)
+	c.Check(rs, check.HasLen, 3)
+
+	for _, body := range []string{`[[1,2,3]]`, `[[1,2],[1]]`, `[[]]`, `{`} {
+		resp, err = http.Post(srv.URL+"/insert", "application/json", strings.NewReader(body))
+		c.Assert(err, check.Equals, nil)
+		resp.Body.Close()
+		c.Check(resp.StatusCode, check.Equals, http.StatusBadRequest, check.Commentf("%s", body))
+	}
+	c.Check(t.Count, check.Equals, 6)
+
+	h.MaxInsertBytes = int64(len(body) - 1)
+	resp, err = http.Post(srv.URL+"/insert", "application/json", strings.NewReader(string(body)))
+	c.Assert(err, check.Equals, nil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, check.Equals, http.StatusBadRequest)
+	c.Check(t.Count, check.Equals, 6)
+}
+
+// flushRecorder records the number of lines written at each flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	lines []int
+}
+
+func (r *flushRecorder) Flush() {
+	r.lines = append(r.lines, strings.Count(r.Body.String(), "\n"))
+	r.ResponseRecorder.Flush()
+}
+
+func (s *S) TestRangeFlush(c *check.C) {
+	n := 3*flushEvery + 10
+	p := make(kdtree.Points, n)
+	for i := range p {
+		p[i] = kdtree.Point{float64(i), float64(n - i)}
+	}
+	h := NewServer(kdtree.New(p, false))
+
+	rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/range?min=0,0&max=10000,10000", nil))
+	c.Check(rec.Code, check.Equals, http.StatusOK)
+	c.Check(rec.lines, check.DeepEquals, []int{flushEvery, 2 * flushEvery, 3 * flushEvery, n})
+}
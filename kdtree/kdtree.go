@@ -90,6 +90,13 @@ type Node struct {
 	Plane       Dim
 	Left, Right *Node
 	*Bounding
+
+	// Summary is the summary of the values in the subtree
+	// rooted at the node maintained by the tree's Augmenter.
+	Summary interface{}
+
+	// handle is the handle referring to Point, if any.
+	handle *Handle
 }
 
 func (n *Node) String() string {
@@ -103,49 +110,74 @@ func (n *Node) String() string {
 type Tree struct {
 	Root  *Node
 	Count int
+
+	// Tracer, if not nil, is notified of the progress of searches.
+	Tracer Tracer
+
+	// Observer, if not nil, is notified of mutations of the tree.
+	Observer *Observer
+
+	// Augmenter, if not nil, maintains the Summary of each node.
+	// Augmenter should be set with Augment.
+	Augmenter Augmenter
+
+	// Bloom, if not nil, is consulted by Has and Get to reject
+	// absent values without a search. Bloom should be set with
+	// UseBloom.
+	Bloom *Bloom
 }
 
 // New returns a k-d tree constructed from the values in p. If p is a Bounder and
 // bounding is true, bounds are determined for each node.
 func New(p Interface, bounding bool) *Tree {
+	return NewSplit(p, bounding, Cycle)
+}
+
+// NewSplit returns a k-d tree constructed from the values in p with the splitting
+// dimension of each node chosen by rule. If rule is nil, Cycle is used. If p is a
+// Bounder and bounding is true, bounds are determined for each node.
+func NewSplit(p Interface, bounding bool, rule SplitRule) *Tree {
+	if rule == nil {
+		rule = Cycle
+	}
 	if p, ok := p.(bounder); ok && bounding {
 		return &Tree{
-			Root:  buildBounded(p, 0, bounding),
+			Root:  buildBounded(p, rule, -1, bounding),
 			Count: p.Len(),
 		}
 	}
 	return &Tree{
-		Root:  build(p, 0),
+		Root:  build(p, rule, -1),
 		Count: p.Len(),
 	}
 }
 
-func build(p Interface, plane Dim) *Node {
+func build(p Interface, rule SplitRule, parent Dim) *Node {
 	if p.Len() == 0 {
 		return nil
 	}
 
+	plane := rule(p, parent)
 	piv := p.Pivot(plane)
 	d := p.Index(piv)
-	np := (plane + 1) % Dim(d.Dims())
 
 	return &Node{
 		Point:    d,
 		Plane:    plane,
-		Left:     build(p.Slice(0, piv), np),
-		Right:    build(p.Slice(piv+1, p.Len()), np),
+		Left:     build(p.Slice(0, piv), rule, plane),
+		Right:    build(p.Slice(piv+1, p.Len()), rule, plane),
 		Bounding: nil,
 	}
 }
 
-func buildBounded(p bounder, plane Dim, bounding bool) *Node {
+func buildBounded(p bounder, rule SplitRule, parent Dim, bounding bool) *Node {
 	if p.Len() == 0 {
 		return nil
 	}
 
+	plane := rule(p, parent)
 	piv := p.Pivot(plane)
 	d := p.Index(piv)
-	np := (plane + 1) % Dim(d.Dims())
 
 	var b *Bounding
 	if bounding {
@@ -154,28 +186,37 @@ func buildBounded(p bounder, plane Dim, bounding bool) *Node {
 	return &Node{
 		Point:    d,
 		Plane:    plane,
-		Left:     buildBounded(p.Slice(0, piv).(bounder), np, bounding),
-		Right:    buildBounded(p.Slice(piv+1, p.Len()).(bounder), np, bounding),
+		Left:     buildBounded(p.Slice(0, piv).(bounder), rule, plane, bounding),
+		Right:    buildBounded(p.Slice(piv+1, p.Len()).(bounder), rule, plane, bounding),
 		Bounding: b,
 	}
 }
 
 // Insert adds a point to the tree, updating the bounding volumes if bounding is
 // true, and the tree is empty or the tree already has bounding volumes stored,
-// and c is an Extender. No rebalancing of the tree is performed.
+// and c is an Extender. No rebalancing of the tree is performed. Insert panics with
+// ErrDimsMismatch if c does not have the dimensionality of the values in the tree.
 func (t *Tree) Insert(c Comparable, bounding bool) {
+	if t.Root != nil && c.Dims() != t.Dims() {
+		panic(ErrDimsMismatch)
+	}
 	t.Count++
 	if t.Root != nil {
 		bounding = t.Root.Bounding != nil
 	}
-	if c, ok := c.(Extender); ok && bounding {
-		t.Root = t.Root.insertBounded(c, 0, bounding)
-		return
-	} else if !ok && t.Root != nil {
-		// If we are not rebounding, mark the tree as non-bounded.
-		t.Root.Bounding = nil
+	if e, ok := c.(Extender); ok && bounding {
+		t.Root = t.Root.insertBounded(e, 0, bounding)
+	} else {
+		if !ok && t.Root != nil {
+			// If we are not rebounding, mark the tree as non-bounded.
+			t.Root.Bounding = nil
+		}
+		t.Root = t.Root.insert(c, 0)
+	}
+	if t.Augmenter != nil {
+		t.summarizePath(c)
 	}
-	t.Root = t.Root.insert(c, 0)
+	t.inserted(c)
 }
 
 func (n *Node) insert(c Comparable, d Dim) *Node {
@@ -223,65 +264,299 @@ func (n *Node) insertBounded(c Extender, d Dim, bounding bool) *Node {
 	return n
 }
 
+// Remove removes a single value from the tree that has the same coordinates as c,
+// returning whether a value was removed. Bounding volumes are updated if the tree has
+// bounding volumes stored and the stored values and bounds are Extenders; otherwise the
+// tree is marked as non-bounded. No rebalancing of the tree is performed.
+func (t *Tree) Remove(c Comparable) bool {
+	if t.Root == nil || c.Dims() != t.Dims() {
+		return false
+	}
+	f := t.fixup()
+	var removed Comparable
+	match := func(n *Node) bool {
+		if !sameCoords(c, n.Point) {
+			return false
+		}
+		removed = n.Point
+		return true
+	}
+	var ok bool
+	t.Root, ok = t.Root.remove(c, match, f)
+	if !ok {
+		return false
+	}
+	t.Count--
+	if !f.bounding && t.Root != nil {
+		t.Root.Bounding = nil
+	}
+	t.removed(removed)
+	return true
+}
+
+// sameCoords returns whether a and b have the same coordinates.
+func sameCoords(a, b Comparable) bool {
+	for d := Dim(0); d < Dim(a.Dims()); d++ {
+		if a.Compare(b, d) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// remove removes the first node on the search path of c for which match returns true,
+// returning the new root of the subtree and whether a node was removed. Nodes on the
+// search path are updated by f.
+func (n *Node) remove(c Comparable, match func(*Node) bool, f *fixup) (*Node, bool) {
+	if n == nil {
+		return nil, false
+	}
+	var ok bool
+	switch {
+	case match(n):
+		n, ok = n.removeNode(f), true
+	case c.Compare(n.Point, n.Plane) <= 0:
+		n.Left, ok = n.Left.remove(c, match, f)
+	default:
+		n.Right, ok = n.Right.remove(c, match, f)
+	}
+	if ok && n != nil {
+		f.update(n)
+	}
+	return n, ok
+}
+
+// removeNode removes the point held by n from the subtree rooted at n, returning the new
+// root of the subtree. The point is replaced by the point with the greatest value in the
+// plane of n from the left subtree, or from the right subtree if there is no left subtree,
+// in which case the remaining right subtree becomes the left subtree.
+func (n *Node) removeNode(f *fixup) *Node {
+	if n.handle != nil {
+		n.handle.node = nil
+		n.handle = nil
+	}
+	sub := n.Left
+	if sub == nil {
+		sub = n.Right
+		if sub == nil {
+			return nil
+		}
+		n.Right = nil
+	}
+	m := sub.maxOn(n.Plane)
+	n.Point = m.Point
+	if m.handle != nil {
+		n.handle, m.handle = m.handle, nil
+		n.handle.node = n
+	}
+	n.Left, _ = sub.removeTarget(m, f)
+	return n
+}
+
+// removeTarget removes the node target from the subtree rooted at n, returning the new
+// root of the subtree and whether target was found. Both subtrees are searched when the
+// point of target lies on the splitting plane of a node. Nodes on the path to target are
+// updated by f.
+func (n *Node) removeTarget(target *Node, f *fixup) (*Node, bool) {
+	if n == nil {
+		return nil, false
+	}
+	var ok bool
+	if n == target {
+		n, ok = n.removeNode(f), true
+	} else {
+		c := target.Point.Compare(n.Point, n.Plane)
+		if c <= 0 {
+			n.Left, ok = n.Left.removeTarget(target, f)
+		}
+		if !ok && c >= 0 {
+			n.Right, ok = n.Right.removeTarget(target, f)
+		}
+	}
+	if ok && n != nil {
+		f.update(n)
+	}
+	return n, ok
+}
+
+// maxOn returns the node in the subtree rooted at n with the greatest value in dimension d.
+func (n *Node) maxOn(d Dim) *Node {
+	if n == nil {
+		return nil
+	}
+	m := n
+	if n.Plane != d {
+		if l := n.Left.maxOn(d); l != nil && l.Point.Compare(m.Point, d) > 0 {
+			m = l
+		}
+	}
+	if r := n.Right.maxOn(d); r != nil && r.Point.Compare(m.Point, d) > 0 {
+		m = r
+	}
+	return m
+}
+
+// rebound recalculates the bounding volume of n from its point and the bounding volumes of
+// its children, returning whether this was possible.
+func (n *Node) rebound() bool {
+	e, ok := n.Point.(Extender)
+	if !ok {
+		return false
+	}
+	b := e.Extend(nil)
+	for _, c := range [...]*Node{n.Left, n.Right} {
+		if c == nil {
+			continue
+		}
+		if c.Bounding == nil {
+			return false
+		}
+		for _, p := range c.Bounding {
+			e, ok := p.(Extender)
+			if !ok {
+				return false
+			}
+			b = e.Extend(b)
+		}
+	}
+	n.Bounding = b
+	return true
+}
+
 // Len returns the number of elements in the tree.
 func (t *Tree) Len() int { return t.Count }
 
+// Dims returns the dimensionality of the values in the tree, or zero if the tree is empty.
+func (t *Tree) Dims() int {
+	if t.Root == nil {
+		return 0
+	}
+	return t.Root.Point.Dims()
+}
+
+// mustMatch panics with ErrDimsMismatch if t is not empty and q does not have the
+// dimensionality of the values in t.
+func (t *Tree) mustMatch(q Comparable) {
+	if t.Root != nil && q.Dims() != t.Root.Point.Dims() {
+		panic(ErrDimsMismatch)
+	}
+}
+
 // Contains returns whether a Comparable is in the bounds of the tree. If no bounding has
-// been constructed Contains returns true.
+// been constructed Contains returns true. ContainsErr distinguishes these cases.
 func (t *Tree) Contains(c Comparable) bool {
-	if t.Root.Bounding == nil {
+	if t.Root == nil || t.Root.Bounding == nil {
 		return true
 	}
 	return t.Root.Contains(c)
 }
 
+// Bounds returns the bounding volume of the values in the tree. If bounding volumes have
+// been constructed for the tree, the volume of the root is returned and must not be altered.
+// Otherwise the volume is computed from the stored values, which must be Extenders. Bounds
+// returns nil if the tree is empty or the volume cannot be computed.
+func (t *Tree) Bounds() *Bounding {
+	if t.Root == nil {
+		return nil
+	}
+	if t.Root.Bounding != nil {
+		return t.Root.Bounding
+	}
+	var b *Bounding
+	ok := !t.Root.do(func(c Comparable, _ *Bounding, _ int) bool {
+		e, isExt := c.(Extender)
+		if !isExt {
+			return true
+		}
+		b = e.Extend(b)
+		return false
+	}, 0)
+	if !ok {
+		return nil
+	}
+	return b
+}
+
+// Points returns the values stored in the tree in the order they are visited by Do.
+func (t *Tree) Points() []Comparable {
+	return t.AppendPoints(make([]Comparable, 0, t.Count))
+}
+
+// AppendPoints appends the values stored in the tree to dst in the order they are
+// visited by Do, and returns the extended slice.
+func (t *Tree) AppendPoints(dst []Comparable) []Comparable {
+	if t.Root == nil {
+		return dst
+	}
+	t.Root.do(func(c Comparable, _ *Bounding, _ int) bool {
+		dst = append(dst, c)
+		return false
+	}, 0)
+	return dst
+}
+
 var inf = math.Inf(1)
 
-// Nearest returns the nearest value to the query and the distance between them.
-func (t *Tree) Nearest(q Comparable) (Comparable, float64) {
+// Nearest returns the nearest value to the query and the distance between them. If more
+// than one value is nearest to the query, the value with the lexically least coordinates is
+// returned; NearestAll returns all the nearest values. If the tree is empty, Nearest returns
+// nil and +Inf; NearestErr reports this case as an error.
+// Nearest panics with ErrDimsMismatch if q does not have the dimensionality of the values
+// in the tree.
+func (t *Tree) Nearest(q Comparable, opts ...SearchOption) (Comparable, float64) {
 	if t.Root == nil {
 		return nil, inf
 	}
-	n, dist := t.Root.search(q, inf)
+	t.mustMatch(q)
+	n, dist := t.Root.search(q, nil, inf, t.searchConfig(opts))
 	if n == nil {
 		return nil, inf
 	}
 	return n.Point, dist
 }
 
-func (n *Node) search(q Comparable, dist float64) (*Node, float64) {
+func (n *Node) search(q Comparable, bn *Node, dist float64, sc *searchConfig) (*Node, float64) {
 	if n == nil {
-		return nil, inf
+		return bn, dist
+	}
+	// Instrumentation is guarded here rather than only in
+	// the searchConfig methods to keep the uninstrumented
+	// search free of calls.
+	if sc != nil {
+		if sc.exhausted() {
+			sc.prune(n)
+			return bn, dist
+		}
+		sc.visit(n)
 	}
 
 	c := q.Compare(n.Point, n.Plane)
-	dist = math.Min(dist, q.Distance(n.Point))
-
-	bn := n
-	if c <= 0 {
-		ln, ld := n.Left.search(q, dist)
-		if ld < dist {
-			dist = ld
-			bn = ln
-		}
-		if c*c < dist {
-			rn, rd := n.Right.search(q, dist)
-			if rd < dist {
-				bn, dist = rn, rd
-			}
+	var d float64
+	if sc == nil {
+		d = q.Distance(n.Point)
+	} else {
+		d = sc.distance(q, n.Point)
+	}
+	if d < dist || (d == dist && bn != nil && lexLess(n.Point, bn.Point)) {
+		bn, dist = n, d
+		if sc != nil {
+			sc.candidate(n, d)
 		}
-		return bn, dist
 	}
-	rn, rd := n.Right.search(q, dist)
-	if rd < dist {
-		dist = rd
-		bn = rn
+
+	near, far := n.Left, n.Right
+	if c > 0 {
+		near, far = far, near
 	}
-	if c*c < dist {
-		ln, ld := n.Left.search(q, dist)
-		if ld < dist {
-			bn, dist = ln, ld
+	bn, dist = near.search(q, bn, dist, sc)
+	if sc == nil {
+		if planeDist(q, c) <= dist {
+			bn, dist = far.search(q, bn, dist, sc)
 		}
+	} else if sc.reach(q, c, dist) {
+		bn, dist = far.search(q, bn, dist, sc)
+	} else {
+		sc.prune(far)
 	}
 	return bn, dist
 }
@@ -294,12 +569,23 @@ type ComparableDist struct {
 	Dist       float64
 }
 
-// Heap is a max heap sorted on Dist.
+// Heap is a max heap sorted on Dist. Values at equal distances are ordered lexically by
+// their coordinates, so the order of sorted results does not depend on the structure of
+// the tree that was searched.
 type Heap []ComparableDist
 
-func (h *Heap) Max() ComparableDist  { return (*h)[0] }
-func (h *Heap) Len() int             { return len(*h) }
-func (h *Heap) Less(i, j int) bool   { return (*h)[i].Comparable == nil || (*h)[i].Dist > (*h)[j].Dist }
+func (h *Heap) Max() ComparableDist { return (*h)[0] }
+func (h *Heap) Len() int            { return len(*h) }
+func (h *Heap) Less(i, j int) bool {
+	a, b := (*h)[i], (*h)[j]
+	if a.Comparable == nil {
+		return true
+	}
+	if a.Dist != b.Dist {
+		return a.Dist > b.Dist
+	}
+	return b.Comparable != nil && lexLess(b.Comparable, a.Comparable)
+}
 func (h *Heap) Swap(i, j int)        { (*h)[i], (*h)[j] = (*h)[j], (*h)[i] }
 func (h *Heap) Push(x interface{})   { (*h) = append(*h, x.(ComparableDist)) }
 func (h *Heap) Pop() (i interface{}) { i, *h = (*h)[len(*h)-1], (*h)[:len(*h)-1]; return i }
@@ -307,28 +593,52 @@ func (h *Heap) Pop() (i interface{}) { i, *h = (*h)[len(*h)-1], (*h)[:len(*h)-1]
 // NKeeper is a Keeper that retains the n best ComparableDists that it is called to Keep.
 type NKeeper struct {
 	Heap
+	n int
 }
 
+// nKeeperCap is the maximum initial capacity of the heap of an NKeeper.
+const nKeeperCap = 64
+
 // NewNKeeper returns an NKeeper with the max value of the heap set to infinite distance. The
-// returned NKeeper is able to retain at most n values.
+// returned NKeeper is able to retain at most n values. Storage for the heap is allocated as
+// values are retained rather than in advance, so n may be large.
 func NewNKeeper(n int) *NKeeper {
-	k := NKeeper{make(Heap, 1, n)}
+	c := n
+	if c > nKeeperCap {
+		c = nKeeperCap
+	}
+	if c < 1 {
+		c = 1
+	}
+	k := NKeeper{Heap: make(Heap, 1, c), n: n}
 	k.Heap[0].Dist = inf
 	return &k
 }
 
-// Keep add c to the heap if its distance is less than the maximum value of the heap. If adding
-// c would increase the size of the heap beyond the initial maximum length, the maximum value of
-// the heap is dropped.
+// Keep add c to the heap if its distance is less than the maximum value of the heap, or if
+// it is equal to the distance of the maximum value and c is lexically less by coordinates. If
+// adding c would increase the size of the heap beyond the initial maximum length, the maximum
+// value of the heap is dropped. The values retained by a search are therefore the n nearest
+// values, with ties at the nth distance resolved in favour of lexically lesser values.
 func (k *NKeeper) Keep(c ComparableDist) {
-	if c.Dist < k.Heap[0].Dist {
-		if len(k.Heap) == cap(k.Heap) {
+	max := k.Heap[0]
+	if c.Dist < max.Dist || (c.Dist == max.Dist && max.Comparable != nil && lexLess(c.Comparable, max.Comparable)) {
+		if len(k.Heap) == k.limit() {
 			heap.Pop(k)
 		}
 		heap.Push(k, c)
 	}
 }
 
+// limit returns the maximum length of the heap. An NKeeper not created by NewNKeeper is
+// limited by the capacity of its heap.
+func (k *NKeeper) limit() int {
+	if k.n > 0 {
+		return k.n
+	}
+	return cap(k.Heap)
+}
+
 // DistKeeper is a Keeper that retains the ComparableDists within the specified distance of the
 // query that it is called to Keep.
 type DistKeeper struct {
@@ -356,12 +666,14 @@ type Keeper interface {
 // NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
 // k must be able to return a ComparableDist specifying the maximum acceptable distance
 // when Max() is called, and retains the results of the search in min sorted order after
-// the call to NearestSet returns.
-func (t *Tree) NearestSet(k Keeper, q Comparable) {
+// the call to NearestSet returns. NearestSet panics with ErrDimsMismatch if q does not
+// have the dimensionality of the values in the tree.
+func (t *Tree) NearestSet(k Keeper, q Comparable, opts ...SearchOption) {
 	if t.Root == nil {
 		return
 	}
-	t.Root.searchSet(q, k)
+	t.mustMatch(q)
+	t.Root.searchSet(q, k, t.searchConfig(opts))
 	if k.Len() == 1 {
 		return
 	}
@@ -369,25 +681,63 @@ func (t *Tree) NearestSet(k Keeper, q Comparable) {
 	return
 }
 
-func (n *Node) searchSet(q Comparable, k Keeper) {
+// NearestN returns the n nearest values to the query in min sorted order. If the tree holds
+// fewer than n values, all the values in the tree are returned. Values at equal distances
+// from the query are returned in lexical order of their coordinates, and ties at the nth
+// distance are resolved in favour of lexically lesser values, so the result depends only
+// on the set of values held by the tree. Values with identical coordinates are ordered
+// arbitrarily.
+func (t *Tree) NearestN(q Comparable, n int, opts ...SearchOption) []ComparableDist {
+	if n > t.Count {
+		n = t.Count
+	}
+	if n <= 0 || t.Root == nil {
+		return nil
+	}
+	k := NewNKeeper(n)
+	t.NearestSet(k, q, opts...)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+func (n *Node) searchSet(q Comparable, k Keeper, sc *searchConfig) {
 	if n == nil {
 		return
 	}
+	// Instrumentation is guarded as in search.
+	if sc != nil {
+		if sc.exhausted() {
+			sc.prune(n)
+			return
+		}
+		sc.visit(n)
+	}
 
 	c := q.Compare(n.Point, n.Plane)
-	k.Keep(ComparableDist{Comparable: n.Point, Dist: q.Distance(n.Point)})
-	if c <= 0 {
-		n.Left.searchSet(q, k)
-		if c*c <= k.Max().Dist {
-			n.Right.searchSet(q, k)
+	if sc == nil {
+		k.Keep(ComparableDist{Comparable: n.Point, Dist: q.Distance(n.Point)})
+	} else {
+		d := sc.distance(q, n.Point)
+		k.Keep(ComparableDist{Comparable: n.Point, Dist: d})
+		sc.candidate(n, d)
+	}
+	near, far := n.Left, n.Right
+	if c > 0 {
+		near, far = far, near
+	}
+	near.searchSet(q, k, sc)
+	if sc == nil {
+		if planeDist(q, c) <= k.Max().Dist {
+			far.searchSet(q, k, sc)
 		}
-		return
-	}
-	n.Right.searchSet(q, k)
-	if c*c <= k.Max().Dist {
-		n.Left.searchSet(q, k)
+	} else if sc.reach(q, c, k.Max().Dist) {
+		far.searchSet(q, k, sc)
+	} else {
+		sc.prune(far)
 	}
-	return
 }
 
 // An Operation is a function that operates on a Comparable. The bounding volume and tree depth
@@ -397,12 +747,13 @@ type Operation func(Comparable, *Bounding, int) (done bool)
 
 // Do performs fn on all values stored in the tree. A boolean is returned indicating whether the
 // Do traversal was interrupted by an Operation returning true. If fn alters stored values' sort
-// relationships, future tree operation behaviors are undefined.
-func (t *Tree) Do(fn Operation) bool {
+// relationships, future tree operation behaviors are undefined. Values are visited in order
+// unless another Order is specified with WithOrder.
+func (t *Tree) Do(fn Operation, opts ...DoOption) bool {
 	if t.Root == nil {
 		return false
 	}
-	return t.Root.do(fn, 0)
+	return t.Root.doOrder(fn, 0, newDoConfig(opts).order)
 }
 
 func (n *Node) do(fn Operation, depth int) (done bool) {
@@ -425,33 +776,38 @@ func (n *Node) do(fn Operation, depth int) (done bool) {
 // DoBounded performs fn on all values stored in the tree that are within the specified bound.
 // If b is nil, the result is the same as a Do. A boolean is returned indicating whether the
 // DoBounded traversal was interrupted by an Operation returning true. If fn alters stored
-// values' sort relationships future tree operation behaviors are undefined.
-func (t *Tree) DoBounded(fn Operation, b *Bounding) bool {
+// values' sort relationships future tree operation behaviors are undefined. The limits of b
+// are inclusive unless made exclusive with WithOpenLower or WithOpenUpper. Bounded traversals
+// are always performed in order. DoBounded panics with ErrDimsMismatch if the corners of b do
+// not have the dimensionality of the values in the tree.
+func (t *Tree) DoBounded(fn Operation, b *Bounding, opts ...DoOption) bool {
 	if t.Root == nil {
 		return false
 	}
 	if b == nil {
 		return t.Root.do(fn, 0)
 	}
-	return t.Root.doBounded(fn, b, 0)
+	t.mustMatch(b[0])
+	t.mustMatch(b[1])
+	return t.Root.doBounded(fn, b, newDoConfig(opts).contains(b), 0)
 }
 
-func (n *Node) doBounded(fn Operation, b *Bounding, depth int) (done bool) {
+func (n *Node) doBounded(fn Operation, b *Bounding, contains func(Comparable) bool, depth int) (done bool) {
 	lc, hc := b[0].Compare(n.Point, n.Plane), b[1].Compare(n.Point, n.Plane)
-	if lc < 0 && n.Left != nil {
-		done = n.Left.doBounded(fn, b, depth+1)
+	if lc <= 0 && n.Left != nil {
+		done = n.Left.doBounded(fn, b, contains, depth+1)
 		if done {
 			return
 		}
 	}
-	if b.Contains(n.Point) {
+	if contains(n.Point) {
 		done = fn(n.Point, b, depth)
 		if done {
 			return
 		}
 	}
-	if hc > 0 && n.Right != nil {
-		done = n.Right.doBounded(fn, b, depth+1)
+	if hc >= 0 && n.Right != nil {
+		done = n.Right.doBounded(fn, b, contains, depth+1)
 	}
 	return
 }
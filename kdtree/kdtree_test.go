@@ -121,6 +121,53 @@ func (s *S) TestInsert(c *check.C) {
 	}
 }
 
+func (s *S) TestRemove(c *check.C) {
+	for _, bounding := range []bool{false, true} {
+		p := make(Points, 200)
+		for i := range p {
+			p[i] = Point{float64(rand.Intn(10)), float64(rand.Intn(10))}
+		}
+		remain := append(Points(nil), p...)
+		t := New(p, bounding)
+		c.Check(t.Remove(Point{-1, -1}), check.Equals, false)
+		for len(remain) != 0 {
+			r := remain[rand.Intn(len(remain))]
+			c.Assert(t.Remove(r), check.Equals, true, check.Commentf("Remove %v", r))
+			for j, e := range remain {
+				if sameCoords(e, r) {
+					remain = append(remain[:j], remain[j+1:]...)
+					break
+				}
+			}
+			c.Check(t.Len(), check.Equals, len(remain))
+			c.Assert(t.Root.isOrdered(), check.Equals, true, check.Commentf("Remove %v", r))
+			if bounding && t.Root != nil {
+				c.Check(t.Root.Bounding, check.DeepEquals, remain.Bounds(), check.Commentf("Remove %v", r))
+			}
+			if len(remain) != 0 {
+				q := Point{rand.Float64() * 10, rand.Float64() * 10}
+				_, d := t.Nearest(q)
+				_, ed := nearest(q, remain)
+				c.Check(d, check.Equals, ed)
+			}
+		}
+		c.Check(t.Root, check.IsNil)
+		c.Check(t.Remove(Point{0, 0}), check.Equals, false)
+	}
+}
+
+// isOrdered returns whether all points in the left subtree of each node are less than or
+// equal to the node's point in its plane and all those in the right are greater.
+func (n *Node) isOrdered() bool {
+	if n == nil {
+		return true
+	}
+	ok := true
+	n.Left.walk(func(l *Node) { ok = ok && l.Point.Compare(n.Point, n.Plane) <= 0 })
+	n.Right.walk(func(r *Node) { ok = ok && r.Point.Compare(n.Point, n.Plane) > 0 })
+	return ok && n.Left.isOrdered() && n.Right.isOrdered()
+}
+
 type compFn func(float64) bool
 
 func left(v float64) bool  { return v <= 0 }
@@ -203,6 +250,49 @@ func nearest(q Point, p Points) (Point, float64) {
 	return p[r], min
 }
 
+func (s *S) TestBounds(c *check.C) {
+	c.Check((&Tree{}).Bounds(), check.IsNil)
+	for i, bounding := range []bool{false, true} {
+		t := New(append(Points(nil), wpData...), bounding)
+		c.Check(t.Bounds(), check.DeepEquals, wpBound, check.Commentf("Test %d", i))
+	}
+	t := New(append(Points(nil), wpData...), false)
+	t.Insert(Point{0, 10}, false)
+	c.Check(t.Bounds(), check.DeepEquals, &Bounding{Point{0, 1}, Point{9, 10}})
+	c.Check(New(append(nbPoints(nil), nbWpData...), false).Bounds(), check.IsNil)
+}
+
+func (s *S) TestDims(c *check.C) {
+	t := &Tree{}
+	c.Check(t.Dims(), check.Equals, 0)
+	t.Insert(Point{1, 2}, false)
+	c.Check(t.Dims(), check.Equals, 2)
+	c.Check(func() { t.Insert(Point{1, 2, 3}, false) }, check.PanicMatches, ErrDimsMismatch.Error())
+	c.Check(t.Len(), check.Equals, 1)
+	c.Check(func() { t.Nearest(Point{1}) }, check.PanicMatches, ErrDimsMismatch.Error())
+	c.Check(func() { t.NearestN(Point{1, 2, 3}, 1) }, check.PanicMatches, ErrDimsMismatch.Error())
+	c.Check(func() { t.InRange(Point{1, 2, 3}, 1) }, check.PanicMatches, ErrDimsMismatch.Error())
+	c.Check(func() {
+		t.DoBounded(func(Comparable, *Bounding, int) bool { return false }, &Bounding{Point{0}, Point{1}})
+	}, check.PanicMatches, ErrDimsMismatch.Error())
+	c.Check(t.Remove(Point{1, 2, 3}), check.Equals, false)
+	c.Check(t.Remove(Point{1, 2}), check.Equals, true)
+	c.Check(t.Dims(), check.Equals, 0)
+}
+
+func (s *S) TestPoints(c *check.C) {
+	c.Check((&Tree{}).Points(), check.HasLen, 0)
+	t := New(append(Points(nil), wpData...), false)
+	var want []Comparable
+	t.Do(func(p Comparable, _ *Bounding, _ int) bool {
+		want = append(want, p)
+		return false
+	})
+	c.Check(t.Points(), check.DeepEquals, want)
+	dst := []Comparable{Point{0, 0}}
+	c.Check(t.AppendPoints(dst), check.DeepEquals, append(dst, want...))
+}
+
 func (s *S) TestNearestRandom(c *check.C) {
 	const (
 		min = 0.
@@ -331,6 +421,38 @@ func (s *S) TestNearestSetN(c *check.C) {
 	}
 }
 
+func (s *S) TestNearestN(c *check.C) {
+	t := New(wpData, false)
+	for k := 0; k <= len(wpData)+2; k++ {
+		for i, q := range []Point{{4, 6}, {7, 5}, {8, 7}, {6, -5}, {1e5, 1e5}} {
+			var ed []float64
+			for _, p := range wpData {
+				ed = append(ed, q.Distance(p))
+			}
+			sort.Float64s(ed)
+			if k < len(ed) {
+				ed = ed[:k]
+			}
+
+			got := t.NearestN(q, k)
+			c.Check(len(got), check.Equals, len(ed), check.Commentf("Test k=%d %d", k, i))
+			for j, p := range got {
+				c.Check(p.Comparable, check.NotNil)
+				c.Check(p.Dist, check.Equals, ed[j], check.Commentf("Test k=%d %d: result %d", k, i, j))
+			}
+		}
+	}
+
+	c.Check((&Tree{}).NearestN(Point{0, 0}, 10), check.HasLen, 0)
+
+	// Huge n must not cause a huge allocation.
+	nk := NewNKeeper(1 << 60)
+	c.Check(cap(nk.Heap) <= nKeeperCap, check.Equals, true)
+	t.NearestSet(nk, Point{4, 6})
+	c.Check(len(nk.Heap), check.Equals, len(wpData)+1)
+	c.Check(t.NearestN(Point{4, 6}, 1<<60), check.HasLen, len(wpData))
+}
+
 func (s *S) TestNearestSetDist(c *check.C) {
 	t := New(wpData, false)
 	for i, q := range []Point{
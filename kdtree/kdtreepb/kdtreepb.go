@@ -0,0 +1,197 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdtreepb provides a Protocol Buffers representation of k-d trees.
+//
+// The message definitions are given in kdtree.proto. The types in this package
+// correspond to those messages and encode to and decode from the standard wire
+// format, so serialised trees may be exchanged with programs generated from
+// kdtree.proto in other languages.
+package kdtreepb
+
+import (
+	"errors"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// Tree corresponds to the Tree message.
+type Tree struct {
+	Count uint64
+	Nodes []Node
+}
+
+// Node corresponds to the Node message. Left and Right are one-based indices into
+// the Nodes field of the containing Tree, with zero indicating no child.
+type Node struct {
+	Point       []float64
+	Plane       uint32
+	Left, Right uint32
+	Bounds      *Bounding
+}
+
+// Bounding corresponds to the Bounding message.
+type Bounding struct {
+	Min, Max []float64
+}
+
+// FromTree returns the Protocol Buffers representation of t. The coordinates of each
+// stored point are obtained by calling coords. If coords is nil, the stored points
+// and bounding volumes must be kdtree.Point values.
+func FromTree(t *kdtree.Tree, coords func(kdtree.Comparable) []float64) *Tree {
+	if coords == nil {
+		coords = func(c kdtree.Comparable) []float64 { return c.(kdtree.Point) }
+	}
+	pt := &Tree{Count: uint64(t.Count)}
+	pt.add(t.Root, coords)
+	return pt
+}
+
+func (t *Tree) add(n *kdtree.Node, coords func(kdtree.Comparable) []float64) uint32 {
+	if n == nil {
+		return 0
+	}
+	t.Nodes = append(t.Nodes, Node{Point: coords(n.Point), Plane: uint32(n.Plane)})
+	i := len(t.Nodes)
+	if n.Bounding != nil {
+		t.Nodes[i-1].Bounds = &Bounding{Min: coords(n.Bounding[0]), Max: coords(n.Bounding[1])}
+	}
+	l := t.add(n.Left, coords)
+	r := t.add(n.Right, coords)
+	t.Nodes[i-1].Left, t.Nodes[i-1].Right = l, r
+	return uint32(i)
+}
+
+// ToTree returns the k-d tree represented by t. Each stored point is constructed from its
+// coordinates by calling point. If point is nil, kdtree.Point values are constructed.
+func (t *Tree) ToTree(point func([]float64) kdtree.Comparable) (*kdtree.Tree, error) {
+	if point == nil {
+		point = func(c []float64) kdtree.Comparable { return kdtree.Point(c) }
+	}
+	nodes := make([]kdtree.Node, len(t.Nodes))
+	for i, n := range t.Nodes {
+		if !validChild(n.Left, i, len(nodes)) || !validChild(n.Right, i, len(nodes)) {
+			return nil, errors.New("kdtreepb: invalid node index")
+		}
+		nodes[i] = kdtree.Node{Point: point(n.Point), Plane: kdtree.Dim(n.Plane)}
+		if n.Bounds != nil {
+			nodes[i].Bounding = &kdtree.Bounding{point(n.Bounds.Min), point(n.Bounds.Max)}
+		}
+		if n.Left != 0 {
+			nodes[i].Left = &nodes[n.Left-1]
+		}
+		if n.Right != 0 {
+			nodes[i].Right = &nodes[n.Right-1]
+		}
+	}
+	kt := &kdtree.Tree{Count: int(t.Count)}
+	if len(nodes) != 0 {
+		kt.Root = &nodes[0]
+	}
+	return kt, nil
+}
+
+// validChild returns whether the one-based index c is a valid pre-order child of the
+// node at zero-based index i in a list of n nodes.
+func validChild(c uint32, i, n int) bool {
+	return c == 0 || (i < int(c)-1 && int(c) <= n)
+}
+
+// Marshal returns the wire format encoding of t.
+func (t *Tree) Marshal() []byte {
+	b := appendUint(nil, 1, t.Count)
+	var buf []byte
+	for _, n := range t.Nodes {
+		buf = n.appendTo(buf[:0])
+		b = appendBytes(b, 2, buf)
+	}
+	return b
+}
+
+func (n *Node) appendTo(b []byte) []byte {
+	b = appendPacked(b, 1, n.Point)
+	b = appendUint(b, 2, uint64(n.Plane))
+	b = appendUint(b, 3, uint64(n.Left))
+	b = appendUint(b, 4, uint64(n.Right))
+	if n.Bounds != nil {
+		var bb []byte
+		bb = appendPacked(bb, 1, n.Bounds.Min)
+		bb = appendPacked(bb, 2, n.Bounds.Max)
+		b = appendBytes(b, 5, bb)
+	}
+	return b
+}
+
+// Unmarshal decodes the wire format encoding in b into t. Unknown fields are ignored.
+func (t *Tree) Unmarshal(b []byte) error {
+	*t = Tree{}
+	for len(b) != 0 {
+		f, rest, err := next(b)
+		if err != nil {
+			return err
+		}
+		b = rest
+		switch {
+		case f.num == 1 && f.wire == wireVarint:
+			t.Count = f.varint
+		case f.num == 2 && f.wire == wireBytes:
+			var n Node
+			err = n.unmarshal(f.bytes)
+			if err != nil {
+				return err
+			}
+			t.Nodes = append(t.Nodes, n)
+		}
+	}
+	return nil
+}
+
+func (n *Node) unmarshal(b []byte) error {
+	for len(b) != 0 {
+		f, rest, err := next(b)
+		if err != nil {
+			return err
+		}
+		b = rest
+		switch {
+		case f.num == 1:
+			n.Point, err = f.doubles(n.Point)
+		case f.num == 2 && f.wire == wireVarint:
+			n.Plane = uint32(f.varint)
+		case f.num == 3 && f.wire == wireVarint:
+			n.Left = uint32(f.varint)
+		case f.num == 4 && f.wire == wireVarint:
+			n.Right = uint32(f.varint)
+		case f.num == 5 && f.wire == wireBytes:
+			if n.Bounds == nil {
+				n.Bounds = &Bounding{}
+			}
+			err = n.Bounds.unmarshal(f.bytes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bnd *Bounding) unmarshal(b []byte) error {
+	for len(b) != 0 {
+		f, rest, err := next(b)
+		if err != nil {
+			return err
+		}
+		b = rest
+		switch f.num {
+		case 1:
+			bnd.Min, err = f.doubles(bnd.Min)
+		case 2:
+			bnd.Max, err = f.doubles(bnd.Max)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtreepb
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestRoundTrip(c *check.C) {
+	p := make(kdtree.Points, 1000)
+	for i := range p {
+		p[i] = kdtree.Point{rand.Float64(), rand.Float64(), rand.Float64()}
+	}
+	for i, t := range []*kdtree.Tree{
+		{},
+		kdtree.New(kdtree.Points{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}, false),
+		kdtree.New(kdtree.Points{{2, 3}, {5, 4}, {9, 6}, {4, 7}, {8, 1}, {7, 2}}, true),
+		kdtree.New(p, true),
+	} {
+		b := FromTree(t, nil).Marshal()
+		var pt Tree
+		c.Assert(pt.Unmarshal(b), check.IsNil, check.Commentf("Test %d", i))
+		got, err := pt.ToTree(nil)
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Count, check.Equals, t.Count)
+		c.Check(got.Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+	}
+}
+
+func (s *S) TestWireFormat(c *check.C) {
+	t := &Tree{Count: 1, Nodes: []Node{{Point: []float64{1}}}}
+	want := []byte{
+		0x08, 0x01, // count: 1
+		0x12, 0x0a, // nodes: 10 bytes
+		0x0a, 0x08, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // point: [1] packed
+	}
+	c.Check(t.Marshal(), check.DeepEquals, want)
+
+	// Unpacked repeated doubles and unknown fields must be accepted.
+	unpacked := []byte{
+		0x08, 0x01, // count: 1
+		0x12, 0x0c, // nodes: 12 bytes
+		0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // point: 1 unpacked
+		0x30, 0x07, // unknown field 6: 7
+		0x10, 0x00, // plane: 0
+	}
+	unpacked[3] = byte(len(unpacked) - 4)
+	var got Tree
+	c.Assert(got.Unmarshal(unpacked), check.IsNil)
+	c.Check(got, check.DeepEquals, *t)
+
+	c.Check(got.Unmarshal(want[:len(want)-1]), check.NotNil)
+}
+
+func (s *S) TestInvalidIndex(c *check.C) {
+	t := &Tree{Count: 2, Nodes: []Node{{Point: []float64{0}, Left: 2}, {Point: []float64{0}, Left: 1}}}
+	_, err := t.ToTree(nil)
+	c.Check(err, check.NotNil)
+}
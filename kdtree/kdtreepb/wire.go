@@ -0,0 +1,121 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtreepb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("kdtreepb: truncated message")
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, v)
+}
+
+func appendPacked(b []byte, field int, v []float64) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(8*len(v)))
+	var buf [8]byte
+	for _, f := range v {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		b = append(b, buf[:]...)
+	}
+	return b
+}
+
+// field is a decoded Protocol Buffers field.
+type field struct {
+	num, wire int
+	varint    uint64
+	bytes     []byte
+}
+
+// next decodes the field at the start of b and returns it with the remaining bytes.
+func next(b []byte) (field, []byte, error) {
+	var f field
+	tag, n := binary.Uvarint(b)
+	if n <= 0 {
+		return f, nil, errTruncated
+	}
+	b = b[n:]
+	f.num, f.wire = int(tag>>3), int(tag&7)
+	switch f.wire {
+	case wireVarint:
+		f.varint, n = binary.Uvarint(b)
+		if n <= 0 {
+			return f, nil, errTruncated
+		}
+		b = b[n:]
+	case wireFixed64:
+		if len(b) < 8 {
+			return f, nil, errTruncated
+		}
+		f.varint, b = binary.LittleEndian.Uint64(b), b[8:]
+	case wireFixed32:
+		if len(b) < 4 {
+			return f, nil, errTruncated
+		}
+		f.varint, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+	case wireBytes:
+		l, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < l {
+			return f, nil, errTruncated
+		}
+		f.bytes, b = b[n:n+int(l)], b[n+int(l):]
+	default:
+		return f, nil, errors.New("kdtreepb: unsupported wire type")
+	}
+	return f, b, nil
+}
+
+// doubles appends the repeated double values held in f to dst. Both packed and unpacked
+// encodings are accepted.
+func (f field) doubles(dst []float64) ([]float64, error) {
+	switch f.wire {
+	case wireFixed64:
+		return append(dst, math.Float64frombits(f.varint)), nil
+	case wireBytes:
+		if len(f.bytes)%8 != 0 {
+			return dst, errTruncated
+		}
+		for b := f.bytes; len(b) != 0; b = b[8:] {
+			dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}
+		return dst, nil
+	}
+	return dst, errors.New("kdtreepb: invalid wire type for double")
+}
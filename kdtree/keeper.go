@@ -0,0 +1,42 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// FilterKeeper returns a Keeper that passes to inner only the ComparableDists whose
+// Comparable is accepted by accept. The values retained by the search are held by inner.
+// For example, the k nearest open restaurants may be found with
+//
+//	k := kdtree.NewNKeeper(n)
+//	t.NearestSet(kdtree.FilterKeeper(k, isOpen), q)
+func FilterKeeper(inner Keeper, accept func(Comparable) bool) Keeper {
+	return filterKeeper{Keeper: inner, accept: accept}
+}
+
+type filterKeeper struct {
+	Keeper
+	accept func(Comparable) bool
+}
+
+func (k filterKeeper) Keep(c ComparableDist) {
+	if k.accept(c.Comparable) {
+		k.Keeper.Keep(c)
+	}
+}
+
+// MapKeeper returns a Keeper that passes the result of applying fn to each ComparableDist
+// to inner. fn may replace the Comparable, for example to retain a payload, or increase the
+// distance, for example to apply a penalty, but must not return a nil Comparable or a
+// distance less than the distance it is given; doing so may cause nearer values to be
+// missed by the search.
+func MapKeeper(inner Keeper, fn func(ComparableDist) ComparableDist) Keeper {
+	return mapKeeper{Keeper: inner, fn: fn}
+}
+
+type mapKeeper struct {
+	Keeper
+	fn func(ComparableDist) ComparableDist
+}
+
+func (k mapKeeper) Keep(c ComparableDist) { k.Keeper.Keep(k.fn(c)) }
@@ -0,0 +1,80 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFilterKeeper(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	var data Data
+	for i := 0; i < 1000; i++ {
+		data = append(data, Datum{Point: Point{rnd.Float64(), rnd.Float64()}, Value: i%3 == 0})
+	}
+	open := func(c Comparable) bool { return c.(Datum).Value.(bool) }
+	var want []float64
+	q := Point{0.5, 0.5}
+	for _, d := range data {
+		if open(d) {
+			want = append(want, d.Distance(q))
+		}
+	}
+	sort.Float64s(want)
+
+	t := New(append(Data(nil), data...), false)
+	k := NewNKeeper(10)
+	t.NearestSet(FilterKeeper(k, open), q)
+	c.Assert(k.Heap, check.HasLen, 10)
+	for i, cd := range k.Heap {
+		c.Check(open(cd.Comparable), check.Equals, true)
+		c.Check(cd.Dist, check.Equals, want[i])
+	}
+
+	dk := NewDistKeeper(0.01)
+	t.NearestSet(FilterKeeper(dk, open), q)
+	h := dk.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	var n int
+	for _, d := range want {
+		if d <= 0.01 {
+			n++
+		}
+	}
+	c.Check(h, check.HasLen, n)
+}
+
+func (s *S) TestMapKeeper(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	type labeled struct {
+		Point
+		label string
+	}
+
+	// Penalize points with x > 5 and label the results.
+	k := NewNKeeper(3)
+	t.NearestSet(MapKeeper(k, func(cd ComparableDist) ComparableDist {
+		p := cd.Comparable.(Point)
+		if p[0] > 5 {
+			cd.Dist += 100
+		}
+		cd.Comparable = labeled{Point: p, label: "x"}
+		return cd
+	}), Point{7, 2})
+	c.Assert(k.Heap, check.HasLen, 3)
+	c.Check(sort.IsSorted(sort.Reverse(k)), check.Equals, true)
+	var got []Point
+	for _, cd := range k.Heap {
+		l := cd.Comparable.(labeled)
+		c.Check(l.label, check.Equals, "x")
+		got = append(got, l.Point)
+	}
+	c.Check(got, check.DeepEquals, []Point{{5, 4}, {2, 3}, {4, 7}})
+}
@@ -0,0 +1,144 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package knn provides k-nearest neighbour classification and regression over k-d trees.
+//
+// Labels and values are obtained from the values held by a tree. Values that implement
+// Labeled or Valued provide their own labels or values, and the Value field of kdtree.Datum
+// values is used as the label or value of a Datum. Other sources may be provided with
+// WithLabel and WithValue.
+package knn
+
+import (
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// Labeled is a Comparable with a class label.
+type Labeled interface {
+	kdtree.Comparable
+	Label() interface{}
+}
+
+// An Option modifies the behaviour of a classification.
+type Option func(*config)
+
+type config struct {
+	kernel Kernel
+	label  func(kdtree.Comparable) interface{}
+	value  func(kdtree.Comparable) float64
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{kernel: Uniform, label: label, value: value}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// Weighted specifies that the contribution of each neighbour is weighted by the inverse of
+// its distance from the query. It is equivalent to WithKernel(InverseDistance).
+func Weighted() Option { return WithKernel(InverseDistance) }
+
+// WithKernel specifies the kernel used to weight the contribution of each neighbour. The
+// default kernel is Uniform.
+func WithKernel(k Kernel) Option {
+	return func(c *config) { c.kernel = k }
+}
+
+// WithLabel specifies the function used to obtain the label of a value.
+func WithLabel(fn func(kdtree.Comparable) interface{}) Option {
+	return func(c *config) { c.label = fn }
+}
+
+// label returns the label of c, panicking if c has no label.
+func label(c kdtree.Comparable) interface{} {
+	switch c := c.(type) {
+	case Labeled:
+		return c.Label()
+	case kdtree.Datum:
+		return c.Value
+	case *kdtree.Datum:
+		return c.Value
+	}
+	panic("knn: value has no label")
+}
+
+// A Vote is the total weight of the votes for a label.
+type Vote struct {
+	Label  interface{}
+	Weight float64
+}
+
+// neighbours returns the k nearest neighbours of q in t and their kernel weights. If the
+// kernel weight of a neighbour is infinite, only the neighbours at that distance are
+// returned, each with a weight of one.
+func neighbours(t *kdtree.Tree, q kdtree.Comparable, k int, kernel Kernel) ([]kdtree.ComparableDist, []float64) {
+	nn := t.NearestN(q, k)
+	if len(nn) == 0 {
+		return nil, nil
+	}
+	w := make([]float64, len(nn))
+	for i, cd := range nn {
+		w[i] = kernel(cd.Dist)
+		if math.IsInf(w[i], 1) {
+			n := i
+			for n < len(nn) && nn[n].Dist == cd.Dist {
+				w[n] = 1
+				n++
+			}
+			return nn[i:n], w[i:n]
+		}
+	}
+	return nn, w
+}
+
+// Votes returns the votes of the k nearest neighbours of q in t for each of their labels,
+// in descending order of weight. Labels with equal weight are ordered by the distance of
+// their nearest voting neighbour. Labels must be comparable with ==. The weight of each vote
+// is given by the kernel, so by default each neighbour contributes a weight of one. If the
+// kernel weight of the nearest neighbours is infinite, as for InverseDistance with exact
+// matches, only they vote.
+func Votes(t *kdtree.Tree, q kdtree.Comparable, k int, opts ...Option) []Vote {
+	cfg := newConfig(opts)
+	nn, weights := neighbours(t, q, k, cfg.kernel)
+	if len(nn) == 0 {
+		return nil
+	}
+	var (
+		votes []Vote
+		index = make(map[interface{}]int)
+	)
+	for j, cd := range nn {
+		w := weights[j]
+		l := cfg.label(cd.Comparable)
+		i, ok := index[l]
+		if !ok {
+			i = len(votes)
+			index[l] = i
+			votes = append(votes, Vote{Label: l})
+		}
+		votes[i].Weight += w
+	}
+	// Labels are first seen in order of distance, so a stable
+	// insertion sort by weight orders ties by distance.
+	for i := 1; i < len(votes); i++ {
+		for j := i; j > 0 && votes[j].Weight > votes[j-1].Weight; j-- {
+			votes[j], votes[j-1] = votes[j-1], votes[j]
+		}
+	}
+	return votes
+}
+
+// Classify returns the label with the greatest vote among the k nearest neighbours of q in
+// t, as described for Votes, and whether t holds any values.
+func Classify(t *kdtree.Tree, q kdtree.Comparable, k int, opts ...Option) (interface{}, bool) {
+	v := Votes(t, q, k, opts...)
+	if len(v) == 0 {
+		return nil, false
+	}
+	return v[0].Label, true
+}
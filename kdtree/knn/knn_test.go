@@ -0,0 +1,128 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package knn
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type flower struct {
+	kdtree.Point
+	species string
+}
+
+func (f flower) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return f.Point[d] - c.(flower).Point[d]
+}
+func (f flower) Distance(c kdtree.Comparable) float64 { return f.Point.Distance(c.(flower).Point) }
+func (f flower) Label() interface{}                   { return f.species }
+
+func (s *S) TestClassify(c *check.C) {
+	t := kdtree.New(kdtree.Data{
+		{Point: kdtree.Point{0, 0}, Value: "a"},
+		{Point: kdtree.Point{1, 0}, Value: "a"},
+		{Point: kdtree.Point{0, 1}, Value: "a"},
+		{Point: kdtree.Point{5, 5}, Value: "b"},
+		{Point: kdtree.Point{6, 5}, Value: "b"},
+	}, false)
+	for _, test := range []struct {
+		q     kdtree.Point
+		k     int
+		opts  []Option
+		label interface{}
+	}{
+		{q: kdtree.Point{0.5, 0.5}, k: 3, label: "a"},
+		{q: kdtree.Point{5, 4}, k: 3, label: "b"},
+		{q: kdtree.Point{5, 4}, k: 5, label: "a"},
+		{q: kdtree.Point{5, 4}, k: 5, opts: []Option{Weighted()}, label: "b"},
+		{q: kdtree.Point{0, 0}, k: 5, opts: []Option{Weighted()}, label: "a"},
+		{q: kdtree.Point{0, 0}, k: 5, opts: []Option{WithLabel(func(c kdtree.Comparable) interface{} {
+			return c.(kdtree.Datum).Value == "b"
+		})}, label: false},
+	} {
+		l, ok := Classify(t, test.q, test.k, test.opts...)
+		c.Check(ok, check.Equals, true)
+		c.Check(l, check.Equals, test.label, check.Commentf("%v k=%d", test.q, test.k))
+	}
+
+	v := Votes(t, kdtree.Point{0, 0}, 5, Weighted())
+	c.Check(v, check.DeepEquals, []Vote{{Label: "a", Weight: 1}})
+
+	// Equal votes are resolved by distance.
+	v = Votes(t, kdtree.Point{3, 3}, 4)
+	c.Check(v, check.DeepEquals, []Vote{{Label: "b", Weight: 2}, {Label: "a", Weight: 2}})
+
+	_, ok := Classify(&kdtree.Tree{}, kdtree.Point{0, 0}, 3)
+	c.Check(ok, check.Equals, false)
+
+	c.Check(func() { Classify(kdtree.New(kdtree.Points{{0, 0}}, false), kdtree.Point{0, 0}, 1) },
+		check.PanicMatches, "knn: value has no label")
+}
+
+func (s *S) TestClassifyLabeled(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	var (
+		p      labeledPoints
+		test   []flower
+		labels = []string{"setosa", "versicolor", "virginica"}
+	)
+	for i := 0; i < 600; i++ {
+		cls := rnd.Intn(3)
+		f := flower{Point: kdtree.Point{float64(cls)*3 + rnd.NormFloat64(), rnd.NormFloat64()}, species: labels[cls]}
+		if i%5 == 0 {
+			test = append(test, f)
+		} else {
+			p = append(p, f)
+		}
+	}
+	t := kdtree.New(p, false)
+	var correct int
+	for _, f := range test {
+		l, _ := Classify(t, f, 7)
+		if l == f.species {
+			correct++
+		}
+	}
+	c.Check(float64(correct)/float64(len(test)) > 0.8, check.Equals, true)
+}
+
+// labeledPoints is a collection of flowers satisfying kdtree.Interface.
+type labeledPoints []flower
+
+func (p labeledPoints) Index(i int) kdtree.Comparable         { return p[i] }
+func (p labeledPoints) Len() int                              { return len(p) }
+func (p labeledPoints) Slice(start, end int) kdtree.Interface { return p[start:end] }
+func (p labeledPoints) Pivot(d kdtree.Dim) int {
+	return planes{labeledPoints: p, Dim: d}.Pivot()
+}
+
+type planes struct {
+	kdtree.Dim
+	labeledPoints
+}
+
+func (p planes) Less(i, j int) bool {
+	return p.labeledPoints[i].Point[p.Dim] < p.labeledPoints[j].Point[p.Dim]
+}
+func (p planes) Pivot() int { return kdtree.Partition(p, kdtree.MedianOfMedians(p)) }
+func (p planes) Slice(start, end int) kdtree.SortSlicer {
+	p.labeledPoints = p.labeledPoints[start:end]
+	return p
+}
+func (p planes) Swap(i, j int) {
+	p.labeledPoints[i], p.labeledPoints[j] = p.labeledPoints[j], p.labeledPoints[i]
+}
@@ -0,0 +1,85 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package knn
+
+import (
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// Valued is a Comparable with a numeric value.
+type Valued interface {
+	kdtree.Comparable
+	Value() float64
+}
+
+// WithValue specifies the function used to obtain the numeric value of a value.
+func WithValue(fn func(kdtree.Comparable) float64) Option {
+	return func(c *config) { c.value = fn }
+}
+
+// value returns the numeric value of c, panicking if c has no value.
+func value(c kdtree.Comparable) float64 {
+	switch c := c.(type) {
+	case Valued:
+		return c.Value()
+	case kdtree.Datum:
+		if v, ok := c.Value.(float64); ok {
+			return v
+		}
+	case *kdtree.Datum:
+		if v, ok := c.Value.(float64); ok {
+			return v
+		}
+	}
+	panic("knn: value has no numeric value")
+}
+
+// A Kernel returns the weight of a neighbour at the given distance from a query, as
+// measured by the Distance method of the values. For kdtree.Point values, the distance
+// is the squared Euclidean distance.
+type Kernel func(dist float64) float64
+
+// Uniform is a Kernel that weights all neighbours equally.
+func Uniform(float64) float64 { return 1 }
+
+// InverseDistance is a Kernel that weights neighbours by the inverse of their distance.
+func InverseDistance(dist float64) float64 { return 1 / dist }
+
+// Gaussian returns a Kernel that weights neighbours at squared Euclidean distance d by
+// exp(-d/(2σ²)).
+func Gaussian(sigma float64) Kernel {
+	s := 2 * sigma * sigma
+	return func(dist float64) float64 { return math.Exp(-dist / s) }
+}
+
+// Epanechnikov returns a Kernel that weights neighbours at squared Euclidean distance d
+// by 1-d/h², and neighbours further than h by zero.
+func Epanechnikov(h float64) Kernel {
+	h2 := h * h
+	return func(dist float64) float64 { return math.Max(0, 1-dist/h2) }
+}
+
+// Regress returns the kernel weighted mean of the numeric values of the k nearest
+// neighbours of q in t, and whether any neighbour had a non-zero weight. If the kernel
+// weight of the nearest neighbours is infinite, as for InverseDistance with exact matches,
+// the unweighted mean of their values is returned.
+func Regress(t *kdtree.Tree, q kdtree.Comparable, k int, opts ...Option) (float64, bool) {
+	cfg := newConfig(opts)
+	nn, weights := neighbours(t, q, k, cfg.kernel)
+	var sum, norm float64
+	for i, cd := range nn {
+		if weights[i] == 0 {
+			continue
+		}
+		sum += weights[i] * cfg.value(cd.Comparable)
+		norm += weights[i]
+	}
+	if norm == 0 {
+		return 0, false
+	}
+	return sum / norm, true
+}
@@ -0,0 +1,62 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package knn
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRegress(c *check.C) {
+	t := kdtree.New(kdtree.Data{
+		{Point: kdtree.Point{0}, Value: 0.},
+		{Point: kdtree.Point{1}, Value: 10.},
+		{Point: kdtree.Point{2}, Value: 20.},
+		{Point: kdtree.Point{10}, Value: 100.},
+	}, false)
+	for _, test := range []struct {
+		q    kdtree.Point
+		k    int
+		opts []Option
+		want float64
+		ok   bool
+	}{
+		{q: kdtree.Point{1}, k: 3, want: 10, ok: true},
+		{q: kdtree.Point{1}, k: 4, want: 32.5, ok: true},
+		{q: kdtree.Point{1}, k: 4, opts: []Option{Weighted()}, want: 10, ok: true},
+		{q: kdtree.Point{0.5}, k: 2, opts: []Option{Weighted()}, want: 5, ok: true},
+		{q: kdtree.Point{0}, k: 4, opts: []Option{WithKernel(InverseDistance)}, want: 0, ok: true},
+		{q: kdtree.Point{0}, k: 2, opts: []Option{WithKernel(Epanechnikov(2))}, want: 10 * 0.75 / 1.75, ok: true},
+		{q: kdtree.Point{50}, k: 2, opts: []Option{WithKernel(Epanechnikov(2))}, ok: false},
+		{q: kdtree.Point{1}, k: 3, opts: []Option{WithValue(func(c kdtree.Comparable) float64 { return 1 })}, want: 1, ok: true},
+	} {
+		got, ok := Regress(t, test.q, test.k, test.opts...)
+		c.Check(ok, check.Equals, test.ok)
+		c.Check(math.Abs(got-test.want) < 1e-12, check.Equals, true, check.Commentf("%v k=%d got=%v", test.q, test.k, got))
+	}
+	_, ok := Regress(&kdtree.Tree{}, kdtree.Point{0}, 3)
+	c.Check(ok, check.Equals, false)
+	c.Check(func() { Regress(kdtree.New(kdtree.Points{{0}}, false), kdtree.Point{0}, 1) },
+		check.PanicMatches, "knn: value has no numeric value")
+
+	// Gaussian weighting recovers a smooth function.
+	rnd := rand.New(rand.NewSource(1))
+	var data kdtree.Data
+	for i := 0; i < 2000; i++ {
+		x, y := rnd.Float64()*10, rnd.Float64()*10
+		data = append(data, kdtree.Datum{Point: kdtree.Point{x, y}, Value: math.Sin(x) + y})
+	}
+	t = kdtree.New(data, false)
+	for i := 0; i < 20; i++ {
+		x, y := 1+rnd.Float64()*8, 1+rnd.Float64()*8
+		got, ok := Regress(t, kdtree.Point{x, y}, 10, WithKernel(Gaussian(0.2)))
+		c.Check(ok, check.Equals, true)
+		c.Check(math.Abs(got-(math.Sin(x)+y)) < 0.2, check.Equals, true)
+	}
+}
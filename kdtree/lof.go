@@ -0,0 +1,103 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// LOF returns the local outlier factor of each value stored in t, in the order returned by
+// Points, using neighbourhoods of the k nearest other values. Distances are measured by the
+// values' Distance method. Scores near one indicate a value lying within a region of similar
+// density to its neighbours and scores substantially greater than one indicate an outlier.
+// Neighbourhoods hold exactly k values, with ties broken as described for NearestN. If t
+// holds no more than k values, k is reduced to one less than the number of values.
+//
+// Values with duplicates numbering at least k have an infinite local reachability density;
+// a value is scored one when its own density and that of all its neighbours is infinite.
+func LOF(t *Tree, k int) []float64 {
+	values := t.Points()
+	if k <= 0 || len(values) == 0 {
+		return nil
+	}
+	if k >= len(values) {
+		k = len(values) - 1
+	}
+	scores := make([]float64, len(values))
+	if k == 0 {
+		for i := range scores {
+			scores[i] = 1
+		}
+		return scores
+	}
+
+	// Neighbours are identified by searching a tree of the values
+	// labelled with their position in values.
+	items := make(comparables, len(values))
+	for i, v := range values {
+		items[i] = indexed{Comparable: v, i: i}
+	}
+	it := New(items, false)
+
+	neighbours := make([][]ComparableDist, len(values))
+	kdist := make([]float64, len(values))
+	for i, v := range values {
+		nn := it.NearestN(indexed{Comparable: v, i: i}, k+1)
+		self := len(nn) - 1
+		for j, nd := range nn {
+			if nd.Comparable.(indexed).i == i {
+				self = j
+				break
+			}
+		}
+		nn = append(nn[:self], nn[self+1:]...)
+		neighbours[i] = nn
+		kdist[i] = nn[len(nn)-1].Dist
+	}
+
+	lrd := make([]float64, len(values))
+	for i, nn := range neighbours {
+		var sum float64
+		for _, nd := range nn {
+			sum += math.Max(kdist[nd.Comparable.(indexed).i], nd.Dist)
+		}
+		lrd[i] = float64(len(nn)) / sum
+	}
+
+	for i, nn := range neighbours {
+		var sum float64
+		for _, nd := range nn {
+			sum += densityRatio(lrd[nd.Comparable.(indexed).i], lrd[i])
+		}
+		scores[i] = sum / float64(len(nn))
+	}
+	return scores
+}
+
+// densityRatio returns a/b, taking the ratio of two infinite densities to be one.
+func densityRatio(a, b float64) float64 {
+	if math.IsInf(a, 1) && math.IsInf(b, 1) {
+		return 1
+	}
+	return a / b
+}
+
+// indexed is a Comparable labelled with an index.
+type indexed struct {
+	Comparable
+	i int
+}
+
+func (v indexed) Compare(c Comparable, d Dim) float64 {
+	if w, ok := c.(indexed); ok {
+		c = w.Comparable
+	}
+	return v.Comparable.Compare(c, d)
+}
+
+func (v indexed) Distance(c Comparable) float64 {
+	if w, ok := c.(indexed); ok {
+		c = w.Comparable
+	}
+	return v.Comparable.Distance(c)
+}
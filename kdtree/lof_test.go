@@ -0,0 +1,59 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestLOF(c *check.C) {
+	var p Points
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			p = append(p, Point{float64(x), float64(y)})
+		}
+	}
+	p = append(p, Point{20, 20})
+	t := New(p, false)
+	values := t.Points()
+	scores := LOF(t, 4)
+	c.Assert(scores, check.HasLen, len(values))
+
+	worst := 0
+	for i, sc := range scores {
+		if sc > scores[worst] {
+			worst = i
+		}
+	}
+	c.Check(values[worst], check.DeepEquals, Point{20, 20})
+	c.Check(scores[worst] > 10, check.Equals, true)
+	for i, sc := range scores {
+		if i != worst {
+			c.Check(sc < 2, check.Equals, true, check.Commentf("value %v scored %v", values[i], sc))
+		}
+	}
+
+	// Uniformly spaced values all score one.
+	t = New(Points{{0}, {1}, {2}, {3}}, false)
+	for _, sc := range LOF(t, 1) {
+		c.Check(sc, check.Equals, 1.)
+	}
+
+	// Duplicates have infinite density.
+	t = New(Points{{0}, {0}, {0}, {5}}, false)
+	for i, sc := range LOF(t, 2) {
+		if t.Points()[i].(Point)[0] == 0 {
+			c.Check(sc, check.Equals, 1.)
+		} else {
+			c.Check(math.IsInf(sc, 1), check.Equals, true)
+		}
+	}
+
+	c.Check(LOF(&Tree{}, 3), check.HasLen, 0)
+	c.Check(LOF(t, 0), check.HasLen, 0)
+	c.Check(LOF(New(Points{{1}}, false), 3), check.DeepEquals, []float64{1})
+}
@@ -0,0 +1,385 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// A PointEncoder writes binary representations of Comparable values.
+type PointEncoder interface {
+	// EncodePoint writes the binary representation of c to w.
+	EncodePoint(w io.Writer, c Comparable) error
+}
+
+// A PointDecoder reads binary representations of Comparable values.
+type PointDecoder interface {
+	// DecodePoint reads a single Comparable from r. DecodePoint must read exactly
+	// the bytes written by the corresponding EncodePoint call.
+	DecodePoint(r io.Reader) (Comparable, error)
+}
+
+// PointCodec is a PointEncoder and PointDecoder for Point values.
+type PointCodec struct{}
+
+var (
+	_ PointEncoder = PointCodec{}
+	_ PointDecoder = PointCodec{}
+)
+
+// EncodePoint writes the dimensionality of c followed by its coordinates. If c is not a
+// Point, EncodePoint returns ErrPointType.
+func (PointCodec) EncodePoint(w io.Writer, c Comparable) error {
+	p, ok := c.(Point)
+	if !ok {
+		return ErrPointType
+	}
+	b := make([]byte, binary.MaxVarintLen64+8*len(p))
+	n := binary.PutUvarint(b, uint64(len(p)))
+	for _, v := range p {
+		binary.LittleEndian.PutUint64(b[n:], math.Float64bits(v))
+		n += 8
+	}
+	_, err := w.Write(b[:n])
+	return err
+}
+
+// DecodePoint reads a Point written by EncodePoint.
+func (PointCodec) DecodePoint(r io.Reader) (Comparable, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &byteReader{Reader: r}
+	}
+	dims, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if dims > maxDims {
+		return nil, ErrFormat
+	}
+	b := make([]byte, 8*dims)
+	_, err = io.ReadFull(r, b)
+	if err != nil {
+		return nil, err
+	}
+	p := make(Point, dims)
+	for i := range p {
+		p[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return p, nil
+}
+
+// byteReader is an io.ByteReader that reads single bytes from an io.Reader.
+type byteReader struct {
+	io.Reader
+	b [1]byte
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(r.Reader, r.b[:])
+	return r.b[0], err
+}
+
+// maxDims is the largest dimensionality accepted by PointCodec.
+const maxDims = 1 << 16
+
+var (
+	// ErrFormat is returned when a stream is not a valid binary encoding of a Tree.
+	ErrFormat = errors.New("kdtree: invalid binary format")
+
+	// ErrVersion is returned when a binary encoding of a Tree has an unsupported version.
+	ErrVersion = errors.New("kdtree: unsupported binary format version")
+
+	// ErrChecksum is returned when the checksum of an encoded Tree does not match its
+	// contents.
+	ErrChecksum = errors.New("kdtree: checksum mismatch")
+
+	// ErrHeaderDims is returned when a point in an encoded Tree does not have the
+	// dimensionality recorded in its header.
+	ErrHeaderDims = errors.New("kdtree: point dimensions do not match header")
+
+	// ErrCount is returned when the number of nodes in an encoded Tree does not match the
+	// count recorded in its header.
+	ErrCount = errors.New("kdtree: node count does not match header")
+
+	// ErrPointType is returned by PointCodec.EncodePoint for values that are not Points.
+	ErrPointType = errors.New("kdtree: PointCodec value is not a Point")
+)
+
+// Binary format versions. Version 1 has no dimension metadata or checksum.
+const (
+	binaryMagic    = "kdt\x00"
+	binaryVersion1 = 1
+	binaryVersion  = 2
+	binaryChecksum = 4
+	unknownDims    = -1
+)
+
+// castagnoli is the CRC-32 table used for checksums of encoded trees.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Node flags used in the binary format.
+const (
+	hasLeft = 1 << iota
+	hasRight
+	hasBounds
+)
+
+// Marshal writes a binary representation of the tree to w, using enc to encode the stored
+// points and bounding volumes. The representation preserves the structure of the tree and
+// may be read by Unmarshal. Only the nodes, their points and bounding volumes, and the
+// count are encoded. The Tracer, Observer, Augmenter and Bloom of the tree and the
+// Summary of each node are not; after decoding, the Augmenter and Bloom must be
+// restored by Augment and UseBloom, and the Tracer and Observer set again.
+//
+// The format consists of a header holding a magic number, the format version, the
+// dimensionality of the points and the number of points in the tree, followed by the
+// nodes of the tree in pre-order and the little-endian CRC-32 (Castagnoli) checksum of
+// all the preceding bytes. Each node is a flag byte marking the presence of left and
+// right children and a bounding volume, the splitting dimension, the point and, if
+// present, the bounding volume. All the points of the tree must have the same
+// dimensionality; Marshal returns ErrPointDims if they do not.
+func (t *Tree) Marshal(w io.Writer, enc PointEncoder) error {
+	bw := bufio.NewWriter(w)
+	h := crc32.New(castagnoli)
+	e := encoder{w: io.MultiWriter(bw, h), enc: enc}
+	e.write([]byte(binaryMagic))
+	e.uvarint(binaryVersion)
+	if t.Root == nil {
+		e.dims = 0
+	} else {
+		e.dims = t.Root.Point.Dims()
+	}
+	e.uvarint(uint64(e.dims))
+	e.uvarint(uint64(t.Count))
+	if t.Root == nil {
+		e.write([]byte{0})
+	} else {
+		e.write([]byte{1})
+		e.node(t.Root)
+	}
+	if e.err != nil {
+		return e.err
+	}
+	var sum [binaryChecksum]byte
+	binary.LittleEndian.PutUint32(sum[:], h.Sum32())
+	_, err := bw.Write(sum[:])
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+type encoder struct {
+	w    io.Writer
+	enc  PointEncoder
+	dims int
+	buf  [binary.MaxVarintLen64]byte
+	err  error
+}
+
+func (e *encoder) write(b []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.write(e.buf[:binary.PutUvarint(e.buf[:], v)])
+}
+
+func (e *encoder) point(c Comparable) {
+	if e.err != nil {
+		return
+	}
+	if c.Dims() != e.dims {
+		e.err = ErrPointDims
+		return
+	}
+	e.err = e.enc.EncodePoint(e.w, c)
+}
+
+func (e *encoder) node(n *Node) {
+	var flags byte
+	if n.Left != nil {
+		flags |= hasLeft
+	}
+	if n.Right != nil {
+		flags |= hasRight
+	}
+	if n.Bounding != nil {
+		flags |= hasBounds
+	}
+	e.write([]byte{flags})
+	e.uvarint(uint64(n.Plane))
+	e.point(n.Point)
+	if n.Bounding != nil {
+		e.point(n.Bounding[0])
+		e.point(n.Bounding[1])
+	}
+	if n.Left != nil {
+		e.node(n.Left)
+	}
+	if n.Right != nil {
+		e.node(n.Right)
+	}
+}
+
+// Unmarshal returns a Tree read from the binary representation written by Marshal, using dec
+// to decode the stored points and bounding volumes. If r is not an io.ByteReader it is
+// wrapped in a buffered reader, so Unmarshal may read beyond the end of the tree.
+//
+// Representations written with format versions 1 and 2 are accepted. For version 2, the
+// dimensionality of each point and the number of nodes are checked against the header,
+// returning ErrHeaderDims or ErrCount on mismatch, and the checksum is verified, returning
+// ErrChecksum on mismatch. Unsupported versions result in ErrVersion. For both versions, a
+// node splitting on a dimension its point does not have results in ErrFormat.
+func Unmarshal(r io.Reader, dec PointDecoder) (*Tree, error) {
+	br, ok := r.(byteReadReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	h := crc32.New(castagnoli)
+	d := decoder{r: &hashReader{r: br, h: h}, dec: dec, dims: unknownDims}
+	magic := make([]byte, len(binaryMagic))
+	d.read(magic)
+	if d.err == nil && string(magic) != binaryMagic {
+		return nil, ErrFormat
+	}
+	version := d.uvarint()
+	if d.err == nil && version != binaryVersion1 && version != binaryVersion {
+		return nil, ErrVersion
+	}
+	if version >= binaryVersion {
+		dims := d.uvarint()
+		if dims > maxDims && d.err == nil {
+			d.err = ErrFormat
+		}
+		d.dims = int(dims)
+	}
+	t := &Tree{Count: int(d.uvarint())}
+	if d.byte() != 0 {
+		t.Root = d.node()
+	}
+	if version >= binaryVersion && d.err == nil {
+		sum := h.Sum32()
+		var b [binaryChecksum]byte
+		_, d.err = io.ReadFull(br, b[:])
+		switch {
+		case d.err != nil:
+		case binary.LittleEndian.Uint32(b[:]) != sum:
+			d.err = ErrChecksum
+		case uint64(d.nodes) != uint64(t.Count):
+			d.err = ErrCount
+		}
+	}
+	if d.err != nil {
+		if d.err == io.EOF {
+			d.err = io.ErrUnexpectedEOF
+		}
+		return nil, d.err
+	}
+	return t, nil
+}
+
+type byteReadReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// hashReader is a byteReadReader that adds the bytes read to a hash.
+type hashReader struct {
+	r byteReadReader
+	h hash.Hash32
+}
+
+func (r *hashReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.h.Write(b[:n])
+	return n, err
+}
+
+func (r *hashReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.h.Write([]byte{b})
+	}
+	return b, err
+}
+
+type decoder struct {
+	r     byteReadReader
+	dec   PointDecoder
+	dims  int
+	nodes int
+	err   error
+}
+
+func (d *decoder) read(b []byte) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.ReadFull(d.r, b)
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	var b byte
+	b, d.err = d.r.ReadByte()
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	v, d.err = binary.ReadUvarint(d.r)
+	return v
+}
+
+func (d *decoder) point() Comparable {
+	if d.err != nil {
+		return nil
+	}
+	var c Comparable
+	c, d.err = d.dec.DecodePoint(d.r)
+	if d.err == nil && d.dims != unknownDims && c.Dims() != d.dims {
+		d.err = ErrHeaderDims
+	}
+	return c
+}
+
+func (d *decoder) node() *Node {
+	flags := d.byte()
+	if flags&^(hasLeft|hasRight|hasBounds) != 0 && d.err == nil {
+		d.err = ErrFormat
+	}
+	d.nodes++
+	plane := d.uvarint()
+	n := &Node{Plane: Dim(plane), Point: d.point()}
+	if d.err == nil && plane >= uint64(n.Point.Dims()) {
+		d.err = ErrFormat
+	}
+	if flags&hasBounds != 0 {
+		n.Bounding = &Bounding{d.point(), d.point()}
+	}
+	if flags&hasLeft != 0 && d.err == nil {
+		n.Left = d.node()
+	}
+	if flags&hasRight != 0 && d.err == nil {
+		n.Right = d.node()
+	}
+	return n
+}
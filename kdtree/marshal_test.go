@@ -0,0 +1,105 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"io"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestMarshal(c *check.C) {
+	for i, t := range []*Tree{
+		{},
+		New(wpData, false),
+		New(append(Points(nil), wpData...), true),
+		bTree,
+	} {
+		var buf bytes.Buffer
+		err := t.Marshal(&buf, PointCodec{})
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+
+		got, err := Unmarshal(&buf, PointCodec{})
+		c.Assert(err, check.IsNil, check.Commentf("Test %d", i))
+		c.Check(got.Count, check.Equals, t.Count)
+		c.Check(got.Root, check.DeepEquals, t.Root, check.Commentf("Test %d", i))
+		c.Check(got.Root.isKDTree(), check.Equals, true)
+	}
+}
+
+func (s *S) TestMarshalPointType(c *check.C) {
+	var buf bytes.Buffer
+	c.Check(PointCodec{}.EncodePoint(&buf, Point32{1, 2}), check.Equals, ErrPointType)
+	t := New(Data{{Point: Point{1, 2}, Value: "a"}}, false)
+	c.Check(t.Marshal(&buf, PointCodec{}), check.Equals, ErrPointType)
+}
+
+func (s *S) TestUnmarshalInvalid(c *check.C) {
+	var buf bytes.Buffer
+	err := New(wpData, false).Marshal(&buf, PointCodec{})
+	c.Assert(err, check.IsNil)
+	b := buf.Bytes()
+
+	bad := append([]byte(nil), b...)
+	bad[0] = 'x'
+	_, err = Unmarshal(bytes.NewReader(bad), PointCodec{})
+	c.Check(err, check.Equals, ErrFormat)
+
+	bad = append([]byte(nil), b...)
+	bad[len(binaryMagic)] = binaryVersion + 1
+	_, err = Unmarshal(bytes.NewReader(bad), PointCodec{})
+	c.Check(err, check.Equals, ErrVersion)
+
+	for _, n := range []int{0, 2, len(b) / 2, len(b) - 1} {
+		_, err = Unmarshal(bytes.NewReader(b[:n]), PointCodec{})
+		c.Check(err, check.Equals, io.ErrUnexpectedEOF, check.Commentf("Length %d", n))
+	}
+}
+
+func (s *S) TestUnmarshalIntegrity(c *check.C) {
+	var buf bytes.Buffer
+	err := New(wpData, true).Marshal(&buf, PointCodec{})
+	c.Assert(err, check.IsNil)
+	b := append([]byte(nil), buf.Bytes()...)
+
+	// Header is magic, version, dims, count and root presence.
+	hdr := len(binaryMagic)
+	c.Check(b[hdr:hdr+4], check.DeepEquals, []byte{binaryVersion, 2, 6, 1})
+
+	bad := append([]byte(nil), b...)
+	bad[len(bad)-10] ^= 1
+	_, err = Unmarshal(bytes.NewReader(bad), PointCodec{})
+	c.Check(err, check.Equals, ErrChecksum)
+
+	bad = append([]byte(nil), b...)
+	bad[hdr+1] = 3
+	_, err = Unmarshal(bytes.NewReader(bad), PointCodec{})
+	c.Check(err, check.Equals, ErrHeaderDims)
+
+	buf.Reset()
+	t := New(wpData, false)
+	t.Count--
+	c.Assert(t.Marshal(&buf, PointCodec{}), check.IsNil)
+	_, err = Unmarshal(&buf, PointCodec{})
+	c.Check(err, check.Equals, ErrCount)
+
+	t = &Tree{Root: &Node{Point: Point{1, 2}, Left: &Node{Point: Point{1}}}, Count: 2}
+	c.Check(t.Marshal(&buf, PointCodec{}), check.Equals, ErrPointDims)
+
+	buf.Reset()
+	t = &Tree{Root: &Node{Point: Point{1, 2}, Plane: 2}, Count: 1}
+	c.Assert(t.Marshal(&buf, PointCodec{}), check.IsNil)
+	_, err = Unmarshal(&buf, PointCodec{})
+	c.Check(err, check.Equals, ErrFormat)
+
+	// Version 1 has no dims field and no checksum.
+	v1 := append([]byte(nil), b[:hdr]...)
+	v1 = append(v1, binaryVersion1)
+	v1 = append(v1, b[hdr+2:len(b)-binaryChecksum]...)
+	got, err := Unmarshal(bytes.NewReader(v1), PointCodec{})
+	c.Assert(err, check.IsNil)
+	c.Check(got, check.DeepEquals, New(wpData, true))
+}
@@ -0,0 +1,59 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"os"
+)
+
+// OpenMmap returns a FlatTree that serves queries directly from a read-only memory mapping
+// of the flat tree file at path, as written by FlatTree.WriteTo. Only the node table is
+// read during opening; point data is paged in by the operating system as queries touch it.
+// On platforms without memory mapping, or on big-endian hosts, the file is read into
+// memory. The checksum of the file is not verified; Verify may be called to check the
+// integrity of the file at the cost of reading it in its entirety.
+//
+// The returned FlatTree must be closed with Close when it is no longer needed. Values
+// returned by queries refer to the mapping and must not be used after Close is called.
+func OpenMmap(path string) (*FlatTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < flatHeaderSize {
+		return nil, ErrFlatFormat
+	}
+	b, unmap, err := mmap(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	t, err := FlatFrom(b)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	if &t.data[0] != &b[0] {
+		// The data were copied, so the mapping is not needed.
+		return t, unmap()
+	}
+	t.unmap = unmap
+	return t, nil
+}
+
+// Close releases the memory mapping held by a FlatTree returned by OpenMmap. Close
+// is a no-op for other FlatTree values.
+func (f *FlatTree) Close() error {
+	if f.unmap == nil {
+		return nil
+	}
+	err := f.unmap()
+	*f = FlatTree{}
+	return err
+}
@@ -0,0 +1,21 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package kdtree
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+func mmap(f *os.File, _ int64) ([]byte, func() error, error) {
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return alignedCopy(b), func() error { return nil }, nil
+}
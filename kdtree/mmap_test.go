@@ -0,0 +1,42 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestOpenMmap(c *check.C) {
+	path := filepath.Join(c.MkDir(), "tree.kdflat")
+	f, err := bTree.Flatten(nil)
+	c.Assert(err, check.IsNil)
+	w, err := os.Create(path)
+	c.Assert(err, check.IsNil)
+	_, err = f.WriteTo(w)
+	c.Assert(err, check.IsNil)
+	c.Assert(w.Close(), check.IsNil)
+
+	m, err := OpenMmap(path)
+	c.Assert(err, check.IsNil)
+	c.Check(m.Len(), check.Equals, bTree.Len())
+	for i := 0; i < 100; i++ {
+		q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+		p, d := m.Nearest(q)
+		ep, ed := bTree.Nearest(q)
+		c.Check(p, check.DeepEquals, ep, check.Commentf("Test %d", i))
+		c.Check(d, check.Equals, ed)
+	}
+	c.Check(m.Close(), check.IsNil)
+	c.Check(m.Len(), check.Equals, 0)
+
+	c.Assert(ioutil.WriteFile(path, []byte("not a tree"), 0644), check.IsNil)
+	_, err = OpenMmap(path)
+	c.Check(err, check.Equals, ErrFlatFormat)
+}
@@ -0,0 +1,68 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// Moments holds the number of values in a subtree and the per-dimension mean and sum of
+// squared deviations from the mean of their coordinates.
+type Moments struct {
+	N    int
+	Mean Point
+	M2   Point
+}
+
+// Variance returns the per-dimension population variance of the coordinates summarized
+// by m.
+func (m *Moments) Variance() Point {
+	v := make(Point, len(m.M2))
+	for d, s := range m.M2 {
+		v[d] = s / float64(m.N)
+	}
+	return v
+}
+
+// MaxVariance returns the dimension with the greatest variance, which is the dimension
+// chosen, up to rounding, by the MaxVariance SplitRule for the values summarized by m.
+func (m *Moments) MaxVariance() Dim {
+	var best Dim
+	for d, s := range m.M2 {
+		if s > m.M2[best] {
+			best = Dim(d)
+		}
+	}
+	return best
+}
+
+// MomentsAugmenter is an Augmenter whose summaries are the *Moments of the values held by
+// each subtree, allowing the mean and variance of the values of any subtree to be read
+// without visiting them, for example to choose a SplitRule for RebuildSplit. Summaries are
+// combined by the pairwise update of Chan, Golub and LeVeque, which is numerically stable.
+// Stored values must be of a type accepted by Point.Compare.
+type MomentsAugmenter struct{}
+
+// Summarize returns the moments of a subtree.
+func (MomentsAugmenter) Summarize(c Comparable, left, right interface{}) interface{} {
+	m := &Moments{N: 1, Mean: make(Point, c.Dims()), M2: make(Point, c.Dims())}
+	for d := range m.Mean {
+		m.Mean[d] = at(c, Dim(d))
+	}
+	for _, s := range [...]interface{}{left, right} {
+		if s != nil {
+			m.merge(s.(*Moments))
+		}
+	}
+	return m
+}
+
+// merge adds the values summarized by o to m.
+func (m *Moments) merge(o *Moments) {
+	na, nb := float64(m.N), float64(o.N)
+	n := na + nb
+	for d := range m.Mean {
+		delta := o.Mean[d] - m.Mean[d]
+		m.Mean[d] += delta * nb / n
+		m.M2[d] += o.M2[d] + delta*delta*na*nb/n
+	}
+	m.N += o.N
+}
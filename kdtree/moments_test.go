@@ -0,0 +1,73 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// checkMoments checks that the summary of every node of t holds the moments of its subtree.
+func checkMoments(c *check.C, t *Tree) {
+	t.Walk(func(_ []*Node, n *Node) bool {
+		var values []Comparable
+		n.do(func(v Comparable, _ *Bounding, _ int) bool {
+			values = append(values, v)
+			return false
+		}, 0)
+		m := n.Summary.(*Moments)
+		c.Check(m.N, check.Equals, len(values))
+		for d := range m.Mean {
+			var sum, ss float64
+			for _, v := range values {
+				sum += v.(Point)[d]
+			}
+			mean := sum / float64(len(values))
+			for _, v := range values {
+				ss += (v.(Point)[d] - mean) * (v.(Point)[d] - mean)
+			}
+			c.Check(math.Abs(m.Mean[d]-mean) < 1e-9, check.Equals, true)
+			c.Check(math.Abs(m.M2[d]-ss) < 1e-9*(1+ss), check.Equals, true)
+		}
+		return false
+	})
+}
+
+func (s *S) TestMoments(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 300)
+	for i := range p {
+		p[i] = Point{rnd.NormFloat64(), 5 * rnd.NormFloat64(), 100 + rnd.Float64()}
+	}
+	t := New(append(Points(nil), p...), false)
+	t.Augment(MomentsAugmenter{})
+	checkMoments(c, t)
+
+	for i := 0; i < 100; i++ {
+		t.Insert(Point{rnd.NormFloat64(), 5 * rnd.NormFloat64(), 100 + rnd.Float64()}, false)
+	}
+	for _, v := range p[:150] {
+		c.Assert(t.Remove(v), check.Equals, true)
+	}
+	checkMoments(c, t)
+
+	root := t.Root.Summary.(*Moments)
+	c.Check(root.N, check.Equals, 250)
+	c.Check(root.MaxVariance(), check.Equals, Dim(1))
+	v := root.Variance()
+	c.Check(v[0] > 0.5 && v[0] < 2, check.Equals, true)
+	c.Check(v[1] > 12 && v[1] < 50, check.Equals, true)
+	c.Check(v[2] < 0.2, check.Equals, true)
+
+	t.RebuildSplit(false, MaxVariance)
+	c.Check(t.Root.Plane, check.Equals, root.MaxVariance())
+	checkMoments(c, t)
+
+	m := MomentsAugmenter{}.Summarize(Point{1, 2}, nil, nil).(*Moments)
+	c.Check(*m, check.DeepEquals, Moments{N: 1, Mean: Point{1, 2}, M2: Point{0, 0}})
+	c.Check(m.Variance(), check.DeepEquals, Point{0, 0})
+}
@@ -0,0 +1,147 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+var (
+	_ SpatialIndex = (*Multiset)(nil)
+	_ coorder      = (*Counted)(nil)
+)
+
+// A Counted is a value held by a Multiset with the number of times a value with its
+// coordinates is held. Counted values may be compared with each other and with the values
+// accepted by Point.Compare.
+type Counted struct {
+	Comparable
+
+	// N is the multiplicity of the value.
+	N int
+}
+
+func (c *Counted) coord(d Dim) float64 { return at(c.Comparable, d) }
+
+// Compare satisfies the Comparable interface.
+func (c *Counted) Compare(o Comparable, d Dim) float64 {
+	if oc, ok := o.(*Counted); ok {
+		o = oc.Comparable
+	}
+	return c.Comparable.Compare(o, d)
+}
+
+// Distance satisfies the Comparable interface.
+func (c *Counted) Distance(o Comparable) float64 {
+	if oc, ok := o.(*Counted); ok {
+		o = oc.Comparable
+	}
+	return c.Comparable.Distance(o)
+}
+
+// Extend satisfies the Extender interface. The value held by c must be an Extender.
+func (c *Counted) Extend(b *Bounding) *Bounding { return c.Comparable.(Extender).Extend(b) }
+
+// A Multiset is a SpatialIndex holding values with identical coordinates once, with a count
+// of their multiplicity, so that datasets with many exactly duplicated coordinates do not
+// bloat the tree and nearest neighbour queries return distinct locations. Values are held
+// as *Counted; the first value inserted with a set of coordinates represents all the values
+// with those coordinates. Values must be of a type accepted by Point.Compare.
+type Multiset struct {
+	tree     *Tree
+	count    int
+	bounding bool
+}
+
+// NewMultiset returns an empty Multiset. If bounding is true, bounding volumes are maintained
+// for the nodes of the underlying tree when the values are Extenders.
+func NewMultiset(bounding bool) *Multiset {
+	return &Multiset{tree: &Tree{}, bounding: bounding}
+}
+
+// Len returns the number of values in the multiset, counting multiplicity.
+func (m *Multiset) Len() int { return m.count }
+
+// Distinct returns the number of distinct sets of coordinates in the multiset.
+func (m *Multiset) Distinct() int { return m.tree.Len() }
+
+// Insert adds c to the multiset, incrementing the multiplicity of a held value with the same
+// coordinates if there is one. Insert returns ErrPointDims if c does not have the
+// dimensionality of the values already in the multiset.
+func (m *Multiset) Insert(c Comparable) error {
+	if m.tree.Root != nil && c.Dims() != m.tree.Dims() {
+		return ErrPointDims
+	}
+	m.count++
+	if v := m.find(c); v != nil {
+		v.N++
+		return nil
+	}
+	_, ok := c.(Extender)
+	m.tree.Insert(&Counted{Comparable: c, N: 1}, m.bounding && ok)
+	return nil
+}
+
+// Remove decrements the multiplicity of the value with the same coordinates as c, removing
+// it when no more remain, and returns whether a value was removed.
+func (m *Multiset) Remove(c Comparable) bool {
+	if m.tree.Root == nil || c.Dims() != m.tree.Dims() {
+		return false
+	}
+	path := m.tree.Root.findPath(c, nil, nil)
+	if path == nil {
+		return false
+	}
+	n := path[len(path)-1]
+	m.count--
+	if v := n.Point.(*Counted); v.N > 1 {
+		v.N--
+		return true
+	}
+	return m.tree.detach(n)
+}
+
+// Count returns the multiplicity of the coordinates of c in the multiset.
+func (m *Multiset) Count(c Comparable) int {
+	if v := m.find(c); v != nil {
+		return v.N
+	}
+	return 0
+}
+
+// find returns the value held with the coordinates of c, or nil if there is none.
+func (m *Multiset) find(c Comparable) *Counted {
+	if m.tree.Root == nil || c.Dims() != m.tree.Dims() {
+		return nil
+	}
+	path := m.tree.Root.findPath(c, nil, nil)
+	if path == nil {
+		return nil
+	}
+	return path[len(path)-1].Point.(*Counted)
+}
+
+// Nearest returns the nearest value to the query, as a *Counted, and the distance between
+// them.
+func (m *Multiset) Nearest(q Comparable) (Comparable, float64) { return m.tree.Nearest(q) }
+
+// NearestN returns the n nearest distinct values to the query, as *Counted, in min sorted
+// order.
+func (m *Multiset) NearestN(q Comparable, n int) []ComparableDist { return m.tree.NearestN(q, n) }
+
+// InRange returns the distinct values within distance d of the query, as *Counted, in min
+// sorted order.
+func (m *Multiset) InRange(q Comparable, d float64) []ComparableDist { return m.tree.InRange(q, d) }
+
+// DoBounded performs fn on all distinct values, as *Counted, within the specified bound,
+// returning whether the traversal was interrupted. Values must not be altered by fn.
+func (m *Multiset) DoBounded(fn Operation, b *Bounding) bool { return m.tree.DoBounded(fn, b) }
+
+// CountBounded returns the number of values within the specified bound, counting
+// multiplicity.
+func (m *Multiset) CountBounded(b *Bounding) int {
+	var n int
+	m.tree.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+		n += c.(*Counted).N
+		return false
+	}, b)
+	return n
+}
@@ -0,0 +1,52 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestMultiset(c *check.C) {
+	for _, bounding := range []bool{false, true} {
+		m := NewMultiset(bounding)
+		for i, p := range wpData {
+			for j := 0; j <= i; j++ {
+				c.Assert(m.Insert(append(Point(nil), p...)), check.IsNil)
+			}
+		}
+		c.Check(m.Insert(Point{1}), check.Equals, ErrPointDims)
+		c.Check(m.Len(), check.Equals, 21)
+		c.Check(m.Distinct(), check.Equals, len(wpData))
+		c.Check(m.tree.Root.Bounding != nil, check.Equals, bounding)
+		for i, p := range wpData {
+			c.Check(m.Count(p), check.Equals, i+1)
+		}
+		c.Check(m.Count(Point{0, 0}), check.Equals, 0)
+
+		// Duplicates do not crowd out other locations.
+		got := m.NearestN(Point{9, 5}, 2)
+		c.Assert(got, check.HasLen, 2)
+		c.Check(got[0].Comparable.(*Counted).Comparable, check.DeepEquals, Point{9, 6})
+		c.Check(got[0].Comparable.(*Counted).N, check.Equals, 6)
+		c.Check(got[1].Comparable.(*Counted).Comparable, check.DeepEquals, Point{7, 2})
+		v, d := m.Nearest(Point{2, 2})
+		c.Check(v.(*Counted).Comparable, check.DeepEquals, Point{2, 3})
+		c.Check(d, check.Equals, 1.)
+		c.Check(m.InRange(Point{5, 4}, 10), check.HasLen, 4)
+
+		b := &Bounding{Point{0, 0}, Point{5, 5}}
+		c.Check(m.CountBounded(b), check.Equals, 3)
+		c.Check(m.CountBounded(nil), check.Equals, 21)
+
+		c.Check(m.Remove(Point{5, 4}), check.Equals, true)
+		c.Check(m.Count(Point{5, 4}), check.Equals, 1)
+		c.Check(m.Remove(Point{5, 4}), check.Equals, true)
+		c.Check(m.Count(Point{5, 4}), check.Equals, 0)
+		c.Check(m.Remove(Point{5, 4}), check.Equals, false)
+		c.Check(m.Len(), check.Equals, 19)
+		c.Check(m.Distinct(), check.Equals, len(wpData)-1)
+		c.Check(m.CountBounded(b), check.Equals, 1)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "sort"
+
+// NeighborLists returns, for each value stored in the tree in the order returned by Points,
+// the indices in that order of the other values within Euclidean distance r of it, in
+// ascending order. Values must be of a type accepted by Point.Compare. The lists are found
+// by a single dual-tree traversal of the tree against itself, as for CrossMatch.
+func (t *Tree) NeighborLists(r float64) [][]int {
+	if t.Root == nil {
+		return nil
+	}
+	m := matcher{r2: r * r}
+	cell := t.cell()
+	m.pair(t.Root, cell, t.Root, cell)
+	index := t.nodeIndex()
+	lists := make([][]int, t.Count)
+	for _, nm := range m.matches {
+		if nm.a != nm.b {
+			i := index[nm.a]
+			lists[i] = append(lists[i], index[nm.b])
+		}
+	}
+	for _, l := range lists {
+		sort.Ints(l)
+	}
+	return lists
+}
@@ -0,0 +1,39 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestNeighborLists(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	// Points are (2,3) (5,4) (4,7) (7,2) (8,1) (9,6) in order.
+	c.Check(t.Points(), check.DeepEquals, []Comparable{Point{2, 3}, Point{5, 4}, Point{4, 7}, Point{7, 2}, Point{8, 1}, Point{9, 6}})
+	c.Check(t.NeighborLists(3), check.DeepEquals, [][]int{nil, {3}, nil, {1, 4}, {3}, nil})
+	c.Check(t.NeighborLists(1), check.DeepEquals, [][]int{nil, nil, nil, nil, nil, nil})
+	c.Check((&Tree{}).NeighborLists(1), check.HasLen, 0)
+
+	// Duplicates are neighbours of each other.
+	c.Check(New(Points{{1}, {1}, {3}}, false).NeighborLists(0), check.DeepEquals, [][]int{{1}, {0}, nil})
+
+	t = New(append(Points(nil), bData...), false)
+	values := t.Points()
+	const r = 0.2
+	lists := t.NeighborLists(r)
+	c.Assert(lists, check.HasLen, len(values))
+	var total int
+	for i, p := range values {
+		var want []int
+		for j, q := range values {
+			if i != j && p.Distance(q) <= r*r {
+				want = append(want, j)
+			}
+		}
+		c.Check(lists[i], check.DeepEquals, want)
+		total += len(want)
+	}
+	c.Check(total > 0, check.Equals, true)
+}
@@ -0,0 +1,251 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+)
+
+const (
+	// nnDescentIterations is the maximum number of NN-descent iterations.
+	nnDescentIterations = 30
+
+	// nnDescentDelta is the fraction of the nk graph edges below which the number of
+	// updates in an iteration ends the descent.
+	nnDescentDelta = 0.001
+
+	// nnDescentSample is the number of values whose neighbours are checked by exact
+	// search to estimate the recall of a graph.
+	nnDescentSample = 100
+)
+
+// A KNNGraph is an approximate k nearest neighbour graph of the values stored in a tree.
+type KNNGraph struct {
+	// Neighbors holds, for each value in the order returned by Points, the indices in
+	// that order of its approximate nearest neighbours in order of increasing distance,
+	// and Dists holds their distances.
+	Neighbors [][]int
+	Dists     [][]float64
+
+	// Iterations is the number of NN-descent iterations performed.
+	Iterations int
+
+	// Recall is the estimated fraction of the true k nearest neighbours held by the
+	// graph, measured by exact searches for a random sample of values.
+	Recall float64
+}
+
+// NNDescent returns an approximate graph of the k nearest neighbours of each value stored in
+// the tree, as measured by the values' Distance method, found by NN-descent (Dong, Moses and
+// Li, 2011). NN-descent refines the graph by comparing the neighbours of the neighbours of
+// each value, on the principle that a neighbour of a neighbour is likely to be a neighbour,
+// until few neighbour lists improve. The graph is initialized with the nearest of the values
+// close to each value in the order of the tree, which are spatially grouped, mixed with
+// random values, so the descent converges in few iterations. Each value of a tree holding no
+// more than k values is given all the other values as neighbours. rnd is used to initialize
+// the graph, to sample the reverse neighbours considered in each iteration and to choose the
+// values checked to estimate recall.
+func (t *Tree) NNDescent(k int, rnd *rand.Rand) *KNNGraph {
+	p := t.Points()
+	if len(p) == 0 || k <= 0 {
+		return &KNNGraph{}
+	}
+	if k >= len(p) {
+		k = len(p) - 1
+	}
+	g := nnGraph{p: p, k: k, lists: make([][]nnNeighbour, len(p))}
+
+	// Initialize with the nearest half of the values
+	// in a block of the in-order sequence of the tree,
+	// which are spatially grouped, and fill the lists
+	// with random values so that the descent can reach
+	// beyond each block. A single block holding all the
+	// values gives the exact graph.
+	block := 2 * (k + 1)
+	if block < len(p) {
+		g.k = (k + 1) / 2
+	}
+	for lo := 0; lo < len(p); lo += block {
+		hi := lo + block
+		if hi > len(p) {
+			hi = len(p)
+			if hi-block > 0 {
+				lo = hi - block
+			} else {
+				lo = 0
+			}
+		}
+		for i := lo; i < hi; i++ {
+			for j := i + 1; j < hi; j++ {
+				g.join(i, j)
+			}
+		}
+	}
+	g.k = k
+	for i := range g.lists {
+		for len(g.lists[i]) < k {
+			if j := rnd.Intn(len(p)); j != i {
+				g.offer(i, j, p[i].Distance(p[j]))
+			}
+		}
+	}
+
+	var iter int
+	for block < len(p) && iter < nnDescentIterations {
+		iter++
+		if g.descend(rnd) < int(nnDescentDelta*float64(len(p)*k)) {
+			break
+		}
+	}
+
+	graph := &KNNGraph{
+		Neighbors:  make([][]int, len(p)),
+		Dists:      make([][]float64, len(p)),
+		Iterations: iter,
+	}
+	for i, l := range g.lists {
+		graph.Neighbors[i] = make([]int, len(l))
+		graph.Dists[i] = make([]float64, len(l))
+		for j, n := range l {
+			graph.Neighbors[i][j], graph.Dists[i][j] = n.index, n.dist
+		}
+	}
+	graph.Recall = t.graphRecall(graph, p, k, rnd)
+	return graph
+}
+
+// graphRecall returns the fraction of the true k nearest neighbours held by the graph for a
+// random sample of the values. Neighbours are matched by distance, so values with identical
+// coordinates are interchangeable.
+func (t *Tree) graphRecall(g *KNNGraph, p []Comparable, k int, rnd *rand.Rand) float64 {
+	sample := rnd.Perm(len(p))
+	if len(sample) > nnDescentSample {
+		sample = sample[:nnDescentSample]
+	}
+	var found, total int
+	for _, i := range sample {
+		// The nearest value to each value is itself.
+		exact := t.NearestN(p[i], k+1)
+		kth := exact[len(exact)-1].Dist
+		for _, d := range g.Dists[i] {
+			if d <= kth {
+				found++
+			}
+		}
+		total += len(exact) - 1
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+// nnNeighbour is an entry in a neighbour list. fresh indicates that the neighbour has not
+// yet been used to find neighbours of neighbours.
+type nnNeighbour struct {
+	index int
+	dist  float64
+	fresh bool
+}
+
+// nnGraph is the state of an NN-descent.
+type nnGraph struct {
+	p     []Comparable
+	k     int
+	lists [][]nnNeighbour
+}
+
+// join offers each of the ith and jth values as a neighbour of the other, returning the
+// number of lists updated.
+func (g *nnGraph) join(i, j int) int {
+	if i == j {
+		return 0
+	}
+	d := g.p[i].Distance(g.p[j])
+	var n int
+	if g.offer(i, j, d) {
+		n++
+	}
+	if g.offer(j, i, d) {
+		n++
+	}
+	return n
+}
+
+// offer adds the jth value at distance d to the neighbour list of the ith value if it is
+// not already present and is among the k nearest, returning whether the list changed.
+func (g *nnGraph) offer(i, j int, d float64) bool {
+	l := g.lists[i]
+	if len(l) == g.k && d >= l[len(l)-1].dist {
+		return false
+	}
+	for _, n := range l {
+		if n.index == j {
+			return false
+		}
+	}
+	at := sort.Search(len(l), func(m int) bool { return l[m].dist > d })
+	if len(l) < g.k {
+		l = append(l, nnNeighbour{})
+	}
+	copy(l[at+1:], l[at:])
+	l[at] = nnNeighbour{index: j, dist: d, fresh: true}
+	g.lists[i] = l
+	return true
+}
+
+// descend performs an iteration of NN-descent, returning the number of list updates.
+func (g *nnGraph) descend(rnd *rand.Rand) int {
+	fresh := make([][]int, len(g.p))
+	stale := make([][]int, len(g.p))
+	for i, l := range g.lists {
+		for m, n := range l {
+			if n.fresh {
+				fresh[i] = append(fresh[i], n.index)
+				l[m].fresh = false
+			} else {
+				stale[i] = append(stale[i], n.index)
+			}
+		}
+	}
+	rfresh := make([][]int, len(g.p))
+	rstale := make([][]int, len(g.p))
+	for i := range g.p {
+		for _, j := range fresh[i] {
+			rfresh[j] = append(rfresh[j], i)
+		}
+		for _, j := range stale[i] {
+			rstale[j] = append(rstale[j], i)
+		}
+	}
+
+	var updates int
+	for i := range g.p {
+		nf := appendSample(fresh[i], rfresh[i], g.k, rnd)
+		ns := appendSample(stale[i], rstale[i], g.k, rnd)
+		for a, u := range nf {
+			for _, v := range nf[a+1:] {
+				updates += g.join(u, v)
+			}
+			for _, v := range ns {
+				updates += g.join(u, v)
+			}
+		}
+	}
+	return updates
+}
+
+// appendSample appends up to k values chosen at random from src to dst.
+func appendSample(dst, src []int, k int, rnd *rand.Rand) []int {
+	if len(src) > k {
+		for i := 0; i < k; i++ {
+			j := i + rnd.Intn(len(src)-i)
+			src[i], src[j] = src[j], src[i]
+		}
+		src = src[:k]
+	}
+	return append(dst, src...)
+}
@@ -0,0 +1,76 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestNNDescent(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	const (
+		n    = 2000
+		dims = 8
+		k    = 10
+	)
+	p := make(Points, n)
+	for i := range p {
+		p[i] = make(Point, dims)
+		for d := range p[i] {
+			p[i][d] = rnd.Float64()
+		}
+	}
+	t := New(p, false)
+	g := t.NNDescent(k, rnd)
+	c.Assert(g.Neighbors, check.HasLen, n)
+	c.Check(g.Iterations > 0, check.Equals, true)
+	c.Check(g.Recall > 0.9, check.Equals, true, check.Commentf("recall %v", g.Recall))
+
+	values := t.Points()
+	var found int
+	for i, l := range g.Neighbors {
+		c.Assert(l, check.HasLen, k)
+		c.Check(sort.Float64sAreSorted(g.Dists[i]), check.Equals, true)
+		for j, m := range l {
+			c.Check(m, check.Not(check.Equals), i)
+			c.Check(g.Dists[i][j], check.Equals, values[i].Distance(values[m]))
+		}
+		var dists []float64
+		for j, v := range values {
+			if j != i {
+				dists = append(dists, values[i].Distance(v))
+			}
+		}
+		sort.Float64s(dists)
+		for _, d := range g.Dists[i] {
+			if d <= dists[k-1] {
+				found++
+			}
+		}
+	}
+	recall := float64(found) / (n * k)
+	c.Check(recall > 0.9, check.Equals, true, check.Commentf("recall %v", recall))
+	c.Check(g.Recall-recall < 0.05 && recall-g.Recall < 0.05, check.Equals, true,
+		check.Commentf("estimated recall %v measured %v", g.Recall, recall))
+}
+
+func (s *S) TestNNDescentSmall(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	g := t.NNDescent(10, rand.New(rand.NewSource(1)))
+	c.Check(g.Iterations, check.Equals, 0)
+	c.Check(g.Recall, check.Equals, 1.)
+	values := t.Points()
+	for i, l := range g.Neighbors {
+		c.Assert(l, check.HasLen, len(wpData)-1)
+		want := t.NearestN(values[i], len(wpData))[1:]
+		for j := range l {
+			c.Check(g.Dists[i][j], check.Equals, want[j].Dist)
+		}
+	}
+	c.Check((&Tree{}).NNDescent(3, rand.New(rand.NewSource(1))).Neighbors, check.HasLen, 0)
+}
@@ -0,0 +1,90 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// normalChunk is the minimum number of values handled by each normal estimating goroutine.
+const normalChunk = 256
+
+// Normals returns the estimated surface normal of each value stored in the three
+// dimensional point cloud held by t, in the order returned by Points. The normal of a value
+// is the unit eigenvector of least eigenvalue of the covariance of its k nearest values,
+// including itself, being the normal of the least squares plane through the neighbourhood.
+// Normals are oriented towards the origin, as for a cloud captured by a sensor at the
+// origin. If t holds fewer than k values, all values are used; if fewer than three values
+// are used, Normals returns nil. Neighbourhoods are found by concurrently running
+// goroutines, so the tree must not be modified during the call and a Tracer held by the
+// tree must be safe for concurrent use. Stored values must be of a
+// type accepted by Point.Compare. Normals panics if the values in the tree are not three
+// dimensional.
+func Normals(t *Tree, k int) []Point {
+	if t.Root == nil {
+		return nil
+	}
+	if t.Dims() != 3 {
+		panic("kdtree: normals of values that are not three dimensional")
+	}
+	values := t.Points()
+	if k > len(values) {
+		k = len(values)
+	}
+	if k < 3 {
+		return nil
+	}
+	normals := make([]Point, len(values))
+	parallel(len(values), normalChunk, func(i int) {
+		nn := t.NearestN(values[i], k)
+		normals[i] = planeNormal(nn)
+		var dot float64
+		for d, v := range normals[i] {
+			dot += v * at(values[i], Dim(d))
+		}
+		if dot > 0 {
+			for d := range normals[i] {
+				normals[i][d] = -normals[i][d]
+			}
+		}
+	})
+	return normals
+}
+
+// planeNormal returns the unit normal of the least squares plane through the values of nn.
+func planeNormal(nn []ComparableDist) Point {
+	var mean [3]float64
+	for _, nd := range nn {
+		for d := range mean {
+			mean[d] += at(nd.Comparable, Dim(d))
+		}
+	}
+	for d := range mean {
+		mean[d] /= float64(len(nn))
+	}
+	cov := [][]float64{make([]float64, 3), make([]float64, 3), make([]float64, 3)}
+	for _, nd := range nn {
+		var x [3]float64
+		for d := range x {
+			x[d] = at(nd.Comparable, Dim(d)) - mean[d]
+		}
+		for i := range cov {
+			for j := range cov[i] {
+				cov[i][j] += x[i] * x[j]
+			}
+		}
+	}
+	values, vectors := jacobi(cov)
+	least := 0
+	for i, v := range values {
+		if v < values[least] {
+			least = i
+		}
+	}
+	n := Point{vectors[0][least], vectors[1][least], vectors[2][least]}
+	norm := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	for d := range n {
+		n[d] /= norm
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestNormals(c *check.C) {
+	// Points on the plane z = x/2 + 1.
+	var p Points
+	for x := 0.; x < 10; x++ {
+		for y := 0.; y < 10; y++ {
+			p = append(p, Point{x, y, x/2 + 1})
+		}
+	}
+	t := New(p, false)
+	want := Point{0.5, 0, -1}
+	norm := math.Sqrt(1.25)
+	for d := range want {
+		want[d] /= norm
+	}
+	normals := Normals(t, 8)
+	c.Assert(normals, check.HasLen, len(p))
+	for _, n := range normals {
+		for d := range n {
+			c.Check(math.Abs(n[d]-want[d]) < 1e-9, check.Equals, true, check.Commentf("%v", n))
+		}
+	}
+
+	// Points on the unit sphere have normals pointing
+	// towards its centre at the origin.
+	rnd := rand.New(rand.NewSource(1))
+	p = make(Points, 5000)
+	for i := range p {
+		v := Point{rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()}
+		r := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+		for d := range v {
+			v[d] /= r
+		}
+		p[i] = v
+	}
+	t = New(p, true)
+	values := t.Points()
+	for i, n := range Normals(t, 10) {
+		v := values[i].(Point)
+		c.Check(n[0]*v[0]+n[1]*v[1]+n[2]*v[2] < -0.99, check.Equals, true)
+	}
+
+	c.Check(Normals(New(Points{{0, 0, 0}, {1, 0, 0}}, false), 10), check.IsNil)
+	c.Check(Normals(&Tree{}, 10), check.IsNil)
+	c.Check(func() { Normals(New(Points{{0, 0}}, false), 3) }, check.Panics, "kdtree: normals of values that are not three dimensional")
+}
@@ -0,0 +1,67 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// An Observer holds functions that are called after a tree is mutated, allowing external
+// indexes, caches or metrics to be maintained in step with the tree. Nil functions are not
+// called.
+type Observer struct {
+	// OnInsert is called with each value inserted
+	// into the tree.
+	OnInsert func(c Comparable)
+
+	// OnRemove is called with each stored value
+	// removed from the tree.
+	OnRemove func(c Comparable)
+
+	// OnRebuild is called with the tree after it
+	// has been rebuilt by Rebuild.
+	OnRebuild func(t *Tree)
+}
+
+func (o *Observer) inserted(c Comparable) {
+	if o != nil && o.OnInsert != nil {
+		o.OnInsert(c)
+	}
+}
+
+func (o *Observer) removed(c Comparable) {
+	if o != nil && o.OnRemove != nil {
+		o.OnRemove(c)
+	}
+}
+
+func (o *Observer) rebuilt(t *Tree) {
+	if o != nil && o.OnRebuild != nil {
+		o.OnRebuild(t)
+	}
+}
+
+// Rebuild rebalances the tree by reconstructing it from its values as for New, restoring
+// the balance lost through insertions and removals. Bounding volumes are determined for
+// each node if bounding is true and the values are Extenders. Handles referring to values
+// in the tree are invalidated.
+func (t *Tree) Rebuild(bounding bool) { t.RebuildSplit(bounding, nil) }
+
+// RebuildSplit rebalances the tree as described for Rebuild, choosing the splitting
+// dimension of each node by rule as for NewSplit. If rule is nil, Cycle is used.
+func (t *Tree) RebuildSplit(bounding bool, rule SplitRule) {
+	t.Walk(func(_ []*Node, n *Node) bool {
+		if n.handle != nil {
+			n.handle.node = nil
+			n.handle = nil
+		}
+		return false
+	})
+	p := comparables(t.Points())
+	if len(p) != 0 {
+		r := NewSplit(p, bounding && p.Bounds() != nil, rule)
+		t.Root, t.Count = r.Root, r.Count
+	}
+	if t.Augmenter != nil {
+		t.Augment(t.Augmenter)
+	}
+	t.Observer.rebuilt(t)
+}
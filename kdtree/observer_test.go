@@ -0,0 +1,53 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestObserver(c *check.C) {
+	var (
+		inserted, removed []Comparable
+		rebuilt           int
+	)
+	t := &Tree{Observer: &Observer{
+		OnInsert:  func(p Comparable) { inserted = append(inserted, p) },
+		OnRemove:  func(p Comparable) { removed = append(removed, p) },
+		OnRebuild: func(*Tree) { rebuilt++ },
+	}}
+	for _, p := range wpData {
+		t.Insert(p, true)
+	}
+	c.Check(inserted, check.HasLen, len(wpData))
+	c.Check(t.Remove(Point{0, 0}), check.Equals, false)
+	c.Check(removed, check.HasLen, 0)
+	d := Datum{Point: Point{1, 1}, Value: "x"}
+	t.Insert(d, true)
+	c.Check(t.Remove(Point{1, 1}), check.Equals, true)
+	c.Check(removed, check.DeepEquals, []Comparable{d})
+
+	for i := 0; i < 20; i++ {
+		t.Insert(Point{float64(i) + 0.5, float64(i) + 0.25}, true)
+	}
+	before := t.Points()
+	t.Rebuild(true)
+	c.Check(rebuilt, check.Equals, 1)
+	c.Check(t.Root.isKDTree(), check.Equals, true)
+	c.Check(t.Root.Bounding, check.NotNil)
+	c.Check(t.Len(), check.Equals, len(before))
+	c.Check(t.Height() < 10, check.Equals, true)
+	c.Check(t.EqualSet(New(comparables(before), false), nil), check.Equals, true)
+
+	t.Rebuild(false)
+	c.Check(t.Root.Bounding, check.IsNil)
+
+	// Unobserved trees are unaffected.
+	u := &Tree{}
+	u.Insert(Point{1, 2}, false)
+	u.Remove(Point{1, 2})
+	u.Rebuild(false)
+	c.Check(u.Len(), check.Equals, 0)
+}
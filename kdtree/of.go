@@ -0,0 +1,64 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package kdtree
+
+// An Item is a value held by a tree constructed by Of. Items may be compared with each
+// other and with Point, Datum and Row values, so the tree may be queried with Points.
+type Item[T any] struct {
+	// Value is the value wrapped by the Item.
+	Value T
+
+	acc *accessor[T]
+}
+
+// accessor holds the coordinate function shared by the items of a tree.
+type accessor[T any] struct {
+	coords func(T, Dim) float64
+	dims   int
+}
+
+func (it Item[T]) coord(d Dim) float64 { return it.acc.coords(it.Value, d) }
+
+// Compare satisfies the Comparable interface.
+func (it Item[T]) Compare(c Comparable, d Dim) float64 { return it.coord(d) - at(c, d) }
+
+// Dims satisfies the Comparable interface.
+func (it Item[T]) Dims() int { return it.acc.dims }
+
+// Distance satisfies the Comparable interface.
+func (it Item[T]) Distance(c Comparable) float64 {
+	var sum float64
+	for d := Dim(0); d < Dim(it.acc.dims); d++ {
+		v := it.coord(d) - at(c, d)
+		sum += v * v
+	}
+	return sum
+}
+
+// Of returns a k-d tree holding the values in items, each wrapped in an Item. The
+// coordinate of a value in dimension d, for d in [0, dims), is given by coords. items
+// is not altered. Values returned by queries of the tree are Item[T] values, from
+// which the original value may be recovered:
+//
+//	t := kdtree.Of(cities, func(c City, d kdtree.Dim) float64 { return c.Loc[d] }, 2)
+//	c, _ := t.Nearest(kdtree.Point{lat, lon})
+//	city := c.(kdtree.Item[City]).Value
+func Of[T any](items []T, coords func(T, Dim) float64, dims int) *Tree {
+	acc := &accessor[T]{coords: coords, dims: dims}
+	p := make(comparables, len(items))
+	for i, v := range items {
+		p[i] = Item[T]{Value: v, acc: acc}
+	}
+	return New(p, false)
+}
+
+// NewItem returns an Item holding v with coordinates given by coords, for insertion into
+// a tree constructed by Of.
+func NewItem[T any](v T, coords func(T, Dim) float64, dims int) Item[T] {
+	return Item[T]{Value: v, acc: &accessor[T]{coords: coords, dims: dims}}
+}
@@ -0,0 +1,73 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+type town struct {
+	name     string
+	lat, lon float64
+}
+
+func townCoord(c town, d Dim) float64 {
+	if d == 0 {
+		return c.lat
+	}
+	return c.lon
+}
+
+func (s *S) TestOf(c *check.C) {
+	towns := []town{
+		{"a", 2, 3}, {"b", 5, 4}, {"c", 9, 6}, {"d", 4, 7}, {"e", 8, 1}, {"f", 7, 2},
+	}
+	orig := append([]town(nil), towns...)
+	t := Of(towns, townCoord, 2)
+	c.Check(towns, check.DeepEquals, orig)
+	c.Check(t.Len(), check.Equals, len(towns))
+	c.Check(t.Dims(), check.Equals, 2)
+	c.Check(t.Root.isKDTree(), check.Equals, true)
+
+	n, d := t.Nearest(Point{9, 5})
+	c.Check(n.(Item[town]).Value.name, check.Equals, "c")
+	c.Check(d, check.Equals, 1.)
+
+	var names []string
+	t.DoBounded(func(v Comparable, _ *Bounding, _ int) bool {
+		names = append(names, v.(Item[town]).Value.name)
+		return false
+	}, &Bounding{Point{4, 0}, Point{8, 4}})
+	c.Check(names, check.HasLen, 3)
+
+	t.Insert(NewItem(town{"g", 9, 5}, townCoord, 2), false)
+	n, d = t.Nearest(Point{9, 5})
+	c.Check(n.(Item[town]).Value.name, check.Equals, "g")
+	c.Check(d, check.Equals, 0.)
+	c.Check(t.Remove(Point{9, 5}), check.Equals, true)
+
+	// Results agree with a tree of Points.
+	rnd := rand.New(rand.NewSource(1))
+	var pts Points
+	towns = towns[:0]
+	for i := 0; i < 500; i++ {
+		p := Point{rnd.Float64(), rnd.Float64()}
+		pts = append(pts, p)
+		towns = append(towns, town{lat: p[0], lon: p[1]})
+	}
+	t = Of(towns, townCoord, 2)
+	pt := New(pts, false)
+	for i := 0; i < 50; i++ {
+		q := Point{rnd.Float64(), rnd.Float64()}
+		_, want := pt.Nearest(q)
+		_, got := t.Nearest(q)
+		c.Check(got, check.Equals, want)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// An Order specifies the order in which a traversal visits the values of a tree.
+type Order int
+
+const (
+	// InOrder visits the left subtree of a node, then the
+	// node, then the right subtree.
+	InOrder Order = iota
+
+	// PreOrder visits a node before its subtrees.
+	PreOrder
+
+	// PostOrder visits a node after its subtrees.
+	PostOrder
+
+	// LevelOrder visits nodes breadth first, in order of
+	// increasing depth and from left to right within a level.
+	LevelOrder
+
+	// ReverseInOrder visits the right subtree of a node,
+	// then the node, then the left subtree.
+	ReverseInOrder
+)
+
+// A DoOption modifies the behaviour of a traversal.
+type DoOption func(*doConfig)
+
+type doConfig struct {
+	order Order
+
+	// openLo and openHi are the dimensions in which the lower
+	// and upper limits of a bounded traversal are exclusive.
+	openLo, openHi openSet
+}
+
+func newDoConfig(opts []DoOption) doConfig {
+	var c doConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// WithOrder returns a DoOption that sets the order of a traversal. The default order
+// is InOrder.
+func WithOrder(o Order) DoOption {
+	return func(c *doConfig) { c.order = o }
+}
+
+// WithOpenLower returns a DoOption that makes the lower limits of the bounding volume of a
+// DoBounded traversal exclusive in the given dimensions, or in all dimensions if none are
+// given.
+func WithOpenLower(dims ...Dim) DoOption {
+	return func(c *doConfig) { c.openLo = openSet{all: len(dims) == 0, dims: dims} }
+}
+
+// WithOpenUpper returns a DoOption that makes the upper limits of the bounding volume of a
+// DoBounded traversal exclusive in the given dimensions, or in all dimensions if none are
+// given. Traversals over a space tiled by boxes with exclusive upper limits visit each value
+// lying on a shared tile border exactly once.
+func WithOpenUpper(dims ...Dim) DoOption {
+	return func(c *doConfig) { c.openHi = openSet{all: len(dims) == 0, dims: dims} }
+}
+
+// openSet is a set of dimensions.
+type openSet struct {
+	all  bool
+	dims []Dim
+}
+
+func (s openSet) empty() bool { return !s.all && len(s.dims) == 0 }
+
+func (s openSet) has(d Dim) bool {
+	if s.all {
+		return true
+	}
+	for _, o := range s.dims {
+		if o == d {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns a function returning whether a value is within b, honouring the
+// exclusive limits of the configuration.
+func (c doConfig) contains(b *Bounding) func(Comparable) bool {
+	if c.openLo.empty() && c.openHi.empty() {
+		return b.Contains
+	}
+	return func(p Comparable) bool {
+		for d := Dim(0); d < Dim(p.Dims()); d++ {
+			lo, hi := p.Compare(b[0], d), p.Compare(b[1], d)
+			if lo < 0 || (lo == 0 && c.openLo.has(d)) || hi > 0 || (hi == 0 && c.openHi.has(d)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// doOrder performs fn on the values of the subtree rooted at n in the order o.
+func (n *Node) doOrder(fn Operation, depth int, o Order) (done bool) {
+	switch o {
+	case InOrder:
+		return n.do(fn, depth)
+	case PreOrder:
+		return fn(n.Point, n.Bounding, depth) ||
+			(n.Left != nil && n.Left.doOrder(fn, depth+1, o)) ||
+			(n.Right != nil && n.Right.doOrder(fn, depth+1, o))
+	case PostOrder:
+		return (n.Left != nil && n.Left.doOrder(fn, depth+1, o)) ||
+			(n.Right != nil && n.Right.doOrder(fn, depth+1, o)) ||
+			fn(n.Point, n.Bounding, depth)
+	case ReverseInOrder:
+		return (n.Right != nil && n.Right.doOrder(fn, depth+1, o)) ||
+			fn(n.Point, n.Bounding, depth) ||
+			(n.Left != nil && n.Left.doOrder(fn, depth+1, o))
+	case LevelOrder:
+		level := []*Node{n}
+		for ; len(level) != 0; depth++ {
+			var next []*Node
+			for _, n := range level {
+				if fn(n.Point, n.Bounding, depth) {
+					return true
+				}
+				if n.Left != nil {
+					next = append(next, n.Left)
+				}
+				if n.Right != nil {
+					next = append(next, n.Right)
+				}
+			}
+			level = next
+		}
+		return false
+	default:
+		panic("kdtree: invalid traversal order")
+	}
+}
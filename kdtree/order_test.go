@@ -0,0 +1,119 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDoOrder(c *check.C) {
+	// The tree built from wpData is:
+	//
+	//          (7,2)
+	//        /       \
+	//     (5,4)     (9,6)
+	//     /   \     /
+	//  (2,3) (4,7) (8,1)
+	t := New(append(Points(nil), wpData...), false)
+	for _, test := range []struct {
+		order Order
+		want  Points
+		depth []int
+	}{
+		{
+			order: InOrder,
+			want:  Points{{2, 3}, {5, 4}, {4, 7}, {7, 2}, {8, 1}, {9, 6}},
+			depth: []int{2, 1, 2, 0, 2, 1},
+		},
+		{
+			order: PreOrder,
+			want:  Points{{7, 2}, {5, 4}, {2, 3}, {4, 7}, {9, 6}, {8, 1}},
+			depth: []int{0, 1, 2, 2, 1, 2},
+		},
+		{
+			order: PostOrder,
+			want:  Points{{2, 3}, {4, 7}, {5, 4}, {8, 1}, {9, 6}, {7, 2}},
+			depth: []int{2, 2, 1, 2, 1, 0},
+		},
+		{
+			order: LevelOrder,
+			want:  Points{{7, 2}, {5, 4}, {9, 6}, {2, 3}, {4, 7}, {8, 1}},
+			depth: []int{0, 1, 1, 2, 2, 2},
+		},
+		{
+			order: ReverseInOrder,
+			want:  Points{{9, 6}, {8, 1}, {7, 2}, {4, 7}, {5, 4}, {2, 3}},
+			depth: []int{1, 2, 0, 2, 1, 2},
+		},
+	} {
+		var (
+			got   Points
+			depth []int
+		)
+		killed := t.Do(func(p Comparable, _ *Bounding, d int) bool {
+			got = append(got, p.(Point))
+			depth = append(depth, d)
+			return false
+		}, WithOrder(test.order))
+		c.Check(killed, check.Equals, false)
+		c.Check(got, check.DeepEquals, test.want, check.Commentf("Order %d", test.order))
+		c.Check(depth, check.DeepEquals, test.depth, check.Commentf("Order %d", test.order))
+
+		// Traversals stop when fn returns true.
+		got = got[:0]
+		killed = t.Do(func(p Comparable, _ *Bounding, _ int) bool {
+			got = append(got, p.(Point))
+			return len(got) == 3
+		}, WithOrder(test.order))
+		c.Check(killed, check.Equals, true)
+		c.Check(got, check.DeepEquals, test.want[:3], check.Commentf("Order %d", test.order))
+	}
+	c.Check(func() { t.Do(func(Comparable, *Bounding, int) bool { return false }, WithOrder(-1)) },
+		check.PanicMatches, "kdtree: invalid traversal order")
+}
+
+func (s *S) TestDoBoundedOpen(c *check.C) {
+	var p Points
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			p = append(p, Point{float64(x), float64(y)})
+		}
+	}
+	t := New(p, false)
+	count := func(b *Bounding, opts ...DoOption) int {
+		var n int
+		t.DoBounded(func(Comparable, *Bounding, int) bool { n++; return false }, b, opts...)
+		return n
+	}
+
+	// Closed tiles double count border points.
+	var closed, open int
+	for x := 0.; x < 10; x += 5 {
+		for y := 0.; y < 10; y += 5 {
+			b := &Bounding{Point{x, y}, Point{x + 5, y + 5}}
+			closed += count(b)
+			n := count(b, WithOpenUpper())
+			c.Check(n, check.Equals, 25)
+			open += n
+		}
+	}
+	c.Check(closed > len(p), check.Equals, true)
+	c.Check(open, check.Equals, len(p))
+
+	b := &Bounding{Point{2, 2}, Point{4, 4}}
+	for _, test := range []struct {
+		opts []DoOption
+		want int
+	}{
+		{want: 9},
+		{opts: []DoOption{WithOpenUpper()}, want: 4},
+		{opts: []DoOption{WithOpenLower()}, want: 4},
+		{opts: []DoOption{WithOpenLower(), WithOpenUpper()}, want: 1},
+		{opts: []DoOption{WithOpenUpper(0)}, want: 6},
+		{opts: []DoOption{WithOpenLower(1), WithOpenUpper(0)}, want: 4},
+	} {
+		c.Check(count(b, test.opts...), check.Equals, test.want)
+	}
+}
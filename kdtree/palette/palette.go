@@ -0,0 +1,109 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package palette provides nearest colour mapping for colour quantization using k-d trees.
+package palette
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Palette maps colours to the index of their nearest palette colour by squared Euclidean
+// distance. Colours may have any number of components, typically three or four.
+type Palette struct {
+	tree   *kdtree.Tree
+	colors []kdtree.Point
+}
+
+// New returns a Palette holding the given colours, which must all have the same number of
+// components. colors is retained but not altered by the Palette.
+func New(colors []kdtree.Point) *Palette {
+	data := make(kdtree.Data, len(colors))
+	for i, c := range colors {
+		data[i] = kdtree.Datum{Point: c, Value: i}
+	}
+	return &Palette{tree: kdtree.New(data, false), colors: colors}
+}
+
+// Len returns the number of colours in the palette.
+func (p *Palette) Len() int { return len(p.colors) }
+
+// Color returns the ith colour of the palette.
+func (p *Palette) Color(i int) kdtree.Point { return p.colors[i] }
+
+// Index returns the index of the palette colour nearest to c, or -1 if the palette is
+// empty. Ties are resolved as described for kdtree.Tree.Nearest.
+func (p *Palette) Index(c kdtree.Point) int {
+	if p.tree.Root == nil {
+		return -1
+	}
+	n, _ := p.tree.Nearest(c)
+	return n.(kdtree.Datum).Value.(int)
+}
+
+// mapChunk is the minimum number of colours mapped by each goroutine.
+const mapChunk = 1024
+
+// Map returns the index of the nearest palette colour to each of the colours in src, as
+// for Index. The indices are written to dst, which is grown if it is shorter than src, and
+// the resulting slice is returned, so the storage of dst may be reused across calls. Colours
+// are mapped by concurrently running goroutines, each of which reuses the previous result
+// when a colour is repeated, as is common along the rows of an image.
+func (p *Palette) Map(dst []int, src []kdtree.Point) []int {
+	if cap(dst) >= len(src) {
+		dst = dst[:len(src)]
+	} else {
+		dst = make([]int, len(src))
+	}
+	if len(src) == 0 {
+		return dst
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if max := (len(src) + mapChunk - 1) / mapChunk; workers > max {
+		workers = max
+	}
+	size := (len(src) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(src); start += size {
+		end := start + size
+		if end > len(src) {
+			end = len(src)
+		}
+		wg.Add(1)
+		go func(dst []int, src []kdtree.Point) {
+			defer wg.Done()
+			p.mapRun(dst, src)
+		}(dst[start:end], src[start:end])
+	}
+	wg.Wait()
+	return dst
+}
+
+// mapRun maps src into dst, reusing the result for repeated colours.
+func (p *Palette) mapRun(dst []int, src []kdtree.Point) {
+	var last kdtree.Point
+	for i, c := range src {
+		if i != 0 && equal(c, last) {
+			dst[i] = dst[i-1]
+			continue
+		}
+		dst[i] = p.Index(c)
+		last = c
+	}
+}
+
+func equal(a, b kdtree.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
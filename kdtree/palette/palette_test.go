@@ -0,0 +1,75 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package palette
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var rgb = []kdtree.Point{
+	{0, 0, 0},
+	{255, 255, 255},
+	{255, 0, 0},
+	{0, 255, 0},
+	{0, 0, 255},
+}
+
+func (s *S) TestIndex(c *check.C) {
+	p := New(rgb)
+	c.Check(p.Len(), check.Equals, 5)
+	c.Check(p.Color(2), check.DeepEquals, kdtree.Point{255, 0, 0})
+	c.Check(p.Index(kdtree.Point{10, 20, 5}), check.Equals, 0)
+	c.Check(p.Index(kdtree.Point{200, 220, 240}), check.Equals, 1)
+	c.Check(p.Index(kdtree.Point{200, 20, 40}), check.Equals, 2)
+	c.Check(p.Index(kdtree.Point{20, 20, 140}), check.Equals, 4)
+	c.Check(rgb[2], check.DeepEquals, kdtree.Point{255, 0, 0})
+	c.Check(New(nil).Index(kdtree.Point{0, 0, 0}), check.Equals, -1)
+}
+
+func (s *S) TestMap(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	colors := make([]kdtree.Point, 64)
+	for i := range colors {
+		colors[i] = kdtree.Point{rnd.Float64() * 255, rnd.Float64() * 255, rnd.Float64() * 255, rnd.Float64() * 255}
+	}
+	p := New(colors)
+	src := make([]kdtree.Point, 10000)
+	for i := range src {
+		if i%3 == 1 {
+			src[i] = src[i-1]
+			continue
+		}
+		src[i] = kdtree.Point{rnd.Float64() * 255, rnd.Float64() * 255, rnd.Float64() * 255, rnd.Float64() * 255}
+	}
+	got := p.Map(nil, src)
+	c.Assert(got, check.HasLen, len(src))
+	for i, q := range src {
+		want := 0
+		for j, col := range colors {
+			if q.Distance(col) < q.Distance(colors[want]) {
+				want = j
+			}
+		}
+		c.Check(got[i], check.Equals, want)
+	}
+
+	buf := make([]int, 0, len(src))
+	again := p.Map(buf, src)
+	c.Check(again, check.DeepEquals, got)
+	c.Check(&again[0], check.Equals, &buf[:1][0])
+	c.Check(p.Map(nil, nil), check.HasLen, 0)
+}
@@ -0,0 +1,96 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+var (
+	_ Interface = Points32{}
+	_ Extender  = Point32{}
+	_ coorder   = Point32{}
+)
+
+// A Point32 is a point in a k-d space with single precision coordinates that satisfies the
+// Comparable and Extender interfaces. A tree constructed from Points32 holds its points and
+// bounding volumes in single precision, using half the space of a tree of Points, while
+// distances are accumulated in double precision. Point32 values may be compared with Point,
+// Datum and *Datum values, so a tree of Points32 may be queried and bounded by Points.
+type Point32 []float32
+
+func (p Point32) coord(d Dim) float64 { return float64(p[d]) }
+
+// Compare satisfies the Comparable interface. c must be a Point32 or a value accepted by
+// Point.Compare.
+func (p Point32) Compare(c Comparable, d Dim) float64 {
+	if q, ok := c.(Point32); ok {
+		return float64(p[d]) - float64(q[d])
+	}
+	return float64(p[d]) - at(c, d)
+}
+
+// Dims returns the number of dimensions of p.
+func (p Point32) Dims() int { return len(p) }
+
+// Distance returns the squared Euclidean distance between p and c, accumulated in double
+// precision. c must be a Point32 or a value accepted by Point.Distance.
+func (p Point32) Distance(c Comparable) float64 {
+	var sum float64
+	if q, ok := c.(Point32); ok {
+		for d, v := range p {
+			x := float64(v) - float64(q[d])
+			sum += x * x
+		}
+		return sum
+	}
+	for d, v := range p {
+		x := float64(v) - at(c, Dim(d))
+		sum += x * x
+	}
+	return sum
+}
+
+// Extend satisfies the Extender interface. The bounding volume is held as Point32 values.
+func (p Point32) Extend(b *Bounding) *Bounding {
+	if b == nil {
+		return &Bounding{append(Point32(nil), p...), append(Point32(nil), p...)}
+	}
+	min, max := b[0].(Point32), b[1].(Point32)
+	for d, v := range p {
+		if v < min[d] {
+			min[d] = v
+		}
+		if v > max[d] {
+			max[d] = v
+		}
+	}
+	return b
+}
+
+// Points32 is a collection of Point32 values that satisfies the Interface.
+type Points32 []Point32
+
+// Bounds returns the bounding volume of the points, held as Point32 values.
+func (p Points32) Bounds() *Bounding {
+	if len(p) == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p Points32) Index(i int) Comparable         { return p[i] }
+func (p Points32) Len() int                       { return len(p) }
+func (p Points32) Pivot(d Dim) int                { return plane32{Dim: d, Points32: p}.Pivot() }
+func (p Points32) Slice(start, end int) Interface { return p[start:end] }
+
+type plane32 struct {
+	Dim
+	Points32
+}
+
+func (p plane32) Less(i, j int) bool              { return p.Points32[i][p.Dim] < p.Points32[j][p.Dim] }
+func (p plane32) Pivot() int                      { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p plane32) Slice(start, end int) SortSlicer { p.Points32 = p.Points32[start:end]; return p }
+func (p plane32) Swap(i, j int)                   { p.Points32[i], p.Points32[j] = p.Points32[j], p.Points32[i] }
@@ -0,0 +1,69 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestPoint32(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p32 := make(Points32, 500)
+	p64 := make(Points, len(p32))
+	for i := range p32 {
+		p32[i] = Point32{rnd.Float32(), rnd.Float32(), rnd.Float32()}
+		p64[i] = Point{float64(p32[i][0]), float64(p32[i][1]), float64(p32[i][2])}
+	}
+	t32 := New(append(Points32(nil), p32...), true)
+	t64 := New(append(Points(nil), p64...), true)
+	c.Check(t32.Height() < len(p32)/4, check.Equals, true)
+	_, ok := t32.Root.Bounding[0].(Point32)
+	c.Check(ok, check.Equals, true)
+
+	for i := 0; i < 100; i++ {
+		q := Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+		v, d := t32.Nearest(q)
+		ev, ed := t64.Nearest(q)
+		c.Check(d, check.Equals, ed, check.Commentf("Test %d", i))
+		c.Check(pointOf(v), check.DeepEquals, ev)
+
+		q32 := Point32{float32(q[0]), float32(q[1]), float32(q[2])}
+		got, want := t32.NearestN(q32, 5), t64.NearestN(Point{float64(q32[0]), float64(q32[1]), float64(q32[2])}, 5)
+		c.Assert(got, check.HasLen, len(want))
+		for j := range got {
+			c.Check(got[j].Dist, check.Equals, want[j].Dist)
+		}
+
+		b := &Bounding{Point{q[0] - 0.2, q[1] - 0.2, q[2] - 0.2}, Point{q[0] + 0.2, q[1] + 0.2, q[2] + 0.2}}
+		var in32, in64 []Comparable
+		t32.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			in32 = append(in32, pointOf(c))
+			return false
+		}, b)
+		t64.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			in64 = append(in64, c)
+			return false
+		}, b)
+		sort.Sort(lexOrder(in32))
+		sort.Sort(lexOrder(in64))
+		c.Check(in32, check.DeepEquals, in64, check.Commentf("Test %d", i))
+	}
+
+	c.Check(Points32{{1, 5}, {3, 2}}.Bounds(), check.DeepEquals, &Bounding{Point32{1, 2}, Point32{3, 5}})
+	c.Check(Points32(nil).Bounds(), check.IsNil)
+	c.Check(Point32{1, 2}.Distance(Point{4, 6}), check.Equals, 25.)
+}
+
+// pointOf returns the coordinates of the Point32 v as a Point.
+func pointOf(v Comparable) Point {
+	p := make(Point, v.Dims())
+	for d := range p {
+		p[d] = float64(v.(Point32)[d])
+	}
+	return p
+}
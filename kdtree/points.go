@@ -5,12 +5,15 @@
 package kdtree
 
 import (
+	"fmt"
 	"math"
 )
 
 var (
 	_ Interface  = Points{}
 	_ Comparable = Point{}
+	_ Interface  = Data{}
+	_ Extender   = Datum{}
 )
 
 // Randoms is the maximum number of random values to sample for calculation of median of
@@ -20,10 +23,48 @@ var Randoms = 100
 // A Point represents a point in a k-d space that satisfies the Comparable interface.
 type Point []float64
 
-func (p Point) Compare(c Comparable, d Dim) float64 { q := c.(Point); return p[d] - q[d] }
-func (p Point) Dims() int                           { return len(p) }
+// Compare satisfies the Comparable interface. c must be a Point, Datum, *Datum, Row or a
+// value held by a tree constructed by Of.
+func (p Point) Compare(c Comparable, d Dim) float64 {
+	if q, ok := c.(Point); ok {
+		return p[d] - q[d]
+	}
+	if r, ok := c.(Row); ok {
+		return p[d] - r.c.cols[d][r.i]
+	}
+	if a, ok := c.(coorder); ok {
+		return p[d] - a.coord(d)
+	}
+	q := coords(c)
+	return p[d] - q[d]
+}
+func (p Point) Dims() int { return len(p) }
+
+// Distance satisfies the Comparable interface, returning the squared Euclidean distance
+// between p and c. c must be a Point, Datum, *Datum, Row or a value held by a tree
+// constructed by Of. The squared distance overflows to +Inf when the coordinates differ by
+// more than about 1e154, and underflows to zero when they differ by less than about 1e-162;
+// queries with an EuclideanPoint remain exact for such values.
 func (p Point) Distance(c Comparable) float64 {
-	q := c.(Point)
+	if q, ok := c.(Point); ok {
+		return p.distance(q)
+	}
+	if r, ok := c.(Row); ok {
+		return r.Distance(p)
+	}
+	if a, ok := c.(coorder); ok {
+		var sum float64
+		for dim, c := range p {
+			d := c - a.coord(Dim(dim))
+			sum += d * d
+		}
+		return sum
+	}
+	return p.distance(coords(c))
+}
+
+// distance returns the squared Euclidean distance between p and q.
+func (p Point) distance(q Point) float64 {
 	var sum float64
 	for dim, c := range p {
 		d := c - q[dim]
@@ -45,6 +86,33 @@ func (p Point) Extend(b *Bounding) *Bounding {
 	return b
 }
 
+// coorder is a Comparable that provides its coordinates by dimension.
+type coorder interface {
+	Comparable
+	coord(Dim) float64
+}
+
+// coords returns the coordinates of a Point, Datum or *Datum.
+func coords(c Comparable) Point {
+	switch c := c.(type) {
+	case Point:
+		return c
+	case Datum:
+		return c.Point
+	case *Datum:
+		return c.Point
+	}
+	panic(fmt.Sprintf("kdtree: cannot compare Point with %T", c))
+}
+
+// A Datum is a Point with an associated value. Datum and *Datum values may be compared
+// with Point values and with each other, so a tree of Data may be queried with Points
+// and bounded by Points.
+type Datum struct {
+	Point
+	Value interface{}
+}
+
 // A Points is a collection of point values that satisfies the Interface.
 type Points []Point
 
@@ -79,3 +147,32 @@ func (p Plane) Slice(start, end int) SortSlicer { p.Points = p.Points[start:end]
 func (p Plane) Swap(i, j int) {
 	p.Points[i], p.Points[j] = p.Points[j], p.Points[i]
 }
+
+// A Data is a collection of Datum values that satisfies the Interface.
+type Data []Datum
+
+// Bounds returns the bounding volume of the points in the Data.
+func (p Data) Bounds() *Bounding {
+	if p.Len() == 0 {
+		return nil
+	}
+	var b *Bounding
+	for _, e := range p {
+		b = e.Extend(b)
+	}
+	return b
+}
+func (p Data) Index(i int) Comparable         { return p[i] }
+func (p Data) Len() int                       { return len(p) }
+func (p Data) Pivot(d Dim) int                { return dataPlane{Data: p, Dim: d}.Pivot() }
+func (p Data) Slice(start, end int) Interface { return p[start:end] }
+
+type dataPlane struct {
+	Dim
+	Data
+}
+
+func (p dataPlane) Less(i, j int) bool              { return p.Data[i].Point[p.Dim] < p.Data[j].Point[p.Dim] }
+func (p dataPlane) Pivot() int                      { return Partition(p, MedianOfRandoms(p, Randoms)) }
+func (p dataPlane) Slice(start, end int) SortSlicer { p.Data = p.Data[start:end]; return p }
+func (p dataPlane) Swap(i, j int)                   { p.Data[i], p.Data[j] = p.Data[j], p.Data[i] }
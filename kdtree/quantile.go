@@ -0,0 +1,129 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"sort"
+)
+
+// Quantile returns the q-quantile of the coordinates in dimension d of the values stored in
+// the tree, being the least coordinate x such that at least a fraction q of the values have
+// coordinates no greater than x. Quantile returns NaN if the tree is empty or q is not in
+// [0, 1].
+//
+// The quantile is bracketed by a binary search over the splitting values of the upper levels
+// of the tree, counting the values on each side of a candidate with bounded traversals, so
+// only the values between the bracketing splits are examined individually. Counting is
+// fastest when the tree is augmented by a Counter. Stored values must be of a type accepted
+// by Point.Compare.
+func (t *Tree) Quantile(d Dim, q float64) float64 {
+	if t.Root == nil || !(0 <= q && q <= 1) {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(q*float64(t.Count))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	// Candidate brackets are the splitting values in d of the
+	// nodes in the levels of the tree holding about the square
+	// root of the number of values.
+	var levels int
+	for n := t.Count; n > 1; n >>= 2 {
+		levels++
+	}
+	var splits []float64
+	var collect func(n *Node, depth int)
+	collect = func(n *Node, depth int) {
+		if n == nil || depth > levels {
+			return
+		}
+		if n.Plane == d {
+			splits = append(splits, at(n.Point, d))
+		}
+		collect(n.Left, depth+1)
+		collect(n.Right, depth+1)
+	}
+	collect(t.Root, 0)
+	sort.Float64s(splits)
+
+	// Find the greatest split below which no more than rank
+	// values lie, and the least split at or below which more
+	// than rank values lie.
+	lo, hi := -inf, inf
+	below := 0
+	i := sort.Search(len(splits), func(i int) bool { return t.countBelow(d, splits[i], false) > rank })
+	if i > 0 {
+		lo = splits[i-1]
+		below = t.countBelow(d, lo, false)
+	}
+	j := sort.Search(len(splits), func(j int) bool { return t.countBelow(d, splits[j], true) > rank })
+	if j < len(splits) {
+		hi = splits[j]
+	}
+
+	var coords []float64
+	t.cover(slab(t.Dims(), d, lo, hi), func(n *Node) {
+		n.do(func(c Comparable, _ *Bounding, _ int) bool {
+			coords = append(coords, at(c, d))
+			return false
+		}, 0)
+	}, func(n *Node) { coords = append(coords, at(n.Point, d)) })
+	sort.Float64s(coords)
+	return coords[rank-below]
+}
+
+// Histogram returns the number of values stored in the tree with coordinates in dimension d
+// in each of the bins delimited by edges, which must be sorted in increasing order. The ith
+// bin holds coordinates x with edges[i] <= x < edges[i+1], except for the final bin which
+// also holds coordinates equal to its upper edge. Each edge is evaluated by counting the
+// values below it with a bounded traversal, which is fastest when the tree is augmented by
+// a Counter. Histogram returns nil if fewer than two edges are given. Stored values must be
+// of a type accepted by Point.Compare.
+func (t *Tree) Histogram(d Dim, edges []float64) []int {
+	if len(edges) < 2 {
+		return nil
+	}
+	counts := make([]int, len(edges)-1)
+	if t.Root == nil {
+		return counts
+	}
+	prev := t.countBelow(d, edges[0], false)
+	for i, e := range edges[1:] {
+		n := t.countBelow(d, e, i == len(counts)-1)
+		counts[i] = n - prev
+		prev = n
+	}
+	return counts
+}
+
+// countBelow returns the number of values in the tree with coordinates in dimension d less
+// than x, or no greater than x if inclusive is true.
+func (t *Tree) countBelow(d Dim, x float64, inclusive bool) int {
+	if !inclusive {
+		x = math.Nextafter(x, -inf)
+	}
+	_, isCounter := t.Augmenter.(Counter)
+	var n int
+	t.cover(slab(t.Dims(), d, -inf, x), func(r *Node) {
+		if isCounter {
+			n += r.Summary.(int)
+		} else {
+			n += r.count()
+		}
+	}, func(*Node) { n++ })
+	return n
+}
+
+// slab returns the bound of a dims dimensional space limited to [lo, hi] in dimension d.
+func slab(dims int, d Dim, lo, hi float64) *Bounding {
+	min, max := make(Point, dims), make(Point, dims)
+	for i := range min {
+		min[i], max[i] = -inf, inf
+	}
+	min[d], max[d] = lo, hi
+	return &Bounding{min, max}
+}
@@ -0,0 +1,73 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestQuantile(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	for _, test := range []struct {
+		d    Dim
+		q    float64
+		want float64
+	}{
+		{d: 0, q: 0, want: 2},
+		{d: 0, q: 0.5, want: 5},
+		{d: 0, q: 0.51, want: 7},
+		{d: 0, q: 1, want: 9},
+		{d: 1, q: 0.5, want: 3},
+		{d: 1, q: 0.9, want: 7},
+	} {
+		c.Check(t.Quantile(test.d, test.q), check.Equals, test.want, check.Commentf("%+v", test))
+	}
+	c.Check(math.IsNaN(t.Quantile(0, 1.5)), check.Equals, true)
+	c.Check(math.IsNaN((&Tree{}).Quantile(0, 0.5)), check.Equals, true)
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 3000)
+	for i := range p {
+		// Coarse coordinates in the second dimension give ties.
+		p[i] = Point{rnd.NormFloat64(), float64(rnd.Intn(20)), rnd.Float64()}
+	}
+	for _, bounding := range []bool{false, true} {
+		for _, aug := range []Augmenter{nil, Counter{}} {
+			t := New(append(Points(nil), p...), bounding)
+			t.Augment(aug)
+			for d := Dim(0); d < 3; d++ {
+				coords := make([]float64, len(p))
+				for i, v := range p {
+					coords[i] = v[d]
+				}
+				sort.Float64s(coords)
+				for _, q := range []float64{0, 0.001, 0.1, 0.25, 0.5, 0.7, 0.99, 1} {
+					rank := int(math.Ceil(q*float64(len(p)))) - 1
+					if rank < 0 {
+						rank = 0
+					}
+					c.Check(t.Quantile(d, q), check.Equals, coords[rank])
+				}
+
+				edges := []float64{-1, 0, 0.5, 5, 10, 19}
+				want := make([]int, len(edges)-1)
+				for _, x := range coords {
+					for i := range want {
+						if edges[i] <= x && (x < edges[i+1] || (i == len(want)-1 && x == edges[i+1])) {
+							want[i]++
+						}
+					}
+				}
+				c.Check(t.Histogram(d, edges), check.DeepEquals, want)
+			}
+		}
+	}
+	c.Check(t.Histogram(0, []float64{1}), check.IsNil)
+	c.Check((&Tree{}).Histogram(0, []float64{0, 1}), check.DeepEquals, []int{0})
+}
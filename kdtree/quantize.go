@@ -0,0 +1,66 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrPrecision is returned by FreezeQuantized when the coordinates of the values in a tree
+// cannot be represented at the requested precision.
+var ErrPrecision = errors.New("kdtree: coordinates not representable at precision")
+
+// FreezeQuantized returns a FrozenTree as for Freeze with the coordinates of the values held
+// as 32-bit fixed-point offsets from the least coordinate in each dimension, halving the
+// space used by coordinates. Coordinates are rounded to the nearest multiple of precision
+// from the least coordinate, so every coordinate used by queries lies within precision/2 of
+// the stored value and the order of the values in each dimension is preserved, although
+// values closer than precision may become equal. Queries are evaluated on the rounded
+// coordinates. Point values returned by queries on the tree are newly allocated from the
+// rounded coordinates; other values are returned as stored.
+//
+// For example, a precision of 1e-7 holds longitudes and latitudes in degrees to the nearest
+// centimetre. ErrPrecision is returned if precision is not positive and finite, or if the
+// range of coordinates in any dimension is not less than 2^32 times precision.
+func (t *Tree) FreezeQuantized(precision float64) (*FrozenTree, error) {
+	if !(precision > 0) || math.IsInf(precision, 1) {
+		return nil, ErrPrecision
+	}
+	f := t.thaw()
+	if f.count == 0 {
+		return f, nil
+	}
+	f.precision = precision
+	f.origin = make([]float64, f.dims)
+	for d := range f.origin {
+		min, max := inf, -inf
+		for i := 0; i < f.count; i++ {
+			x := f.coords[i*f.dims+d]
+			if math.IsNaN(x) {
+				return nil, ErrPrecision
+			}
+			min = math.Min(min, x)
+			max = math.Max(max, x)
+		}
+		if math.IsInf(min, 0) || math.IsInf(max, 0) || math.Floor((max-min)/precision+0.5) > math.MaxUint32 {
+			return nil, ErrPrecision
+		}
+		f.origin[d] = min
+	}
+
+	// Round the coordinates before the layout is determined
+	// so that splits agree with the coordinates of queries.
+	f.fixed = make([]uint32, len(f.coords))
+	for i, x := range f.coords {
+		f.fixed[i] = uint32(math.Floor((x-f.origin[i%f.dims])/precision + 0.5))
+	}
+	for i, q := range f.fixed {
+		f.coords[i] = f.origin[i%f.dims] + float64(q)*precision
+	}
+	f.layout(frozenBucket, nil)
+	f.coords = nil
+	return f, nil
+}
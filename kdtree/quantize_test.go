@@ -0,0 +1,79 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFreezeQuantized(c *check.C) {
+	const precision = 1e-7
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64()*360 - 180, rnd.Float64()*180 - 90}
+	}
+	t := New(append(Points(nil), p...), false)
+	f, err := t.FreezeQuantized(precision)
+	c.Assert(err, check.IsNil)
+	c.Check(f.Len(), check.Equals, len(p))
+	c.Check(f.coords, check.IsNil)
+	c.Check(f.fixed, check.HasLen, 2*len(p))
+
+	// Every rounded value lies within precision/2 of a
+	// stored value in each dimension.
+	var rounded Points
+	f.Do(func(v Comparable, _ *Bounding, _ int) bool {
+		rounded = append(rounded, v.(Point))
+		return false
+	})
+	c.Assert(rounded, check.HasLen, len(p))
+	for _, r := range rounded {
+		n, _ := t.Nearest(r)
+		for d, x := range r {
+			c.Check(math.Abs(x-n.(Point)[d]) <= precision/2*(1+1e-6), check.Equals, true)
+		}
+	}
+
+	// Queries agree with a tree of the rounded values.
+	rt := New(rounded, false)
+	for i := 0; i < 100; i++ {
+		q := Point{rnd.Float64()*360 - 180, rnd.Float64()*180 - 90}
+		c.Check(f.NearestN(q, 5), check.DeepEquals, rt.NearestN(q, 5), check.Commentf("Test %d", i))
+		v, d := f.Nearest(q)
+		ev, ed := rt.Nearest(q)
+		c.Check(v, check.DeepEquals, ev)
+		c.Check(d, check.Equals, ed)
+	}
+
+	for _, test := range []struct {
+		t         *Tree
+		precision float64
+	}{
+		{t: t, precision: 0},
+		{t: t, precision: math.NaN()},
+		{t: t, precision: math.Inf(1)},
+		{t: New(Points{{0}, {1e10}}, false), precision: precision},
+		{t: New(Points{{0}, {math.Inf(1)}}, false), precision: precision},
+	} {
+		_, err := test.t.FreezeQuantized(test.precision)
+		c.Check(err, check.Equals, ErrPrecision)
+	}
+}
+
+func (s *S) TestFreezeQuantizedValues(c *check.C) {
+	f, err := New(Data{
+		{Point: Point{2, 3}, Value: "a"},
+		{Point: Point{5, 4}, Value: "b"},
+		{Point: Point{9, 6}, Value: "c"},
+	}, false).FreezeQuantized(0.5)
+	c.Assert(err, check.IsNil)
+	v, d := f.Nearest(Point{8, 7.5})
+	c.Check(v.(Datum).Value, check.Equals, "c")
+	c.Check(d, check.Equals, 3.25)
+}
@@ -0,0 +1,287 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"reflect"
+	"sync"
+)
+
+var _ SpatialIndex = (*Rebuilder)(nil)
+
+// A Rebuilder is a SpatialIndex that keeps a tree balanced under insertion and removal by
+// rebuilding it in a background goroutine, so that no single operation bears the cost of a
+// full rebalance. Once the number of mutations since the last rebuild exceeds a threshold,
+// a new tree is built concurrently from the values the current tree was built from and the
+// mutations that followed. Queries and mutations continue against the current tree during
+// the build; mutations made while the build runs are replayed onto the new tree, which then
+// atomically replaces the current tree.
+//
+// A value removed from the index is identified by the rebuild by its coordinates and by
+// equality with the removed value, or deep equality if values of its type are not
+// comparable, so stored values must not be altered while they are held by the index.
+//
+// The Rebuilder is safe for concurrent use. A Rebuilder must not be copied after first use.
+type Rebuilder struct {
+	bounding  bool
+	threshold float64
+
+	mu   sync.RWMutex
+	tree *Tree
+
+	// values holds the values from which tree was
+	// built, and log holds the mutations since. The
+	// values are not altered once tree is built.
+	values []Comparable
+	log    []change
+
+	// done is closed when the running rebuild, if
+	// any, has replaced tree.
+	done     chan struct{}
+	rebuilds int
+}
+
+// change is a logged mutation of a Rebuilder.
+type change struct {
+	c      Comparable
+	insert bool
+}
+
+// NewRebuilder returns a Rebuilder holding the values of t, which must not be used
+// directly after the call. A rebuild is started once the number of insertions and removals
+// since the previous rebuild exceeds threshold times the number of values held after it.
+// Bounding volumes are determined for the nodes of rebuilt trees and maintained on
+// insertion as described for Tree.Insert if bounding is true and the values are Extenders.
+// The Augmenter of t, if any, is applied to rebuilt trees, which retain the Bloom filter of
+// t, and t's Observer, if any, is notified of each rebuild.
+func NewRebuilder(t *Tree, bounding bool, threshold float64) *Rebuilder {
+	return &Rebuilder{
+		bounding:  bounding,
+		threshold: threshold,
+		tree:      t,
+		values:    t.Points(),
+	}
+}
+
+// Insert adds c to the index. Insert returns ErrPointDims if c does not have the
+// dimensionality of the values already in the index.
+func (r *Rebuilder) Insert(c Comparable) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tree.Root != nil && r.tree.Dims() != c.Dims() {
+		return ErrPointDims
+	}
+	r.tree.Insert(c, r.bounding)
+	r.log = append(r.log, change{c: c, insert: true})
+	r.check()
+	return nil
+}
+
+// Remove removes a single value from the index that has the same coordinates as c,
+// returning whether a value was removed.
+func (r *Rebuilder) Remove(c Comparable) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tree.Root == nil || r.tree.Dims() != c.Dims() {
+		return false
+	}
+	path := r.tree.Root.findPath(c, nil, nil)
+	if path == nil {
+		return false
+	}
+	target := path[len(path)-1]
+	removed := target.Point
+	if !r.tree.detach(target) {
+		return false
+	}
+	r.log = append(r.log, change{c: removed})
+	r.check()
+	return true
+}
+
+// check starts a rebuild if none is running and the logged mutations exceed the threshold.
+func (r *Rebuilder) check() {
+	if r.done == nil && float64(len(r.log)) > r.threshold*float64(len(r.values)) {
+		r.start()
+	}
+}
+
+// start starts a rebuild of the tree in a new goroutine.
+func (r *Rebuilder) start() {
+	r.done = make(chan struct{})
+	values, log := r.values, r.log
+	r.log = nil
+	go r.rebuild(values, log, r.tree.Augmenter, r.done)
+}
+
+// rebuild builds a tree from values with the mutations in log applied, replays the
+// mutations logged since the rebuild was started and replaces the tree of r.
+func (r *Rebuilder) rebuild(values []Comparable, log []change, aug Augmenter, done chan struct{}) {
+	p := apply(values, log)
+	t := &Tree{}
+	if len(p) != 0 {
+		t = New(p, r.bounding && p.Bounds() != nil)
+	}
+	if aug != nil {
+		t.Augment(aug)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ch := range r.log {
+		if ch.insert {
+			t.Insert(ch.c, r.bounding)
+			continue
+		}
+		if target := t.Root.findIdentical(ch.c, nil); target != nil {
+			t.detach(target)
+		}
+	}
+	t.Observer, t.Bloom = r.tree.Observer, r.tree.Bloom
+	r.tree, r.values = t, p
+	r.rebuilds++
+	r.done = nil
+	close(done)
+	t.Observer.rebuilt(t)
+}
+
+// apply returns the values in values with the logged mutations in log applied.
+func apply(values []Comparable, log []change) comparables {
+	var removed comparables
+	p := make(comparables, 0, len(values)+len(log))
+	p = append(p, values...)
+	for _, ch := range log {
+		if ch.insert {
+			p = append(p, ch.c)
+		} else {
+			removed = append(removed, ch.c)
+		}
+	}
+	if len(removed) == 0 {
+		return p
+	}
+
+	// Each removed value cancels a single identical
+	// value; identical values are interchangeable so
+	// the order of the mutations does not matter.
+	rm := New(removed, false)
+	claimed := make(map[*Node]bool, len(removed))
+	kept := p[:0]
+	for _, v := range p {
+		if len(claimed) < len(removed) {
+			if n := rm.Root.findIdentical(v, claimed); n != nil {
+				claimed[n] = true
+				continue
+			}
+		}
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// findIdentical returns an unclaimed node in the subtree rooted at n holding a value
+// identical to c, or nil if there is none. Both subtrees are searched when c lies on the
+// splitting plane of a node.
+func (n *Node) findIdentical(c Comparable, claimed map[*Node]bool) *Node {
+	if n == nil {
+		return nil
+	}
+	if !claimed[n] && sameCoords(c, n.Point) && identical(c, n.Point) {
+		return n
+	}
+	cmp := c.Compare(n.Point, n.Plane)
+	if cmp <= 0 {
+		if m := n.Left.findIdentical(c, claimed); m != nil {
+			return m
+		}
+	}
+	if cmp >= 0 {
+		return n.Right.findIdentical(c, claimed)
+	}
+	return nil
+}
+
+// identical returns whether a and b are the same pointer, or deeply equal if they are not
+// pointers. Values are not compared with == unless they are pointers since a comparable
+// type, such as a struct with an interface field, may hold values that panic when compared.
+func identical(a, b Comparable) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta.Kind() == reflect.Ptr {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Rebuild starts a rebuild of the tree if none is running.
+func (r *Rebuilder) Rebuild() {
+	r.mu.Lock()
+	if r.done == nil {
+		r.start()
+	}
+	r.mu.Unlock()
+}
+
+// Wait waits for the running rebuild, if any, to complete.
+func (r *Rebuilder) Wait() {
+	r.mu.RLock()
+	done := r.done
+	r.mu.RUnlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// Rebuilds returns the number of rebuilds that have completed.
+func (r *Rebuilder) Rebuilds() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.rebuilds
+}
+
+// Height returns the height of the current tree.
+func (r *Rebuilder) Height() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.Height()
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (r *Rebuilder) Nearest(q Comparable) (Comparable, float64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.Nearest(q)
+}
+
+// NearestN returns the n nearest values to the query in min sorted order.
+func (r *Rebuilder) NearestN(q Comparable, n int) []ComparableDist {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.NearestN(q, n)
+}
+
+// InRange returns the values within distance d of the query in min sorted order.
+func (r *Rebuilder) InRange(q Comparable, d float64) []ComparableDist {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.InRange(q, d)
+}
+
+// DoBounded performs fn on all values within the specified bound, returning whether the
+// traversal was interrupted. fn must not mutate the index.
+func (r *Rebuilder) DoBounded(fn Operation, b *Bounding) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.DoBounded(fn, b)
+}
+
+// Len returns the number of values in the index.
+func (r *Rebuilder) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tree.Len()
+}
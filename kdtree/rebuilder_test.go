@@ -0,0 +1,102 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestRebuilder(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	r := NewRebuilder(&Tree{}, true, 0.5)
+	var want []Comparable
+	for i := 0; i < 2000; i++ {
+		// Sorted insertions degenerate an unbalanced tree.
+		p := Point{float64(i), float64(i)}
+		c.Assert(r.Insert(p), check.IsNil)
+		want = append(want, p)
+	}
+	c.Check(r.Insert(Point{1}), check.Equals, ErrPointDims)
+	for i := 0; i < len(want); i += 2 {
+		c.Check(r.Remove(want[i]), check.Equals, true)
+	}
+	c.Check(r.Remove(Point{0, 0}), check.Equals, false)
+	r.Wait()
+	r.Rebuild()
+	r.Wait()
+
+	var (
+		kept   []Comparable
+		points Points
+	)
+	for i := 1; i < len(want); i += 2 {
+		kept = append(kept, want[i])
+		points = append(points, want[i].(Point))
+	}
+	c.Check(r.Len(), check.Equals, len(kept))
+	c.Check(r.Rebuilds() > 1, check.Equals, true)
+	c.Check(r.Height() <= 3*int(math.Ceil(math.Log2(float64(len(kept))))), check.Equals, true)
+	var got []Comparable
+	r.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+		got = append(got, c)
+		return false
+	}, nil)
+	sort.Sort(lexOrder(got))
+	c.Check(got, check.DeepEquals, kept)
+	c.Check(r.tree.Root.partitioned(), check.Equals, true)
+	for i := 0; i < 100; i++ {
+		q := Point{rnd.Float64() * 2000, rnd.Float64() * 2000}
+		p, d := r.Nearest(q)
+		wp, wd := nearest(q, points)
+		c.Check(p, check.DeepEquals, wp)
+		c.Check(d, check.Equals, wd)
+	}
+}
+
+func (s *S) TestRebuilderIdentical(c *check.C) {
+	r := NewRebuilder(New(Data{
+		{Point: Point{1, 1}, Value: "a"},
+		{Point: Point{1, 1}, Value: "b"},
+		{Point: Point{2, 2}, Value: "c"},
+	}, false), false, 10)
+	c.Check(r.Remove(Point{1, 1}), check.Equals, true)
+	values := func() []interface{} {
+		var v []interface{}
+		r.DoBounded(func(c Comparable, _ *Bounding, _ int) bool {
+			v = append(v, c.(Datum).Value)
+			return false
+		}, nil)
+		sort.Sort(byString(v))
+		return v
+	}
+	before := values()
+	r.Rebuild()
+	r.Wait()
+	c.Check(r.Rebuilds(), check.Equals, 1)
+	c.Check(values(), check.DeepEquals, before)
+	c.Check(values(), check.HasLen, 2)
+
+	// Datum is comparable, but comparing Values holding
+	// slices with == panics.
+	r = NewRebuilder(New(Data{
+		{Point: Point{1, 1}, Value: []int{1}},
+		{Point: Point{1, 1}, Value: []int{2}},
+	}, false), false, 10)
+	c.Check(r.Remove(Point{1, 1}), check.Equals, true)
+	r.Rebuild()
+	r.Wait()
+	c.Check(r.Len(), check.Equals, 1)
+}
+
+// byString sorts string values.
+type byString []interface{}
+
+func (s byString) Len() int           { return len(s) }
+func (s byString) Less(i, j int) bool { return s[i].(string) < s[j].(string) }
+func (s byString) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
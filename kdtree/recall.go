@@ -0,0 +1,106 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// A SearchFunc returns the k nearest values to the query that it finds, in min sorted order.
+type SearchFunc func(q Comparable, k int) []ComparableDist
+
+// RecallStats holds the quality and latency of an approximate search measured by
+// EvaluateRecall.
+type RecallStats struct {
+	// Queries is the number of queries evaluated.
+	Queries int
+
+	// Recall is the mean over the queries of the fraction of the true k nearest
+	// neighbours returned by the approximate search, and MinRecall is the least recall of
+	// any query.
+	Recall    float64
+	MinRecall float64
+
+	// Mean, P50, P90, P99 and Max describe the distribution of the latency of the
+	// approximate search.
+	Mean, P50, P90, P99, Max time.Duration
+
+	// ExactMean is the mean latency of the exact search.
+	ExactMean time.Duration
+}
+
+// EvaluateRecall returns the recall and latency of the approximate search approx for the k
+// nearest neighbours of each of the queries, compared with exact searches of t, so that the
+// parameters of an approximate search can be chosen with data. Results are matched by
+// distance, so values with identical distances from a query are interchangeable. The zero
+// RecallStats is returned if there are no queries or k is not positive.
+func EvaluateRecall(t *Tree, approx SearchFunc, queries []Comparable, k int) RecallStats {
+	if len(queries) == 0 || k <= 0 {
+		return RecallStats{}
+	}
+	stats := RecallStats{Queries: len(queries), MinRecall: 1}
+	latency := make(durations, len(queries))
+	var exactTotal time.Duration
+	for i, q := range queries {
+		start := time.Now()
+		exact := t.NearestN(q, k)
+		exactTotal += time.Since(start)
+
+		start = time.Now()
+		got := approx(q, k)
+		latency[i] = time.Since(start)
+
+		r := 1.
+		if len(exact) != 0 {
+			kth := exact[len(exact)-1].Dist
+			var found int
+			for _, v := range got {
+				if v.Dist <= kth && found < len(exact) {
+					found++
+				}
+			}
+			r = float64(found) / float64(len(exact))
+		}
+		stats.Recall += r
+		if r < stats.MinRecall {
+			stats.MinRecall = r
+		}
+	}
+	stats.Recall /= float64(len(queries))
+
+	var total time.Duration
+	for _, d := range latency {
+		total += d
+	}
+	sort.Sort(latency)
+	stats.Mean = total / time.Duration(len(latency))
+	stats.P50 = latency.percentile(0.5)
+	stats.P90 = latency.percentile(0.9)
+	stats.P99 = latency.percentile(0.99)
+	stats.Max = latency[len(latency)-1]
+	stats.ExactMean = exactTotal / time.Duration(len(queries))
+	return stats
+}
+
+// durations is a sortable collection of latencies.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// percentile returns the nearest-rank p quantile of the sorted latencies.
+func (d durations) percentile(p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(d)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(d) {
+		i = len(d) - 1
+	}
+	return d[i]
+}
@@ -0,0 +1,81 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestEvaluateRecall(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	queries := []Comparable{Point{1, 1}, Point{5, 5}, Point{9, 9}, Point{6, 2}}
+	exact := func(q Comparable, k int) []ComparableDist { return t.NearestN(q, k) }
+
+	st := EvaluateRecall(t, exact, queries, 4)
+	c.Check(st.Queries, check.Equals, len(queries))
+	c.Check(st.Recall, check.Equals, 1.)
+	c.Check(st.MinRecall, check.Equals, 1.)
+	c.Check(st.P50 <= st.P90 && st.P90 <= st.P99 && st.P99 <= st.Max, check.Equals, true)
+	c.Check(st.Mean <= st.Max, check.Equals, true)
+
+	half := func(q Comparable, k int) []ComparableDist { return t.NearestN(q, k/2) }
+	st = EvaluateRecall(t, half, queries, 4)
+	c.Check(st.Recall, check.Equals, 0.5)
+	c.Check(st.MinRecall, check.Equals, 0.5)
+
+	// Returning any value more than once is not rewarded.
+	repeat := func(q Comparable, k int) []ComparableDist {
+		r := t.NearestN(q, 1)
+		return append(r, r[0], r[0], r[0])
+	}
+	st = EvaluateRecall(t, repeat, []Comparable{Point{9, 6}}, 4)
+	c.Check(st.Recall, check.Equals, 1.)
+
+	// The tree holds fewer than k values.
+	st = EvaluateRecall(t, exact, queries, 10)
+	c.Check(st.Recall, check.Equals, 1.)
+	st = EvaluateRecall(t, func(Comparable, int) []ComparableDist { return nil }, queries, 10)
+	c.Check(st.Recall, check.Equals, 0.)
+
+	c.Check(EvaluateRecall(t, exact, nil, 4), check.Equals, RecallStats{})
+	c.Check(EvaluateRecall(t, exact, queries, 0), check.Equals, RecallStats{})
+}
+
+func (s *S) TestEvaluateRecallForest(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	t := New(append(Points(nil), p...), false)
+	f := NewForest(p, 4, rnd)
+	queries := make([]Comparable, 50)
+	for i := range queries {
+		queries[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	var prev float64
+	for _, checks := range []int{1, 32, len(p)} {
+		st := EvaluateRecall(t, func(q Comparable, k int) []ComparableDist {
+			return f.NearestN(q, k, checks)
+		}, queries, 5)
+		c.Check(st.Recall >= prev, check.Equals, true,
+			check.Commentf("checks=%d recall %v", checks, st.Recall))
+		c.Check(st.MinRecall <= st.Recall, check.Equals, true)
+		c.Check(st.Max > time.Duration(0), check.Equals, true)
+		prev = st.Recall
+	}
+	c.Check(prev, check.Equals, 1.)
+}
+
+func (s *S) TestPercentile(c *check.C) {
+	d := durations{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	c.Check(d.percentile(0.5), check.Equals, time.Duration(5))
+	c.Check(d.percentile(0.9), check.Equals, time.Duration(9))
+	c.Check(d.percentile(0.99), check.Equals, time.Duration(10))
+	c.Check(d.percentile(0), check.Equals, time.Duration(1))
+}
@@ -0,0 +1,218 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A Move is a change of a stored value from Old to New.
+type Move struct {
+	Old, New Comparable
+}
+
+// Refresh applies the moves in one pass, replacing a stored value with the coordinates of
+// each Old value by its New value, and returns the number of moves applied. Moves whose Old
+// value is not found are ignored.
+//
+// A moved value that remains correctly placed with respect to the splitting planes of its
+// node's ancestors and descendants is replaced in place, and a handle referring to it
+// remains valid. Otherwise the smallest subtree on the path to the node whose region holds
+// the New value is rebuilt, with nested rebuilds merged, and handles referring to values in
+// rebuilt subtrees are invalidated. Bounding volumes and summaries are recalculated for the
+// affected nodes, and the tree's Observer is notified of the removal of each Old value and
+// the insertion of each New value. Refresh panics with ErrDimsMismatch if a New value does
+// not have the dimensionality of the values in the tree.
+func (t *Tree) Refresh(moves []Move) int {
+	if t.Root == nil || len(moves) == 0 {
+		return 0
+	}
+	for _, m := range moves {
+		t.mustMatch(m.New)
+	}
+
+	type pending struct {
+		path []*Node
+		new  Comparable
+	}
+	var (
+		claimed = make(map[*Node]bool)
+		touched = make(map[*Node]bool)
+		moved   []pending
+		applied int
+	)
+	for _, m := range moves {
+		path := t.Root.findPath(m.Old, claimed, nil)
+		if path == nil {
+			continue
+		}
+		applied++
+		n := path[len(path)-1]
+		claimed[n] = true
+		if !inRegion(m.New, path) || !n.accepts(m.New) {
+			moved = append(moved, pending{path: path, new: m.New})
+			continue
+		}
+		for _, a := range path {
+			touched[a] = true
+		}
+		t.removed(n.Point)
+		n.Point = m.New
+		t.inserted(m.New)
+	}
+
+	// Find the root of the smallest subtree holding the
+	// new value of each value that could not be moved in
+	// place, after all the in place moves are complete.
+	var (
+		depth   = make(map[*Node]int)
+		replace = make(map[*Node]Comparable)
+	)
+	for i, p := range moved {
+		k := len(p.path) - 1
+		for !inRegion(p.new, p.path[:k+1]) {
+			k--
+		}
+		moved[i].path = p.path[:k+1]
+		depth[p.path[k]] = k
+		replace[p.path[len(p.path)-1]] = p.new
+	}
+
+	// Rebuild the outermost of the subtrees.
+	f := t.fixup()
+	rebuilt := make(map[*Node]bool)
+outer:
+	for _, p := range moved {
+		k := len(p.path) - 1
+		n := p.path[k]
+		if rebuilt[n] {
+			continue
+		}
+		for _, a := range p.path[:k] {
+			if _, ok := depth[a]; ok {
+				continue outer
+			}
+		}
+		rebuilt[n] = true
+		for _, a := range p.path[:k] {
+			touched[a] = true
+		}
+
+		var values comparables
+		n.doNodes(func(m *Node) {
+			if m.handle != nil {
+				m.handle.node = nil
+				m.handle = nil
+			}
+			v, ok := replace[m]
+			if ok {
+				t.removed(m.Point)
+				t.inserted(v)
+			} else {
+				v = m.Point
+			}
+			values = append(values, v)
+		})
+		parent := Dim(-1)
+		if k > 0 {
+			parent = p.path[k-1].Plane
+		}
+		var sub *Node
+		if f.bounding && values.Bounds() != nil {
+			sub = buildBounded(values, Cycle, parent, true)
+		} else {
+			sub = build(values, Cycle, parent)
+			f.bounding = false
+		}
+		if t.Augmenter != nil {
+			sub.augment(t.Augmenter)
+		}
+		switch {
+		case k == 0:
+			t.Root = sub
+		case p.path[k-1].Left == n:
+			p.path[k-1].Left = sub
+		default:
+			p.path[k-1].Right = sub
+		}
+	}
+
+	t.Root.refix(touched, f)
+	if !f.bounding {
+		t.Root.Bounding = nil
+	}
+	return applied
+}
+
+// findPath returns the path from n to an unclaimed node holding a value with the same
+// coordinates as c, appended to path, or nil if there is none. Both subtrees are searched
+// when c lies on the splitting plane of a node.
+func (n *Node) findPath(c Comparable, claimed map[*Node]bool, path []*Node) []*Node {
+	if n == nil {
+		return nil
+	}
+	path = append(path, n)
+	if !claimed[n] && sameCoords(c, n.Point) {
+		return path
+	}
+	cmp := c.Compare(n.Point, n.Plane)
+	if cmp <= 0 {
+		if p := n.Left.findPath(c, claimed, path); p != nil {
+			return p
+		}
+	}
+	if cmp >= 0 {
+		return n.Right.findPath(c, claimed, path)
+	}
+	return nil
+}
+
+// inRegion returns whether c lies on the side of the splitting plane of each node of path
+// that leads to the following node. Values on a splitting plane belong to the left subtree,
+// as for Insert and Remove.
+func inRegion(c Comparable, path []*Node) bool {
+	for i, a := range path[:len(path)-1] {
+		cmp := c.Compare(a.Point, a.Plane)
+		if (path[i+1] == a.Left && cmp > 0) || (path[i+1] == a.Right && cmp <= 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// accepts returns whether the subtrees of n are partitioned by c on the plane of n, with
+// values on the plane in the left subtree.
+func (n *Node) accepts(c Comparable) bool {
+	if m := n.Left.maxOn(n.Plane); m != nil && m.Point.Compare(c, n.Plane) > 0 {
+		return false
+	}
+	if m := n.Right.minOn(n.Plane); m != nil && m.Point.Compare(c, n.Plane) <= 0 {
+		return false
+	}
+	return true
+}
+
+// minOn returns the node in the subtree rooted at n with the least value in dimension d.
+func (n *Node) minOn(d Dim) *Node {
+	if n == nil {
+		return nil
+	}
+	m := n
+	if l := n.Left.minOn(d); l != nil && l.Point.Compare(m.Point, d) < 0 {
+		m = l
+	}
+	if n.Plane != d {
+		if r := n.Right.minOn(d); r != nil && r.Point.Compare(m.Point, d) < 0 {
+			m = r
+		}
+	}
+	return m
+}
+
+// refix updates the touched nodes of the subtree rooted at n in post-order.
+func (n *Node) refix(touched map[*Node]bool, f *fixup) {
+	if n == nil || !touched[n] {
+		return
+	}
+	n.Left.refix(touched, f)
+	n.Right.refix(touched, f)
+	f.update(n)
+}
@@ -0,0 +1,119 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+// partitioned returns whether every value in the subtree rooted at n lies on the correct
+// side of the splitting planes of its ancestors.
+func (n *Node) partitioned() bool {
+	if n == nil {
+		return true
+	}
+	if m := n.Left.maxOn(n.Plane); m != nil && m.Point.Compare(n.Point, n.Plane) > 0 {
+		return false
+	}
+	if m := n.Right.minOn(n.Plane); m != nil && m.Point.Compare(n.Point, n.Plane) < 0 {
+		return false
+	}
+	return n.Left.partitioned() && n.Right.partitioned()
+}
+
+func (s *S) TestRefresh(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, bounding := range []bool{false, true} {
+		points := make(Points, len(bData))
+		for i, p := range bData {
+			points[i] = append(Point(nil), p...)
+		}
+		t := New(append(Points(nil), points...), bounding)
+		t.Augment(counter{})
+		h := t.InsertHandle(Point{0.5, 0.5, 0.5}, bounding)
+		points = append(points, Point{0.5, 0.5, 0.5})
+
+		for tick := 0; tick < 10; tick++ {
+			var moves []Move
+			for i, p := range points {
+				if rnd.Intn(3) == 0 {
+					continue
+				}
+				q := append(Point(nil), p...)
+				scale := 0.001
+				if rnd.Intn(10) == 0 {
+					scale = 0.5
+				}
+				for d := range q {
+					q[d] += rnd.NormFloat64() * scale
+				}
+				moves = append(moves, Move{Old: p, New: q})
+				points[i] = q
+			}
+			moves = append(moves, Move{Old: Point{-10, -10, -10}, New: Point{0, 0, 0}})
+			c.Check(t.Refresh(moves), check.Equals, len(moves)-1)
+			c.Check(t.Count, check.Equals, len(points))
+			c.Check(t.Root.partitioned(), check.Equals, true)
+			checkCounts(c, t)
+			if bounding {
+				c.Check(t.Root.isContainedBy(t.Root.Bounding), check.Equals, true)
+				c.Check(t.Bounds(), check.DeepEquals, New(append(Points(nil), points...), true).Root.Bounding)
+			}
+
+			got := t.Points()
+			sort.Sort(byCoords(got))
+			want := make([]Comparable, len(points))
+			for i, p := range points {
+				want[i] = p
+			}
+			sort.Sort(byCoords(want))
+			c.Check(got, check.DeepEquals, want)
+			for _, p := range points[:10] {
+				nn, d := t.Nearest(p)
+				c.Check(d, check.Equals, 0.)
+				c.Check(nn, check.DeepEquals, p)
+			}
+		}
+		if h.Valid() {
+			c.Check(h.Point(), check.DeepEquals, points[len(points)-1])
+		}
+	}
+
+	// A small move is made in place.
+	t := New(append(Points(nil), wpData...), false)
+	h := t.InsertHandle(Point{8, 3}, false)
+	c.Check(t.Refresh([]Move{{Old: Point{8, 3}, New: Point{7.5, 3}}}), check.Equals, 1)
+	c.Check(h.Valid(), check.Equals, true)
+	c.Check(h.Point(), check.DeepEquals, Point{7.5, 3})
+
+	// A large move rebuilds the subtree.
+	c.Check(t.Refresh([]Move{{Old: Point{7.5, 3}, New: Point{0, 0}}}), check.Equals, 1)
+	c.Check(h.Valid(), check.Equals, false)
+	c.Check(t.Root.partitioned(), check.Equals, true)
+	nn, _ := t.Nearest(Point{0.1, 0.1})
+	c.Check(nn, check.DeepEquals, Point{0, 0})
+
+	// A move onto a splitting plane places the value on the
+	// left of the plane, where Remove looks for it.
+	for _, move := range []Move{
+		{Old: Point{10, 10}, New: Point{5, 10}},
+		{Old: Point{0, 0}, New: Point{5, 0}},
+	} {
+		t = &Tree{}
+		for _, p := range []Point{{5, 5}, {0, 0}, {10, 10}} {
+			t.Insert(p, true)
+		}
+		c.Check(t.Refresh([]Move{move}), check.Equals, 1)
+		c.Check(t.Root.isKDTree(), check.Equals, true, check.Commentf("%v", move))
+		c.Check(t.Remove(move.New), check.Equals, true, check.Commentf("%v", move))
+		c.Check(t.Count, check.Equals, 2)
+	}
+
+	c.Check((&Tree{}).Refresh([]Move{{Old: Point{0}, New: Point{1}}}), check.Equals, 0)
+	c.Check(func() { t.Refresh([]Move{{Old: Point{0, 0}, New: Point{1}}}) }, check.PanicMatches, ErrDimsMismatch.Error())
+}
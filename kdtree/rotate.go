@@ -0,0 +1,294 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"sort"
+)
+
+var _ SpatialIndex = (*Rotated)(nil)
+
+// A Rotation is a rigid transformation of a space, mapping x to the coordinates of x-Mean
+// along each of the orthonormal Axes. Since a Rotation preserves Euclidean distances, a
+// tree holding rotated values answers distance queries about the original values.
+type Rotation struct {
+	Mean Point
+	Axes []Point
+}
+
+// Apply returns the coordinates of c under r. c must be of a type accepted by
+// Point.Compare.
+func (r *Rotation) Apply(c Comparable) Point {
+	x := make(Point, len(r.Mean))
+	for d := range x {
+		x[d] = at(c, Dim(d)) - r.Mean[d]
+	}
+	p := make(Point, len(r.Axes))
+	for i, a := range r.Axes {
+		for d, v := range a {
+			p[i] += v * x[d]
+		}
+	}
+	return p
+}
+
+// PCA returns the Rotation onto the principal axes of the values in p, in order of
+// decreasing variance. The values must be of a type accepted by Point.Compare. PCA returns
+// nil if p is empty.
+func PCA(p Interface) *Rotation {
+	if p.Len() == 0 {
+		return nil
+	}
+	dims := p.Index(0).Dims()
+	mean := make(Point, dims)
+	for i := 0; i < p.Len(); i++ {
+		for d := range mean {
+			mean[d] += at(p.Index(i), Dim(d))
+		}
+	}
+	for d := range mean {
+		mean[d] /= float64(p.Len())
+	}
+	cov := make([][]float64, dims)
+	for i := range cov {
+		cov[i] = make([]float64, dims)
+	}
+	x := make([]float64, dims)
+	for i := 0; i < p.Len(); i++ {
+		for d := range x {
+			x[d] = at(p.Index(i), Dim(d)) - mean[d]
+		}
+		for j := range cov {
+			for k := range cov[j] {
+				cov[j][k] += x[j] * x[k]
+			}
+		}
+	}
+	values, vectors := jacobi(cov)
+	order := make([]int, dims)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Sort(byValue{order: order, values: values})
+	r := &Rotation{Mean: mean, Axes: make([]Point, dims)}
+	for i, col := range order {
+		a := make(Point, dims)
+		for d := range a {
+			a[d] = vectors[d][col]
+		}
+		r.Axes[i] = a
+	}
+	return r
+}
+
+type byValue struct {
+	order  []int
+	values []float64
+}
+
+func (v byValue) Len() int           { return len(v.order) }
+func (v byValue) Less(i, j int) bool { return v.values[v.order[i]] > v.values[v.order[j]] }
+func (v byValue) Swap(i, j int)      { v.order[i], v.order[j] = v.order[j], v.order[i] }
+
+// jacobi returns the eigenvalues and the eigenvectors, held in columns, of the symmetric
+// matrix a using the cyclic Jacobi method. a is overwritten.
+func jacobi(a [][]float64) (values []float64, vectors [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		var off, diag float64
+		for i := range a {
+			diag += a[i][i] * a[i][i]
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off <= 1e-30*diag || off == 0 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	values = make([]float64, n)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return values, v
+}
+
+// A Rotated is a SpatialIndex holding values in a rotated space, such as the space of
+// their principal axes, improving the choice of splitting planes and the pruning of
+// searches for correlated high-dimensional data. Values are passed to and returned from
+// the index unchanged, and distances are the squared Euclidean distances of the values,
+// which are preserved by the rotation up to rounding. Ties between values at equal distances
+// are broken by their rotated coordinates. Values must be of a type accepted by Point.Compare.
+type Rotated struct {
+	tree *Tree
+	rot  *Rotation
+}
+
+// NewRotated returns a Rotated index constructed from the values in p rotated by r. If r
+// is nil, the rotation returned by PCA(p) is used. p is not altered.
+func NewRotated(p Interface, r *Rotation) *Rotated {
+	if r == nil {
+		r = PCA(p)
+	}
+	values := make(comparables, p.Len())
+	for i := range values {
+		values[i] = r.value(p.Index(i))
+	}
+	return &Rotated{tree: New(values, false), rot: r}
+}
+
+// Rotation returns the rotation used by the index.
+func (t *Rotated) Rotation() *Rotation { return t.rot }
+
+// rotatedValue is a value held in rotated space.
+type rotatedValue struct {
+	Point
+	orig Comparable
+}
+
+func (v rotatedValue) coord(d Dim) float64 { return v.Point[d] }
+
+func (r *Rotation) value(c Comparable) rotatedValue {
+	return rotatedValue{Point: r.Apply(c), orig: c}
+}
+
+// Len returns the number of values in the index.
+func (t *Rotated) Len() int { return t.tree.Len() }
+
+// Insert adds c to the index. Insert returns ErrPointDims if c does not have the
+// dimensionality of the rotation.
+func (t *Rotated) Insert(c Comparable) error {
+	if t.rot == nil {
+		t.rot = identity(c.Dims())
+	}
+	if c.Dims() != len(t.rot.Mean) {
+		return ErrPointDims
+	}
+	t.tree.Insert(t.rot.value(c), false)
+	return nil
+}
+
+// identity returns the identity Rotation of a space with the given dimensionality.
+func identity(dims int) *Rotation {
+	r := &Rotation{Mean: make(Point, dims), Axes: make([]Point, dims)}
+	for i := range r.Axes {
+		r.Axes[i] = make(Point, dims)
+		r.Axes[i][i] = 1
+	}
+	return r
+}
+
+// Remove removes a single value from the index that has the same coordinates as c,
+// returning whether a value was removed.
+func (t *Rotated) Remove(c Comparable) bool {
+	if t.rot == nil || c.Dims() != len(t.rot.Mean) {
+		return false
+	}
+	return t.tree.Remove(t.rot.value(c))
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Rotated) Nearest(q Comparable) (Comparable, float64) {
+	if t.tree.Root == nil {
+		return nil, inf
+	}
+	c, d := t.tree.Nearest(t.rot.Apply(q))
+	return c.(rotatedValue).orig, d
+}
+
+// NearestN returns the n nearest values to the query in min sorted order.
+func (t *Rotated) NearestN(q Comparable, n int) []ComparableDist {
+	if t.tree.Root == nil {
+		return nil
+	}
+	return unrotate(t.tree.NearestN(t.rot.Apply(q), n))
+}
+
+// InRange returns the values within distance d of the query in min sorted order.
+func (t *Rotated) InRange(q Comparable, d float64) []ComparableDist {
+	if t.tree.Root == nil {
+		return nil
+	}
+	return unrotate(t.tree.InRange(t.rot.Apply(q), d))
+}
+
+func unrotate(h []ComparableDist) []ComparableDist {
+	for i, c := range h {
+		h[i].Comparable = c.Comparable.(rotatedValue).orig
+	}
+	return h
+}
+
+// DoBounded performs fn on all values within the bound b, which is given in the original
+// space, returning whether the traversal was interrupted. The rotated tree is searched
+// within the bounding box of the rotated bound. If b is nil, all values are visited. The
+// Bounding passed to fn is b and values are not visited in any particular order.
+func (t *Rotated) DoBounded(fn Operation, b *Bounding) bool {
+	if t.tree.Root == nil {
+		return false
+	}
+	visit := func(c Comparable, _ *Bounding, depth int) bool {
+		return fn(c.(rotatedValue).orig, b, depth)
+	}
+	if b == nil {
+		return t.tree.Do(visit)
+	}
+	dims := len(t.rot.Mean)
+	lo, hi := make(Point, len(t.rot.Axes)), make(Point, len(t.rot.Axes))
+	for i, a := range t.rot.Axes {
+		for d := 0; d < dims; d++ {
+			l := a[d] * (at(b[0], Dim(d)) - t.rot.Mean[d])
+			h := a[d] * (at(b[1], Dim(d)) - t.rot.Mean[d])
+			lo[i] += math.Min(l, h)
+			hi[i] += math.Max(l, h)
+		}
+		// Widen the box to allow for rounding in the rotation of values
+		// lying on the bound; values outside b are filtered below.
+		eps := 1e-9 * (math.Abs(lo[i]) + math.Abs(hi[i]) + 1)
+		lo[i] -= eps
+		hi[i] += eps
+	}
+	return t.tree.DoBounded(func(c Comparable, _ *Bounding, depth int) bool {
+		v := c.(rotatedValue).orig
+		for d := 0; d < dims; d++ {
+			if x := at(v, Dim(d)); x < at(b[0], Dim(d)) || x > at(b[1], Dim(d)) {
+				return false
+			}
+		}
+		return fn(v, b, depth)
+	}, &Bounding{lo, hi})
+}
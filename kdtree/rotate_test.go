@@ -0,0 +1,115 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+// correlated returns n points in 4 dimensions lying close to the line through the origin
+// in the direction (1, 1, 1, 1).
+func correlated(rnd *rand.Rand, n int) Points {
+	p := make(Points, n)
+	for i := range p {
+		t := rnd.NormFloat64() * 10
+		p[i] = Point{t + rnd.NormFloat64()*0.1 + 1, t + rnd.NormFloat64()*0.1 + 2, t + rnd.NormFloat64()*0.1 + 3, t + rnd.NormFloat64()*0.1 + 4}
+	}
+	return p
+}
+
+func (s *S) TestPCA(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := correlated(rnd, 2000)
+	r := PCA(p)
+	c.Assert(r, check.NotNil)
+	for d, m := range r.Mean {
+		c.Check(math.Abs(m-float64(d+1)) < 1, check.Equals, true)
+	}
+	for i, a := range r.Axes {
+		for j, b := range r.Axes {
+			var dot float64
+			for d := range a {
+				dot += a[d] * b[d]
+			}
+			want := 0.
+			if i == j {
+				want = 1
+			}
+			c.Check(math.Abs(dot-want) < 1e-9, check.Equals, true)
+		}
+	}
+	for _, v := range r.Axes[0] {
+		c.Check(math.Abs(math.Abs(v)-0.5) < 0.01, check.Equals, true)
+	}
+
+	// Rotations preserve distances.
+	a, b := r.Apply(p[0]), r.Apply(p[1])
+	c.Check(math.Abs(a.Distance(b)-p[0].Distance(p[1])) < 1e-9, check.Equals, true)
+	c.Check(PCA(Points{}), check.IsNil)
+}
+
+func (s *S) TestRotated(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := correlated(rnd, 1000)
+	t := NewRotated(p, nil)
+	c.Check(t.Len(), check.Equals, len(p))
+	ref := New(append(Points(nil), p...), false)
+
+	near := func(a, b float64) bool { return math.Abs(a-b) <= 1e-9*(1+math.Abs(b)) }
+	for i := 0; i < 20; i++ {
+		q := correlated(rnd, 1)[0]
+		got, d := t.Nearest(q)
+		want, wd := ref.Nearest(q)
+		c.Check(got, check.DeepEquals, want)
+		c.Check(near(d, wd), check.Equals, true)
+
+		gn, wn := t.NearestN(q, 5), ref.NearestN(q, 5)
+		c.Assert(gn, check.HasLen, 5)
+		for j := range gn {
+			c.Check(gn[j].Comparable, check.DeepEquals, wn[j].Comparable)
+			c.Check(near(gn[j].Dist, wn[j].Dist), check.Equals, true)
+		}
+		c.Check(t.InRange(q, 1), check.HasLen, len(ref.InRange(q, 1)))
+
+		b := &Bounding{Point{q[0] - 3, q[1] - 3, q[2] - 3, q[3] - 3}, Point{q[0] + 3, q[1] + 3, q[2] + 3, q[3] + 3}}
+		var inside, visited int
+		ref.DoBounded(func(Comparable, *Bounding, int) bool { inside++; return false }, b)
+		t.DoBounded(func(v Comparable, vb *Bounding, _ int) bool {
+			c.Check(b.Contains(v), check.Equals, true)
+			c.Check(vb, check.Equals, b)
+			visited++
+			return false
+		}, b)
+		c.Check(visited, check.Equals, inside)
+	}
+	var n int
+	t.DoBounded(func(Comparable, *Bounding, int) bool { n++; return false }, nil)
+	c.Check(n, check.Equals, len(p))
+
+	c.Check(t.Remove(p[10]), check.Equals, true)
+	c.Check(t.Len(), check.Equals, len(p)-1)
+	c.Check(t.Insert(Point{1}), check.Equals, ErrPointDims)
+	c.Check(t.Insert(p[10]), check.IsNil)
+	got, d := t.Nearest(p[10])
+	c.Check(got, check.DeepEquals, p[10])
+	c.Check(d < 1e-20, check.Equals, true)
+
+	// A supplied rotation is used in place of PCA.
+	e := NewRotated(Points{}, nil)
+	c.Check(e.Rotation(), check.IsNil)
+	v, _ := e.Nearest(Point{0, 0})
+	c.Check(v, check.IsNil)
+	c.Check(e.Insert(Point{1, 2}), check.IsNil)
+	c.Check(e.Rotation(), check.DeepEquals, &Rotation{Mean: Point{0, 0}, Axes: []Point{{1, 0}, {0, 1}}})
+	theta := math.Pi / 6
+	r := &Rotation{Mean: Point{1, 1}, Axes: []Point{{math.Cos(theta), math.Sin(theta)}, {-math.Sin(theta), math.Cos(theta)}}}
+	t = NewRotated(append(Points(nil), wpData...), r)
+	c.Check(t.Rotation(), check.Equals, r)
+	got, _ = t.Nearest(Point{8, 1.2})
+	c.Check(got, check.DeepEquals, Point{8, 1})
+}
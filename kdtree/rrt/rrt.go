@@ -0,0 +1,149 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rrt provides a k-d tree vertex index for rapidly-exploring random tree (RRT and
+// RRT*) motion planners.
+//
+// An Index holds the vertices of a planning tree, supporting the queries made by each
+// planner iteration: the nearest vertex to a random sample, the vertices within the
+// rewiring radius of a new state, and insertion of the new state as a vertex. Vertices are
+// held by a kdtree.Dynamic, so the index remains balanced as the planning tree grows.
+package rrt
+
+import (
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Vertex is a state of a planning tree.
+type Vertex struct {
+	// State is the location of the vertex in the state space.
+	State kdtree.Point
+
+	// Parent is the vertex from which the vertex was reached.
+	// Parent is nil for the root of the planning tree.
+	Parent *Vertex
+
+	// Cost is the cost of the path from the root to the vertex.
+	Cost float64
+
+	// Value is a value associated with the vertex by the planner.
+	Value interface{}
+}
+
+func state(c kdtree.Comparable) kdtree.Comparable {
+	if v, ok := c.(*Vertex); ok {
+		return v.State
+	}
+	return c
+}
+
+// Compare satisfies the Compare method of the kdtree.Comparable interface.
+func (v *Vertex) Compare(c kdtree.Comparable, d kdtree.Dim) float64 {
+	return v.State.Compare(state(c), d)
+}
+
+// Dims satisfies the Dims method of the kdtree.Comparable interface.
+func (v *Vertex) Dims() int { return len(v.State) }
+
+// Distance satisfies the Distance method of the kdtree.Comparable interface.
+func (v *Vertex) Distance(c kdtree.Comparable) float64 { return v.State.Distance(state(c)) }
+
+// Path returns the states of the vertices on the path from the root of the planning tree
+// to v.
+func (v *Vertex) Path() []kdtree.Point {
+	var n int
+	for u := v; u != nil; u = u.Parent {
+		n++
+	}
+	path := make([]kdtree.Point, n)
+	for u := v; u != nil; u = u.Parent {
+		n--
+		path[n] = u.State
+	}
+	return path
+}
+
+// An Index is a vertex index for a planning tree.
+type Index struct {
+	t        *kdtree.Dynamic
+	vertices []*Vertex
+}
+
+// NewIndex returns an empty Index.
+func NewIndex() *Index {
+	return &Index{t: kdtree.NewDynamic(false)}
+}
+
+// Len returns the number of vertices in the index.
+func (ix *Index) Len() int { return len(ix.vertices) }
+
+// Vertices returns the vertices of the index in the order they were inserted. The returned
+// slice must not be altered.
+func (ix *Index) Vertices() []*Vertex { return ix.vertices }
+
+// Insert adds a vertex at state reached from parent with the given path cost and returns
+// it. Insert returns kdtree.ErrPointDims if state does not have the dimensionality of the
+// vertices already in the index.
+func (ix *Index) Insert(state kdtree.Point, parent *Vertex, cost float64) (*Vertex, error) {
+	v := &Vertex{State: state, Parent: parent, Cost: cost}
+	err := ix.t.Insert(v)
+	if err != nil {
+		return nil, err
+	}
+	ix.vertices = append(ix.vertices, v)
+	return v, nil
+}
+
+// Nearest returns the vertex nearest to the sample and its Euclidean distance from the
+// sample, or nil if the index is empty.
+func (ix *Index) Nearest(sample kdtree.Point) (*Vertex, float64) {
+	if len(ix.vertices) == 0 {
+		return nil, math.Inf(1)
+	}
+	c, d := ix.t.Nearest(&Vertex{State: sample})
+	return c.(*Vertex), math.Sqrt(d)
+}
+
+// Near returns the vertices within Euclidean distance radius of the state, in order of
+// increasing distance.
+func (ix *Index) Near(state kdtree.Point, radius float64) []*Vertex {
+	if len(ix.vertices) == 0 {
+		return nil
+	}
+	nd := ix.t.InRange(&Vertex{State: state}, radius*radius)
+	near := make([]*Vertex, len(nd))
+	for i, c := range nd {
+		near[i] = c.Comparable.(*Vertex)
+	}
+	return near
+}
+
+// Steer returns the state reached by moving from toward to by at most step.
+func Steer(from, to kdtree.Point, step float64) kdtree.Point {
+	d := math.Sqrt(from.Distance(to))
+	s := make(kdtree.Point, len(from))
+	if d <= step {
+		copy(s, to)
+		return s
+	}
+	f := step / d
+	for i := range s {
+		s[i] = from[i] + f*(to[i]-from[i])
+	}
+	return s
+}
+
+// RewireRadius returns the RRT* rewiring radius for an index holding n vertices in a state
+// space with the given dimensionality, min(gamma*(log(n)/n)^(1/dims), step). gamma scales
+// the radius with the volume of the free state space and step is the maximum steering
+// distance.
+func RewireRadius(gamma, step float64, n, dims int) float64 {
+	if n < 2 {
+		return step
+	}
+	r := gamma * math.Pow(math.Log(float64(n))/float64(n), 1/float64(dims))
+	return math.Min(r, step)
+}
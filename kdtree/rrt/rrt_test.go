@@ -0,0 +1,100 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rrt
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestIndex(c *check.C) {
+	ix := NewIndex()
+	v, d := ix.Nearest(kdtree.Point{0, 0})
+	c.Check(v, check.IsNil)
+	c.Check(math.IsInf(d, 1), check.Equals, true)
+	c.Check(ix.Near(kdtree.Point{0, 0}, 1), check.HasLen, 0)
+
+	rnd := rand.New(rand.NewSource(1))
+	root, err := ix.Insert(kdtree.Point{0, 0}, nil, 0)
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 500; i++ {
+		p := kdtree.Point{rnd.Float64()*10 - 5, rnd.Float64()*10 - 5}
+		parent, _ := ix.Nearest(p)
+		s := Steer(parent.State, p, 0.5)
+		_, err := ix.Insert(s, parent, parent.Cost+math.Sqrt(parent.State.Distance(s)))
+		c.Assert(err, check.IsNil)
+	}
+	c.Check(ix.Len(), check.Equals, 501)
+	c.Check(ix.Vertices()[0], check.Equals, root)
+
+	_, err = ix.Insert(kdtree.Point{0}, nil, 0)
+	c.Check(err, check.Equals, kdtree.ErrPointDims)
+
+	for i := 0; i < 20; i++ {
+		q := kdtree.Point{rnd.Float64()*10 - 5, rnd.Float64()*10 - 5}
+		var (
+			want     *Vertex
+			wantDist = math.Inf(1)
+			near     = make(map[*Vertex]bool)
+		)
+		for _, v := range ix.Vertices() {
+			d := math.Sqrt(v.State.Distance(q))
+			if d < wantDist {
+				want, wantDist = v, d
+			}
+			if d <= 1 {
+				near[v] = true
+			}
+		}
+		got, d := ix.Nearest(q)
+		c.Check(d, check.Equals, wantDist)
+		c.Check(got.State, check.DeepEquals, want.State)
+
+		res := ix.Near(q, 1)
+		c.Check(res, check.HasLen, len(near))
+		for j, v := range res {
+			c.Check(near[v], check.Equals, true)
+			if j != 0 {
+				c.Check(v.Distance(q) >= res[j-1].Distance(q), check.Equals, true)
+			}
+		}
+	}
+}
+
+func (s *S) TestPath(c *check.C) {
+	a := &Vertex{State: kdtree.Point{0}}
+	b := &Vertex{State: kdtree.Point{1}, Parent: a}
+	d := &Vertex{State: kdtree.Point{2}, Parent: b}
+	c.Check(d.Path(), check.DeepEquals, []kdtree.Point{{0}, {1}, {2}})
+	c.Check(a.Path(), check.DeepEquals, []kdtree.Point{{0}})
+}
+
+func (s *S) TestSteer(c *check.C) {
+	c.Check(Steer(kdtree.Point{0, 0}, kdtree.Point{3, 4}, 10), check.DeepEquals, kdtree.Point{3, 4})
+	c.Check(Steer(kdtree.Point{0, 0}, kdtree.Point{3, 4}, 2.5), check.DeepEquals, kdtree.Point{1.5, 2})
+}
+
+func (s *S) TestRewireRadius(c *check.C) {
+	c.Check(RewireRadius(10, 1, 1, 2), check.Equals, 1.)
+	c.Check(RewireRadius(10, 100, 100, 2), check.Equals, 10*math.Sqrt(math.Log(100)/100))
+	var last = math.Inf(1)
+	for n := 10; n < 1e6; n *= 10 {
+		r := RewireRadius(10, 100, n, 3)
+		c.Check(r < last, check.Equals, true)
+		last = r
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math/rand"
+
+// Counter is an Augmenter whose summaries are the number of values held by each subtree, as
+// an int. Trees augmented by a Counter are sampled by Sample and SampleBounded without
+// visiting every candidate value.
+type Counter struct{}
+
+// Summarize returns the number of values in a subtree.
+func (Counter) Summarize(_ Comparable, left, right interface{}) interface{} {
+	n := 1
+	if left != nil {
+		n += left.(int)
+	}
+	if right != nil {
+		n += right.(int)
+	}
+	return n
+}
+
+// Sample returns k values chosen uniformly at random without replacement from the values
+// stored in the tree, in the order they were drawn. If the tree holds no more than k values,
+// all the values are returned in a random order. If the tree is augmented by a Counter, each
+// value is drawn by a single descent of the tree guided by the sizes of its subtrees;
+// otherwise all the values are visited.
+func (t *Tree) Sample(k int, rnd *rand.Rand) []Comparable {
+	return t.SampleBounded(k, nil, rnd)
+}
+
+// SampleBounded returns k values chosen uniformly at random without replacement from the
+// values stored in the tree that are within the bound b, in the order they were drawn. If b
+// is nil, values are chosen from the whole tree. If no more than k values are within b, all
+// of them are returned in a random order. If the tree is augmented by a Counter, only the
+// roots of the maximal subtrees lying within b and the values outside those subtrees are
+// visited, and each value is drawn by descending a subtree chosen in proportion to its size.
+// Stored values and b must be of a type accepted by Point.Compare.
+func (t *Tree) SampleBounded(k int, b *Bounding, rnd *rand.Rand) []Comparable {
+	if t.Root == nil || k <= 0 {
+		return nil
+	}
+	if b != nil {
+		t.mustMatch(b[0])
+		t.mustMatch(b[1])
+	}
+	if _, ok := t.Augmenter.(Counter); !ok {
+		var candidates []Comparable
+		t.cover(b, func(n *Node) {
+			n.do(func(c Comparable, _ *Bounding, _ int) bool {
+				candidates = append(candidates, c)
+				return false
+			}, 0)
+		}, func(n *Node) { candidates = append(candidates, n.Point) })
+		return choose(candidates, k, rnd)
+	}
+
+	// pieces holds the subtrees of the region, and cum[i]
+	// is the number of values in the first i pieces.
+	var (
+		pieces []*Node
+		cum    = []int{0}
+	)
+	t.cover(b, func(n *Node) {
+		pieces = append(pieces, n)
+		cum = append(cum, cum[len(cum)-1]+n.Summary.(int))
+	}, func(n *Node) {
+		// A single value is represented by its node
+		// with the size of the subtree excluded.
+		pieces = append(pieces, n)
+		cum = append(cum, cum[len(cum)-1]+1)
+	})
+	total := cum[len(cum)-1]
+	if 2*k > total {
+		var candidates []Comparable
+		for i, n := range pieces {
+			if cum[i+1]-cum[i] == 1 {
+				candidates = append(candidates, n.Point)
+				continue
+			}
+			n.do(func(c Comparable, _ *Bounding, _ int) bool {
+				candidates = append(candidates, c)
+				return false
+			}, 0)
+		}
+		return choose(candidates, k, rnd)
+	}
+
+	drawn := make(map[*Node]bool, k)
+	samples := make([]Comparable, 0, k)
+	for len(samples) < k {
+		r := rnd.Intn(total)
+		lo, hi := 0, len(pieces)
+		for lo+1 < hi {
+			mid := (lo + hi) / 2
+			if cum[mid] <= r {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		n := pieces[lo]
+		if cum[lo+1]-cum[lo] > 1 {
+			n = n.nth(r - cum[lo])
+		}
+		if drawn[n] {
+			continue
+		}
+		drawn[n] = true
+		samples = append(samples, n.Point)
+	}
+	return samples
+}
+
+// nth returns the node holding the ith value of the subtree rooted at n in order, using the
+// subtree sizes held by Counter summaries.
+func (n *Node) nth(i int) *Node {
+	for {
+		var left int
+		if n.Left != nil {
+			left = n.Left.Summary.(int)
+		}
+		switch {
+		case i < left:
+			n = n.Left
+		case i == left:
+			return n
+		default:
+			i -= left + 1
+			n = n.Right
+		}
+	}
+}
+
+// choose returns k values chosen uniformly at random without replacement from candidates,
+// which is reordered.
+func choose(candidates []Comparable, k int, rnd *rand.Rand) []Comparable {
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+	for i := 0; i < k; i++ {
+		j := i + rnd.Intn(len(candidates)-i)
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	}
+	return candidates[:k]
+}
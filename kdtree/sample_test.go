@@ -0,0 +1,63 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSample(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 40)
+	for i := range p {
+		p[i] = Point{float64(i % 8), float64(i / 8)}
+	}
+	b := &Bounding{Point{2, 1}, Point{5, 3}}
+	var inside int
+	for _, v := range p {
+		if b.Contains(v) {
+			inside++
+		}
+	}
+	for _, bounding := range []bool{false, true} {
+		for _, aug := range []Augmenter{nil, Counter{}} {
+			t := New(append(Points(nil), p...), bounding)
+			t.Augment(aug)
+
+			all := t.Sample(100, rnd)
+			c.Check(all, check.HasLen, len(p))
+			sort.Sort(lexOrder(all))
+			want := append(lexOrder(nil), t.Points()...)
+			sort.Sort(want)
+			c.Check(all, check.DeepEquals, []Comparable(want))
+			c.Check(t.SampleBounded(100, b, rnd), check.HasLen, inside)
+			c.Check(t.SampleBounded(5, &Bounding{Point{10, 10}, Point{11, 11}}, rnd), check.HasLen, 0)
+
+			// Samples are uniform and without replacement.
+			const rounds, k = 10000, 3
+			freq := make(map[[2]float64]int)
+			for i := 0; i < rounds; i++ {
+				seen := make(map[[2]float64]bool)
+				for _, v := range t.SampleBounded(k, b, rnd) {
+					key := [2]float64{v.(Point)[0], v.(Point)[1]}
+					c.Check(b.Contains(v), check.Equals, true)
+					c.Check(seen[key], check.Equals, false)
+					seen[key] = true
+					freq[key]++
+				}
+			}
+			c.Check(freq, check.HasLen, inside)
+			expect := rounds * k / inside
+			for key, n := range freq {
+				c.Check(n > expect*4/5 && n < expect*6/5, check.Equals, true, check.Commentf("%v: %d", key, n))
+			}
+		}
+	}
+	c.Check((&Tree{}).Sample(3, rnd), check.HasLen, 0)
+	c.Check(func() { New(p, false).SampleBounded(1, &Bounding{Point{0}, Point{1}}, rnd) }, check.Panics, ErrDimsMismatch)
+}
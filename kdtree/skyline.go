@@ -0,0 +1,152 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "container/heap"
+
+// Skyline returns the values stored in the tree that are not dominated by any other value.
+// A value dominates another if it is no worse in every dimension and better in at least one,
+// where lower coordinates are better in the dimensions d for which minimize[d] is true and
+// higher coordinates are better in the others. Values with identical coordinates do not
+// dominate each other, so all are returned.
+//
+// The skyline is found by branch-and-bound, visiting subtrees in order of the best corner of
+// their bounding boxes and discarding subtrees whose best corner is dominated by a value
+// already found. Bounding boxes are taken from the nodes' bounding volumes when these are
+// present and are otherwise derived from the splitting planes of the tree. Values are
+// returned in order of increasing sum of their coordinates, negated in the maximized
+// dimensions. Stored values must be of a type accepted by Point.Compare. Skyline panics with
+// ErrDimsMismatch if len(minimize) is not the dimensionality of the values in the tree.
+func (t *Tree) Skyline(minimize []bool) []Comparable {
+	if t.Root == nil {
+		return nil
+	}
+	if len(minimize) != t.Dims() {
+		panic(ErrDimsMismatch)
+	}
+	orient := func(v float64, d int) float64 {
+		if minimize[d] {
+			return v
+		}
+		return -v
+	}
+
+	var (
+		sky    []Comparable
+		scores []Point
+	)
+	dominated := func(p Point) bool {
+		for _, s := range scores {
+			if dominates(s, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lo, hi := make([]float64, len(minimize)), make([]float64, len(minimize))
+	for d := range lo {
+		lo[d], hi[d] = -inf, inf
+	}
+	queue := skyQueue{t.Root.skyCell(lo, hi, orient)}
+	for len(queue) != 0 {
+		e := heap.Pop(&queue).(skyEntry)
+		if dominated(e.corner) {
+			continue
+		}
+		if e.node == nil {
+			sky = append(sky, e.value)
+			scores = append(scores, e.corner)
+			continue
+		}
+		n := e.node
+		p := make(Point, len(minimize))
+		for d := range p {
+			p[d] = orient(at(n.Point, Dim(d)), d)
+		}
+		heap.Push(&queue, skyEntry{value: n.Point, corner: p, key: sum(p)})
+		split := at(n.Point, n.Plane)
+		if n.Left != nil {
+			hi := append([]float64(nil), e.hi...)
+			hi[n.Plane] = split
+			heap.Push(&queue, n.Left.skyCell(e.lo, hi, orient))
+		}
+		if n.Right != nil {
+			lo := append([]float64(nil), e.lo...)
+			lo[n.Plane] = split
+			heap.Push(&queue, n.Right.skyCell(lo, e.hi, orient))
+		}
+	}
+	return sky
+}
+
+// skyCell returns the queue entry for the subtree rooted at n with the cell from lo to hi.
+func (n *Node) skyCell(lo, hi []float64, orient func(float64, int) float64) skyEntry {
+	e := skyEntry{node: n, lo: lo, hi: hi, corner: make(Point, len(lo))}
+	blo, bhi := lo, hi
+	if n.Bounding != nil {
+		blo, bhi = make([]float64, len(lo)), make([]float64, len(hi))
+		for d := range blo {
+			blo[d], bhi[d] = at(n.Bounding[0], Dim(d)), at(n.Bounding[1], Dim(d))
+		}
+	}
+	for d := range e.corner {
+		a, b := orient(blo[d], d), orient(bhi[d], d)
+		if b < a {
+			a = b
+		}
+		e.corner[d] = a
+	}
+	e.key = sum(e.corner)
+	return e
+}
+
+// dominates returns whether the oriented point a dominates b.
+func dominates(a, b Point) bool {
+	var better bool
+	for d, v := range a {
+		if v > b[d] {
+			return false
+		}
+		if v < b[d] {
+			better = true
+		}
+	}
+	return better
+}
+
+func sum(p Point) float64 {
+	var s float64
+	for _, v := range p {
+		s += v
+	}
+	return s
+}
+
+// skyEntry is either a subtree with its cell, when node is not nil, or a value.
+type skyEntry struct {
+	node   *Node
+	lo, hi []float64
+
+	value Comparable
+
+	// corner is the oriented best corner of
+	// the subtree or the oriented value, and
+	// key is the sum of its coordinates.
+	corner Point
+	key    float64
+}
+
+type skyQueue []skyEntry
+
+func (q skyQueue) Len() int            { return len(q) }
+func (q skyQueue) Less(i, j int) bool  { return q[i].key < q[j].key }
+func (q skyQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *skyQueue) Push(x interface{}) { *q = append(*q, x.(skyEntry)) }
+func (q *skyQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
@@ -0,0 +1,76 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSkyline(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	// (2,3) is lowest in x and (8,1) in y; (7,2) is dominated
+	// by neither.
+	sky := t.Skyline([]bool{true, true})
+	c.Check(sky[0], check.DeepEquals, Point{2, 3})
+	sort.Sort(lexOrder(sky))
+	c.Check(sky, check.DeepEquals, []Comparable{Point{2, 3}, Point{7, 2}, Point{8, 1}})
+	c.Check(t.Skyline([]bool{false, false}), check.DeepEquals, []Comparable{Point{9, 6}, Point{4, 7}})
+	c.Check((&Tree{}).Skyline(nil), check.HasLen, 0)
+	c.Check(func() { t.Skyline([]bool{true}) }, check.Panics, ErrDimsMismatch)
+
+	// Identical values do not dominate each other.
+	t.Insert(Point{2, 3}, false)
+	c.Check(t.Skyline([]bool{true, true}), check.HasLen, 4)
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 500)
+	for i := range p {
+		p[i] = Point{float64(rnd.Intn(50)), float64(rnd.Intn(50)), float64(rnd.Intn(50))}
+	}
+	for _, bounding := range []bool{false, true} {
+		t := New(append(Points(nil), p...), bounding)
+		for _, min := range [][]bool{{true, true, true}, {true, false, true}, {false, false, false}} {
+			orient := func(v Point) Point {
+				o := make(Point, len(v))
+				for d := range v {
+					o[d] = v[d]
+					if !min[d] {
+						o[d] = -v[d]
+					}
+				}
+				return o
+			}
+			var want []Comparable
+			for _, v := range p {
+				ok := true
+				for _, u := range p {
+					if dominates(orient(u), orient(v)) {
+						ok = false
+						break
+					}
+				}
+				if ok {
+					want = append(want, v)
+				}
+			}
+			got := t.Skyline(min)
+			for i := 1; i < len(got); i++ {
+				c.Check(sum(orient(got[i-1].(Point))) <= sum(orient(got[i].(Point))), check.Equals, true)
+			}
+			sort.Sort(lexOrder(want))
+			sort.Sort(lexOrder(got))
+			c.Check(got, check.DeepEquals, want)
+		}
+	}
+}
+
+type lexOrder []Comparable
+
+func (p lexOrder) Len() int           { return len(p) }
+func (p lexOrder) Less(i, j int) bool { return lexLess(p[i], p[j]) }
+func (p lexOrder) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
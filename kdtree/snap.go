@@ -0,0 +1,60 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"runtime"
+	"sync"
+)
+
+// snapChunk is the minimum number of queries handled by each snapping goroutine.
+const snapChunk = 256
+
+// Snap returns, for each query in q, up to n nearest values in the tree within distance
+// maxDist of the query, as found by GatherN. Queries are shared between concurrently
+// running goroutines, so the tree must not be modified during the call and a Tracer held
+// by the tree must be safe for concurrent use. Snap panics with ErrDimsMismatch if a query
+// does not have the dimensionality of the values in the tree.
+func (t *Tree) Snap(q []Comparable, n int, maxDist float64) [][]ComparableDist {
+	res := make([][]ComparableDist, len(q))
+	if t.Root == nil || n <= 0 || len(q) == 0 {
+		return res
+	}
+	for _, c := range q {
+		t.mustMatch(c)
+	}
+	parallel(len(q), snapChunk, func(i int) {
+		res[i], _ = t.GatherN(q[i], n, maxDist)
+	})
+	return res
+}
+
+// parallel calls fn with each integer in [0, n), sharing the calls between concurrently
+// running goroutines each making at least chunk calls.
+func parallel(n, chunk int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if max := (n + chunk - 1) / chunk; workers > max {
+		workers = max
+	}
+	if workers == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	size := (n + workers - 1) / workers
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
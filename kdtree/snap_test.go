@@ -0,0 +1,41 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSnap(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	ref := make(Points, 1000)
+	for i := range ref {
+		ref[i] = Point{rnd.Float64(), rnd.Float64()}
+	}
+	t := New(ref, false)
+	q := make([]Comparable, 2000)
+	for i := range q {
+		q[i] = Point{rnd.Float64() * 1.2, rnd.Float64() * 1.2}
+	}
+	got := t.Snap(q, 3, 0.001)
+	c.Assert(got, check.HasLen, len(q))
+	var empty int
+	for i, v := range q {
+		want, _ := t.GatherN(v, 3, 0.001)
+		if len(want) == 0 {
+			empty++
+			c.Check(got[i], check.HasLen, 0)
+			continue
+		}
+		c.Check(got[i], check.DeepEquals, want)
+	}
+	c.Check(empty > 0 && empty < len(q), check.Equals, true)
+
+	c.Check((&Tree{}).Snap(q, 3, 1), check.HasLen, len(q))
+	c.Check(t.Snap(nil, 3, 1), check.HasLen, 0)
+	c.Check(func() { t.Snap([]Comparable{Point{0}}, 1, 1) }, check.PanicMatches, ErrDimsMismatch.Error())
+}
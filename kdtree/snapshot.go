@@ -0,0 +1,113 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Save writes a snapshot of a tree of Point values to the file at path. It is equivalent
+// to SaveCodec(path, PointCodec{}).
+func (t *Tree) Save(path string) error {
+	return t.SaveCodec(path, PointCodec{})
+}
+
+// SaveCodec writes a snapshot of the tree to the file at path, using enc to encode points.
+// The snapshot is the binary representation written by Marshal, whose checksum protects its
+// integrity. The snapshot is written to a temporary file in the same directory that is
+// renamed to path once it has been synced to stable storage, and the directory is then
+// synced so that the rename is durable. An existing file at path is replaced atomically,
+// keeping its permissions, and is left intact if SaveCodec fails. A new file is created
+// with the permissions used by os.Create.
+func (t *Tree) SaveCodec(path string, enc PointEncoder) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := tempFile(dir, base+".tmp")
+	if err != nil {
+		return err
+	}
+	if fi, serr := os.Stat(path); serr == nil {
+		err = f.Chmod(fi.Mode().Perm())
+	}
+	if err == nil {
+		err = t.Marshal(f, enc)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+// tempFile creates a new file in dir with a name beginning with prefix, opened for writing.
+// Unlike ioutil.TempFile, the file is created with the permissions used by os.Create.
+func tempFile(dir, prefix string) (*os.File, error) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(os.Getpid())))
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rnd.Uint32()), 10))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && i < 10000 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// syncDir syncs the directory dir to stable storage.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Load returns a tree of Point values read from a snapshot written by Save. It is
+// equivalent to LoadCodec(path, PointCodec{}).
+func Load(path string) (*Tree, error) {
+	return LoadCodec(path, PointCodec{})
+}
+
+// LoadCodec returns a tree read from the snapshot at path written by SaveCodec, using dec
+// to decode points. The checksum of the snapshot is verified by Unmarshal, which returns
+// ErrChecksum if it does not match. Snapshots written with any binary format version
+// supported by Unmarshal may be read; ErrFormat is returned if the file holds data after
+// the tree.
+func LoadCodec(path string, dec PointDecoder) (*Tree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	t, err := Unmarshal(r, dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.ReadByte(); err == nil {
+		return nil, ErrFormat
+	}
+	return t, nil
+}
@@ -0,0 +1,74 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSaveLoad(c *check.C) {
+	dir, err := ioutil.TempDir("", "kdtree")
+	c.Assert(err, check.Equals, nil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tree.snap")
+
+	for i, t := range []*Tree{
+		{},
+		New(wpData, false),
+		New(wpData, true),
+	} {
+		c.Assert(t.Save(path), check.Equals, nil, check.Commentf("Test %d", i))
+		got, err := Load(path)
+		c.Assert(err, check.Equals, nil, check.Commentf("Test %d", i))
+		c.Check(got, check.DeepEquals, t, check.Commentf("Test %d", i))
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, check.Equals, nil)
+	c.Check(files, check.HasLen, 1)
+
+	b, err := ioutil.ReadFile(path)
+	c.Assert(err, check.Equals, nil)
+	for _, test := range []struct {
+		b   []byte
+		err error
+	}{
+		{b[:3], io.ErrUnexpectedEOF},
+		{b[:len(b)-1], io.ErrUnexpectedEOF},
+		{append(append([]byte(nil), b[:len(b)-10]...), append([]byte{b[len(b)-10] ^ 1}, b[len(b)-9:]...)...), ErrChecksum},
+		{append(append([]byte(nil), b...), 0), ErrFormat},
+	} {
+		c.Assert(ioutil.WriteFile(path, test.b, 0666), check.Equals, nil)
+		_, err = Load(path)
+		c.Check(err, check.Equals, test.err)
+	}
+
+	// Permissions of an existing file are kept, and new
+	// files are created as by os.Create.
+	c.Assert(os.Chmod(path, 0640), check.Equals, nil)
+	c.Assert(New(wpData, false).Save(path), check.Equals, nil)
+	fi, err := os.Stat(path)
+	c.Assert(err, check.Equals, nil)
+	c.Check(fi.Mode().Perm(), check.Equals, os.FileMode(0640))
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	c.Assert(err, check.Equals, nil)
+	ref.Close()
+	want, err := os.Stat(ref.Name())
+	c.Assert(err, check.Equals, nil)
+	newPath := filepath.Join(dir, "new.snap")
+	c.Assert(New(wpData, false).Save(newPath), check.Equals, nil)
+	fi, err = os.Stat(newPath)
+	c.Assert(err, check.Equals, nil)
+	c.Check(fi.Mode().Perm(), check.Equals, want.Mode().Perm())
+
+	c.Check(New(wpData, false).Save(filepath.Join(dir, "missing", "tree.snap")), check.NotNil)
+	_, err = Load(filepath.Join(dir, "missing"))
+	c.Check(os.IsNotExist(err), check.Equals, true)
+}
@@ -0,0 +1,134 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "time"
+
+// A SplitRule returns the dimension on which the non-empty list p is to be partitioned
+// when constructing a node. The splitting dimension of the parent node is provided as
+// parent; for the root node parent is -1.
+type SplitRule func(p Interface, parent Dim) Dim
+
+// Cycle is a SplitRule that cycles through dimensions in order from the root of
+// the tree. It is the rule used by New.
+func Cycle(p Interface, parent Dim) Dim {
+	return (parent + 1) % Dim(p.Index(0).Dims())
+}
+
+// WidestSpread is a SplitRule that splits on the dimension with the largest extent
+// over the points in p.
+func WidestSpread(p Interface, parent Dim) Dim {
+	return widest(p, func(lo, hi, _, _ float64) float64 { return hi - lo })
+}
+
+// MaxVariance is a SplitRule that splits on the dimension with the largest variance
+// over the points in p.
+func MaxVariance(p Interface, parent Dim) Dim {
+	return widest(p, func(_, _, sum, sumSq float64) float64 {
+		n := float64(p.Len())
+		return sumSq/n - (sum/n)*(sum/n)
+	})
+}
+
+// widest returns the dimension of p with the greatest score. Coordinates are measured
+// relative to the first element of p, so score is given the minimum, maximum, sum and
+// sum of squares of those relative coordinates.
+func widest(p Interface, score func(lo, hi, sum, sumSq float64) float64) Dim {
+	ref := p.Index(0)
+	var (
+		best Dim
+		max  = -inf
+	)
+	for d := Dim(0); d < Dim(ref.Dims()); d++ {
+		var lo, hi, sum, sumSq float64
+		for i := 1; i < p.Len(); i++ {
+			v := p.Index(i).Compare(ref, d)
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+			sum += v
+			sumSq += v * v
+		}
+		if s := score(lo, hi, sum, sumSq); s > max {
+			best, max = d, s
+		}
+	}
+	return best
+}
+
+// SplitRules is the set of candidate rules evaluated by AutoTune and AutoTuneFrozen.
+var SplitRules = []SplitRule{Cycle, WidestSpread, MaxVariance}
+
+// LeafSizes is the set of candidate leaf bucket sizes evaluated by AutoTuneFrozen.
+var LeafSizes = []int{1, 2, 4, 8, 16, 32, 64}
+
+// AutoTune returns a k-d tree constructed from the values in p using the SplitRule from
+// SplitRules that requires the fewest distance evaluations to perform a nearest neighbour
+// search for each of sampleQueries. If sampleQueries is empty, the tree is constructed
+// with Cycle. The bounding parameter is interpreted as for New. Each node of a Tree holds
+// a single value, so trees with leaf buckets are tuned by AutoTuneFrozen.
+func AutoTune(p Interface, bounding bool, sampleQueries []Comparable) *Tree {
+	if len(sampleQueries) == 0 {
+		return New(p, bounding)
+	}
+	var (
+		best *Tree
+		min  int
+	)
+	for _, rule := range SplitRules {
+		t := NewSplit(p, bounding, rule)
+		var st SearchStats
+		for _, q := range sampleQueries {
+			t.Nearest(q, WithStats(&st))
+		}
+		if best == nil || st.Distances < min {
+			best, min = t, st.Distances
+		}
+	}
+	return best
+}
+
+// autoTuneRounds is the number of times the sample queries are timed for each candidate
+// configuration by AutoTuneFrozen.
+const autoTuneRounds = 3
+
+// AutoTuneFrozen returns a FrozenTree holding the values stored in t, constructed by
+// FreezeLeaf with the combination of leaf size from LeafSizes and SplitRule from SplitRules
+// that takes the least time to perform a nearest neighbour search for each of
+// sampleQueries. Distance evaluations are not a useful measure of the cost of bucketed
+// trees, since larger buckets trade evaluations for fewer splits, so each configuration is
+// timed, taking the least of several rounds. The result therefore depends on the machine
+// and its load. If sampleQueries is empty, AutoTuneFrozen returns t.Freeze().
+func AutoTuneFrozen(t *Tree, sampleQueries []Comparable) *FrozenTree {
+	if len(sampleQueries) == 0 {
+		return t.Freeze()
+	}
+	var (
+		best *FrozenTree
+		min  time.Duration
+	)
+	for _, size := range LeafSizes {
+		for _, rule := range SplitRules {
+			f := t.FreezeLeaf(size, rule)
+			var elapsed time.Duration
+			for i := 0; i < autoTuneRounds; i++ {
+				start := time.Now()
+				for _, q := range sampleQueries {
+					f.Nearest(q)
+				}
+				if d := time.Since(start); i == 0 || d < elapsed {
+					elapsed = d
+				}
+			}
+			if best == nil || elapsed < min {
+				best, min = f, elapsed
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,90 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSplitRules(c *check.C) {
+	for i, test := range []struct {
+		rule SplitRule
+		data Points
+		root Dim
+	}{
+		{Cycle, Points{{0, 0}, {1, 10}, {2, 20}}, 0},
+		{WidestSpread, Points{{0, 0}, {1, 10}, {2, 20}}, 1},
+		{WidestSpread, Points{{0, 0}, {10, 1}, {20, 2}}, 0},
+		{MaxVariance, Points{{0, 0}, {1, 10}, {2, 20}}, 1},
+		{MaxVariance, Points{{0, 0}, {10, 1}, {20, 2}}, 0},
+	} {
+		c.Check(test.rule(test.data, -1), check.Equals, test.root, check.Commentf("Test %d", i))
+	}
+}
+
+func (s *S) TestNewSplit(c *check.C) {
+	for i, rule := range SplitRules {
+		p := make(Points, 1000)
+		for j := range p {
+			p[j] = Point{rand.Float64(), 10 * rand.Float64(), 100 * rand.Float64()}
+		}
+		data := append(Points(nil), p...)
+		t := NewSplit(p, true, rule)
+		c.Check(t.Root.isKDTree(), check.Equals, true, check.Commentf("Test %d", i))
+		for j := 0; j < 100; j++ {
+			q := Point{rand.Float64(), 10 * rand.Float64(), 100 * rand.Float64()}
+			got, d := t.Nearest(q)
+			ep, ed := nearest(q, data)
+			c.Check(got, check.DeepEquals, ep, check.Commentf("Test %d: query %.3f expects %.3f", i, q, ep))
+			c.Check(d, check.Equals, ed)
+		}
+	}
+}
+
+func (s *S) TestAutoTune(c *check.C) {
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rand.Float64(), 1000 * rand.Float64()}
+	}
+	data := append(Points(nil), p...)
+	var queries []Comparable
+	for i := 0; i < 100; i++ {
+		queries = append(queries, Point{rand.Float64(), 1000 * rand.Float64()})
+	}
+	t := AutoTune(p, false, queries)
+	c.Check(t.Len(), check.Equals, len(data))
+	c.Check(t.Root.isKDTree(), check.Equals, true)
+	for i, q := range queries {
+		got, _ := t.Nearest(q)
+		ep, _ := nearest(q.(Point), data)
+		c.Check(got, check.DeepEquals, ep, check.Commentf("Test %d", i))
+	}
+
+	c.Check(AutoTune(Points{{1, 2}}, false, nil).Len(), check.Equals, 1)
+}
+
+func (s *S) TestAutoTuneFrozen(c *check.C) {
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rand.Float64(), 1000 * rand.Float64()}
+	}
+	t := New(p, false)
+	var queries []Comparable
+	for i := 0; i < 100; i++ {
+		queries = append(queries, Point{rand.Float64(), 1000 * rand.Float64()})
+	}
+	f := AutoTuneFrozen(t, queries)
+	c.Check(f.Len(), check.Equals, t.Len())
+	for i, q := range queries {
+		got, gd := f.Nearest(q)
+		ep, ed := t.Nearest(q)
+		c.Check(got, check.DeepEquals, ep, check.Commentf("Test %d", i))
+		c.Check(gd, check.Equals, ed)
+	}
+
+	c.Check(AutoTuneFrozen(New(Points{{1, 2}}, false), nil).Len(), check.Equals, 1)
+}
@@ -0,0 +1,98 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// SearchStats holds counts of the work performed by a search.
+type SearchStats struct {
+	// Visited is the number of nodes visited.
+	Visited int
+
+	// Leaves is the number of visited nodes that have no children.
+	Leaves int
+
+	// Distances is the number of distance evaluations.
+	Distances int
+
+	// Pruned is the number of non-empty subtrees that were not searched.
+	Pruned int
+}
+
+// A SearchOption modifies the behaviour of a search.
+type SearchOption func(*searchConfig)
+
+type searchConfig struct {
+	stats  *SearchStats
+	tracer Tracer
+
+	// eps and checks hold the approximation of the search, and evaluated is the number of
+	// distance evaluations made against checks.
+	eps       float64
+	checks    int
+	evaluated int
+}
+
+// searchConfig returns the configuration for a search of t with the provided options.
+// If no instrumentation or approximation is required, searchConfig returns nil.
+func (t *Tree) searchConfig(opts []SearchOption) *searchConfig {
+	if t.Tracer == nil && len(opts) == 0 {
+		return nil
+	}
+	c := &searchConfig{tracer: t.Tracer}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *searchConfig) visit(n *Node) {
+	if c == nil {
+		return
+	}
+	if c.stats != nil {
+		c.stats.Visited++
+		if n.Left == nil && n.Right == nil {
+			c.stats.Leaves++
+		}
+	}
+	if c.tracer != nil {
+		c.tracer.Enter(n)
+	}
+}
+
+func (c *searchConfig) distance(q, p Comparable) float64 {
+	if c == nil {
+		return q.Distance(p)
+	}
+	c.evaluated++
+	if c.stats != nil {
+		c.stats.Distances++
+	}
+	return q.Distance(p)
+}
+
+func (c *searchConfig) candidate(n *Node, d float64) {
+	if c != nil && c.tracer != nil {
+		c.tracer.Candidate(n, d)
+	}
+}
+
+func (c *searchConfig) prune(n *Node) {
+	if c == nil || n == nil {
+		return
+	}
+	if c.stats != nil {
+		c.stats.Pruned++
+	}
+	if c.tracer != nil {
+		c.tracer.Prune(n)
+	}
+}
+
+// WithStats returns a SearchOption that adds the counts of work performed by the
+// search to s. The counts are not reset by the search, so s may be used to
+// accumulate the cost of a series of searches.
+func WithStats(s *SearchStats) SearchOption {
+	return func(c *searchConfig) { c.stats = s }
+}
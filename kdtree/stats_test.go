@@ -0,0 +1,62 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (n *Node) leaves() int {
+	if n == nil {
+		return 0
+	}
+	if n.Left == nil && n.Right == nil {
+		return 1
+	}
+	return n.Left.leaves() + n.Right.leaves()
+}
+
+func (s *S) TestWithStats(c *check.C) {
+	t := New(wpData, false)
+
+	var st SearchStats
+	t.NearestSet(NewDistKeeper(inf), Point{5, 5}, WithStats(&st))
+	c.Check(st, check.Equals, SearchStats{
+		Visited:   t.Len(),
+		Leaves:    t.Root.leaves(),
+		Distances: t.Len(),
+		Pruned:    0,
+	})
+
+	st = SearchStats{}
+	p, _ := t.Nearest(Point{9, 6}, WithStats(&st))
+	c.Check(p, check.DeepEquals, Point{9, 6})
+	c.Check(st.Visited, check.Equals, st.Distances)
+	c.Check(st.Visited < t.Len(), check.Equals, true)
+	c.Check(st.Pruned > 0, check.Equals, true)
+
+	// Stats accumulate over searches.
+	last := st
+	t.Nearest(Point{9, 6}, WithStats(&st))
+	c.Check(st, check.Equals, SearchStats{
+		Visited:   2 * last.Visited,
+		Leaves:    2 * last.Leaves,
+		Distances: 2 * last.Distances,
+		Pruned:    2 * last.Pruned,
+	})
+}
+
+func (s *S) TestWithStatsRandom(c *check.C) {
+	for i := 0; i < 100; i++ {
+		var st SearchStats
+		q := Point{rand.Float64(), rand.Float64(), rand.Float64()}
+		bTree.Nearest(q, WithStats(&st))
+		c.Check(st.Visited, check.Equals, st.Distances)
+		c.Check(st.Leaves <= st.Visited, check.Equals, true)
+		c.Check(st.Visited <= bTree.Len(), check.Equals, true)
+	}
+}
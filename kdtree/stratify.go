@@ -0,0 +1,138 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+)
+
+// An Allocation specifies how samples are shared between the strata of a stratified sample.
+type Allocation int
+
+const (
+	// Proportional allocates samples to strata in
+	// proportion to the number of values they hold.
+	Proportional Allocation = iota
+
+	// Equal allocates an equal number of samples
+	// to each stratum.
+	Equal
+)
+
+// Strata returns the values stored in the tree partitioned into the spatial cells formed by
+// the subtrees rooted at the given depth, in order. A value held by a node shallower than
+// depth lies on the boundary between cells and is placed in the cell of its in-order
+// predecessor, or of its successor if it has no left subtree, or in a cell of its own if it
+// is a leaf. If depth is zero, a single stratum holding all the values is returned.
+func (t *Tree) Strata(depth int) [][]Comparable {
+	if depth < 0 {
+		depth = 0
+	}
+	return t.Root.strata(depth)
+}
+
+func (n *Node) strata(depth int) [][]Comparable {
+	if n == nil {
+		return nil
+	}
+	if depth == 0 {
+		var values []Comparable
+		n.do(func(c Comparable, _ *Bounding, _ int) bool {
+			values = append(values, c)
+			return false
+		}, 0)
+		return [][]Comparable{values}
+	}
+	left, right := n.Left.strata(depth-1), n.Right.strata(depth-1)
+	switch {
+	case len(left) != 0:
+		left[len(left)-1] = append(left[len(left)-1], n.Point)
+	case len(right) != 0:
+		right[0] = append([]Comparable{n.Point}, right[0]...)
+	default:
+		return [][]Comparable{{n.Point}}
+	}
+	return append(left, right...)
+}
+
+// SampleStratified returns a spatially balanced sample of k values stored in the tree, chosen
+// uniformly at random without replacement within each of the strata returned by
+// Strata(depth). The samples for each stratum are returned in stratum order. The k samples
+// are divided between the strata according to alloc by the largest remainder method, with
+// the allocation of a stratum holding too few values redistributed among the others. If the
+// tree holds no more than k values, all the values are returned.
+func (t *Tree) SampleStratified(k, depth int, alloc Allocation, rnd *rand.Rand) [][]Comparable {
+	strata := t.Strata(depth)
+	if len(strata) == 0 || k <= 0 {
+		return nil
+	}
+	sizes := make([]int, len(strata))
+	for i, s := range strata {
+		sizes[i] = len(s)
+	}
+	quota := allocate(sizes, k, alloc)
+	samples := make([][]Comparable, len(strata))
+	for i, s := range strata {
+		samples[i] = choose(s, quota[i], rnd)
+	}
+	return samples
+}
+
+// allocate returns the number of samples to take from strata of the given sizes so that k
+// samples are taken in total, or all the values if there are no more than k.
+func allocate(sizes []int, k int, alloc Allocation) []int {
+	quota := make([]int, len(sizes))
+	for {
+		var (
+			open   []int
+			weight float64
+		)
+		for i, n := range sizes {
+			if quota[i] < n {
+				open = append(open, i)
+				if alloc == Proportional {
+					weight += float64(n)
+				} else {
+					weight++
+				}
+			}
+		}
+		if k == 0 || len(open) == 0 {
+			return quota
+		}
+
+		// Share k between the open strata by the largest
+		// remainder method, capping each at its size.
+		share := make([]float64, len(open))
+		var given int
+		for j, i := range open {
+			w := 1.
+			if alloc == Proportional {
+				w = float64(sizes[i])
+			}
+			share[j] = float64(k) * w / weight
+			given += int(share[j])
+		}
+		for given < k {
+			best, rem := -1, -1.
+			for j, s := range share {
+				if r := s - math.Floor(s); r > rem {
+					best, rem = j, r
+				}
+			}
+			share[best] = math.Floor(share[best]) + 1
+			given++
+		}
+		for j, i := range open {
+			add := int(share[j])
+			if quota[i]+add > sizes[i] {
+				add = sizes[i] - quota[i]
+			}
+			quota[i] += add
+			k -= add
+		}
+	}
+}
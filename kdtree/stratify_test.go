@@ -0,0 +1,78 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestStrata(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64()}
+	}
+	t := New(p, false)
+	want := append(lexOrder(nil), t.Points()...)
+	sort.Sort(want)
+	for depth := 0; depth < 5; depth++ {
+		strata := t.Strata(depth)
+		c.Check(len(strata) <= 1<<uint(depth), check.Equals, true)
+		var all lexOrder
+		for _, s := range strata {
+			c.Check(len(s) > 0, check.Equals, true)
+			all = append(all, s...)
+		}
+		sort.Sort(all)
+		c.Check(all, check.DeepEquals, want)
+	}
+	c.Check(t.Strata(0), check.HasLen, 1)
+	c.Check(t.Strata(4), check.HasLen, 16)
+	c.Check((&Tree{}).Strata(3), check.HasLen, 0)
+
+	for _, alloc := range []Allocation{Proportional, Equal} {
+		samples := t.SampleStratified(100, 3, alloc, rnd)
+		strata := t.Strata(3)
+		c.Assert(samples, check.HasLen, len(strata))
+		var n int
+		for i, s := range samples {
+			n += len(s)
+			in := make(map[*float64]bool)
+			for _, v := range strata[i] {
+				in[&v.(Point)[0]] = true
+			}
+			for _, v := range s {
+				c.Check(in[&v.(Point)[0]], check.Equals, true)
+			}
+			if alloc == Equal {
+				c.Check(len(s) == 12 || len(s) == 13, check.Equals, true)
+			} else {
+				c.Check(len(s)-100*len(strata[i])/len(p) <= 1, check.Equals, true)
+			}
+		}
+		c.Check(n, check.Equals, 100)
+	}
+	c.Check(t.SampleStratified(0, 3, Equal, rnd), check.HasLen, 0)
+}
+
+func (s *S) TestAllocate(c *check.C) {
+	for _, test := range []struct {
+		sizes []int
+		k     int
+		alloc Allocation
+		want  []int
+	}{
+		{sizes: []int{10, 20, 70}, k: 10, alloc: Proportional, want: []int{1, 2, 7}},
+		{sizes: []int{10, 20, 70}, k: 10, alloc: Equal, want: []int{4, 3, 3}},
+		{sizes: []int{1, 20, 70}, k: 10, alloc: Equal, want: []int{1, 5, 4}},
+		{sizes: []int{2, 3}, k: 10, alloc: Proportional, want: []int{2, 3}},
+		{sizes: []int{5, 5, 5}, k: 1, alloc: Proportional, want: []int{1, 0, 0}},
+	} {
+		c.Check(allocate(test.sizes, test.k, test.alloc), check.DeepEquals, test.want)
+	}
+}
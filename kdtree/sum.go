@@ -0,0 +1,55 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A SumAugmenter is an Augmenter that maintains the sum of a numeric value attached to each
+// value in a subtree, as a float64, for use by SumBounded.
+type SumAugmenter struct {
+	// Value returns the numeric value
+	// attached to c.
+	Value func(c Comparable) float64
+}
+
+// Summarize satisfies the Augmenter interface, returning a float64.
+func (a SumAugmenter) Summarize(c Comparable, left, right interface{}) interface{} {
+	s := a.Value(c)
+	if left != nil {
+		s += left.(float64)
+	}
+	if right != nil {
+		s += right.(float64)
+	}
+	return s
+}
+
+// SumBounded returns the sum of the numeric values attached to the values stored in the tree
+// that are within the bound b, such as the total revenue of the stores within a viewport. If b
+// is nil, the sum over the whole tree is returned. The partial sum of each subtree lying
+// entirely within b is read from its summary, so only the nodes on the boundary of b are
+// visited individually, O(n^(1-1/k)) nodes for a balanced tree of n k-dimensional values.
+//
+// The tree must be augmented by a SumAugmenter. SumBounded panics with ErrDimsMismatch if b
+// does not have the dimensionality of the values in the tree.
+func (t *Tree) SumBounded(b *Bounding) float64 {
+	a, ok := t.Augmenter.(SumAugmenter)
+	if !ok {
+		panic("kdtree: tree not augmented by SumAugmenter")
+	}
+	s := sumAggregator{value: a.Value}
+	t.Aggregate(b, &s)
+	return s.sum
+}
+
+// sumAggregator is a SubtreeAggregator summing values using SumAugmenter summaries.
+type sumAggregator struct {
+	value func(Comparable) float64
+	sum   float64
+}
+
+func (s *sumAggregator) Add(c Comparable) { s.sum += s.value(c) }
+func (s *sumAggregator) AddSubtree(summary interface{}) bool {
+	s.sum += summary.(float64)
+	return true
+}
@@ -0,0 +1,57 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSumBounded(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	var calls int
+	value := func(v Comparable) float64 {
+		calls++
+		return v.(Datum).Value.(float64)
+	}
+	// Stores with revenues.
+	stores := make(comparables, 4000)
+	for i := range stores {
+		stores[i] = Datum{Point: Point{rnd.Float64(), rnd.Float64()}, Value: float64(rnd.Intn(1000))}
+	}
+	for _, bounding := range []bool{false, true} {
+		t := New(append(comparables(nil), stores...), bounding)
+		c.Check(func() { t.SumBounded(nil) }, check.Panics, "kdtree: tree not augmented by SumAugmenter")
+		t.Augment(SumAugmenter{Value: value})
+
+		var total float64
+		for _, s := range stores {
+			total += s.(Datum).Value.(float64)
+		}
+		calls = 0
+		c.Check(t.SumBounded(nil), check.Equals, total)
+		c.Check(calls, check.Equals, 0)
+
+		for i := 0; i < 20; i++ {
+			x, y := rnd.Float64()/2, rnd.Float64()/2
+			b := &Bounding{Point{x, y}, Point{x + 0.5, y + 0.5}}
+			var want float64
+			for _, s := range stores {
+				if inBox(s, b[0].(Point), b[1].(Point)) {
+					want += s.(Datum).Value.(float64)
+				}
+			}
+			calls = 0
+			c.Check(math.Abs(t.SumBounded(b)-want) < 1e-6, check.Equals, true)
+			c.Check(calls < len(stores)/10, check.Equals, true, check.Commentf("%d values visited", calls))
+		}
+		c.Check(func() { t.SumBounded(&Bounding{Point{0}, Point{1}}) }, check.Panics, ErrDimsMismatch)
+	}
+	t := &Tree{}
+	t.Augment(SumAugmenter{Value: value})
+	c.Check(t.SumBounded(nil), check.Equals, 0.)
+}
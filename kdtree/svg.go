@@ -0,0 +1,193 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// An SVGOption modifies the output of Tree.SVG.
+type SVGOption func(*svgConfig)
+
+type svgConfig struct {
+	width, height float64
+	bounds        bool
+	query         Comparable
+}
+
+// SVGSize sets the size of the image written by Tree.SVG. The default is 512×512.
+func SVGSize(width, height int) SVGOption {
+	return func(c *svgConfig) { c.width, c.height = float64(width), float64(height) }
+}
+
+// SVGBounds specifies that Tree.SVG draws the bounding volumes held by the tree's nodes.
+func SVGBounds() SVGOption {
+	return func(c *svgConfig) { c.bounds = true }
+}
+
+// SVGQuery specifies that Tree.SVG overlays the trace of a nearest neighbour search for q.
+// Visited points are drawn in orange, each improving candidate is joined to the query and
+// the nearest point is circled.
+func SVGQuery(q Comparable) SVGOption {
+	return func(c *svgConfig) { c.query = q }
+}
+
+// errSVGDims is returned by Tree.SVG for trees that are not two dimensional.
+var errSVGDims = errors.New("kdtree: SVG requires two dimensional Point, Datum or Row values")
+
+// SVG writes an SVG image of a two dimensional tree to w. Points are drawn as dots within
+// the regions defined by the splitting lines of the tree, with the first dimension on the
+// horizontal axis increasing to the right and the second on the vertical axis increasing
+// upwards. The points of the tree must be Point, Datum or Row values.
+func (t *Tree) SVG(w io.Writer, opts ...SVGOption) error {
+	cfg := svgConfig{width: 512, height: 512}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	var (
+		ok     = true
+		region = [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	)
+	extend := func(c Comparable) {
+		if c.Dims() != 2 {
+			ok = false
+			return
+		}
+		x, xok := coordOf(c, 0)
+		y, yok := coordOf(c, 1)
+		if !xok || !yok {
+			ok = false
+			return
+		}
+		region = [4]float64{math.Min(region[0], x), math.Min(region[1], y), math.Max(region[2], x), math.Max(region[3], y)}
+	}
+	t.Root.walk(func(n *Node) { extend(n.Point) })
+	if cfg.query != nil {
+		extend(cfg.query)
+	}
+	if !ok {
+		return errSVGDims
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %[1]g %[2]g\">\n", cfg.width, cfg.height)
+	fmt.Fprintf(bw, "<rect width=\"%g\" height=\"%g\" fill=\"white\"/>\n", cfg.width, cfg.height)
+	if t.Root != nil {
+		p := newSVGProjection(region, cfg.width, cfg.height)
+		var trace *svgTrace
+		if cfg.query != nil {
+			trace = &svgTrace{visited: make(map[*Node]bool)}
+			t.Nearest(cfg.query, WithTracer(trace))
+		}
+		if cfg.bounds {
+			t.Root.walk(func(n *Node) {
+				if n.Bounding != nil {
+					x0, y0 := p.point(n.Bounding[0])
+					x1, y1 := p.point(n.Bounding[1])
+					fmt.Fprintf(bw, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"none\" stroke=\"#1f77b4\" stroke-opacity=\"0.5\"/>\n",
+						x0, y1, x1-x0, y0-y1)
+				}
+			})
+		}
+		t.Root.svgSplits(bw, p, region)
+		t.Root.walk(func(n *Node) {
+			x, y := p.point(n.Point)
+			fill := "black"
+			if trace != nil && trace.visited[n] {
+				fill = "orange"
+			}
+			fmt.Fprintf(bw, "<circle cx=\"%g\" cy=\"%g\" r=\"3\" fill=\"%s\"/>\n", x, y, fill)
+		})
+		if trace != nil {
+			qx, qy := p.point(cfg.query)
+			for _, n := range trace.candidates {
+				x, y := p.point(n.Point)
+				fmt.Fprintf(bw, "<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"orange\" stroke-dasharray=\"4 2\"/>\n", qx, qy, x, y)
+			}
+			if len(trace.candidates) != 0 {
+				x, y := p.point(trace.candidates[len(trace.candidates)-1].Point)
+				fmt.Fprintf(bw, "<circle cx=\"%g\" cy=\"%g\" r=\"6\" fill=\"none\" stroke=\"red\"/>\n", x, y)
+			}
+			fmt.Fprintf(bw, "<circle cx=\"%g\" cy=\"%g\" r=\"4\" fill=\"red\"/>\n", qx, qy)
+		}
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// svgProjection maps tree coordinates to image coordinates, preserving the aspect ratio.
+type svgProjection struct {
+	minX, minY float64
+	scale      float64
+	offX, offY float64
+	height     float64
+}
+
+func newSVGProjection(region [4]float64, width, height float64) svgProjection {
+	const margin = 0.05
+	dx, dy := region[2]-region[0], region[3]-region[1]
+	if dx == 0 {
+		dx = 1
+	}
+	if dy == 0 {
+		dy = 1
+	}
+	innerW, innerH := width*(1-2*margin), height*(1-2*margin)
+	scale := math.Min(innerW/dx, innerH/dy)
+	return svgProjection{
+		minX:   region[0],
+		minY:   region[1],
+		scale:  scale,
+		offX:   width*margin + (innerW-dx*scale)/2,
+		offY:   height*margin + (innerH-dy*scale)/2,
+		height: height,
+	}
+}
+
+func (p svgProjection) xy(x, y float64) (float64, float64) {
+	return p.offX + (x-p.minX)*p.scale, p.height - (p.offY + (y-p.minY)*p.scale)
+}
+
+func (p svgProjection) point(c Comparable) (float64, float64) {
+	return p.xy(at(c, 0), at(c, 1))
+}
+
+// svgSplits draws the splitting line of n across region, given as minimum x, minimum y,
+// maximum x and maximum y, and recursively the splitting lines of its children.
+func (n *Node) svgSplits(w io.Writer, p svgProjection, region [4]float64) {
+	if n == nil {
+		return
+	}
+	v := at(n.Point, n.Plane)
+	var x0, y0, x1, y1 float64
+	left, right := region, region
+	if n.Plane == 0 {
+		x0, y0 = p.xy(v, region[1])
+		x1, y1 = p.xy(v, region[3])
+		left[2], right[0] = v, v
+	} else {
+		x0, y0 = p.xy(region[0], v)
+		x1, y1 = p.xy(region[2], v)
+		left[3], right[1] = v, v
+	}
+	fmt.Fprintf(w, "<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"gray\"/>\n", x0, y0, x1, y1)
+	n.Left.svgSplits(w, p, left)
+	n.Right.svgSplits(w, p, right)
+}
+
+// svgTrace is a Tracer recording the progress of a search for Tree.SVG.
+type svgTrace struct {
+	visited    map[*Node]bool
+	candidates []*Node
+}
+
+func (t *svgTrace) Enter(n *Node)                { t.visited[n] = true }
+func (t *svgTrace) Prune(*Node)                  {}
+func (t *svgTrace) Candidate(n *Node, _ float64) { t.candidates = append(t.candidates, n) }
@@ -0,0 +1,55 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSVG(c *check.C) {
+	t := New(wpData, true)
+	var buf bytes.Buffer
+	c.Assert(t.SVG(&buf, SVGSize(200, 100), SVGBounds(), SVGQuery(Point{5, 5})), check.Equals, nil)
+	out := buf.String()
+
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, check.Equals, nil)
+		if e, ok := tok.(xml.StartElement); ok {
+			counts[e.Name.Local]++
+		}
+	}
+	c.Check(counts["svg"], check.Equals, 1)
+	c.Check(counts["rect"], check.Equals, 1+t.Count)
+
+	var rec recorder
+	_, d := t.Nearest(Point{5, 5}, WithTracer(&rec))
+	c.Check(d, check.Equals, 1.)
+	c.Check(counts["circle"], check.Equals, t.Count+2)
+	c.Check(counts["line"], check.Equals, t.Count+len(rec.candidates))
+	c.Check(strings.Count(out, `fill="orange"`), check.Equals, len(rec.entered))
+	c.Check(strings.Contains(out, `width="200" height="100"`), check.Equals, true)
+}
+
+func (s *S) TestSVGDims(c *check.C) {
+	var buf bytes.Buffer
+	c.Check(New(Points{{1, 2, 3}}, false).SVG(&buf), check.Equals, errSVGDims)
+	c.Check(New(nbPoints{{1, 2}}, false).SVG(&buf), check.Equals, errSVGDims)
+	c.Check(New(wpData, false).SVG(&buf, SVGQuery(Point{1})), check.Equals, errSVGDims)
+
+	buf.Reset()
+	c.Check((&Tree{}).SVG(&buf), check.Equals, nil)
+	c.Check(strings.HasSuffix(buf.String(), "</svg>\n"), check.Equals, true)
+}
@@ -0,0 +1,63 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "math"
+
+// DoSwept performs fn on all values stored in the tree that are within the Euclidean
+// distance radius of the line segment from one point to another, the region swept by a
+// sphere of the given radius moving between the points. Stored values must be of a type
+// accepted by Point.Compare. The Bounding passed to fn is the bounding box of the swept
+// region. A boolean is returned indicating whether the traversal was interrupted by an
+// Operation returning true. Values are visited in order. DoSwept panics with
+// ErrDimsMismatch if from or to do not have the dimensionality of the values in the tree.
+func (t *Tree) DoSwept(fn Operation, from, to Point, radius float64) bool {
+	if t.Root == nil {
+		return false
+	}
+	t.mustMatch(from)
+	t.mustMatch(to)
+	lo, hi := make(Point, len(from)), make(Point, len(from))
+	for d := range from {
+		lo[d] = math.Min(from[d], to[d]) - radius
+		hi[d] = math.Max(from[d], to[d]) + radius
+	}
+	r2 := radius * radius
+	return t.Root.doBounded(fn, &Bounding{lo, hi}, func(c Comparable) bool {
+		return segmentDist(c, from, to) <= r2
+	}, 0)
+}
+
+// Swept returns the values stored in the tree that are within the Euclidean distance
+// radius of the line segment from one point to another, in the order they are visited by
+// DoSwept.
+func (t *Tree) Swept(from, to Point, radius float64) []Comparable {
+	var res []Comparable
+	t.DoSwept(func(c Comparable, _ *Bounding, _ int) bool {
+		res = append(res, c)
+		return false
+	}, from, to, radius)
+	return res
+}
+
+// segmentDist returns the squared Euclidean distance from c to the line segment from a to b.
+func segmentDist(c Comparable, a, b Point) float64 {
+	var dot, len2 float64
+	for d := range a {
+		ab := b[d] - a[d]
+		dot += (at(c, Dim(d)) - a[d]) * ab
+		len2 += ab * ab
+	}
+	var f float64
+	if len2 > 0 {
+		f = math.Max(0, math.Min(1, dot/len2))
+	}
+	var sum float64
+	for d := range a {
+		delta := at(c, Dim(d)) - (a[d] + f*(b[d]-a[d]))
+		sum += delta * delta
+	}
+	return sum
+}
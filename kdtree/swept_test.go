@@ -0,0 +1,43 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSwept(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	c.Check(t.Swept(Point{2, 3}, Point{9, 6}, 0.2), check.DeepEquals, []Comparable{Point{2, 3}, Point{9, 6}})
+	c.Check(t.Swept(Point{2, 3}, Point{9, 6}, 1.2), check.DeepEquals, []Comparable{Point{2, 3}, Point{5, 4}, Point{9, 6}})
+	c.Check(t.Swept(Point{4, 7}, Point{4, 7}, 0), check.DeepEquals, []Comparable{Point{4, 7}})
+	c.Check(t.Swept(Point{0, 10}, Point{10, 10}, 1), check.HasLen, 0)
+	c.Check((&Tree{}).Swept(Point{0, 0}, Point{1, 1}, 1), check.HasLen, 0)
+	c.Check(func() { t.Swept(Point{0}, Point{1, 1}, 1) }, check.PanicMatches, ErrDimsMismatch.Error())
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	t = New(append(Points(nil), p...), false)
+	for i := 0; i < 10; i++ {
+		from := Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+		to := Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+		var want int
+		for _, v := range p {
+			if segmentDist(v, from, to) <= 0.01 {
+				want++
+			}
+		}
+		got := t.Swept(from, to, 0.1)
+		c.Check(got, check.HasLen, want)
+		for _, v := range got {
+			c.Check(segmentDist(v, from, to) <= 0.01, check.Equals, true)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "sort"
+
+// NearestAll returns all the values at the least distance from the query, in lexical order
+// of their coordinates.
+func (t *Tree) NearestAll(q Comparable, opts ...SearchOption) []ComparableDist {
+	if t.Root == nil {
+		return nil
+	}
+	k := &tieKeeper{Heap{{Dist: inf}}}
+	t.NearestSet(k, q, opts...)
+	h := k.Heap
+	if len(h) != 0 && h[0].Comparable == nil {
+		return nil
+	}
+	sort.Sort(byCoordsDist(h))
+	return h
+}
+
+// tieKeeper is a Keeper that retains all the ComparableDists at the least distance that it
+// is called to Keep.
+type tieKeeper struct {
+	Heap
+}
+
+func (k *tieKeeper) Keep(c ComparableDist) {
+	switch {
+	case c.Dist < k.Heap[0].Dist:
+		k.Heap = append(k.Heap[:0], c)
+	case c.Dist == k.Heap[0].Dist && k.Heap[0].Comparable != nil:
+		k.Heap = append(k.Heap, c)
+	}
+}
+
+// byCoordsDist sorts ComparableDists lexically by the coordinates of their Comparable.
+type byCoordsDist []ComparableDist
+
+func (p byCoordsDist) Len() int           { return len(p) }
+func (p byCoordsDist) Less(i, j int) bool { return lexLess(p[i].Comparable, p[j].Comparable) }
+func (p byCoordsDist) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
@@ -0,0 +1,99 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestNearestAll(c *check.C) {
+	c.Check((&Tree{}).NearestAll(Point{0, 0}), check.HasLen, 0)
+
+	// A lattice gives many equidistant values.
+	var p Points
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			p = append(p, Point{float64(x), float64(y)})
+		}
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 5; i++ {
+		for j := range p {
+			k := j + rnd.Intn(len(p)-j)
+			p[j], p[k] = p[k], p[j]
+		}
+		t := &Tree{}
+		for _, v := range p {
+			t.Insert(v, false)
+		}
+		for _, test := range []struct {
+			q    Point
+			want []Point
+		}{
+			{q: Point{4.5, 4.5}, want: []Point{{4, 4}, {4, 5}, {5, 4}, {5, 5}}},
+			{q: Point{4.5, 4}, want: []Point{{4, 4}, {5, 4}}},
+			{q: Point{3, 3}, want: []Point{{3, 3}}},
+			{q: Point{-1, 4.5}, want: []Point{{0, 4}, {0, 5}}},
+		} {
+			var got []Point
+			for _, cd := range t.NearestAll(test.q) {
+				got = append(got, cd.Comparable.(Point))
+				c.Check(cd.Dist, check.Equals, test.q.Distance(test.want[0]))
+			}
+			c.Check(got, check.DeepEquals, test.want)
+
+			// Nearest breaks ties by lexical order.
+			n, d := t.Nearest(test.q)
+			c.Check(n, check.DeepEquals, test.want[0])
+			c.Check(d, check.Equals, test.q.Distance(test.want[0]))
+		}
+	}
+}
+
+func (s *S) TestNearestNDeterministic(c *check.C) {
+	var p Points
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			p = append(p, Point{float64(x), float64(y)})
+		}
+	}
+	rnd := rand.New(rand.NewSource(1))
+	queries := []Point{{3.5, 3.5}, {0, 0}, {4, 4}, {2.5, 6}}
+	want := make(map[[2]int][]ComparableDist)
+	for i := 0; i < 10; i++ {
+		for j := range p {
+			k := j + rnd.Intn(len(p)-j)
+			p[j], p[k] = p[k], p[j]
+		}
+		var t *Tree
+		if i%2 == 0 {
+			t = New(append(Points(nil), p...), false)
+		} else {
+			t = &Tree{}
+			for _, v := range p {
+				t.Insert(v, false)
+			}
+		}
+		for j, q := range queries {
+			for _, n := range []int{1, 3, 6, 10} {
+				got := t.NearestN(q, n)
+				c.Assert(got, check.HasLen, n)
+				for k := 1; k < len(got); k++ {
+					c.Check(got[k-1].Dist < got[k].Dist ||
+						(got[k-1].Dist == got[k].Dist && lexLess(got[k-1].Comparable, got[k].Comparable)),
+						check.Equals, true)
+				}
+				key := [2]int{j, n}
+				if i == 0 {
+					want[key] = got
+					continue
+				}
+				c.Check(got, check.DeepEquals, want[key])
+			}
+		}
+	}
+}
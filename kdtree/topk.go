@@ -0,0 +1,171 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// A MaxAugmenter is an Augmenter that maintains the greatest score of the values in each
+// subtree, as a float64, for use by TopKBounded.
+type MaxAugmenter struct {
+	// Score returns the score of c.
+	Score func(c Comparable) float64
+}
+
+// Summarize satisfies the Augmenter interface, returning a float64.
+func (a MaxAugmenter) Summarize(c Comparable, left, right interface{}) interface{} {
+	m := a.Score(c)
+	for _, s := range [...]interface{}{left, right} {
+		if s != nil && s.(float64) > m {
+			m = s.(float64)
+		}
+	}
+	return m
+}
+
+// TopKBounded returns the k values stored in the tree within the bound b with the highest
+// scores, in order of decreasing score. Values with equal scores are returned in lexical
+// order of their coordinates. If b is nil, values are chosen from the whole tree. If fewer
+// than k values are within b, all of them are returned.
+//
+// Subtrees are visited in order of decreasing maximum score. If the tree is augmented by a
+// MaxAugmenter, whose Score must return the same scores as score, the maximum score of a
+// subtree is read from its summary and the search stops once no unvisited subtree can hold
+// a value scoring higher than the kth best found; otherwise every value within b is scored.
+// Subtrees lying outside b are pruned using the nodes' bounding volumes when these are
+// present and otherwise the splitting planes of the tree. Stored values and b must be of a
+// type accepted by Point.Compare.
+func (t *Tree) TopKBounded(b *Bounding, k int, score func(Comparable) float64) []Comparable {
+	if t.Root == nil || k <= 0 {
+		return nil
+	}
+	if b != nil {
+		t.mustMatch(b[0])
+		t.mustMatch(b[1])
+	}
+	_, augmented := t.Augmenter.(MaxAugmenter)
+	bound := func(n *Node) float64 {
+		if augmented && n.Summary != nil {
+			return n.Summary.(float64)
+		}
+		return inf
+	}
+
+	dims := t.Dims()
+	blo, bhi := make([]float64, dims), make([]float64, dims)
+	lo, hi := make([]float64, dims), make([]float64, dims)
+	for d := range blo {
+		blo[d], bhi[d] = -inf, inf
+		if b != nil {
+			blo[d], bhi[d] = at(b[0], Dim(d)), at(b[1], Dim(d))
+		}
+		lo[d], hi[d] = -inf, inf
+	}
+	var (
+		best  scoreHeap
+		queue = subtreeQueue{{node: t.Root, lo: lo, hi: hi, max: bound(t.Root)}}
+	)
+	for len(queue) != 0 {
+		s := heap.Pop(&queue).(subtree)
+		if len(best) == k && s.max < best[0].score {
+			break
+		}
+		n := s.node
+		if !n.overlaps(s.lo, s.hi, blo, bhi) {
+			continue
+		}
+		if inBox(n.Point, blo, bhi) {
+			v := scored{c: n.Point, score: score(n.Point)}
+			switch {
+			case len(best) < k:
+				heap.Push(&best, v)
+			case worse(best[0], v):
+				best[0] = v
+				heap.Fix(&best, 0)
+			}
+		}
+		split := at(n.Point, n.Plane)
+		if n.Left != nil && blo[n.Plane] <= split {
+			hi := append([]float64(nil), s.hi...)
+			hi[n.Plane] = split
+			heap.Push(&queue, subtree{node: n.Left, lo: s.lo, hi: hi, max: bound(n.Left)})
+		}
+		if n.Right != nil && bhi[n.Plane] >= split {
+			lo := append([]float64(nil), s.lo...)
+			lo[n.Plane] = split
+			heap.Push(&queue, subtree{node: n.Right, lo: lo, hi: s.hi, max: bound(n.Right)})
+		}
+	}
+
+	sort.Sort(sort.Reverse(best))
+	top := make([]Comparable, len(best))
+	for i, v := range best {
+		top[i] = v.c
+	}
+	return top
+}
+
+// overlaps returns whether the bounding box of n, or the cell from lo to hi if n has no
+// bounding volume, overlaps the box from blo to bhi.
+func (n *Node) overlaps(lo, hi, blo, bhi []float64) bool {
+	for d := range blo {
+		clo, chi := lo[d], hi[d]
+		if n.Bounding != nil {
+			clo, chi = at(n.Bounding[0], Dim(d)), at(n.Bounding[1], Dim(d))
+		}
+		if clo > bhi[d] || chi < blo[d] {
+			return false
+		}
+	}
+	return true
+}
+
+type scored struct {
+	c     Comparable
+	score float64
+}
+
+// worse returns whether a ranks below b.
+func worse(a, b scored) bool {
+	if a.score != b.score {
+		return a.score < b.score
+	}
+	return lexLess(b.c, a.c)
+}
+
+// scoreHeap is a min-heap of scored values with the lowest ranked value at its root.
+type scoreHeap []scored
+
+func (h scoreHeap) Len() int            { return len(h) }
+func (h scoreHeap) Less(i, j int) bool  { return worse(h[i], h[j]) }
+func (h scoreHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoreHeap) Push(x interface{}) { *h = append(*h, x.(scored)) }
+func (h *scoreHeap) Pop() interface{} {
+	x := (*h)[len(*h)-1]
+	*h = (*h)[:len(*h)-1]
+	return x
+}
+
+// subtree is a subtree with its cell and the greatest score it may hold.
+type subtree struct {
+	node   *Node
+	lo, hi []float64
+	max    float64
+}
+
+// subtreeQueue is a max-heap of subtrees ordered by their greatest possible score.
+type subtreeQueue []subtree
+
+func (q subtreeQueue) Len() int            { return len(q) }
+func (q subtreeQueue) Less(i, j int) bool  { return q[i].max > q[j].max }
+func (q subtreeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *subtreeQueue) Push(x interface{}) { *q = append(*q, x.(subtree)) }
+func (q *subtreeQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
@@ -0,0 +1,60 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestTopKBounded(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	y := func(c Comparable) float64 { return c.(Point)[1] }
+	c.Check(t.TopKBounded(nil, 2, y), check.DeepEquals, []Comparable{Point{4, 7}, Point{9, 6}})
+	c.Check(t.TopKBounded(&Bounding{Point{5, 0}, Point{10, 5}}, 2, y), check.DeepEquals, []Comparable{Point{5, 4}, Point{7, 2}})
+	c.Check(t.TopKBounded(&Bounding{Point{5, 0}, Point{10, 5}}, 10, y), check.HasLen, 3)
+	c.Check(t.TopKBounded(nil, 0, y), check.HasLen, 0)
+	c.Check(func() { t.TopKBounded(&Bounding{Point{0}, Point{1}}, 1, y) }, check.Panics, ErrDimsMismatch)
+
+	rnd := rand.New(rand.NewSource(1))
+	var calls int
+	score := func(c Comparable) float64 {
+		calls++
+		// Integer scores give ties.
+		return float64(int(c.(Point)[2] * 1000))
+	}
+	p := make(Points, 5000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	for _, bounding := range []bool{false, true} {
+		for _, aug := range []Augmenter{nil, MaxAugmenter{Score: score}} {
+			t := New(append(Points(nil), p...), bounding)
+			t.Augment(aug)
+			for i := 0; i < 10; i++ {
+				x, y := rnd.Float64()/2, rnd.Float64()/2
+				b := &Bounding{Point{x, y, 0}, Point{x + 0.5, y + 0.5, 1}}
+				var want []scored
+				for _, v := range p {
+					if b.Contains(v) {
+						want = append(want, scored{c: v, score: score(v)})
+					}
+				}
+				sort.Sort(sort.Reverse(scoreHeap(want)))
+				calls = 0
+				got := t.TopKBounded(b, 10, score)
+				c.Assert(got, check.HasLen, 10)
+				for j, v := range got {
+					c.Check(v, check.DeepEquals, want[j].c)
+				}
+				if aug != nil {
+					c.Check(calls < len(want)/4, check.Equals, true, check.Commentf("%d values scored", calls))
+				}
+			}
+		}
+	}
+}
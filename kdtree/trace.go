@@ -0,0 +1,27 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// A Tracer is notified of the progress of searches of a Tree. Tracer methods must not
+// alter the tree.
+type Tracer interface {
+	// Enter is called when a search enters the node n.
+	Enter(n *Node)
+
+	// Prune is called when a search declines to enter the subtree rooted at n.
+	Prune(n *Node)
+
+	// Candidate is called with the node n and the distance d between its point and
+	// the query when the point is considered as a result. For Nearest, Candidate is
+	// called each time a closer point is found. For NearestSet, Candidate is called
+	// for each point offered to the Keeper.
+	Candidate(n *Node, d float64)
+}
+
+// WithTracer returns a SearchOption that notifies tr of the progress of the search in
+// place of the tree's Tracer.
+func WithTracer(tr Tracer) SearchOption {
+	return func(c *searchConfig) { c.tracer = tr }
+}
@@ -0,0 +1,56 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+type recorder struct {
+	entered, pruned []*Node
+	candidates      []float64
+}
+
+func (r *recorder) Enter(n *Node)                { r.entered = append(r.entered, n) }
+func (r *recorder) Prune(n *Node)                { r.pruned = append(r.pruned, n) }
+func (r *recorder) Candidate(n *Node, d float64) { r.candidates = append(r.candidates, d) }
+
+func (s *S) TestTracer(c *check.C) {
+	t := New(wpData, false)
+	rec := &recorder{}
+	t.Tracer = rec
+
+	t.NearestSet(NewDistKeeper(inf), Point{5, 5})
+	c.Check(rec.entered, check.HasLen, t.Len())
+	c.Check(rec.pruned, check.HasLen, 0)
+	c.Check(rec.candidates, check.HasLen, t.Len())
+	c.Check(rec.entered[0], check.Equals, t.Root)
+
+	for i := 0; i < 100; i++ {
+		*rec = recorder{}
+		q := Point{rand.Float64() * 10, rand.Float64() * 10}
+		var st SearchStats
+		_, d := t.Nearest(q, WithStats(&st))
+		c.Check(rec.entered, check.HasLen, st.Visited)
+		c.Check(rec.pruned, check.HasLen, st.Pruned)
+		c.Assert(len(rec.candidates) > 0, check.Equals, true)
+		c.Check(rec.candidates[len(rec.candidates)-1], check.Equals, d)
+		for j := 1; j < len(rec.candidates); j++ {
+			c.Check(rec.candidates[j] < rec.candidates[j-1], check.Equals, true)
+		}
+	}
+}
+
+func (s *S) TestWithTracer(c *check.C) {
+	t := New(wpData, false)
+	def, opt := &recorder{}, &recorder{}
+	t.Tracer = def
+	t.Nearest(Point{8, 7}, WithTracer(opt))
+	c.Check(def.entered, check.HasLen, 0)
+	c.Check(len(opt.entered) > 0, check.Equals, true)
+	c.Check(opt.candidates[len(opt.candidates)-1], check.Equals, 2.)
+}
@@ -0,0 +1,87 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "errors"
+
+var (
+	// ErrRecallTarget is returned by Tune when the target recall is not in (0, 1].
+	ErrRecallTarget = errors.New("kdtree: recall target out of range")
+
+	// ErrNoQueries is returned by Tune when there are no queries to tune with.
+	ErrNoQueries = errors.New("kdtree: no tuning queries")
+)
+
+// tuneEpsilons are the approximation factors considered by Tune.
+var tuneEpsilons = []float64{0, 0.1, 0.25, 0.5, 1, 2, 4}
+
+// An ApproxSearcher is an approximate nearest neighbour search of a tree configured by Tune.
+type ApproxSearcher struct {
+	Tree *Tree
+
+	// Epsilon and Checks are the parameters of the search, as for WithEpsilon and
+	// WithChecks.
+	Epsilon float64
+	Checks  int
+
+	// Stats holds the recall and latency of the search measured for the tuning queries.
+	Stats RecallStats
+}
+
+// Options returns the SearchOptions configuring a search of the tree as s does.
+func (s *ApproxSearcher) Options() []SearchOption {
+	return []SearchOption{WithEpsilon(s.Epsilon), WithChecks(s.Checks)}
+}
+
+// Nearest returns the approximate nearest value to the query and the distance between them.
+func (s *ApproxSearcher) Nearest(q Comparable) (Comparable, float64) {
+	return s.Tree.Nearest(q, s.Options()...)
+}
+
+// NearestN returns the approximate n nearest values to the query in min sorted order.
+// NearestN is a SearchFunc.
+func (s *ApproxSearcher) NearestN(q Comparable, n int) []ComparableDist {
+	return s.Tree.NearestN(q, n, s.Options()...)
+}
+
+// Tune returns an ApproxSearcher of t for the k nearest neighbours whose recall, measured by
+// EvaluateRecall for the sample queries, is at least target, choosing the parameters with
+// the least mean latency. Tune considers approximation factors from 0 to 4 in combination
+// with budgets of distance evaluations doubling from k, taking for each factor the least
+// budget meeting the target. The exact search always meets the target, so Tune fails only
+// if target is not in (0, 1], returning ErrRecallTarget, or there are no queries or k is
+// not positive, returning ErrNoQueries. The chosen parameters are only as representative as
+// the sample queries are of the queries that will be made.
+func Tune(t *Tree, queries []Comparable, k int, target float64) (*ApproxSearcher, error) {
+	if !(target > 0 && target <= 1) {
+		return nil, ErrRecallTarget
+	}
+	if len(queries) == 0 || k <= 0 {
+		return nil, ErrNoQueries
+	}
+	var checks []int
+	for n := k; n < t.Count; n *= 2 {
+		checks = append(checks, n)
+	}
+	// No budget last.
+	checks = append(checks, 0)
+
+	var best *ApproxSearcher
+	for _, eps := range tuneEpsilons {
+		for _, n := range checks {
+			s := &ApproxSearcher{Tree: t, Epsilon: eps, Checks: n}
+			s.Stats = EvaluateRecall(t, s.NearestN, queries, k)
+			if s.Stats.Recall < target {
+				continue
+			}
+			if best == nil || s.Stats.Mean < best.Stats.Mean {
+				best = s
+			}
+			// Greater budgets only add latency.
+			break
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,52 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestTune(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := New(randForestPoints(rnd, 2000, 6), false)
+	sample := make([]Comparable, 100)
+	for i, p := range randForestPoints(rnd, len(sample), 6) {
+		sample[i] = p
+	}
+	const (
+		k      = 5
+		target = 0.9
+	)
+	a, err := Tune(t, sample, k, target)
+	c.Assert(err, check.IsNil)
+	c.Check(a.Stats.Recall >= target, check.Equals, true, check.Commentf("%+v", a.Stats))
+	c.Check(a.Stats.Queries, check.Equals, len(sample))
+	c.Check(EvaluateRecall(t, a.NearestN, sample, k).Recall, check.Equals, a.Stats.Recall)
+
+	held := make([]Comparable, 100)
+	for i, p := range randForestPoints(rnd, len(held), 6) {
+		held[i] = p
+	}
+	st := EvaluateRecall(t, a.NearestN, held, k)
+	c.Check(st.Recall > target-0.1, check.Equals, true, check.Commentf("%+v", st))
+
+	p, d := a.Nearest(held[0])
+	c.Check(held[0].Distance(p), check.Equals, d)
+
+	a, err = Tune(t, sample, k, 1)
+	c.Assert(err, check.IsNil)
+	c.Check(a.Stats.Recall, check.Equals, 1.)
+
+	_, err = Tune(t, sample, k, 0)
+	c.Check(err, check.Equals, ErrRecallTarget)
+	_, err = Tune(t, sample, k, 1.5)
+	c.Check(err, check.Equals, ErrRecallTarget)
+	_, err = Tune(t, nil, k, target)
+	c.Check(err, check.Equals, ErrNoQueries)
+	_, err = Tune(t, sample, 0, target)
+	c.Check(err, check.Equals, ErrNoQueries)
+}
@@ -0,0 +1,109 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// A Representative specifies the value chosen to represent the values within a voxel.
+type Representative int
+
+const (
+	// VoxelCentroid represents a voxel by the centroid of its values.
+	VoxelCentroid Representative = iota
+
+	// VoxelMedoid represents a voxel by its stored value nearest to the
+	// centroid of its values, an approximation of the voxel's medoid that
+	// retains the stored value rather than synthesizing a Point.
+	VoxelMedoid
+)
+
+// Voxelize buckets the values stored in the tree into a grid of cubic voxels with sides of
+// the given size, aligned with the origin, and returns one representative for each occupied
+// voxel, for example to build a smaller tree for real-time queries. Centroids are returned as
+// Points. Representatives are returned in lexical order of their voxels' grid coordinates.
+// Stored values must be of a type accepted by Point.Compare. Voxelize panics if size is not
+// positive.
+func (t *Tree) Voxelize(size float64, rep Representative) []Comparable {
+	if !(size > 0) {
+		panic("kdtree: invalid voxel size")
+	}
+	if t.Root == nil {
+		return nil
+	}
+	dims := t.Dims()
+	voxels := make(map[string]*voxel)
+	buf := make([]byte, 8*dims)
+	t.Do(func(c Comparable, _ *Bounding, _ int) bool {
+		cell := make([]int64, dims)
+		for d := range cell {
+			cell[d] = int64(math.Floor(at(c, Dim(d)) / size))
+			binary.BigEndian.PutUint64(buf[8*d:], uint64(cell[d]))
+		}
+		v, ok := voxels[string(buf)]
+		if !ok {
+			v = &voxel{cell: cell, sum: make(Point, dims)}
+			voxels[string(buf)] = v
+		}
+		v.values = append(v.values, c)
+		for d := range v.sum {
+			v.sum[d] += at(c, Dim(d))
+		}
+		return false
+	})
+
+	occupied := make(byCell, 0, len(voxels))
+	for _, v := range voxels {
+		occupied = append(occupied, v)
+	}
+	sort.Sort(occupied)
+	reps := make([]Comparable, len(occupied))
+	for i, v := range occupied {
+		centroid := v.sum
+		for d := range centroid {
+			centroid[d] /= float64(len(v.values))
+		}
+		if rep == VoxelCentroid {
+			reps[i] = centroid
+			continue
+		}
+		best, bestDist := v.values[0], inf
+		for _, c := range v.values {
+			var dist float64
+			for d, x := range centroid {
+				delta := at(c, Dim(d)) - x
+				dist += delta * delta
+			}
+			if dist < bestDist {
+				best, bestDist = c, dist
+			}
+		}
+		reps[i] = best
+	}
+	return reps
+}
+
+// voxel holds the values within a voxel and the sum of their coordinates.
+type voxel struct {
+	cell   []int64
+	values []Comparable
+	sum    Point
+}
+
+type byCell []*voxel
+
+func (v byCell) Len() int { return len(v) }
+func (v byCell) Less(i, j int) bool {
+	for d, c := range v[i].cell {
+		if c != v[j].cell[d] {
+			return c < v[j].cell[d]
+		}
+	}
+	return false
+}
+func (v byCell) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
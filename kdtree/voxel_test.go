@@ -0,0 +1,46 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestVoxelize(c *check.C) {
+	t := New(append(Points(nil), wpData...), false)
+	c.Check(t.Voxelize(5, VoxelCentroid), check.DeepEquals, []Comparable{
+		Point{2, 3}, Point{4, 7}, Point{20.0 / 3, 7.0 / 3}, Point{9, 6},
+	})
+	c.Check(t.Voxelize(5, VoxelMedoid), check.DeepEquals, []Comparable{
+		Point{2, 3}, Point{4, 7}, Point{7, 2}, Point{9, 6},
+	})
+	c.Check(t.Voxelize(100, VoxelCentroid), check.DeepEquals, []Comparable{Point{35.0 / 6, 23.0 / 6}})
+	c.Check(t.Voxelize(0.5, VoxelMedoid), check.HasLen, 6)
+	c.Check((&Tree{}).Voxelize(1, VoxelCentroid), check.HasLen, 0)
+	c.Check(func() { t.Voxelize(0, VoxelCentroid) }, check.Panics, "kdtree: invalid voxel size")
+
+	// Negative coordinates are bucketed by floor.
+	t = New(Points{{-0.5, 0.5}, {-0.25, 0.75}, {0.5, 0.5}}, false)
+	c.Check(t.Voxelize(1, VoxelCentroid), check.DeepEquals, []Comparable{Point{-0.375, 0.625}, Point{0.5, 0.5}})
+
+	rnd := rand.New(rand.NewSource(1))
+	p := make(Points, 1000)
+	for i := range p {
+		p[i] = Point{rnd.Float64(), rnd.Float64(), rnd.Float64()}
+	}
+	t = New(p, false)
+	reps := t.Voxelize(0.25, VoxelMedoid)
+	c.Check(len(reps) <= 64, check.Equals, true)
+	c.Check(len(reps) > 48, check.Equals, true)
+	seen := make(map[[3]int]bool)
+	for _, r := range reps {
+		v := r.(Point)
+		cell := [3]int{int(v[0] / 0.25), int(v[1] / 0.25), int(v[2] / 0.25)}
+		c.Check(seen[cell], check.Equals, false)
+		seen[cell] = true
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Log operation codes.
+const (
+	opInsert byte = iota + 1
+	opInsertBounded
+	opRemove
+)
+
+// ErrLogCorrupt is returned when a log record fails its integrity check.
+var ErrLogCorrupt = errors.New("kdtree: corrupt log record")
+
+// maxLogRecord is the largest accepted encoded point size in a log record.
+const maxLogRecord = 1 << 26
+
+// A Log writes an append-only record of Insert and Remove operations that may be
+// replayed onto a Tree by ReplayFrom.
+//
+// Each record is an operation byte, the uvarint length of the encoded point, the
+// point encoded by the Log's PointEncoder and the little-endian IEEE CRC-32 of the
+// preceding bytes of the record.
+type Log struct {
+	w   io.Writer
+	enc PointEncoder
+	buf bytes.Buffer
+}
+
+// NewLog returns a Log that writes records to w using enc to encode points. Each
+// record is written to w with a single Write call.
+func NewLog(w io.Writer, enc PointEncoder) *Log {
+	return &Log{w: w, enc: enc}
+}
+
+// Insert records the insertion of c into a tree with the given bounding parameter.
+func (l *Log) Insert(c Comparable, bounding bool) error {
+	op := opInsert
+	if bounding {
+		op = opInsertBounded
+	}
+	return l.write(op, c)
+}
+
+// Remove records the removal of c from a tree.
+func (l *Log) Remove(c Comparable) error {
+	return l.write(opRemove, c)
+}
+
+func (l *Log) write(op byte, c Comparable) error {
+	l.buf.Reset()
+	err := l.enc.EncodePoint(&l.buf, c)
+	if err != nil {
+		return err
+	}
+	p := l.buf.Bytes()
+	rec := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(p)+4)
+	rec[0] = op
+	rec = rec[:1+binary.PutUvarint(rec[1:], uint64(len(p)))]
+	rec = append(rec, p...)
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.ChecksumIEEE(rec))
+	_, err = l.w.Write(append(rec, sum[:]...))
+	return err
+}
+
+// ReplayFrom applies the operations recorded by a Log in r to the tree, using dec to
+// decode points. It returns the number of operations applied. If the final record is
+// incomplete, as may happen when a process is interrupted while writing, ReplayFrom
+// returns io.ErrUnexpectedEOF after applying all complete records. A record failing
+// its integrity check results in ErrLogCorrupt, and an insertion of a point without the
+// dimensionality of the values in the tree results in ErrDimsMismatch.
+func (t *Tree) ReplayFrom(r io.Reader, dec PointDecoder) (int, error) {
+	br := bufio.NewReader(r)
+	var n int
+	for {
+		op, err := br.ReadByte()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return n, unexpected(err)
+		}
+		if length > maxLogRecord {
+			return n, ErrLogCorrupt
+		}
+		body := make([]byte, length+4)
+		_, err = io.ReadFull(br, body)
+		if err != nil {
+			return n, unexpected(err)
+		}
+		rec := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(body))
+		rec[0] = op
+		rec = append(rec[:1+binary.PutUvarint(rec[1:], length)], body[:length]...)
+		if crc32.ChecksumIEEE(rec) != binary.LittleEndian.Uint32(body[length:]) {
+			return n, ErrLogCorrupt
+		}
+		c, err := dec.DecodePoint(bytes.NewReader(body[:length]))
+		if err != nil {
+			return n, err
+		}
+		switch op {
+		case opInsert, opInsertBounded:
+			if t.Root != nil && c.Dims() != t.Dims() {
+				return n, ErrDimsMismatch
+			}
+			t.Insert(c, op == opInsertBounded)
+		case opRemove:
+			t.Remove(c)
+		default:
+			return n, ErrLogCorrupt
+		}
+		n++
+	}
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// A WAL maintains a Tree with durable incremental updates held in a directory. Operations
+// are recorded in a write-ahead log before being applied to the tree, and the tree is
+// periodically compacted into a snapshot, allowing a long-lived tree to be recovered after
+// a process restart without rebuilding from the original data.
+//
+// The directory holds a snapshot file, written by Tree.Marshal and prefixed with the
+// uvarint generation of the log that follows it, and a log file for that generation.
+type WAL struct {
+	// Tree is the maintained tree. It must not be altered except
+	// through the WAL.
+	Tree *Tree
+
+	// CompactEvery is the number of logged operations after which
+	// the tree is compacted into a new snapshot. If CompactEvery is
+	// zero, compaction only happens when Compact is called.
+	CompactEvery int
+
+	// Sync specifies whether the log is synced to stable storage
+	// after each operation.
+	Sync bool
+
+	dir string
+	enc PointEncoder
+	dec PointDecoder
+
+	gen  uint64
+	file *os.File
+	log  *Log
+	ops  int
+}
+
+const walSnapshot = "snapshot"
+
+func walLog(gen uint64) string { return fmt.Sprintf("log.%d", gen) }
+
+// OpenWAL opens the WAL held in dir, creating dir if it does not exist, and recovers the
+// tree from the snapshot and log held there. Points are encoded by enc and decoded by dec.
+// An incomplete final log record is discarded.
+func OpenWAL(dir string, enc PointEncoder, dec PointDecoder) (*WAL, error) {
+	err := os.MkdirAll(dir, 0777)
+	if err != nil {
+		return nil, err
+	}
+	w := &WAL{Tree: &Tree{}, dir: dir, enc: enc, dec: dec}
+
+	f, err := os.Open(filepath.Join(dir, walSnapshot))
+	switch {
+	case err == nil:
+		br := bufio.NewReader(f)
+		w.gen, err = binary.ReadUvarint(br)
+		if err == nil {
+			w.Tree, err = Unmarshal(br, dec)
+		}
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	var torn bool
+	f, err = os.Open(filepath.Join(dir, walLog(w.gen)))
+	switch {
+	case err == nil:
+		w.ops, err = w.Tree.ReplayFrom(f, dec)
+		f.Close()
+		if err == io.ErrUnexpectedEOF {
+			torn, err = true, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	if torn {
+		// Discard the incomplete record by compacting into a new generation.
+		return w, w.Compact()
+	}
+	return w, w.openLog()
+}
+
+func (w *WAL) openLog() error {
+	f, err := os.OpenFile(filepath.Join(w.dir, walLog(w.gen)), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.log = NewLog(&truncWriter{f: f, size: fi.Size()}, w.enc)
+	return nil
+}
+
+// truncWriter appends to a file, truncating the file to its previous length when a write
+// fails so that a partially written record does not precede later records.
+type truncWriter struct {
+	f    *os.File
+	size int64
+}
+
+func (w *truncWriter) Write(b []byte) (int, error) {
+	n, err := w.f.Write(b)
+	if err != nil {
+		w.f.Truncate(w.size)
+		return n, err
+	}
+	w.size += int64(n)
+	return n, nil
+}
+
+// Insert records the insertion of c and inserts it into the tree as described for Tree.Insert.
+// Insert returns ErrDimsMismatch, recording nothing, if c does not have the dimensionality of
+// the values in the tree.
+func (w *WAL) Insert(c Comparable, bounding bool) error {
+	if w.Tree.Root != nil && c.Dims() != w.Tree.Dims() {
+		return ErrDimsMismatch
+	}
+	err := w.record(w.log.Insert(c, bounding))
+	if err != nil {
+		return err
+	}
+	w.Tree.Insert(c, bounding)
+	return w.maybeCompact()
+}
+
+// Remove records the removal of c and removes it from the tree as described for Tree.Remove.
+func (w *WAL) Remove(c Comparable) (bool, error) {
+	err := w.record(w.log.Remove(c))
+	if err != nil {
+		return false, err
+	}
+	ok := w.Tree.Remove(c)
+	return ok, w.maybeCompact()
+}
+
+func (w *WAL) record(err error) error {
+	if err != nil {
+		return err
+	}
+	w.ops++
+	if w.Sync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+func (w *WAL) maybeCompact() error {
+	if w.CompactEvery > 0 && w.ops >= w.CompactEvery {
+		return w.Compact()
+	}
+	return nil
+}
+
+// Compact writes the current tree to a new snapshot and starts a new, empty log.
+func (w *WAL) Compact() error {
+	tmp := filepath.Join(w.dir, walSnapshot+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	var hdr [binary.MaxVarintLen64]byte
+	_, err = f.Write(hdr[:binary.PutUvarint(hdr[:], w.gen+1)])
+	if err == nil {
+		err = w.Tree.Marshal(f, w.enc)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(w.dir, walSnapshot))
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// The rename must be durable before the old log is
+	// removed, or a crash could lose the logged operations.
+	err = syncDir(w.dir)
+	if err != nil {
+		return err
+	}
+
+	if w.file != nil {
+		w.file.Close()
+	}
+	old := walLog(w.gen)
+	w.gen++
+	w.ops = 0
+	err = w.openLog()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(w.dir, old))
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return err
+}
+
+// Close closes the log file. The tree remains usable but may no longer be altered
+// through the WAL.
+func (w *WAL) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
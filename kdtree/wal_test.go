@@ -0,0 +1,106 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestReplayFrom(c *check.C) {
+	var buf bytes.Buffer
+	l := NewLog(&buf, PointCodec{})
+	want := New(append(Points(nil), wpData...), true)
+	for _, p := range []Point{{0, 0}, {10, 10}, {5, 5}} {
+		c.Assert(l.Insert(p, true), check.IsNil)
+		want.Insert(p, true)
+	}
+	c.Assert(l.Remove(Point{5, 4}), check.IsNil)
+	want.Remove(Point{5, 4})
+
+	got := New(append(Points(nil), wpData...), true)
+	n, err := got.ReplayFrom(bytes.NewReader(buf.Bytes()), PointCodec{})
+	c.Check(err, check.IsNil)
+	c.Check(n, check.Equals, 4)
+	c.Check(got.Root, check.DeepEquals, want.Root)
+	c.Check(got.Count, check.Equals, want.Count)
+
+	got = &Tree{}
+	n, err = got.ReplayFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), PointCodec{})
+	c.Check(err, check.Equals, io.ErrUnexpectedEOF)
+	c.Check(n, check.Equals, 3)
+
+	bad := append([]byte(nil), buf.Bytes()...)
+	bad[3] ^= 0xff
+	_, err = (&Tree{}).ReplayFrom(bytes.NewReader(bad), PointCodec{})
+	c.Check(err, check.Equals, ErrLogCorrupt)
+
+	c.Assert(l.Insert(Point{1, 2, 3}, false), check.IsNil)
+	n, err = (&Tree{}).ReplayFrom(bytes.NewReader(buf.Bytes()), PointCodec{})
+	c.Check(err, check.Equals, ErrDimsMismatch)
+	c.Check(n, check.Equals, 4)
+}
+
+func (s *S) TestWAL(c *check.C) {
+	dir := c.MkDir()
+	w, err := OpenWAL(dir, PointCodec{}, PointCodec{})
+	c.Assert(err, check.IsNil)
+	w.CompactEvery = 7
+
+	var want Points
+	for i := 0; i < 50; i++ {
+		p := Point{rand.Float64(), rand.Float64()}
+		c.Assert(w.Insert(p, true), check.IsNil)
+		want = append(want, p)
+		if i%5 == 4 {
+			ok, err := w.Remove(want[0])
+			c.Assert(err, check.IsNil)
+			c.Check(ok, check.Equals, true)
+			want = want[1:]
+		}
+	}
+	c.Assert(w.Close(), check.IsNil)
+
+	verify := func(t *Tree) {
+		c.Check(t.Len(), check.Equals, len(want))
+		for _, p := range want {
+			q, d := t.Nearest(p)
+			c.Check(q, check.DeepEquals, p)
+			c.Check(d, check.Equals, 0.)
+		}
+	}
+
+	r, err := OpenWAL(dir, PointCodec{}, PointCodec{})
+	c.Assert(err, check.IsNil)
+	c.Check(r.Tree.Root, check.DeepEquals, w.Tree.Root)
+	verify(r.Tree)
+
+	// Mismatched insertions are not logged.
+	c.Check(r.Insert(Point{1, 2, 3}, true), check.Equals, ErrDimsMismatch)
+	verify(r.Tree)
+
+	// Simulate a crash during a log write.
+	p := Point{-1, -1}
+	c.Assert(r.Insert(p, true), check.IsNil)
+	c.Assert(r.Close(), check.IsNil)
+	logPath := filepath.Join(dir, walLog(r.gen))
+	fi, err := os.Stat(logPath)
+	c.Assert(err, check.IsNil)
+	c.Assert(os.Truncate(logPath, fi.Size()-1), check.IsNil)
+
+	r, err = OpenWAL(dir, PointCodec{}, PointCodec{})
+	c.Assert(err, check.IsNil)
+	verify(r.Tree)
+	c.Check(r.Close(), check.IsNil)
+
+	logs, err := filepath.Glob(filepath.Join(dir, "log.*"))
+	c.Assert(err, check.IsNil)
+	c.Check(logs, check.HasLen, 1)
+}
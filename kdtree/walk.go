@@ -0,0 +1,27 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+// Walk calls fn on each node of the tree in pre-order with path holding the ancestors of
+// the node, from the root to the node's parent. The path of the root is empty. The path
+// slice is reused between calls and must not be retained or altered by fn. If fn returns
+// true the walk is stopped. Walk returns whether the walk was stopped.
+func (t *Tree) Walk(fn func(path []*Node, n *Node) (done bool)) bool {
+	if t.Root == nil {
+		return false
+	}
+	path := make([]*Node, 0, 32)
+	return t.Root.walkPath(fn, &path)
+}
+
+func (n *Node) walkPath(fn func([]*Node, *Node) bool, path *[]*Node) bool {
+	if fn(*path, n) {
+		return true
+	}
+	*path = append(*path, n)
+	defer func() { *path = (*path)[:len(*path)-1] }()
+	return (n.Left != nil && n.Left.walkPath(fn, path)) ||
+		(n.Right != nil && n.Right.walkPath(fn, path))
+}
@@ -0,0 +1,49 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestWalk(c *check.C) {
+	c.Check((&Tree{}).Walk(func([]*Node, *Node) bool { return true }), check.Equals, false)
+
+	t := New(append(Points(nil), bData...), false)
+	var (
+		n     int
+		depth = make(map[*Node]int)
+	)
+	killed := t.Walk(func(path []*Node, nd *Node) bool {
+		n++
+		if len(path) == 0 {
+			c.Check(nd, check.Equals, t.Root)
+		} else {
+			parent := path[len(path)-1]
+			c.Check(parent.Left == nd || parent.Right == nd, check.Equals, true)
+			c.Check(path[0], check.Equals, t.Root)
+			for i, a := range path[1:] {
+				c.Check(path[i].Left == a || path[i].Right == a, check.Equals, true)
+			}
+		}
+		depth[nd] = len(path)
+		return false
+	})
+	c.Check(killed, check.Equals, false)
+	c.Check(n, check.Equals, t.Count)
+
+	var max int
+	for _, d := range depth {
+		if d > max {
+			max = d
+		}
+	}
+	c.Check(max+1, check.Equals, t.Height())
+
+	n = 0
+	killed = t.Walk(func([]*Node, *Node) bool { n++; return n == 5 })
+	c.Check(killed, check.Equals, true)
+	c.Check(n, check.Equals, 5)
+}
@@ -0,0 +1,82 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// SampleWeighted returns k values drawn at random with replacement from the values stored in
+// the tree, each with probability proportional to its weight, in the order they were drawn.
+// Weights must be non-negative; values of zero weight are never drawn. SampleWeighted returns
+// nil if k is not positive or the total weight is zero.
+//
+// If the tree is augmented by a SumAugmenter, whose Value must return the same weights as
+// weight, each value is drawn by a single descent of the tree guided by the weight sums of
+// its subtrees, taking O(log n) time for a balanced tree; otherwise the weights of all the
+// values are evaluated once before drawing.
+func (t *Tree) SampleWeighted(k int, weight func(Comparable) float64, rnd *rand.Rand) []Comparable {
+	if t.Root == nil || k <= 0 {
+		return nil
+	}
+	if _, ok := t.Augmenter.(SumAugmenter); ok {
+		total := t.Root.Summary.(float64)
+		if !(total > 0) {
+			return nil
+		}
+		samples := make([]Comparable, 0, k)
+		for len(samples) < k {
+			// A value of zero weight may be reached only
+			// through rounding and is drawn again.
+			if c := t.Root.weighted(rnd.Float64()*total, weight).Point; weight(c) > 0 {
+				samples = append(samples, c)
+			}
+		}
+		return samples
+	}
+
+	var (
+		values []Comparable
+		cum    []float64
+		total  float64
+	)
+	t.Root.do(func(c Comparable, _ *Bounding, _ int) bool {
+		total += weight(c)
+		values = append(values, c)
+		cum = append(cum, total)
+		return false
+	}, 0)
+	if !(total > 0) {
+		return nil
+	}
+	samples := make([]Comparable, k)
+	for i := range samples {
+		r := rnd.Float64() * total
+		samples[i] = values[sort.Search(len(cum), func(j int) bool { return cum[j] > r })]
+	}
+	return samples
+}
+
+// weighted returns the node of the subtree rooted at n holding the value at weight offset r
+// in order, using the weight sums held by SumAugmenter summaries.
+func (n *Node) weighted(r float64, weight func(Comparable) float64) *Node {
+	for {
+		if n.Left != nil {
+			lw := n.Left.Summary.(float64)
+			if r < lw {
+				n = n.Left
+				continue
+			}
+			r -= lw
+		}
+		w := weight(n.Point)
+		if r < w || n.Right == nil {
+			return n
+		}
+		r -= w
+		n = n.Right
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestSampleWeighted(c *check.C) {
+	// Values are weighted by their first coordinate
+	// less two, so (2, 3) is never drawn.
+	weight := func(c Comparable) float64 { return c.(Point)[0] - 2 }
+	var total float64
+	for _, p := range wpData {
+		total += weight(p)
+	}
+	for _, augmented := range []bool{false, true} {
+		t := New(append(Points(nil), wpData...), false)
+		if augmented {
+			t.Augment(SumAugmenter{Value: weight})
+		}
+		rnd := rand.New(rand.NewSource(1))
+		const n = 20000
+		counts := make(map[float64]int)
+		samples := t.SampleWeighted(n, weight, rnd)
+		c.Assert(samples, check.HasLen, n)
+		for _, v := range samples {
+			counts[v.(Point)[0]]++
+		}
+		c.Check(counts[2], check.Equals, 0)
+		for _, p := range wpData[1:] {
+			want := n * weight(p) / total
+			got := float64(counts[p[0]])
+			c.Check(math.Abs(got-want) < 4*math.Sqrt(want), check.Equals, true,
+				check.Commentf("augmented=%t %v: got %v want %v", augmented, p, got, want))
+		}
+		c.Check(t.SampleWeighted(0, weight, rnd), check.HasLen, 0)
+	}
+	t := New(append(Points(nil), wpData...), false)
+	c.Check(t.SampleWeighted(3, func(Comparable) float64 { return 0 }, rand.New(rand.NewSource(1))), check.HasLen, 0)
+	c.Check((&Tree{}).SampleWeighted(3, weight, rand.New(rand.NewSource(1))), check.HasLen, 0)
+}
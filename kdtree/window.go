@@ -0,0 +1,223 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"container/heap"
+	"time"
+)
+
+var _ SpatialIndex = (*Window)(nil)
+
+// minCompact is the least number of insertions between automatic compactions of a Window.
+const minCompact = 64
+
+// A Window is a SpatialIndex holding the values inserted within a sliding window of time.
+// Each value is inserted with a timestamp and expires once it is older than the window.
+// Expired values are removed from the underlying tree at the start of each query, and the
+// tree is periodically compacted by rebuilding it from its live values, restoring the
+// balance lost to the insertions and removals. A Window is not safe for concurrent use.
+type Window struct {
+	tree   *Tree
+	window time.Duration
+	now    func() time.Time
+
+	// queue holds the values of the tree
+	// ordered by timestamp.
+	queue timedQueue
+
+	// inserts is the number of insertions
+	// since the last compaction.
+	inserts int
+}
+
+// NewWindow returns an empty Window holding values for the duration window. The current
+// time is obtained by calling now; if now is nil, time.Now is used.
+func NewWindow(window time.Duration, now func() time.Time) *Window {
+	if now == nil {
+		now = time.Now
+	}
+	return &Window{tree: &Tree{}, window: window, now: now}
+}
+
+// timedValue is a value held by a Window and its timestamp.
+type timedValue struct {
+	Comparable
+	at time.Time
+
+	// removed is whether the value has been
+	// removed from the tree by Remove.
+	removed bool
+}
+
+func untimed(c Comparable) Comparable {
+	if v, ok := c.(*timedValue); ok {
+		return v.Comparable
+	}
+	return c
+}
+
+func (v *timedValue) Compare(c Comparable, d Dim) float64 { return v.Comparable.Compare(untimed(c), d) }
+func (v *timedValue) Distance(c Comparable) float64       { return v.Comparable.Distance(untimed(c)) }
+func (v *timedValue) coord(d Dim) float64                 { return at(v.Comparable, d) }
+
+type timedQueue []*timedValue
+
+func (q timedQueue) Len() int            { return len(q) }
+func (q timedQueue) Less(i, j int) bool  { return q[i].at.Before(q[j].at) }
+func (q timedQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *timedQueue) Push(x interface{}) { *q = append(*q, x.(*timedValue)) }
+func (q *timedQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
+
+// Insert adds c to the window with the current time as its timestamp. Insert returns
+// ErrPointDims if c does not have the dimensionality of the values in the window.
+func (w *Window) Insert(c Comparable) error { return w.InsertAt(c, w.now()) }
+
+// InsertAt adds c to the window with the timestamp at. Timestamps need not be inserted in
+// order. A value whose timestamp is already outside the window is removed by the next
+// query. InsertAt returns ErrPointDims if c does not have the dimensionality of the values
+// in the window.
+func (w *Window) InsertAt(c Comparable, at time.Time) error {
+	if w.tree.Root != nil && c.Dims() != w.tree.Dims() {
+		return ErrPointDims
+	}
+	v := &timedValue{Comparable: c, at: at}
+	w.tree.Insert(v, false)
+	heap.Push(&w.queue, v)
+	w.inserts++
+	if w.inserts >= minCompact && w.inserts >= w.tree.Count {
+		w.Compact()
+	}
+	return nil
+}
+
+// Expire removes the values whose timestamps are older than the window from the
+// underlying tree, returning the number of values removed.
+func (w *Window) Expire() int {
+	cutoff := w.now().Add(-w.window)
+	var n int
+	for len(w.queue) != 0 && w.queue[0].at.Before(cutoff) {
+		v := heap.Pop(&w.queue).(*timedValue)
+		if v.removed {
+			continue
+		}
+		target := w.tree.Root.find(v)
+		if target == nil {
+			continue
+		}
+		f := w.tree.fixup()
+		w.tree.Root, _ = w.tree.Root.removeTarget(target, f)
+		w.tree.Count--
+		n++
+	}
+	return n
+}
+
+// find returns the node in the subtree rooted at n holding v. Both subtrees are searched
+// when v lies on the splitting plane of a node.
+func (n *Node) find(v *timedValue) *Node {
+	if n == nil {
+		return nil
+	}
+	if n.Point == Comparable(v) {
+		return n
+	}
+	c := v.Compare(n.Point, n.Plane)
+	if c <= 0 {
+		if m := n.Left.find(v); m != nil {
+			return m
+		}
+	}
+	if c >= 0 {
+		return n.Right.find(v)
+	}
+	return nil
+}
+
+// Compact removes expired values and rebuilds the underlying tree from the values that
+// remain. Compact is called automatically by InsertAt once the number of insertions since
+// the last compaction reaches the number of values held.
+func (w *Window) Compact() {
+	w.Expire()
+	w.tree.Rebuild(false)
+	w.inserts = 0
+}
+
+// Tree returns the underlying tree, holding the values of the window wrapped with their
+// timestamps. The tree must not be altered.
+func (w *Window) Tree() *Tree { return w.tree }
+
+// Len returns the number of unexpired values in the window.
+func (w *Window) Len() int {
+	w.Expire()
+	return w.tree.Count
+}
+
+// Remove removes a single value from the window that has the same coordinates as c,
+// returning whether a value was removed.
+func (w *Window) Remove(c Comparable) bool {
+	w.Expire()
+	if w.tree.Root == nil || c.Dims() != w.tree.Dims() {
+		return false
+	}
+	var removed *timedValue
+	f := w.tree.fixup()
+	var ok bool
+	w.tree.Root, ok = w.tree.Root.remove(c, func(n *Node) bool {
+		if !sameCoords(c, n.Point) {
+			return false
+		}
+		removed = n.Point.(*timedValue)
+		return true
+	}, f)
+	if !ok {
+		return false
+	}
+	removed.removed = true
+	w.tree.Count--
+	return true
+}
+
+// Nearest returns the nearest unexpired value to the query and the distance between them.
+func (w *Window) Nearest(q Comparable) (Comparable, float64) {
+	w.Expire()
+	if w.tree.Root == nil {
+		return nil, inf
+	}
+	c, d := w.tree.Nearest(&timedValue{Comparable: q})
+	return untimed(c), d
+}
+
+// NearestN returns the n nearest unexpired values to the query in min sorted order.
+func (w *Window) NearestN(q Comparable, n int) []ComparableDist {
+	w.Expire()
+	return untimedDists(w.tree.NearestN(&timedValue{Comparable: q}, n))
+}
+
+// InRange returns the unexpired values within distance d of the query in min sorted order.
+func (w *Window) InRange(q Comparable, d float64) []ComparableDist {
+	w.Expire()
+	return untimedDists(w.tree.InRange(&timedValue{Comparable: q}, d))
+}
+
+func untimedDists(h []ComparableDist) []ComparableDist {
+	for i, c := range h {
+		h[i].Comparable = untimed(c.Comparable)
+	}
+	return h
+}
+
+// DoBounded performs fn on all unexpired values within the bound b, returning whether the
+// traversal was interrupted. If b is nil, all unexpired values are visited.
+func (w *Window) DoBounded(fn Operation, b *Bounding) bool {
+	w.Expire()
+	return w.tree.DoBounded(func(c Comparable, b *Bounding, depth int) bool {
+		return fn(untimed(c), b, depth)
+	}, b)
+}
@@ -0,0 +1,118 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"math/rand"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// clock is a manually advanced time source.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func (s *S) TestWindow(c *check.C) {
+	clk := &clock{t: time.Unix(0, 0)}
+	w := NewWindow(10*time.Minute, clk.now)
+	for i, p := range wpData {
+		c.Assert(w.Insert(p), check.IsNil)
+		if i < len(wpData)-1 {
+			clk.advance(time.Minute)
+		}
+	}
+	c.Check(w.Insert(Point{1}), check.Equals, ErrPointDims)
+	c.Check(w.Len(), check.Equals, 6)
+	nn, _ := w.Nearest(Point{2, 3})
+	c.Check(nn, check.DeepEquals, Point{2, 3})
+
+	// (2,3) was inserted at t=0 and (5,4) at t=1m.
+	clk.advance(5*time.Minute + 30*time.Second)
+	c.Check(w.Len(), check.Equals, 5)
+	nn, _ = w.Nearest(Point{2, 3})
+	c.Check(nn, check.DeepEquals, Point{5, 4})
+	clk.advance(time.Minute)
+	nn, _ = w.Nearest(Point{2, 3})
+	c.Check(nn, check.DeepEquals, Point{4, 7})
+	c.Check(w.NearestN(Point{2, 3}, 10), check.HasLen, 4)
+	c.Check(w.InRange(Point{8, 1}, 2), check.DeepEquals, []ComparableDist{{Point{8, 1}, 0}, {Point{7, 2}, 2}})
+	var n int
+	w.DoBounded(func(v Comparable, _ *Bounding, _ int) bool {
+		_, ok := v.(Point)
+		c.Check(ok, check.Equals, true)
+		n++
+		return false
+	}, &Bounding{Point{0, 0}, Point{8, 8}})
+	c.Check(n, check.Equals, 3)
+
+	c.Check(w.Remove(Point{9, 6}), check.Equals, true)
+	c.Check(w.Remove(Point{9, 6}), check.Equals, false)
+	c.Check(w.Len(), check.Equals, 3)
+
+	// Values inserted with old timestamps expire at the next query.
+	c.Check(w.InsertAt(Point{0, 0}, clk.now().Add(-time.Hour)), check.IsNil)
+	c.Check(w.Len(), check.Equals, 3)
+
+	clk.advance(time.Hour)
+	c.Check(w.Len(), check.Equals, 0)
+	nn, _ = w.Nearest(Point{0, 0})
+	c.Check(nn, check.IsNil)
+	c.Check(w.Tree().Root, check.IsNil)
+
+	// Duplicate values expire individually.
+	for i := 0; i < 3; i++ {
+		w.Insert(Point{1, 1})
+		w.Insert(Point{1, 2})
+		clk.advance(time.Minute)
+	}
+	clk.advance(7*time.Minute + 30*time.Second)
+	for i := 2; i >= 0; i-- {
+		c.Check(w.NearestN(Point{1, 1}, 10), check.HasLen, 2*i)
+		clk.advance(time.Minute)
+	}
+	c.Check(w.Len(), check.Equals, 0)
+}
+
+func (s *S) TestWindowStream(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	clk := &clock{t: time.Unix(0, 0)}
+	w := NewWindow(time.Minute, clk.now)
+	type event struct {
+		p  Point
+		at time.Time
+	}
+	var events []event
+	for i := 0; i < 5000; i++ {
+		clk.advance(time.Duration(rnd.Intn(100)) * time.Millisecond)
+		p := Point{rnd.Float64(), rnd.Float64()}
+		// Some events arrive late.
+		at := clk.now().Add(-time.Duration(rnd.Intn(2000)) * time.Millisecond)
+		c.Assert(w.InsertAt(p, at), check.IsNil)
+		events = append(events, event{p, at})
+		if i%500 == 0 {
+			var live int
+			for _, e := range events {
+				if !e.at.Before(clk.now().Add(-time.Minute)) {
+					live++
+				}
+			}
+			c.Check(w.Len(), check.Equals, live)
+		}
+	}
+	// Compaction keeps the tree shallow.
+	c.Check(w.Tree().Height() < 4*depthBound(w.Tree().Count), check.Equals, true)
+}
+
+// depthBound returns the height of a balanced tree holding n values.
+func depthBound(n int) int {
+	var h int
+	for ; n > 0; n >>= 1 {
+		h++
+	}
+	return h
+}
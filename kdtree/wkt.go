@@ -0,0 +1,208 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrGeometry is returned when a WKT or WKB geometry is not a valid POINT.
+var ErrGeometry = errors.New("kdtree: invalid point geometry")
+
+// ParseWKT returns the Point described by the Well-Known Text POINT geometry in s.
+// Two dimensional and Z geometries are accepted, as is the PostGIS extended form
+// with a leading SRID. Measure (M) values are discarded. POINT EMPTY is rejected.
+func ParseWKT(s string) (Point, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			return nil, ErrGeometry
+		}
+		s = strings.TrimSpace(s[i+1:])
+	}
+	if len(s) < 5 || !strings.EqualFold(s[:5], "POINT") {
+		return nil, ErrGeometry
+	}
+	s = strings.TrimSpace(s[5:])
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return nil, ErrGeometry
+	}
+	var hasZ, hasM bool
+	switch strings.ToUpper(strings.TrimSpace(s[:open])) {
+	case "":
+	case "Z":
+		hasZ = true
+	case "M":
+		hasM = true
+	case "ZM":
+		hasZ, hasM = true, true
+	default:
+		return nil, ErrGeometry
+	}
+	fields := strings.Fields(s[open+1 : len(s)-1])
+	if !hasZ && !hasM && len(fields) != 2 {
+		// Untagged geometries may carry a Z, or Z and M, coordinate.
+		switch len(fields) {
+		case 3:
+			hasZ = true
+		case 4:
+			hasZ, hasM = true, true
+		}
+	}
+	want := 2
+	if hasZ {
+		want++
+	}
+	if hasM {
+		want++
+	}
+	if len(fields) != want {
+		return nil, ErrGeometry
+	}
+	if hasM {
+		fields = fields[:want-1]
+	}
+	p := make(Point, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, ErrGeometry
+		}
+		p[i] = v
+	}
+	return p, nil
+}
+
+// MarshalWKT returns the Well-Known Text POINT representation of p. p must have two or
+// three dimensions.
+func (p Point) MarshalWKT() (string, error) {
+	var tag string
+	switch len(p) {
+	case 2:
+	case 3:
+		tag = "Z "
+	default:
+		return "", fmt.Errorf("kdtree: cannot represent %d dimensional point as WKT", len(p))
+	}
+	coords := make([]string, len(p))
+	for i, v := range p {
+		coords[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return "POINT " + tag + "(" + strings.Join(coords, " ") + ")", nil
+}
+
+// WKB geometry type codes and extended WKB flags.
+const (
+	wkbPoint  = 1
+	wkbZ      = 1000
+	wkbM      = 2000
+	ewkbZ     = 0x80000000
+	ewkbM     = 0x40000000
+	ewkbSRID  = 0x20000000
+	ewkbFlags = ewkbZ | ewkbM | ewkbSRID
+	wkbXDR    = 0
+	wkbNDR    = 1
+)
+
+// ParseWKB returns the Point described by the Well-Known Binary POINT geometry in b.
+// Both ISO and PostGIS extended (EWKB) Z and M geometries are accepted. Measure values
+// are discarded. Points with NaN coordinates, which encode POINT EMPTY, are rejected.
+func ParseWKB(b []byte) (Point, error) {
+	if len(b) < 5 {
+		return nil, ErrGeometry
+	}
+	var order binary.ByteOrder
+	switch b[0] {
+	case wkbXDR:
+		order = binary.BigEndian
+	case wkbNDR:
+		order = binary.LittleEndian
+	default:
+		return nil, ErrGeometry
+	}
+	typ := order.Uint32(b[1:])
+	b = b[5:]
+	hasZ, hasM := typ&ewkbZ != 0, typ&ewkbM != 0
+	if typ&ewkbSRID != 0 {
+		if len(b) < 4 {
+			return nil, ErrGeometry
+		}
+		b = b[4:]
+	}
+	typ &^= ewkbFlags
+	switch typ / 1000 {
+	case 0:
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	default:
+		return nil, ErrGeometry
+	}
+	if typ%1000 != wkbPoint {
+		return nil, ErrGeometry
+	}
+	n := 2
+	if hasZ {
+		n++
+	}
+	if hasM {
+		n++
+	}
+	if len(b) != 8*n {
+		return nil, ErrGeometry
+	}
+	if hasM {
+		n--
+	}
+	p := make(Point, n)
+	for i := range p {
+		p[i] = math.Float64frombits(order.Uint64(b[8*i:]))
+		if math.IsNaN(p[i]) {
+			return nil, ErrGeometry
+		}
+	}
+	return p, nil
+}
+
+// ParseHexWKB returns the Point described by the hexadecimal encoded WKB or EWKB POINT
+// geometry in s, as used in PostGIS dumps.
+func ParseHexWKB(s string) (Point, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, ErrGeometry
+	}
+	return ParseWKB(b)
+}
+
+// MarshalWKB returns the little-endian ISO Well-Known Binary POINT representation of p.
+// p must have two or three dimensions.
+func (p Point) MarshalWKB() ([]byte, error) {
+	typ := uint32(wkbPoint)
+	switch len(p) {
+	case 2:
+	case 3:
+		typ += wkbZ
+	default:
+		return nil, fmt.Errorf("kdtree: cannot represent %d dimensional point as WKB", len(p))
+	}
+	b := make([]byte, 5+8*len(p))
+	b[0] = wkbNDR
+	binary.LittleEndian.PutUint32(b[1:], typ)
+	for i, v := range p {
+		binary.LittleEndian.PutUint64(b[5+8*i:], math.Float64bits(v))
+	}
+	return b, nil
+}
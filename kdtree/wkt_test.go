@@ -0,0 +1,86 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestParseWKT(c *check.C) {
+	for i, test := range []struct {
+		in   string
+		want Point
+		err  error
+	}{
+		{"POINT (1 2)", Point{1, 2}, nil},
+		{"point(1.5 -2e3)", Point{1.5, -2e3}, nil},
+		{"POINT Z (1 2 3)", Point{1, 2, 3}, nil},
+		{"POINT (1 2 3)", Point{1, 2, 3}, nil},
+		{"POINT M (1 2 3)", Point{1, 2}, nil},
+		{"POINT ZM (1 2 3 4)", Point{1, 2, 3}, nil},
+		{"SRID=4326;POINT(-71.06 42.36)", Point{-71.06, 42.36}, nil},
+		{"POINT EMPTY", nil, ErrGeometry},
+		{"POINT (1)", nil, ErrGeometry},
+		{"POINT Z (1 2)", nil, ErrGeometry},
+		{"POINT (a b)", nil, ErrGeometry},
+		{"LINESTRING (0 0, 1 1)", nil, ErrGeometry},
+	} {
+		p, err := ParseWKT(test.in)
+		c.Check(err, check.Equals, test.err, check.Commentf("Test %d: %q", i, test.in))
+		c.Check(p, check.DeepEquals, test.want, check.Commentf("Test %d: %q", i, test.in))
+	}
+}
+
+func (s *S) TestParseWKB(c *check.C) {
+	for i, test := range []struct {
+		in   string
+		want Point
+		err  error
+	}{
+		// ISO little-endian POINT (1 2).
+		{"0101000000000000000000f03f0000000000000040", Point{1, 2}, nil},
+		// Big-endian POINT (1 2).
+		{"00000000013ff00000000000004000000000000000", Point{1, 2}, nil},
+		// ISO POINT Z (1 2 3).
+		{"01e9030000000000000000f03f00000000000000400000000000000840", Point{1, 2, 3}, nil},
+		// EWKB SRID=4326;POINT(1 2) as produced by PostGIS.
+		{"0101000020e6100000000000000000f03f0000000000000040", Point{1, 2}, nil},
+		// EWKB POINT M (1 2 3).
+		{"0101000040000000000000f03f00000000000000400000000000000840", Point{1, 2}, nil},
+		// POINT EMPTY.
+		{"0101000000000000000000f87f000000000000f87f", nil, ErrGeometry},
+		// LINESTRING.
+		{"010200000000000000", nil, ErrGeometry},
+		{"01010000", nil, ErrGeometry},
+		{"zz", nil, ErrGeometry},
+	} {
+		p, err := ParseHexWKB(test.in)
+		c.Check(err, check.Equals, test.err, check.Commentf("Test %d: %q", i, test.in))
+		c.Check(p, check.DeepEquals, test.want, check.Commentf("Test %d: %q", i, test.in))
+	}
+}
+
+func (s *S) TestMarshalWKTWKB(c *check.C) {
+	for _, p := range []Point{{1, 2}, {-71.06, 42.36}, {1, 2, 3}} {
+		t, err := p.MarshalWKT()
+		c.Assert(err, check.IsNil)
+		got, err := ParseWKT(t)
+		c.Check(err, check.IsNil)
+		c.Check(got, check.DeepEquals, p)
+
+		b, err := p.MarshalWKB()
+		c.Assert(err, check.IsNil)
+		got, err = ParseWKB(b)
+		c.Check(err, check.IsNil)
+		c.Check(got, check.DeepEquals, p)
+	}
+	t, _ := Point{1, 2, 3}.MarshalWKT()
+	c.Check(t, check.Equals, "POINT Z (1 2 3)")
+
+	_, err := Point{1}.MarshalWKT()
+	c.Check(err, check.NotNil)
+	_, err = Point{1, 2, 3, 4}.MarshalWKB()
+	c.Check(err, check.NotNil)
+}
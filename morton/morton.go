@@ -0,0 +1,400 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package morton implements a linear quadtree indexing integer grid coordinates by their
+// Morton codes.
+//
+// A Morton, or Z-order, code interleaves the bits of a point's coordinates so that points
+// sharing a code prefix lie within the same cell of an implicit quadtree or octree. An
+// Index holds entries in a slice sorted by code, so each cell of the implicit tree is a
+// contiguous run of the slice found by binary search. Range and nearest neighbour
+// queries decompose the query into cells, visiting only the cells that hold entries.
+//
+// An Index is compact, is built by a single sort and may be serialized by writing its
+// entries in order.
+package morton
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"sort"
+)
+
+// MaxDims is the maximum number of dimensions of an Index.
+const MaxDims = 4
+
+// ErrDims is returned when an index is created with an invalid number of dimensions
+// and when a point does not have the dimensionality of an index.
+var ErrDims = errors.New("morton: invalid dimensions")
+
+// Bits returns the number of bits per coordinate available to codes of points with the
+// given dimensionality.
+func Bits(dims int) uint {
+	b := 64 / uint(dims)
+	if b > 32 {
+		b = 32
+	}
+	return b
+}
+
+// Encode returns the Morton code of the point p. Encode panics if a coordinate of p
+// does not fit in Bits(len(p)) bits or if len(p) is not in [1, MaxDims].
+func Encode(p []uint32) uint64 {
+	dims := len(p)
+	if dims < 1 || dims > MaxDims {
+		panic("morton: invalid dimensions")
+	}
+	bits := Bits(dims)
+	var code uint64
+	for d, v := range p {
+		if bits < 32 && v>>bits != 0 {
+			panic("morton: coordinate out of range")
+		}
+		for b := uint(0); b < bits; b++ {
+			code |= uint64(v>>b&1) << (b*uint(dims) + uint(d))
+		}
+	}
+	return code
+}
+
+// Decode returns the coordinates of the point with the given Morton code and
+// dimensionality.
+func Decode(code uint64, dims int) []uint32 {
+	p := make([]uint32, dims)
+	decode(code, p)
+	return p
+}
+
+func decode(code uint64, p []uint32) {
+	dims := uint(len(p))
+	bits := Bits(len(p))
+	for d := range p {
+		var v uint32
+		for b := uint(0); b < bits; b++ {
+			v |= uint32(code>>(b*dims+uint(d))&1) << b
+		}
+		p[d] = v
+	}
+}
+
+// An Entry is a value held by an Index and the Morton code of its location.
+type Entry struct {
+	Code  uint64
+	Value interface{}
+}
+
+// NewEntry returns an Entry holding v at the point p.
+func NewEntry(p []uint32, v interface{}) Entry {
+	return Entry{Code: Encode(p), Value: v}
+}
+
+// An Index is a linear quadtree.
+type Index struct {
+	dims    int
+	bits    uint
+	entries []Entry
+}
+
+// New returns an Index of the given dimensionality holding the entries in e. The entries
+// are sorted in place and retained by the Index.
+func New(dims int, e []Entry) (*Index, error) {
+	if dims < 1 || dims > MaxDims {
+		return nil, ErrDims
+	}
+	sort.Stable(byCode(e))
+	return &Index{dims: dims, bits: Bits(dims), entries: e}, nil
+}
+
+type byCode []Entry
+
+func (e byCode) Len() int           { return len(e) }
+func (e byCode) Less(i, j int) bool { return e[i].Code < e[j].Code }
+func (e byCode) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// Len returns the number of entries in the index.
+func (ix *Index) Len() int { return len(ix.entries) }
+
+// Dims returns the dimensionality of the index.
+func (ix *Index) Dims() int { return ix.dims }
+
+// Entries returns the entries of the index in code order. The returned slice must not be
+// altered.
+func (ix *Index) Entries() []Entry { return ix.entries }
+
+// Coords returns the coordinates of the entry e.
+func (ix *Index) Coords(e Entry) []uint32 { return Decode(e.Code, ix.dims) }
+
+// Insert adds v at the point p. Insert takes time linear in the size of the index; to
+// add many entries, construct a new index with New. Insert returns ErrDims, adding
+// nothing, if p does not have the dimensionality of the index.
+func (ix *Index) Insert(p []uint32, v interface{}) error {
+	if len(p) != ix.dims {
+		return ErrDims
+	}
+	e := NewEntry(p, v)
+	i := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].Code > e.Code })
+	ix.entries = append(ix.entries, Entry{})
+	copy(ix.entries[i+1:], ix.entries[i:])
+	ix.entries[i] = e
+	return nil
+}
+
+// Remove removes a single entry at the point p for which match returns true, returning
+// whether an entry was removed. If match is nil, any entry at p is removed. No entry is
+// removed if p does not have the dimensionality of the index.
+func (ix *Index) Remove(p []uint32, match func(interface{}) bool) bool {
+	if len(p) != ix.dims {
+		return false
+	}
+	code := Encode(p)
+	i := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].Code >= code })
+	for ; i < len(ix.entries) && ix.entries[i].Code == code; i++ {
+		if match == nil || match(ix.entries[i].Value) {
+			ix.entries = append(ix.entries[:i], ix.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// cell is a cell of the implicit tree at level, holding codes with the given prefix and the
+// entries with index range lo to hi.
+type cell struct {
+	prefix uint64
+	level  uint
+	lo, hi int
+}
+
+// mask returns the mask of code bits below the prefix of cells at the given level.
+func (ix *Index) mask(level uint) uint64 {
+	if level == 0 {
+		return 0
+	}
+	return ^uint64(0) >> (64 - level*uint(ix.dims))
+}
+
+// root returns the cell covering the whole index.
+func (ix *Index) root() cell {
+	return cell{level: ix.bits, lo: 0, hi: len(ix.entries)}
+}
+
+// children calls fn with each non-empty child of c.
+func (ix *Index) children(c cell, fn func(cell) bool) bool {
+	level := c.level - 1
+	shift := level * uint(ix.dims)
+	lo := c.lo
+	for i := uint64(0); i < 1<<uint(ix.dims) && lo < c.hi; i++ {
+		prefix := c.prefix | i<<shift
+		last := prefix | ix.mask(level)
+		sub := ix.entries[lo:c.hi]
+		hi := lo + sort.Search(len(sub), func(j int) bool { return sub[j].Code > last })
+		if hi > lo && fn(cell{prefix: prefix, level: level, lo: lo, hi: hi}) {
+			return true
+		}
+		lo = hi
+	}
+	return false
+}
+
+// extent returns the minimum and maximum coordinates of c.
+func (ix *Index) extent(c cell, min, max []uint32) {
+	decode(c.prefix, min)
+	for d := range min {
+		max[d] = min[d] + uint32(uint64(1)<<c.level-1)
+	}
+}
+
+// scanSize is the number of entries in a cell below which
+// the cell's entries are examined individually.
+const scanSize = 16
+
+// DoRange calls fn on each entry within the box from min to max inclusive. If fn returns
+// true the traversal is stopped. DoRange returns whether the traversal was stopped. Entries
+// are visited in code order.
+func (ix *Index) DoRange(fn func(Entry) (done bool), min, max []uint32) bool {
+	if len(min) != ix.dims || len(max) != ix.dims || len(ix.entries) == 0 {
+		return false
+	}
+	return ix.doRange(ix.root(), fn, min, max, make([]uint32, ix.dims), make([]uint32, ix.dims), make([]uint32, ix.dims))
+}
+
+func (ix *Index) doRange(c cell, fn func(Entry) bool, min, max, cmin, cmax, p []uint32) bool {
+	ix.extent(c, cmin, cmax)
+	inside := true
+	for d := range min {
+		if cmin[d] > max[d] || cmax[d] < min[d] {
+			return false
+		}
+		if cmin[d] < min[d] || cmax[d] > max[d] {
+			inside = false
+		}
+	}
+	if inside || c.hi-c.lo <= scanSize || c.level == 0 {
+		for _, e := range ix.entries[c.lo:c.hi] {
+			if !inside {
+				decode(e.Code, p)
+				if !within(p, min, max) {
+					continue
+				}
+			}
+			if fn(e) {
+				return true
+			}
+		}
+		return false
+	}
+	return ix.children(c, func(c cell) bool { return ix.doRange(c, fn, min, max, cmin, cmax, p) })
+}
+
+func within(p, min, max []uint32) bool {
+	for d, v := range p {
+		if v < min[d] || v > max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of entries within the box from min to max inclusive.
+func (ix *Index) Count(min, max []uint32) int {
+	if len(min) != ix.dims || len(max) != ix.dims || len(ix.entries) == 0 {
+		return 0
+	}
+	return ix.count(ix.root(), min, max, make([]uint32, ix.dims), make([]uint32, ix.dims), make([]uint32, ix.dims))
+}
+
+func (ix *Index) count(c cell, min, max, cmin, cmax, p []uint32) int {
+	ix.extent(c, cmin, cmax)
+	inside := true
+	for d := range min {
+		if cmin[d] > max[d] || cmax[d] < min[d] {
+			return 0
+		}
+		if cmin[d] < min[d] || cmax[d] > max[d] {
+			inside = false
+		}
+	}
+	if inside {
+		return c.hi - c.lo
+	}
+	var n int
+	if c.hi-c.lo <= scanSize || c.level == 0 {
+		for _, e := range ix.entries[c.lo:c.hi] {
+			decode(e.Code, p)
+			if within(p, min, max) {
+				n++
+			}
+		}
+		return n
+	}
+	ix.children(c, func(c cell) bool {
+		n += ix.count(c, min, max, cmin, cmax, p)
+		return false
+	})
+	return n
+}
+
+// A Result is an entry and its squared Euclidean distance from a query.
+type Result struct {
+	Entry
+	Dist float64
+}
+
+// NearestN returns the n entries nearest to the point q in order of increasing distance.
+// If the index holds fewer than n entries, all the entries are returned.
+func (ix *Index) NearestN(q []uint32, n int) []Result {
+	if n > len(ix.entries) {
+		n = len(ix.entries)
+	}
+	if n <= 0 || len(q) != ix.dims {
+		return nil
+	}
+	var (
+		res        []Result
+		queue      = cellQueue{{cell: ix.root()}}
+		cmin, cmax = make([]uint32, ix.dims), make([]uint32, ix.dims)
+		p          = make([]uint32, ix.dims)
+	)
+	worst := func() float64 {
+		if len(res) < n {
+			return inf
+		}
+		return res[len(res)-1].Dist
+	}
+	for queue.Len() != 0 {
+		qc := heap.Pop(&queue).(queuedCell)
+		if qc.dist > worst() {
+			break
+		}
+		c := qc.cell
+		if c.hi-c.lo <= scanSize || c.level == 0 {
+			for _, e := range ix.entries[c.lo:c.hi] {
+				decode(e.Code, p)
+				d := dist(q, p, p)
+				if d >= worst() {
+					continue
+				}
+				i := sort.Search(len(res), func(i int) bool { return res[i].Dist > d })
+				if len(res) < n {
+					res = append(res, Result{})
+				}
+				copy(res[i+1:], res[i:])
+				res[i] = Result{Entry: e, Dist: d}
+			}
+			continue
+		}
+		ix.children(c, func(c cell) bool {
+			ix.extent(c, cmin, cmax)
+			heap.Push(&queue, queuedCell{cell: c, dist: dist(q, cmin, cmax)})
+			return false
+		})
+	}
+	return res
+}
+
+// Nearest returns the entry nearest to the point q, its squared Euclidean distance from q
+// and whether the index holds any entries.
+func (ix *Index) Nearest(q []uint32) (Result, bool) {
+	r := ix.NearestN(q, 1)
+	if len(r) == 0 {
+		return Result{}, false
+	}
+	return r[0], true
+}
+
+var inf = math.Inf(1)
+
+// dist returns the squared distance from q to the nearest point in the box min to max.
+func dist(q, min, max []uint32) float64 {
+	var sum float64
+	for d, v := range q {
+		var delta float64
+		switch {
+		case v < min[d]:
+			delta = float64(min[d] - v)
+		case v > max[d]:
+			delta = float64(v - max[d])
+		}
+		sum += delta * delta
+	}
+	return sum
+}
+
+type queuedCell struct {
+	cell
+	dist float64
+}
+
+type cellQueue []queuedCell
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(queuedCell)) }
+func (q *cellQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
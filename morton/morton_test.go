@@ -0,0 +1,160 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package morton
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestEncode(c *check.C) {
+	c.Check(Encode([]uint32{0, 0}), check.Equals, uint64(0))
+	c.Check(Encode([]uint32{1, 0}), check.Equals, uint64(1))
+	c.Check(Encode([]uint32{0, 1}), check.Equals, uint64(2))
+	c.Check(Encode([]uint32{3, 3}), check.Equals, uint64(15))
+	c.Check(Encode([]uint32{1, 1, 1}), check.Equals, uint64(7))
+	c.Check(Encode([]uint32{2, 0, 0}), check.Equals, uint64(8))
+	c.Check(Encode([]uint32{1 << 31}), check.Equals, uint64(1<<31))
+
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= MaxDims; dims++ {
+		for i := 0; i < 100; i++ {
+			p := make([]uint32, dims)
+			for d := range p {
+				p[d] = uint32(rnd.Int63n(1 << Bits(dims)))
+			}
+			c.Check(Decode(Encode(p), dims), check.DeepEquals, p)
+		}
+	}
+	c.Check(func() { Encode([]uint32{1 << 21, 0, 0}) }, check.PanicMatches, "morton: coordinate out of range")
+	c.Check(func() { Encode(nil) }, check.PanicMatches, "morton: invalid dimensions")
+
+	_, err := New(MaxDims+1, nil)
+	c.Check(err, check.Equals, ErrDims)
+}
+
+type point struct {
+	p  []uint32
+	id int
+}
+
+func randIndex(c *check.C, rnd *rand.Rand, n, dims int, max uint32) (*Index, []point) {
+	pts := make([]point, n)
+	e := make([]Entry, n)
+	for i := range pts {
+		p := make([]uint32, dims)
+		for d := range p {
+			p[d] = uint32(rnd.Intn(int(max)))
+		}
+		pts[i] = point{p, i}
+		e[i] = NewEntry(p, i)
+	}
+	ix, err := New(dims, e)
+	c.Assert(err, check.Equals, nil)
+	return ix, pts
+}
+
+func (s *S) TestDoRange(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= MaxDims; dims++ {
+		ix, pts := randIndex(c, rnd, 2000, dims, 1000)
+		c.Check(sort.IsSorted(byCode(ix.Entries())), check.Equals, true)
+		for i := 0; i < 50; i++ {
+			min := make([]uint32, dims)
+			max := make([]uint32, dims)
+			for d := range min {
+				a, b := uint32(rnd.Intn(1000)), uint32(rnd.Intn(1000))
+				if a > b {
+					a, b = b, a
+				}
+				min[d], max[d] = a, b
+			}
+			want := make(map[int]bool)
+			for _, p := range pts {
+				if within(p.p, min, max) {
+					want[p.id] = true
+				}
+			}
+			got := make(map[int]bool)
+			ix.DoRange(func(e Entry) bool {
+				got[e.Value.(int)] = true
+				return false
+			}, min, max)
+			c.Check(got, check.DeepEquals, want)
+			c.Check(ix.Count(min, max), check.Equals, len(want))
+		}
+	}
+}
+
+func (s *S) TestNearest(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= MaxDims; dims++ {
+		ix, pts := randIndex(c, rnd, 2000, dims, 1<<Bits(dims)-1)
+		for i := 0; i < 50; i++ {
+			q := make([]uint32, dims)
+			for d := range q {
+				q[d] = uint32(rnd.Int63n(1 << Bits(dims)))
+			}
+			want := make([]float64, len(pts))
+			for j, p := range pts {
+				want[j] = dist(q, p.p, p.p)
+			}
+			sort.Float64s(want)
+			got := ix.NearestN(q, 10)
+			c.Assert(got, check.HasLen, 10)
+			for j, r := range got {
+				c.Check(r.Dist, check.Equals, want[j])
+				c.Check(dist(q, ix.Coords(r.Entry), ix.Coords(r.Entry)), check.Equals, r.Dist)
+			}
+			r, ok := ix.Nearest(q)
+			c.Check(ok, check.Equals, true)
+			c.Check(r.Dist, check.Equals, want[0])
+		}
+	}
+	empty, _ := New(2, nil)
+	_, ok := empty.Nearest([]uint32{1, 2})
+	c.Check(ok, check.Equals, false)
+}
+
+func (s *S) TestInsertRemove(c *check.C) {
+	ix, _ := New(2, nil)
+	c.Check(ix.Insert([]uint32{5, 5}, "a"), check.IsNil)
+	c.Check(ix.Insert([]uint32{1, 7}, "b"), check.IsNil)
+	c.Check(ix.Insert([]uint32{5, 5}, "c"), check.IsNil)
+	c.Check(ix.Len(), check.Equals, 3)
+	c.Check(sort.IsSorted(byCode(ix.Entries())), check.Equals, true)
+	c.Check(ix.Remove([]uint32{5, 5}, func(v interface{}) bool { return v == "c" }), check.Equals, true)
+	c.Check(ix.Remove([]uint32{5, 5}, func(v interface{}) bool { return v == "c" }), check.Equals, false)
+	c.Check(ix.Remove([]uint32{1, 7}, nil), check.Equals, true)
+	c.Check(ix.Len(), check.Equals, 1)
+	c.Check(ix.Insert([]uint32{1, 2, 3}, "d"), check.Equals, ErrDims)
+	c.Check(ix.Remove([]uint32{5, 5, 0}, nil), check.Equals, false)
+	c.Check(ix.Len(), check.Equals, 1)
+	r, _ := ix.Nearest([]uint32{0, 0})
+	c.Check(r.Value, check.Equals, "a")
+}
+
+func BenchmarkNew(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	e := make([]Entry, 1e5)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for j := range e {
+			e[j] = NewEntry([]uint32{uint32(rnd.Intn(1 << 20)), uint32(rnd.Intn(1 << 20))}, j)
+		}
+		b.StartTimer()
+		New(2, e)
+	}
+}
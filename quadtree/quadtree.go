@@ -0,0 +1,363 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package quadtree implements region quadtrees and octrees.
+//
+// A Tree covers a fixed region of two or three dimensional space that is recursively
+// divided into quadrants or octants as points are added. Insertion and removal are
+// local operations that never require rebalancing, making the structure suitable for
+// highly dynamic data where rebuilding a k-d tree would be too costly.
+//
+// Trees hold kdtree.Comparable values whose Compare and Distance methods accept
+// kdtree.Point parameters, such as kdtree.Point and kdtree.Datum, and queries follow
+// the kdtree package's API.
+package quadtree
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+const (
+	// DefaultCapacity is the default number of points held
+	// by a leaf before it is divided.
+	DefaultCapacity = 8
+
+	// maxDepth limits the division of regions holding many
+	// coincident points.
+	maxDepth = 32
+)
+
+var _ kdtree.SpatialIndex = (*Tree)(nil)
+
+var (
+	// ErrDims is returned when a region does not have two or three dimensions.
+	ErrDims = errors.New("quadtree: dimensions must be 2 or 3")
+
+	// ErrOutside is returned when inserting a point outside the region of the tree.
+	ErrOutside = errors.New("quadtree: point outside region")
+)
+
+type node struct {
+	min, max kdtree.Point
+	center   kdtree.Point
+
+	// points holds the values of a leaf.
+	points []kdtree.Comparable
+
+	// children holds the subregions of an
+	// internal node, or nil for a leaf.
+	children []*node
+
+	// count is the number of values held by
+	// the subtree rooted at the node.
+	count int
+}
+
+func newNode(min, max kdtree.Point) *node {
+	n := &node{min: min, max: max, center: make(kdtree.Point, len(min))}
+	for d := range min {
+		n.center[d] = (min[d] + max[d]) / 2
+	}
+	return n
+}
+
+// A Tree is a region quadtree when it covers two dimensions and a region octree when
+// it covers three.
+type Tree struct {
+	root     *node
+	capacity int
+}
+
+// New returns an empty tree covering the region from min to max inclusive. Leaves are
+// divided when they hold more than capacity values; if capacity is less than one,
+// DefaultCapacity is used.
+func New(min, max kdtree.Point, capacity int) (*Tree, error) {
+	if len(min) != len(max) || len(min) < 2 || len(min) > 3 {
+		return nil, ErrDims
+	}
+	for d := range min {
+		if !(min[d] <= max[d]) {
+			return nil, errors.New("quadtree: invalid region")
+		}
+	}
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	return &Tree{
+		root:     newNode(append(kdtree.Point(nil), min...), append(kdtree.Point(nil), max...)),
+		capacity: capacity,
+	}, nil
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree) Len() int { return t.root.count }
+
+// Bounds returns the region covered by the tree.
+func (t *Tree) Bounds() *kdtree.Bounding {
+	return &kdtree.Bounding{append(kdtree.Point(nil), t.root.min...), append(kdtree.Point(nil), t.root.max...)}
+}
+
+// contains returns whether c lies within the region of n.
+func (n *node) contains(c kdtree.Comparable) bool {
+	for d := range n.min {
+		if c.Compare(n.min, kdtree.Dim(d)) < 0 || c.Compare(n.max, kdtree.Dim(d)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// child returns the index of the child of n whose region holds c.
+func (n *node) child(c kdtree.Comparable) int {
+	var i int
+	for d := range n.center {
+		if c.Compare(n.center, kdtree.Dim(d)) >= 0 {
+			i |= 1 << uint(d)
+		}
+	}
+	return i
+}
+
+// divide converts the leaf n into an internal node, distributing its values.
+func (n *node) divide() {
+	n.children = make([]*node, 1<<uint(len(n.min)))
+	for i := range n.children {
+		min := make(kdtree.Point, len(n.min))
+		max := make(kdtree.Point, len(n.min))
+		for d := range min {
+			if i&(1<<uint(d)) == 0 {
+				min[d], max[d] = n.min[d], n.center[d]
+			} else {
+				min[d], max[d] = n.center[d], n.max[d]
+			}
+		}
+		n.children[i] = newNode(min, max)
+	}
+	for _, c := range n.points {
+		ch := n.children[n.child(c)]
+		ch.points = append(ch.points, c)
+		ch.count++
+	}
+	n.points = nil
+}
+
+// Insert adds c to the tree. Insert returns kdtree.ErrPointDims if c does not have the
+// dimensionality of the tree and ErrOutside if c is outside the tree's region.
+func (t *Tree) Insert(c kdtree.Comparable) error {
+	if c.Dims() != len(t.root.min) {
+		return kdtree.ErrPointDims
+	}
+	if !t.root.contains(c) {
+		return ErrOutside
+	}
+	n := t.root
+	for depth := 0; ; depth++ {
+		n.count++
+		if n.children == nil {
+			n.points = append(n.points, c)
+			if len(n.points) > t.capacity && depth < maxDepth {
+				n.divide()
+			}
+			return nil
+		}
+		n = n.children[n.child(c)]
+	}
+}
+
+// Remove removes a single value from the tree that has the same coordinates as c,
+// returning whether a value was removed. Regions left holding few enough values are
+// merged.
+func (t *Tree) Remove(c kdtree.Comparable) bool {
+	if c.Dims() != len(t.root.min) || !t.root.contains(c) {
+		return false
+	}
+	return t.root.remove(c, t.capacity)
+}
+
+func (n *node) remove(c kdtree.Comparable, capacity int) bool {
+	if n.children == nil {
+		for i, p := range n.points {
+			if same(p, c) {
+				n.points = append(n.points[:i], n.points[i+1:]...)
+				n.count--
+				return true
+			}
+		}
+		return false
+	}
+	if !n.children[n.child(c)].remove(c, capacity) {
+		return false
+	}
+	n.count--
+	if n.count <= capacity {
+		points := make([]kdtree.Comparable, 0, n.count)
+		for _, ch := range n.children {
+			points = ch.collect(points)
+		}
+		n.points, n.children = points, nil
+	}
+	return true
+}
+
+// same returns whether a and b have the same coordinates.
+func same(a, b kdtree.Comparable) bool {
+	for d := 0; d < a.Dims(); d++ {
+		if a.Compare(b, kdtree.Dim(d)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *node) collect(dst []kdtree.Comparable) []kdtree.Comparable {
+	if n.children == nil {
+		return append(dst, n.points...)
+	}
+	for _, ch := range n.children {
+		dst = ch.collect(dst)
+	}
+	return dst
+}
+
+// regionDist returns the squared distance from q to the nearest point of the region of n.
+func (n *node) regionDist(q kdtree.Comparable) float64 {
+	var sum float64
+	for d := range n.min {
+		var delta float64
+		if v := q.Compare(n.min, kdtree.Dim(d)); v < 0 {
+			delta = v
+		} else if v := q.Compare(n.max, kdtree.Dim(d)); v > 0 {
+			delta = v
+		}
+		sum += delta * delta
+	}
+	return sum
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Tree) Nearest(q kdtree.Comparable) (kdtree.Comparable, float64) {
+	k := kdtree.NewNKeeper(1)
+	t.root.search(q, k)
+	return k.Heap[0].Comparable, k.Heap[0].Dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (t *Tree) NearestSet(k kdtree.Keeper, q kdtree.Comparable) {
+	t.root.search(q, k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// NearestN returns the n nearest values to the query in min sorted order. If the tree holds
+// fewer than n values, all the values in the tree are returned.
+func (t *Tree) NearestN(q kdtree.Comparable, n int) []kdtree.ComparableDist {
+	if n > t.Len() {
+		n = t.Len()
+	}
+	if n <= 0 {
+		return nil
+	}
+	k := kdtree.NewNKeeper(n)
+	t.NearestSet(k, q)
+	h := k.Heap
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// InRange returns the values within distance d of the query, as measured by the values'
+// Distance method, in order of increasing distance.
+func (t *Tree) InRange(q kdtree.Comparable, d float64) []kdtree.ComparableDist {
+	k := kdtree.NewDistKeeper(d)
+	t.NearestSet(k, q)
+	res := make([]kdtree.ComparableDist, 0, k.Len()-1)
+	for _, cd := range k.Heap {
+		if cd.Comparable != nil {
+			res = append(res, cd)
+		}
+	}
+	return res
+}
+
+func (n *node) search(q kdtree.Comparable, k kdtree.Keeper) {
+	if n.count == 0 {
+		return
+	}
+	if n.children == nil {
+		for _, p := range n.points {
+			k.Keep(kdtree.ComparableDist{Comparable: p, Dist: q.Distance(p)})
+		}
+		return
+	}
+	var (
+		order [8]int
+		dists [8]float64
+	)
+	m := len(n.children)
+	for i, ch := range n.children {
+		order[i], dists[i] = i, ch.regionDist(q)
+	}
+	// Insertion sort the few children by distance.
+	for i := 1; i < m; i++ {
+		for j := i; j > 0 && dists[order[j]] < dists[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	for _, i := range order[:m] {
+		if dists[i] > k.Max().Dist {
+			break
+		}
+		n.children[i].search(q, k)
+	}
+}
+
+// Do performs fn on all values stored in the tree. The Bounding passed to fn is nil and the
+// depth is the depth of the region holding the value. A boolean is returned indicating
+// whether the Do traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn kdtree.Operation) bool {
+	return t.root.doBounded(fn, nil, 0)
+}
+
+// DoBounded performs fn on all values stored in the tree that are within the specified bound.
+// If b is nil, the result is the same as a Do. A boolean is returned indicating whether the
+// DoBounded traversal was interrupted by an Operation returning true.
+func (t *Tree) DoBounded(fn kdtree.Operation, b *kdtree.Bounding) bool {
+	return t.root.doBounded(fn, b, 0)
+}
+
+func (n *node) doBounded(fn kdtree.Operation, b *kdtree.Bounding, depth int) bool {
+	if n.count == 0 {
+		return false
+	}
+	if b != nil {
+		for d := range n.min {
+			if b[0].Compare(n.max, kdtree.Dim(d)) > 0 || b[1].Compare(n.min, kdtree.Dim(d)) < 0 {
+				return false
+			}
+		}
+	}
+	if n.children == nil {
+		for _, p := range n.points {
+			if b.Contains(p) && fn(p, nil, depth) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ch := range n.children {
+		if ch.doBounded(fn, b, depth+1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package quadtree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randPoints(rnd *rand.Rand, n, dims int) []kdtree.Point {
+	p := make([]kdtree.Point, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			p[i][d] = rnd.Float64() * 100
+		}
+	}
+	return p
+}
+
+func newTree(c *check.C, dims, capacity int) *Tree {
+	min := make(kdtree.Point, dims)
+	max := make(kdtree.Point, dims)
+	for d := range max {
+		max[d] = 100
+	}
+	t, err := New(min, max, capacity)
+	c.Assert(err, check.Equals, nil)
+	return t
+}
+
+func (s *S) TestNew(c *check.C) {
+	_, err := New(kdtree.Point{0}, kdtree.Point{1}, 0)
+	c.Check(err, check.Equals, ErrDims)
+	_, err = New(kdtree.Point{0, 0}, kdtree.Point{1, 1, 1}, 0)
+	c.Check(err, check.Equals, ErrDims)
+	_, err = New(kdtree.Point{0, 2}, kdtree.Point{1, 1}, 0)
+	c.Check(err, check.ErrorMatches, "quadtree: invalid region")
+
+	t := newTree(c, 2, 0)
+	c.Check(t.capacity, check.Equals, DefaultCapacity)
+	c.Check(t.Bounds(), check.DeepEquals, &kdtree.Bounding{kdtree.Point{0, 0}, kdtree.Point{100, 100}})
+	c.Check(t.Insert(kdtree.Point{1, 101}), check.Equals, ErrOutside)
+	c.Check(t.Insert(kdtree.Point{1, 1, 1}), check.Equals, kdtree.ErrPointDims)
+	c.Check(t.Len(), check.Equals, 0)
+	p, _ := t.Nearest(kdtree.Point{1, 1})
+	c.Check(p, check.IsNil)
+}
+
+func (s *S) TestNearest(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, dims := range []int{2, 3} {
+		t := newTree(c, dims, 4)
+		pts := randPoints(rnd, 1000, dims)
+		for _, p := range pts {
+			c.Assert(t.Insert(p), check.Equals, nil)
+		}
+		c.Check(t.Len(), check.Equals, len(pts))
+		for _, q := range randPoints(rnd, 100, dims) {
+			want := pts[0]
+			for _, p := range pts[1:] {
+				if q.Distance(p) < q.Distance(want) {
+					want = p
+				}
+			}
+			got, d := t.Nearest(q)
+			c.Check(got, check.DeepEquals, want)
+			c.Check(d, check.Equals, q.Distance(want))
+
+			k := kdtree.NewNKeeper(10)
+			t.NearestSet(k, q)
+			c.Check(k.Len(), check.Equals, 10)
+			c.Check(sort.IsSorted(sort.Reverse(k)), check.Equals, true)
+			c.Check(k.Heap[0].Comparable, check.DeepEquals, want)
+		}
+	}
+}
+
+func (s *S) TestInRange(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := newTree(c, 3, 4)
+	pts := randPoints(rnd, 1000, 3)
+	for _, p := range pts {
+		t.Insert(p)
+	}
+	q := kdtree.Point{50, 50, 50}
+	const r = 400.
+	var want int
+	for _, p := range pts {
+		if q.Distance(p) <= r {
+			want++
+		}
+	}
+	got := t.InRange(q, r)
+	c.Check(len(got), check.Equals, want)
+	for i, cd := range got {
+		c.Check(cd.Dist <= r, check.Equals, true)
+		if i > 0 {
+			c.Check(got[i-1].Dist <= cd.Dist, check.Equals, true)
+		}
+	}
+}
+
+func (s *S) TestDoBounded(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := newTree(c, 2, 4)
+	pts := randPoints(rnd, 1000, 2)
+	for _, p := range pts {
+		t.Insert(p)
+	}
+	b := &kdtree.Bounding{kdtree.Point{20, 30}, kdtree.Point{45, 60}}
+	var want int
+	for _, p := range pts {
+		if b.Contains(p) {
+			want++
+		}
+	}
+	var got int
+	c.Check(t.DoBounded(func(p kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+		c.Check(b.Contains(p), check.Equals, true)
+		got++
+		return false
+	}, b), check.Equals, false)
+	c.Check(got, check.Equals, want)
+
+	var n int
+	t.Do(func(kdtree.Comparable, *kdtree.Bounding, int) bool { n++; return false })
+	c.Check(n, check.Equals, len(pts))
+	n = 0
+	c.Check(t.Do(func(kdtree.Comparable, *kdtree.Bounding, int) bool { n++; return n == 10 }), check.Equals, true)
+	c.Check(n, check.Equals, 10)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	t := newTree(c, 2, 4)
+	pts := randPoints(rnd, 500, 2)
+	for _, p := range pts {
+		t.Insert(p)
+	}
+	c.Check(t.Remove(kdtree.Point{200, 200}), check.Equals, false)
+	c.Check(t.Remove(kdtree.Point{1, 1}), check.Equals, false)
+	for i, j := range rnd.Perm(len(pts)) {
+		c.Assert(t.Remove(pts[j]), check.Equals, true)
+		c.Check(t.Len(), check.Equals, len(pts)-i-1)
+	}
+	c.Check(t.root.children, check.IsNil)
+	c.Check(t.root.points, check.HasLen, 0)
+}
+
+func (s *S) TestCoincident(c *check.C) {
+	t := newTree(c, 2, 2)
+	d := []kdtree.Datum{
+		{Point: kdtree.Point{5, 5}, Value: 0},
+		{Point: kdtree.Point{5, 5}, Value: 1},
+		{Point: kdtree.Point{5, 5}, Value: 2},
+		{Point: kdtree.Point{5, 5}, Value: 3},
+	}
+	for _, e := range d {
+		c.Assert(t.Insert(e), check.Equals, nil)
+	}
+	c.Check(t.Len(), check.Equals, len(d))
+	got := t.InRange(kdtree.Point{5, 5}, 0)
+	c.Check(got, check.HasLen, len(d))
+	for range d {
+		c.Check(t.Remove(kdtree.Point{5, 5}), check.Equals, true)
+	}
+	c.Check(t.Len(), check.Equals, 0)
+}
+
+func BenchmarkInsertRemove(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	t, _ := New(kdtree.Point{0, 0}, kdtree.Point{100, 100}, 0)
+	pts := randPoints(rnd, 1e4, 2)
+	for _, p := range pts {
+		t.Insert(p)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := pts[i%len(pts)]
+		t.Remove(p)
+		t.Insert(p)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rangetree implements static multi-level range trees for orthogonal range
+// reporting and counting.
+//
+// A range tree over d dimensions reports the k values within an axis aligned box in
+// O(log^(d-1) n + k) time and counts them in O(log^(d-1) n) time, using O(n log^(d-1) n)
+// space. The final two levels of the tree are joined by fractional cascading. For
+// workloads dominated by box queries this is asymptotically better than the O(n^(1-1/d) + k)
+// time of a k-d tree, at the cost of memory and of being unable to insert or remove values.
+//
+// Trees hold kdtree.Comparable values whose Compare method accepts kdtree.Point
+// parameters, such as kdtree.Point and kdtree.Datum. The Distance method is not used.
+package rangetree
+
+import (
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// item is a value held by the tree and its coordinates.
+type item struct {
+	c kdtree.Comparable
+	x []float64
+}
+
+// A node is a node of a level of the tree, holding a contiguous run of the level's
+// items in order along the level's dimension.
+type node struct {
+	min, max    float64
+	left, right *node
+
+	// item is the single value held by a leaf node.
+	item *item
+
+	// assoc is the root of the next level of the tree,
+	// holding the items of the subtree rooted at node.
+	// assoc is used for all levels but the last two.
+	assoc *node
+
+	// ys holds the items of the subtree rooted at node
+	// sorted along the final dimension and bridge[i]
+	// is the number of items in ys[:i] that are held by
+	// the left subtree. ys and bridge are used only for
+	// the penultimate level.
+	ys     []*item
+	bridge []int
+}
+
+// A Tree is a static range tree.
+type Tree struct {
+	root *node
+
+	// items holds the items of the tree sorted
+	// along the first dimension.
+	items []*item
+
+	dims int
+}
+
+// New returns a range tree constructed from the values in p. All values in p must have
+// the same dimensionality. p is not altered.
+func New(p kdtree.Interface) *Tree {
+	t := &Tree{}
+	if p.Len() == 0 {
+		return t
+	}
+	t.dims = p.Index(0).Dims()
+	zero := make(kdtree.Point, t.dims)
+	items := make([]*item, p.Len())
+	for i := range items {
+		c := p.Index(i)
+		if c.Dims() != t.dims {
+			panic("rangetree: dimension mismatch")
+		}
+		x := make([]float64, t.dims)
+		for d := range x {
+			x[d] = c.Compare(zero, kdtree.Dim(d))
+		}
+		items[i] = &item{c: c, x: x}
+	}
+	sortOn(items, 0)
+	t.items = items
+	if t.dims > 1 {
+		t.root = t.build(items, 0)
+	}
+	return t
+}
+
+// sortOn sorts items by their coordinate in dimension d.
+func sortOn(items []*item, d int) {
+	sort.Stable(byDim{items: items, d: d})
+}
+
+type byDim struct {
+	items []*item
+	d     int
+}
+
+func (s byDim) Len() int           { return len(s.items) }
+func (s byDim) Less(i, j int) bool { return s.items[i].x[s.d] < s.items[j].x[s.d] }
+func (s byDim) Swap(i, j int)      { s.items[i], s.items[j] = s.items[j], s.items[i] }
+
+// build returns a level of the tree for dimension d holding items, which must be sorted
+// along d.
+func (t *Tree) build(items []*item, d int) *node {
+	n := &node{min: items[0].x[d], max: items[len(items)-1].x[d]}
+	if len(items) == 1 {
+		n.item = items[0]
+		if d == t.dims-2 {
+			n.ys = items[:1:1]
+		}
+		return n
+	}
+	mid := len(items) / 2
+	n.left = t.build(items[:mid], d)
+	n.right = t.build(items[mid:], d)
+	if d == t.dims-2 {
+		n.ys, n.bridge = merge(n.left.ys, n.right.ys, t.dims-1)
+		return n
+	}
+	next := append([]*item(nil), items...)
+	sortOn(next, d+1)
+	n.assoc = t.build(next, d+1)
+	return n
+}
+
+// merge returns the merge of a and b, which are sorted along dimension d, and the number
+// of elements of a in each prefix of the result.
+func merge(a, b []*item, d int) ([]*item, []int) {
+	m := make([]*item, 0, len(a)+len(b))
+	bridge := make([]int, 1, len(a)+len(b)+1)
+	var i, j int
+	for i < len(a) || j < len(b) {
+		if j == len(b) || (i < len(a) && a[i].x[d] <= b[j].x[d]) {
+			m = append(m, a[i])
+			i++
+		} else {
+			m = append(m, b[j])
+			j++
+		}
+		bridge = append(bridge, i)
+	}
+	return m, bridge
+}
+
+// Len returns the number of values in the tree.
+func (t *Tree) Len() int { return len(t.items) }
+
+// box is a query range in the coordinates of the tree.
+type box struct {
+	lo, hi []float64
+}
+
+func (t *Tree) box(b *kdtree.Bounding) box {
+	zero := make(kdtree.Point, t.dims)
+	q := box{lo: make([]float64, t.dims), hi: make([]float64, t.dims)}
+	for d := range q.lo {
+		q.lo[d] = b[0].Compare(zero, kdtree.Dim(d))
+		q.hi[d] = b[1].Compare(zero, kdtree.Dim(d))
+	}
+	return q
+}
+
+// contains returns whether the item's coordinates from dimension d onwards are within q.
+func (q box) contains(it *item, d int) bool {
+	for ; d < len(q.lo); d++ {
+		if it.x[d] < q.lo[d] || it.x[d] > q.hi[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// span returns the index range of items within [lo, hi] along dimension d of the
+// sorted items.
+func span(items []*item, d int, lo, hi float64) (int, int) {
+	i := sort.Search(len(items), func(i int) bool { return items[i].x[d] >= lo })
+	j := sort.Search(len(items), func(i int) bool { return items[i].x[d] > hi })
+	if j < i {
+		j = i
+	}
+	return i, j
+}
+
+// DoBounded performs fn on all values stored in the tree that are within the specified bound.
+// The Bounding and depth passed to fn are nil and zero. If b is nil, the result is the same
+// as a Do. A boolean is returned indicating whether the DoBounded traversal was interrupted
+// by an Operation returning true. Values are not visited in any particular order.
+func (t *Tree) DoBounded(fn kdtree.Operation, b *kdtree.Bounding) bool {
+	if b == nil {
+		return t.Do(fn)
+	}
+	if len(t.items) == 0 {
+		return false
+	}
+	report := func(items []*item) bool {
+		for _, it := range items {
+			if fn(it.c, nil, 0) {
+				return true
+			}
+		}
+		return false
+	}
+	q := t.box(b)
+	if t.dims == 1 {
+		i, j := span(t.items, 0, q.lo[0], q.hi[0])
+		return report(t.items[i:j])
+	}
+	var done bool
+	t.root.query(q, 0, t.dims, func(items []*item) bool {
+		done = report(items)
+		return done
+	}, func(it *item) bool {
+		done = fn(it.c, nil, 0)
+		return done
+	})
+	return done
+}
+
+// Count returns the number of values stored in the tree that are within the bound b.
+func (t *Tree) Count(b *kdtree.Bounding) int {
+	if len(t.items) == 0 {
+		return 0
+	}
+	q := t.box(b)
+	if t.dims == 1 {
+		i, j := span(t.items, 0, q.lo[0], q.hi[0])
+		return j - i
+	}
+	var n int
+	t.root.query(q, 0, t.dims,
+		func(items []*item) bool { n += len(items); return false },
+		func(*item) bool { n++; return false },
+	)
+	return n
+}
+
+// query calls run with each canonical run of items within q and single with each item
+// within q that is found at a leaf above the penultimate level. The traversal is abandoned
+// when either function returns true.
+func (n *node) query(q box, d, dims int, run func([]*item) bool, single func(*item) bool) bool {
+	if n.max < q.lo[d] || n.min > q.hi[d] {
+		return false
+	}
+	if d == dims-2 {
+		i, j := span(n.ys, dims-1, q.lo[dims-1], q.hi[dims-1])
+		return n.cascade(q, d, i, j, run)
+	}
+	if n.item != nil {
+		if q.contains(n.item, d) {
+			return single(n.item)
+		}
+		return false
+	}
+	if q.lo[d] <= n.min && n.max <= q.hi[d] {
+		return n.assoc.query(q, d+1, dims, run, single)
+	}
+	return n.left.query(q, d, dims, run, single) || n.right.query(q, d, dims, run, single)
+}
+
+// cascade reports the items within q from the penultimate level rooted at n, given the
+// index range i to j of items within q along the final dimension in n.ys.
+func (n *node) cascade(q box, d, i, j int, run func([]*item) bool) bool {
+	if i == j || n.max < q.lo[d] || n.min > q.hi[d] {
+		return false
+	}
+	if q.lo[d] <= n.min && n.max <= q.hi[d] {
+		return run(n.ys[i:j])
+	}
+	if n.item != nil {
+		return false
+	}
+	li, lj := n.bridge[i], n.bridge[j]
+	return n.left.cascade(q, d, li, lj, run) || n.right.cascade(q, d, i-li, j-lj, run)
+}
+
+// Do performs fn on all values stored in the tree in order along the first dimension. The
+// Bounding and depth passed to fn are nil and zero. A boolean is returned indicating whether
+// the Do traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn kdtree.Operation) bool {
+	for _, it := range t.items {
+		if fn(it.c, nil, 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rangetree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randPoints(rnd *rand.Rand, n, dims int) kdtree.Points {
+	p := make(kdtree.Points, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			// Use a coarse grid to generate ties.
+			p[i][d] = float64(rnd.Intn(50))
+		}
+	}
+	return p
+}
+
+func randBox(rnd *rand.Rand, dims int) *kdtree.Bounding {
+	lo := make(kdtree.Point, dims)
+	hi := make(kdtree.Point, dims)
+	for d := range lo {
+		a, b := float64(rnd.Intn(60)-5), float64(rnd.Intn(60)-5)
+		if a > b {
+			a, b = b, a
+		}
+		lo[d], hi[d] = a, b
+	}
+	return &kdtree.Bounding{lo, hi}
+}
+
+func (s *S) TestEmpty(c *check.C) {
+	t := New(kdtree.Points{})
+	c.Check(t.Len(), check.Equals, 0)
+	c.Check(t.Count(&kdtree.Bounding{kdtree.Point{0}, kdtree.Point{1}}), check.Equals, 0)
+	c.Check(t.Do(func(kdtree.Comparable, *kdtree.Bounding, int) bool { return true }), check.Equals, false)
+	c.Check(func() { New(kdtree.Points{{1, 2}, {1}}) }, check.PanicMatches, "rangetree: dimension mismatch")
+}
+
+func (s *S) TestQuery(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= 4; dims++ {
+		for _, n := range []int{1, 2, 3, 10, 500} {
+			pts := randPoints(rnd, n, dims)
+			t := New(append(kdtree.Points(nil), pts...))
+			c.Check(t.Len(), check.Equals, n)
+
+			var all int
+			t.Do(func(kdtree.Comparable, *kdtree.Bounding, int) bool { all++; return false })
+			c.Check(all, check.Equals, n)
+
+			for i := 0; i < 50; i++ {
+				b := randBox(rnd, dims)
+				var want int
+				for _, p := range pts {
+					if b.Contains(p) {
+						want++
+					}
+				}
+				c.Check(t.Count(b), check.Equals, want, check.Commentf("dims=%d n=%d box=%v", dims, n, b))
+
+				var got int
+				t.DoBounded(func(p kdtree.Comparable, _ *kdtree.Bounding, _ int) bool {
+					c.Check(b.Contains(p), check.Equals, true)
+					got++
+					return false
+				}, b)
+				c.Check(got, check.Equals, want, check.Commentf("dims=%d n=%d box=%v", dims, n, b))
+			}
+		}
+	}
+}
+
+func (s *S) TestDoBoundedDone(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for dims := 1; dims <= 3; dims++ {
+		t := New(randPoints(rnd, 1000, dims))
+		b := &kdtree.Bounding{make(kdtree.Point, dims), make(kdtree.Point, dims)}
+		for d := 0; d < dims; d++ {
+			b[1].(kdtree.Point)[d] = 50
+		}
+		var n int
+		c.Check(t.DoBounded(func(kdtree.Comparable, *kdtree.Bounding, int) bool { n++; return n == 5 }, b), check.Equals, true)
+		c.Check(n, check.Equals, 5)
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	t := New(randPoints(rnd, 1e5, 2))
+	boxes := make([]*kdtree.Bounding, 100)
+	for i := range boxes {
+		boxes[i] = randBox(rnd, 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t.Count(boxes[i%len(boxes)])
+	}
+}
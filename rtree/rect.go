@@ -0,0 +1,132 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"errors"
+	"math"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// A Rect is an axis aligned hyperrectangle. Min and Max must have the same length and
+// each element of Min must be no greater than the corresponding element of Max.
+type Rect struct {
+	Min, Max kdtree.Point
+}
+
+// FromBounding returns the Rect corresponding to b. The corners of b must be kdtree.Point
+// values.
+func FromBounding(b *kdtree.Bounding) (Rect, error) {
+	min, ok := b[0].(kdtree.Point)
+	if !ok {
+		return Rect{}, errors.New("rtree: bounding corner is not a kdtree.Point")
+	}
+	max, ok := b[1].(kdtree.Point)
+	if !ok {
+		return Rect{}, errors.New("rtree: bounding corner is not a kdtree.Point")
+	}
+	return Rect{Min: min, Max: max}, nil
+}
+
+// Point returns a degenerate Rect covering only p.
+func Point(p kdtree.Point) Rect { return Rect{Min: p, Max: p} }
+
+// Bounding returns the kdtree.Bounding corresponding to r.
+func (r Rect) Bounding() *kdtree.Bounding { return &kdtree.Bounding{r.Min, r.Max} }
+
+// Intersects returns whether r and s share any point.
+func (r Rect) Intersects(s Rect) bool {
+	for d := range r.Min {
+		if r.Min[d] > s.Max[d] || s.Min[d] > r.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns whether s lies entirely within r.
+func (r Rect) Contains(s Rect) bool {
+	for d := range r.Min {
+		if s.Min[d] < r.Min[d] || s.Max[d] > r.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns whether r and s are the same rectangle.
+func (r Rect) Equal(s Rect) bool {
+	if len(r.Min) != len(s.Min) {
+		return false
+	}
+	for d := range r.Min {
+		if r.Min[d] != s.Min[d] || r.Max[d] != s.Max[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the smallest Rect containing both r and s.
+func (r Rect) Union(s Rect) Rect {
+	u := Rect{Min: make(kdtree.Point, len(r.Min)), Max: make(kdtree.Point, len(r.Max))}
+	for d := range r.Min {
+		u.Min[d] = math.Min(r.Min[d], s.Min[d])
+		u.Max[d] = math.Max(r.Max[d], s.Max[d])
+	}
+	return u
+}
+
+// Area returns the hypervolume of r.
+func (r Rect) Area() float64 {
+	a := 1.
+	for d := range r.Min {
+		a *= r.Max[d] - r.Min[d]
+	}
+	return a
+}
+
+// margin returns the sum of the edge lengths of r.
+func (r Rect) margin() float64 {
+	var m float64
+	for d := range r.Min {
+		m += r.Max[d] - r.Min[d]
+	}
+	return m
+}
+
+// overlap returns the hypervolume of the intersection of r and s.
+func (r Rect) overlap(s Rect) float64 {
+	a := 1.
+	for d := range r.Min {
+		l := math.Min(r.Max[d], s.Max[d]) - math.Max(r.Min[d], s.Min[d])
+		if l <= 0 {
+			return 0
+		}
+		a *= l
+	}
+	return a
+}
+
+// enlargement returns the increase in area of r required to include s.
+func (r Rect) enlargement(s Rect) float64 { return r.Union(s).Area() - r.Area() }
+
+// Distance returns the squared Euclidean distance between p and the nearest point of r.
+// The distance is zero if p is within r.
+func (r Rect) Distance(p kdtree.Point) float64 {
+	var sum float64
+	for d, v := range p {
+		var delta float64
+		switch {
+		case v < r.Min[d]:
+			delta = r.Min[d] - v
+		case v > r.Max[d]:
+			delta = v - r.Max[d]
+		}
+		sum += delta * delta
+	}
+	return sum
+}
@@ -0,0 +1,364 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rtree implements R-trees for indexing rectangular extents.
+//
+// Where the kdtree package indexes points, an R-tree indexes axis aligned rectangles, and
+// so may be used to hold extended objects such as line segments and polygons by their
+// bounding boxes. Node splitting and subtree selection follow the R*-tree heuristics
+// described by Beckmann, Kriegel, Schneider and Seeger in "The R*-tree: an efficient and
+// robust access method for points and rectangles", SIGMOD 1990.
+package rtree
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+// DefaultMaxEntries is the default maximum number of entries held by a node.
+const DefaultMaxEntries = 16
+
+type entry struct {
+	rect  Rect
+	child *node
+	value interface{}
+}
+
+type node struct {
+	// level is the height of the node
+	// above the leaves, which are at
+	// level zero.
+	level   int
+	entries []entry
+}
+
+func (n *node) bounds() Rect {
+	r := n.entries[0].rect
+	for _, e := range n.entries[1:] {
+		r = r.Union(e.rect)
+	}
+	return r
+}
+
+// A Tree is an R-tree. Values are stored with the rectangles that describe their extent.
+// All rectangles held by a Tree must have the same dimensionality.
+type Tree struct {
+	root     *node
+	count    int
+	min, max int
+}
+
+// New returns an empty Tree whose nodes hold at most maxEntries entries. If maxEntries
+// is less than 4, DefaultMaxEntries is used.
+func New(maxEntries int) *Tree {
+	if maxEntries < 4 {
+		maxEntries = DefaultMaxEntries
+	}
+	min := maxEntries * 2 / 5
+	if min < 2 {
+		min = 2
+	}
+	return &Tree{root: &node{}, min: min, max: maxEntries}
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree) Len() int { return t.count }
+
+// Bounds returns the bounding rectangle of all the values in the tree. The returned
+// Rect is the zero Rect if the tree is empty.
+func (t *Tree) Bounds() Rect {
+	if len(t.root.entries) == 0 {
+		return Rect{}
+	}
+	return t.root.bounds()
+}
+
+// Insert adds v with the extent r to the tree.
+func (t *Tree) Insert(r Rect, v interface{}) {
+	t.count++
+	t.insert(entry{rect: r, value: v}, 0)
+}
+
+// insert adds e to a node at the given level, splitting nodes as required.
+func (t *Tree) insert(e entry, level int) {
+	split := t.insertAt(t.root, e, level)
+	if split != nil {
+		old := t.root
+		t.root = &node{
+			level:   old.level + 1,
+			entries: []entry{{rect: old.bounds(), child: old}, {rect: split.bounds(), child: split}},
+		}
+	}
+}
+
+func (t *Tree) insertAt(n *node, e entry, level int) *node {
+	if n.level == level {
+		n.entries = append(n.entries, e)
+	} else {
+		i := n.chooseSubtree(e.rect)
+		c := &n.entries[i]
+		split := t.insertAt(c.child, e, level)
+		c.rect = c.child.bounds()
+		if split != nil {
+			n.entries = append(n.entries, entry{rect: split.bounds(), child: split})
+		}
+	}
+	if len(n.entries) > t.max {
+		return t.split(n)
+	}
+	return nil
+}
+
+// chooseSubtree returns the index of the entry of n best suited to hold r. For nodes
+// whose children are leaves the entry needing least overlap enlargement is chosen,
+// otherwise the entry needing least area enlargement is chosen.
+func (n *node) chooseSubtree(r Rect) int {
+	best := 0
+	var bestOverlap, bestEnlarge, bestArea float64
+	for i, e := range n.entries {
+		var overlap float64
+		if n.level == 1 {
+			u := e.rect.Union(r)
+			for j, o := range n.entries {
+				if j != i {
+					overlap += u.overlap(o.rect) - e.rect.overlap(o.rect)
+				}
+			}
+		}
+		enlarge := e.rect.enlargement(r)
+		area := e.rect.Area()
+		if i == 0 || overlap < bestOverlap ||
+			(overlap == bestOverlap && (enlarge < bestEnlarge || (enlarge == bestEnlarge && area < bestArea))) {
+			best, bestOverlap, bestEnlarge, bestArea = i, overlap, enlarge, area
+		}
+	}
+	return best
+}
+
+// split divides the entries of the overfull node n between n and a new node that is
+// returned. The split axis is chosen to minimise the total margin of the candidate
+// distributions, and the distribution on that axis with the least overlap is used.
+func (t *Tree) split(n *node) *node {
+	dims := len(n.entries[0].rect.Min)
+	var (
+		bestAxis   int
+		bestMargin float64
+	)
+	for d := 0; d < dims; d++ {
+		var margin float64
+		for _, upper := range []bool{false, true} {
+			sort.Sort(byAxis{entries: n.entries, dim: d, upper: upper})
+			for k := t.min; k <= len(n.entries)-t.min; k++ {
+				margin += group(n.entries[:k]).margin() + group(n.entries[k:]).margin()
+			}
+		}
+		if d == 0 || margin < bestMargin {
+			bestAxis, bestMargin = d, margin
+		}
+	}
+
+	var (
+		bestUpper            bool
+		bestK                int
+		bestOverlap, bestSum float64
+		first                = true
+	)
+	for _, upper := range []bool{false, true} {
+		sort.Sort(byAxis{entries: n.entries, dim: bestAxis, upper: upper})
+		for k := t.min; k <= len(n.entries)-t.min; k++ {
+			a, b := group(n.entries[:k]), group(n.entries[k:])
+			overlap, sum := a.overlap(b), a.Area()+b.Area()
+			if first || overlap < bestOverlap || (overlap == bestOverlap && sum < bestSum) {
+				bestUpper, bestK, bestOverlap, bestSum = upper, k, overlap, sum
+				first = false
+			}
+		}
+	}
+	sort.Sort(byAxis{entries: n.entries, dim: bestAxis, upper: bestUpper})
+	right := &node{level: n.level, entries: append([]entry(nil), n.entries[bestK:]...)}
+	n.entries = append([]entry(nil), n.entries[:bestK]...)
+	return right
+}
+
+// group returns the bounding rectangle of the entries.
+func group(entries []entry) Rect {
+	r := entries[0].rect
+	for _, e := range entries[1:] {
+		r = r.Union(e.rect)
+	}
+	return r
+}
+
+// byAxis sorts entries by the lower or upper bound of their rectangle on a dimension.
+type byAxis struct {
+	entries []entry
+	dim     int
+	upper   bool
+}
+
+func (b byAxis) Len() int { return len(b.entries) }
+func (b byAxis) Less(i, j int) bool {
+	ri, rj := b.entries[i].rect, b.entries[j].rect
+	if b.upper {
+		return ri.Max[b.dim] < rj.Max[b.dim] || (ri.Max[b.dim] == rj.Max[b.dim] && ri.Min[b.dim] < rj.Min[b.dim])
+	}
+	return ri.Min[b.dim] < rj.Min[b.dim] || (ri.Min[b.dim] == rj.Min[b.dim] && ri.Max[b.dim] < rj.Max[b.dim])
+}
+func (b byAxis) Swap(i, j int) { b.entries[i], b.entries[j] = b.entries[j], b.entries[i] }
+
+// Remove removes a single value with the extent r for which match returns true, returning
+// whether a value was removed. If match is nil, any value with the extent r is removed.
+func (t *Tree) Remove(r Rect, match func(interface{}) bool) bool {
+	var orphans []entry
+	if !t.remove(t.root, r, match, &orphans) {
+		return false
+	}
+	t.count--
+	for t.root.level > 0 && len(t.root.entries) == 1 {
+		t.root = t.root.entries[0].child
+	}
+	if t.root.level > 0 && len(t.root.entries) == 0 {
+		t.root = &node{}
+	}
+	for _, e := range orphans {
+		t.insert(e, 0)
+	}
+	return true
+}
+
+// remove removes a matching value from the subtree rooted at n. The leaf entries of nodes left
+// underfull are removed from the tree and appended to orphans for reinsertion.
+func (t *Tree) remove(n *node, r Rect, match func(interface{}) bool, orphans *[]entry) bool {
+	if n.level == 0 {
+		for i, e := range n.entries {
+			if e.rect.Equal(r) && (match == nil || match(e.value)) {
+				n.entries = append(n.entries[:i], n.entries[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	for i, e := range n.entries {
+		if !e.rect.Contains(r) || !t.remove(e.child, r, match, orphans) {
+			continue
+		}
+		if len(e.child.entries) < t.min {
+			*orphans = e.child.leaves(*orphans)
+			n.entries = append(n.entries[:i], n.entries[i+1:]...)
+		} else {
+			n.entries[i].rect = e.child.bounds()
+		}
+		return true
+	}
+	return false
+}
+
+// leaves appends the leaf entries of the subtree rooted at n to dst.
+func (n *node) leaves(dst []entry) []entry {
+	if n.level == 0 {
+		return append(dst, n.entries...)
+	}
+	for _, e := range n.entries {
+		dst = e.child.leaves(dst)
+	}
+	return dst
+}
+
+// An Operation is a function that operates on a value and its extent. If done is
+// returned true, the Operation is indicating that no further work needs to be done
+// and so the traversal should proceed no further.
+type Operation func(r Rect, v interface{}) (done bool)
+
+// Do performs fn on all values stored in the tree. A boolean is returned indicating
+// whether the traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn Operation) bool {
+	return t.root.do(fn, func(Rect) bool { return true }, func(Rect) bool { return true })
+}
+
+// Intersecting performs fn on all values whose extent intersects q. A boolean is returned
+// indicating whether the traversal was interrupted by an Operation returning true.
+func (t *Tree) Intersecting(fn Operation, q Rect) bool {
+	return t.root.do(fn, q.Intersects, q.Intersects)
+}
+
+// Within performs fn on all values whose extent lies entirely within q. A boolean is
+// returned indicating whether the traversal was interrupted by an Operation returning true.
+func (t *Tree) Within(fn Operation, q Rect) bool {
+	return t.root.do(fn, q.Intersects, q.Contains)
+}
+
+// Containing performs fn on all values whose extent entirely contains q. A boolean is
+// returned indicating whether the traversal was interrupted by an Operation returning true.
+func (t *Tree) Containing(fn Operation, q Rect) bool {
+	contains := func(r Rect) bool { return r.Contains(q) }
+	return t.root.do(fn, contains, contains)
+}
+
+// do performs fn on the values of the subtree rooted at n that satisfy match, descending
+// only into entries that satisfy enter.
+func (n *node) do(fn Operation, enter, match func(Rect) bool) bool {
+	for _, e := range n.entries {
+		if n.level == 0 {
+			if match(e.rect) && fn(e.rect, e.value) {
+				return true
+			}
+			continue
+		}
+		if enter(e.rect) && e.child.do(fn, enter, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Result is a value found by a nearest neighbour search, with its extent and the
+// squared distance from the query to the nearest point of the extent.
+type Result struct {
+	Rect  Rect
+	Value interface{}
+	Dist  float64
+}
+
+// Nearest returns the n values whose extents are nearest to q in order of increasing
+// distance. Fewer than n values are returned if the tree holds fewer than n values.
+func (t *Tree) Nearest(q kdtree.Point, n int) []Result {
+	if n < 1 || t.count == 0 {
+		return nil
+	}
+	var (
+		res   []Result
+		queue = &entryQueue{{entry: entry{child: t.root}, dist: 0}}
+	)
+	for queue.Len() != 0 && len(res) < n {
+		e := heap.Pop(queue).(queuedEntry)
+		if e.child == nil {
+			res = append(res, Result{Rect: e.rect, Value: e.value, Dist: e.dist})
+			continue
+		}
+		for _, c := range e.child.entries {
+			heap.Push(queue, queuedEntry{entry: c, dist: c.rect.Distance(q)})
+		}
+	}
+	return res
+}
+
+type queuedEntry struct {
+	entry
+	dist float64
+}
+
+// entryQueue is a min heap of entries sorted on distance.
+type entryQueue []queuedEntry
+
+func (q entryQueue) Len() int            { return len(q) }
+func (q entryQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q entryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *entryQueue) Push(x interface{}) { *q = append(*q, x.(queuedEntry)) }
+func (q *entryQueue) Pop() interface{} {
+	x := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return x
+}
@@ -0,0 +1,175 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func randRect(dims int, size float64) Rect {
+	r := Rect{Min: make(kdtree.Point, dims), Max: make(kdtree.Point, dims)}
+	for d := range r.Min {
+		r.Min[d] = rand.Float64() * 100
+		r.Max[d] = r.Min[d] + rand.Float64()*size
+	}
+	return r
+}
+
+// isValid returns whether the structural invariants of the tree hold.
+func (t *Tree) isValid() bool {
+	var count int
+	ok := t.root.isValid(t, true, &count)
+	return ok && count == t.count
+}
+
+func (n *node) isValid(t *Tree, root bool, count *int) bool {
+	if len(n.entries) > t.max || (!root && len(n.entries) < t.min) {
+		return false
+	}
+	for _, e := range n.entries {
+		if n.level == 0 {
+			if e.child != nil {
+				return false
+			}
+			*count++
+			continue
+		}
+		if e.child == nil || e.child.level != n.level-1 || !e.rect.Equal(e.child.bounds()) {
+			return false
+		}
+		if !e.child.isValid(t, false, count) {
+			return false
+		}
+	}
+	return true
+}
+
+type item struct {
+	r Rect
+	v int
+}
+
+func collect(fn func(Operation) bool) []int {
+	var got []int
+	fn(func(_ Rect, v interface{}) bool { got = append(got, v.(int)); return false })
+	sort.Ints(got)
+	return got
+}
+
+func brute(items []item, match func(Rect) bool) []int {
+	var want []int
+	for _, it := range items {
+		if match(it.r) {
+			want = append(want, it.v)
+		}
+	}
+	sort.Ints(want)
+	return want
+}
+
+func (s *S) TestRect(c *check.C) {
+	a := Rect{Min: kdtree.Point{0, 0}, Max: kdtree.Point{2, 2}}
+	b := Rect{Min: kdtree.Point{1, 1}, Max: kdtree.Point{3, 4}}
+	c.Check(a.Intersects(b), check.Equals, true)
+	c.Check(a.Contains(b), check.Equals, false)
+	c.Check(a.Union(b), check.DeepEquals, Rect{Min: kdtree.Point{0, 0}, Max: kdtree.Point{3, 4}})
+	c.Check(a.overlap(b), check.Equals, 1.)
+	c.Check(b.Area(), check.Equals, 6.)
+	c.Check(a.Distance(kdtree.Point{5, 6}), check.Equals, 25.)
+	c.Check(a.Distance(kdtree.Point{1, 1}), check.Equals, 0.)
+	c.Check(a.Contains(Point(kdtree.Point{1, 2})), check.Equals, true)
+
+	r, err := FromBounding(a.Bounding())
+	c.Check(err, check.IsNil)
+	c.Check(r, check.DeepEquals, a)
+	_, err = FromBounding(&kdtree.Bounding{nil, nil})
+	c.Check(err, check.NotNil)
+}
+
+func (s *S) TestQueries(c *check.C) {
+	t := New(8)
+	var items []item
+	for i := 0; i < 2000; i++ {
+		r := randRect(2, 5)
+		items = append(items, item{r, i})
+		t.Insert(r, i)
+	}
+	c.Check(t.Len(), check.Equals, len(items))
+	c.Assert(t.isValid(), check.Equals, true)
+	c.Check(collect(t.Do), check.HasLen, len(items))
+
+	for i := 0; i < 50; i++ {
+		q := randRect(2, 20)
+		c.Check(collect(func(fn Operation) bool { return t.Intersecting(fn, q) }), check.DeepEquals, brute(items, q.Intersects))
+		c.Check(collect(func(fn Operation) bool { return t.Within(fn, q) }), check.DeepEquals, brute(items, q.Contains))
+		p := randRect(2, 0)
+		c.Check(collect(func(fn Operation) bool { return t.Containing(fn, p) }), check.DeepEquals,
+			brute(items, func(r Rect) bool { return r.Contains(p) }))
+	}
+
+	for i := 0; i < 50; i++ {
+		q := randRect(2, 0).Min
+		got := t.Nearest(q, 5)
+		c.Assert(got, check.HasLen, 5)
+		dists := make([]float64, len(items))
+		for j, it := range items {
+			dists[j] = it.r.Distance(q)
+		}
+		sort.Float64s(dists)
+		for j, r := range got {
+			c.Check(r.Dist, check.Equals, dists[j])
+			c.Check(r.Rect.Equal(items[r.Value.(int)].r), check.Equals, true)
+		}
+	}
+	c.Check(New(0).Nearest(kdtree.Point{0, 0}, 1), check.IsNil)
+	c.Check(t.Nearest(kdtree.Point{0, 0}, 5000), check.HasLen, len(items))
+}
+
+func (s *S) TestRemove(c *check.C) {
+	t := New(6)
+	var items []item
+	for i := 0; i < 1000; i++ {
+		r := randRect(3, 10)
+		items = append(items, item{r, i})
+		t.Insert(r, i)
+	}
+	c.Check(t.Remove(items[0].r, func(v interface{}) bool { return v == -1 }), check.Equals, false)
+	for i, j := range rand.Perm(len(items)) {
+		items[i], items[j] = items[j], items[i]
+	}
+	for len(items) > 0 {
+		it := items[len(items)-1]
+		items = items[:len(items)-1]
+		c.Assert(t.Remove(it.r, func(v interface{}) bool { return v == it.v }), check.Equals, true)
+		c.Assert(t.Len(), check.Equals, len(items))
+		if len(items)%100 == 0 {
+			c.Assert(t.isValid(), check.Equals, true)
+			q := randRect(3, 30)
+			c.Check(collect(func(fn Operation) bool { return t.Intersecting(fn, q) }), check.DeepEquals, brute(items, q.Intersects))
+		}
+	}
+	c.Check(t.Bounds(), check.DeepEquals, Rect{})
+	c.Check(t.root.level, check.Equals, 0)
+
+	// Values need not be comparable.
+	r := randRect(3, 10)
+	t.Insert(r, kdtree.Point{1, 2})
+	t.Insert(r, kdtree.Point{3, 4})
+	c.Check(t.Remove(r, func(v interface{}) bool { return v.(kdtree.Point)[0] == 3 }), check.Equals, true)
+	c.Check(t.Remove(r, nil), check.Equals, true)
+	c.Check(t.Len(), check.Equals, 0)
+}
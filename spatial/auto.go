@@ -0,0 +1,182 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spatial
+
+import (
+	"math"
+
+	"github.com/biogo/store/grid"
+	"github.com/biogo/store/kdtree"
+	"github.com/biogo/store/quadtree"
+)
+
+const (
+	// bruteMax is the number of values below which a
+	// brute force search outperforms a tree.
+	bruteMax = 100
+
+	// cellLoad is the mean number of values per
+	// occupied cell targeted by a chosen grid.
+	cellLoad = 2
+
+	// uniformOccupancy is the fraction of occupied
+	// grid cells above which data are treated as
+	// uniformly distributed. For uniform data with
+	// a mean load of two values per cell, about 86%
+	// of cells are occupied.
+	uniformOccupancy = 0.6
+
+	// duplicateRate is the fraction of values with
+	// duplicated coordinates above which a grid is
+	// preferred to a k-d tree.
+	duplicateRate = 0.25
+
+	// maxCells is the largest number of cells
+	// considered when estimating occupancy.
+	maxCells = 1 << 24
+)
+
+// Choose returns a Config describing the index kind best suited to the values in p, based
+// on their number, dimensionality, spread and rate of duplication:
+//
+//   - fewer than 100 values are searched by brute force;
+//   - data of up to three dimensions that are approximately uniformly distributed, or
+//     that have many duplicated coordinates, are held in a grid with a cell size giving
+//     a small number of values per cell;
+//   - otherwise a k-d tree is used.
+//
+// The coordinates of values in p are obtained by comparison with kdtree.Point values.
+// The Config also describes the region of p for use with a QuadTree.
+func Choose(p kdtree.Interface) Config {
+	n := p.Len()
+	if n == 0 {
+		return Config{Kind: BruteForce}
+	}
+	dims := p.Index(0).Dims()
+	zero := make(kdtree.Point, dims)
+	coords := func(i int, dst []float64) {
+		c := p.Index(i)
+		for d := range dst {
+			dst[d] = c.Compare(zero, kdtree.Dim(d))
+		}
+	}
+
+	min := make(kdtree.Point, dims)
+	max := make(kdtree.Point, dims)
+	x := make([]float64, dims)
+	for i := 0; i < n; i++ {
+		coords(i, x)
+		for d, v := range x {
+			if i == 0 || v < min[d] {
+				min[d] = v
+			}
+			if i == 0 || v > max[d] {
+				max[d] = v
+			}
+		}
+	}
+	cfg := Config{Kind: KDTree, Min: min, Max: max, Dims: dims}
+
+	// Choose a cell size giving the target load for uniform data,
+	// ignoring dimensions without extent.
+	vol, spanned := 1., 0
+	for d := range min {
+		if w := max[d] - min[d]; w > 0 {
+			vol *= w
+			spanned++
+		}
+	}
+	if spanned == 0 {
+		cfg.CellSize = 1
+	} else {
+		cfg.CellSize = math.Pow(vol*cellLoad/float64(n), 1/float64(spanned))
+	}
+
+	switch {
+	case n < bruteMax:
+		cfg.Kind = BruteForce
+	case dims > grid.MaxDims:
+	case duplicates(n, dims, coords) > duplicateRate:
+		cfg.Kind = Grid
+	case occupancy(n, min, max, cfg.CellSize, coords) > uniformOccupancy:
+		cfg.Kind = Grid
+	}
+	return cfg
+}
+
+// duplicates returns the fraction of the n values with coordinates equal to those of an
+// earlier value. dims must not be greater than grid.MaxDims.
+func duplicates(n, dims int, coords func(int, []float64)) float64 {
+	seen := make(map[[grid.MaxDims]float64]struct{}, n)
+	var k [grid.MaxDims]float64
+	for i := 0; i < n; i++ {
+		coords(i, k[:dims])
+		seen[k] = struct{}{}
+	}
+	return float64(n-len(seen)) / float64(n)
+}
+
+// occupancy returns the fraction of grid cells of the given size over the region min to
+// max that hold at least one of the n values. dims must not be greater than grid.MaxDims.
+func occupancy(n int, min, max kdtree.Point, size float64, coords func(int, []float64)) float64 {
+	cells := 1.
+	for d := range min {
+		cells *= math.Floor((max[d]-min[d])/size) + 1
+	}
+	if cells > maxCells {
+		return 0
+	}
+	occupied := make(map[[grid.MaxDims]int]struct{})
+	x := make([]float64, len(min))
+	for i := 0; i < n; i++ {
+		coords(i, x)
+		var k [grid.MaxDims]int
+		for d, v := range x {
+			k[d] = int((v - min[d]) / size)
+		}
+		occupied[k] = struct{}{}
+	}
+	return float64(len(occupied)) / cells
+}
+
+// An AutoOption modifies the index constructed by Auto.
+type AutoOption func(*Config)
+
+// WithKind returns an AutoOption that overrides the kind of index chosen by Auto.
+func WithKind(kind string) AutoOption {
+	return func(c *Config) { c.Kind = kind }
+}
+
+// WithBounding returns an AutoOption specifying that a k-d tree constructed by Auto
+// maintains bounding volumes.
+func WithBounding() AutoOption {
+	return func(c *Config) { c.Bounding = true }
+}
+
+// Auto returns an index holding the values in p, of the kind returned by Choose unless
+// overridden by the provided options. p may be reordered by Auto.
+func Auto(p kdtree.Interface, opts ...AutoOption) (kdtree.SpatialIndex, error) {
+	cfg := Choose(p)
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.Kind == KDTree || cfg.Kind == "" {
+		return kdtree.New(p, cfg.Bounding).Index(cfg.Bounding), nil
+	}
+	if cfg.Kind == QuadTree && cfg.Capacity == 0 {
+		cfg.Capacity = quadtree.DefaultCapacity
+	}
+	idx, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < p.Len(); i++ {
+		err = idx.Insert(p.Index(i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
@@ -0,0 +1,99 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spatial
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+func uniform(rnd *rand.Rand, n, dims int) kdtree.Points {
+	p := make(kdtree.Points, n)
+	for i := range p {
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			p[i][d] = rnd.Float64() * 100
+		}
+	}
+	return p
+}
+
+func clustered(rnd *rand.Rand, n, dims int) kdtree.Points {
+	p := make(kdtree.Points, n)
+	centres := uniform(rnd, 5, dims)
+	for i := range p {
+		c := centres[rnd.Intn(len(centres))]
+		p[i] = make(kdtree.Point, dims)
+		for d := range p[i] {
+			p[i][d] = c[d] + rnd.NormFloat64()*0.5
+		}
+	}
+	return p
+}
+
+func (s *S) TestChoose(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	dups := make(kdtree.Points, 1000)
+	for i := range dups {
+		dups[i] = kdtree.Point{float64(rnd.Intn(20)), float64(rnd.Intn(20))}
+	}
+	for i, t := range []struct {
+		p    kdtree.Points
+		kind string
+	}{
+		{p: nil, kind: BruteForce},
+		{p: uniform(rnd, 50, 2), kind: BruteForce},
+		{p: uniform(rnd, 50, 10), kind: BruteForce},
+		{p: uniform(rnd, 5000, 2), kind: Grid},
+		{p: uniform(rnd, 5000, 3), kind: Grid},
+		{p: uniform(rnd, 5000, 5), kind: KDTree},
+		{p: clustered(rnd, 5000, 2), kind: KDTree},
+		{p: clustered(rnd, 5000, 3), kind: KDTree},
+		{p: dups, kind: Grid},
+	} {
+		cfg := Choose(t.p)
+		c.Check(cfg.Kind, check.Equals, t.kind, check.Commentf("Test %d", i))
+		if cfg.Kind == Grid {
+			c.Check(cfg.CellSize > 0, check.Equals, true, check.Commentf("Test %d", i))
+		}
+	}
+}
+
+func (s *S) TestAuto(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	for i, t := range []struct {
+		p    kdtree.Points
+		opts []AutoOption
+	}{
+		{p: uniform(rnd, 50, 2)},
+		{p: uniform(rnd, 2000, 2)},
+		{p: clustered(rnd, 2000, 2)},
+		{p: uniform(rnd, 2000, 2), opts: []AutoOption{WithKind(QuadTree)}},
+		{p: uniform(rnd, 2000, 2), opts: []AutoOption{WithKind(KDTree), WithBounding()}},
+		{p: uniform(rnd, 2000, 2), opts: []AutoOption{WithKind(BruteForce)}},
+	} {
+		want := append(kdtree.Points(nil), t.p...)
+		idx, err := Auto(t.p, t.opts...)
+		c.Assert(err, check.Equals, nil, check.Commentf("Test %d", i))
+		c.Check(idx.Len(), check.Equals, len(want), check.Commentf("Test %d", i))
+		for j := 0; j < 10; j++ {
+			q := kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100}
+			wantDist := math.Inf(1)
+			for _, p := range want {
+				if d := p.Distance(q); d < wantDist {
+					wantDist = d
+				}
+			}
+			_, d := idx.Nearest(q)
+			c.Check(d, check.Equals, wantDist, check.Commentf("Test %d", i))
+		}
+	}
+
+	_, err := Auto(uniform(rnd, 10, 2), WithKind("btree"))
+	c.Check(err, check.NotNil)
+}
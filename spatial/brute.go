@@ -0,0 +1,126 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spatial
+
+import (
+	"math"
+	"sort"
+
+	"github.com/biogo/store/kdtree"
+)
+
+var _ kdtree.SpatialIndex = (*Brute)(nil)
+
+// A Brute is a SpatialIndex that examines every value for each query. For a few tens of
+// values a Brute is faster than any tree.
+type Brute struct {
+	values []kdtree.Comparable
+}
+
+// Len returns the number of values in the index.
+func (b *Brute) Len() int { return len(b.values) }
+
+// Insert adds c to the index. Insert returns kdtree.ErrPointDims if c does not have the
+// dimensionality of the values already in the index.
+func (b *Brute) Insert(c kdtree.Comparable) error {
+	if len(b.values) != 0 && b.values[0].Dims() != c.Dims() {
+		return kdtree.ErrPointDims
+	}
+	b.values = append(b.values, c)
+	return nil
+}
+
+// Remove removes a single value from the index that has the same coordinates as c,
+// returning whether a value was removed.
+func (b *Brute) Remove(c kdtree.Comparable) bool {
+	for i, v := range b.values {
+		if v.Dims() == c.Dims() && same(v, c) {
+			b.values = append(b.values[:i], b.values[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// same returns whether a and b have the same coordinates.
+func same(a, b kdtree.Comparable) bool {
+	for d := 0; d < a.Dims(); d++ {
+		if a.Compare(b, kdtree.Dim(d)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (b *Brute) Nearest(q kdtree.Comparable) (kdtree.Comparable, float64) {
+	var (
+		best kdtree.Comparable
+		dist = math.Inf(1)
+	)
+	for _, v := range b.values {
+		if d := q.Distance(v); d < dist {
+			best, dist = v, d
+		}
+	}
+	return best, dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (b *Brute) NearestSet(k kdtree.Keeper, q kdtree.Comparable) {
+	for _, v := range b.values {
+		k.Keep(kdtree.ComparableDist{Comparable: v, Dist: q.Distance(v)})
+	}
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+// NearestN returns the n nearest values to the query in min sorted order. If the index holds
+// fewer than n values, all the values in the index are returned.
+func (b *Brute) NearestN(q kdtree.Comparable, n int) []kdtree.ComparableDist {
+	if n > len(b.values) {
+		n = len(b.values)
+	}
+	if n <= 0 {
+		return nil
+	}
+	k := kdtree.NewNKeeper(n)
+	b.NearestSet(k, q)
+	return trim(k.Heap)
+}
+
+// InRange returns the values within distance d of the query, as measured by the values'
+// Distance method, in min sorted order.
+func (b *Brute) InRange(q kdtree.Comparable, d float64) []kdtree.ComparableDist {
+	k := kdtree.NewDistKeeper(d)
+	b.NearestSet(k, q)
+	return trim(k.Heap)
+}
+
+// trim returns h without its trailing sentinel values.
+func trim(h kdtree.Heap) []kdtree.ComparableDist {
+	for len(h) != 0 && h[len(h)-1].Comparable == nil {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// DoBounded performs fn on all values stored in the index that are within the specified
+// bound. The Bounding passed to fn is nil and the depth is zero. If bound is nil, fn is
+// performed on all values. A boolean is returned indicating whether the traversal was
+// interrupted by an Operation returning true.
+func (b *Brute) DoBounded(fn kdtree.Operation, bound *kdtree.Bounding) bool {
+	for _, v := range b.values {
+		if bound.Contains(v) && fn(v, nil, 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spatial constructs kdtree.SpatialIndex values from configuration, allowing
+// applications to select the index type used for their data at run time.
+package spatial
+
+import (
+	"fmt"
+
+	"github.com/biogo/store/grid"
+	"github.com/biogo/store/kdtree"
+	"github.com/biogo/store/quadtree"
+)
+
+// Index kinds.
+const (
+	KDTree     = "kdtree"
+	QuadTree   = "quadtree"
+	Grid       = "grid"
+	BruteForce = "brute"
+)
+
+// Config describes a spatial index. Fields not used by the specified kind are ignored.
+type Config struct {
+	// Kind is the kind of index; one of KDTree,
+	// QuadTree, Grid or BruteForce. If Kind is
+	// empty, KDTree is used.
+	Kind string `json:"kind"`
+
+	// Bounding specifies whether a KDTree index
+	// maintains bounding volumes.
+	Bounding bool `json:"bounding,omitempty"`
+
+	// Min and Max specify the region covered
+	// by a QuadTree index.
+	Min kdtree.Point `json:"min,omitempty"`
+	Max kdtree.Point `json:"max,omitempty"`
+
+	// Capacity is the leaf capacity of a QuadTree
+	// index. If zero, quadtree.DefaultCapacity is
+	// used.
+	Capacity int `json:"capacity,omitempty"`
+
+	// Dims and CellSize specify the dimensionality
+	// and cell size of a Grid index.
+	Dims     int     `json:"dims,omitempty"`
+	CellSize float64 `json:"cellSize,omitempty"`
+}
+
+// New returns an empty index described by cfg.
+func New(cfg Config) (kdtree.SpatialIndex, error) {
+	switch cfg.Kind {
+	case "", KDTree:
+		return (&kdtree.Tree{}).Index(cfg.Bounding), nil
+	case QuadTree:
+		t, err := quadtree.New(cfg.Min, cfg.Max, cfg.Capacity)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case Grid:
+		g, err := grid.New(cfg.Dims, cfg.CellSize)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	case BruteForce:
+		return &Brute{}, nil
+	default:
+		return nil, fmt.Errorf("spatial: unknown index kind %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spatial
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/biogo/store/kdtree"
+	"gopkg.in/check.v1"
+)
+
+// Tests
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+var configs = []string{
+	`{"kind":"kdtree","bounding":true}`,
+	`{"kind":"quadtree","min":[0,0],"max":[100,100],"capacity":4}`,
+	`{"kind":"grid","dims":2,"cellSize":10}`,
+	`{"kind":"brute"}`,
+}
+
+func (s *S) TestNew(c *check.C) {
+	_, err := New(Config{Kind: "btree"})
+	c.Check(err, check.ErrorMatches, `spatial: unknown index kind "btree"`)
+	idx, err := New(Config{Kind: QuadTree})
+	c.Check(err, check.NotNil)
+	c.Check(idx, check.IsNil)
+	idx, err = New(Config{Kind: Grid})
+	c.Check(err, check.NotNil)
+	c.Check(idx, check.IsNil)
+	idx, err = New(Config{})
+	c.Check(err, check.Equals, nil)
+	c.Check(idx.Len(), check.Equals, 0)
+}
+
+func (s *S) TestIndexes(c *check.C) {
+	rnd := rand.New(rand.NewSource(1))
+	pts := make([]kdtree.Point, 500)
+	for i := range pts {
+		pts[i] = kdtree.Point{rnd.Float64() * 100, rnd.Float64() * 100}
+	}
+	queries := []kdtree.Point{{50, 50}, {0, 0}, {99, 1}, {25, 75}}
+	b := &kdtree.Bounding{kdtree.Point{10, 20}, kdtree.Point{40, 60}}
+
+	type result struct {
+		nearest float64
+		nearN   []float64
+		inRange int
+		bounded int
+	}
+	var want []result
+	for i, js := range configs {
+		var cfg Config
+		c.Assert(json.Unmarshal([]byte(js), &cfg), check.Equals, nil)
+		idx, err := New(cfg)
+		c.Assert(err, check.Equals, nil, check.Commentf("Test %d", i))
+		for _, p := range pts {
+			c.Assert(idx.Insert(p), check.Equals, nil)
+		}
+		c.Check(idx.Insert(kdtree.Point{1, 2, 3}), check.NotNil, check.Commentf("Test %d", i))
+		for _, p := range pts[:100] {
+			c.Check(idx.Remove(p), check.Equals, true)
+		}
+		c.Check(idx.Len(), check.Equals, len(pts)-100)
+
+		var got []result
+		for _, q := range queries {
+			var r result
+			_, r.nearest = idx.Nearest(q)
+			for _, cd := range idx.NearestN(q, 5) {
+				r.nearN = append(r.nearN, cd.Dist)
+			}
+			r.inRange = len(idx.InRange(q, 100))
+			idx.DoBounded(func(kdtree.Comparable, *kdtree.Bounding, int) bool { r.bounded++; return false }, b)
+			got = append(got, r)
+		}
+		if want == nil {
+			want = got
+			continue
+		}
+		c.Check(got, check.DeepEquals, want, check.Commentf("Test %d", i))
+	}
+}
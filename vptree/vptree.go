@@ -0,0 +1,159 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vptree implements vantage point trees for nearest neighbour search in metric
+// spaces.
+//
+// Unlike k-d trees, vantage point trees require only a distance between values, so they
+// may be used to index data such as strings under edit distance where there are no
+// coordinates to compare. The query API mirrors that of the kdtree package, with Keeper
+// types guiding NearestSet searches.
+package vptree
+
+import (
+	"math"
+	"sort"
+)
+
+var inf = math.Inf(1)
+
+// A Comparable is a value that may be stored in a vantage point tree.
+type Comparable interface {
+	// Distance returns the distance between the receiver and the parameter.
+	// The distance must be a metric; it must be non-negative, symmetric,
+	// zero only for identical values and satisfy the triangle inequality.
+	// Note that, unlike kdtree.Comparable, the distance is not squared.
+	Distance(Comparable) float64
+}
+
+// A Node holds a single vantage point of a vantage point tree. Values of the subtree
+// Closer are no further than Radius from the node's point, and values of the subtree
+// Further are no closer than Radius.
+type Node struct {
+	Point           Comparable
+	Radius          float64
+	Closer, Further *Node
+}
+
+// A Tree implements a vantage point tree.
+type Tree struct {
+	Root  *Node
+	Count int
+}
+
+// New returns a vantage point tree constructed from the values in p. The order of the
+// elements of p is altered.
+func New(p []Comparable) *Tree {
+	return &Tree{Root: build(p), Count: len(p)}
+}
+
+func build(p []Comparable) *Node {
+	if len(p) == 0 {
+		return nil
+	}
+	// Values far from the bulk of the data make good vantage points, so take
+	// the value most distant from an arbitrary value.
+	var (
+		v   int
+		max = -1.
+	)
+	for i, e := range p {
+		if d := p[0].Distance(e); d > max {
+			v, max = i, d
+		}
+	}
+	p[0], p[v] = p[v], p[0]
+	n := &Node{Point: p[0]}
+	rest := byDist{p: p[1:], d: make([]float64, len(p)-1)}
+	if len(rest.p) == 0 {
+		return n
+	}
+	for i, e := range rest.p {
+		rest.d[i] = n.Point.Distance(e)
+	}
+	sort.Sort(rest)
+	m := len(rest.p) / 2
+	n.Radius = rest.d[m]
+	n.Closer = build(rest.p[:m])
+	n.Further = build(rest.p[m:])
+	return n
+}
+
+// byDist sorts values by their distance from a vantage point.
+type byDist struct {
+	p []Comparable
+	d []float64
+}
+
+func (b byDist) Len() int           { return len(b.p) }
+func (b byDist) Less(i, j int) bool { return b.d[i] < b.d[j] }
+func (b byDist) Swap(i, j int) {
+	b.p[i], b.p[j] = b.p[j], b.p[i]
+	b.d[i], b.d[j] = b.d[j], b.d[i]
+}
+
+// Len returns the number of values stored in the tree.
+func (t *Tree) Len() int { return t.Count }
+
+// Nearest returns the nearest value to the query and the distance between them.
+func (t *Tree) Nearest(q Comparable) (Comparable, float64) {
+	if t.Root == nil {
+		return nil, inf
+	}
+	k := NewNKeeper(1)
+	t.Root.search(q, k)
+	return k.Heap[0].Comparable, k.Heap[0].Dist
+}
+
+// NearestSet finds the nearest values to the query accepted by the provided Keeper, k.
+// k must be able to return a ComparableDist specifying the maximum acceptable distance
+// when Max() is called, and retains the results of the search in min sorted order after
+// the call to NearestSet returns.
+func (t *Tree) NearestSet(k Keeper, q Comparable) {
+	if t.Root == nil {
+		return
+	}
+	t.Root.search(q, k)
+	if k.Len() == 1 {
+		return
+	}
+	sort.Sort(sort.Reverse(k))
+}
+
+func (n *Node) search(q Comparable, k Keeper) {
+	if n == nil {
+		return
+	}
+	d := q.Distance(n.Point)
+	k.Keep(ComparableDist{Comparable: n.Point, Dist: d})
+	if d < n.Radius {
+		n.Closer.search(q, k)
+		if d+k.Max().Dist >= n.Radius {
+			n.Further.search(q, k)
+		}
+		return
+	}
+	n.Further.search(q, k)
+	if d-k.Max().Dist <= n.Radius {
+		n.Closer.search(q, k)
+	}
+}
+
+// An Operation is a function that operates on a Comparable. The tree depth of the value
+// is also provided. If done is returned true, the Operation is indicating that no further
+// work needs to be done and so the Do function should traverse no further.
+type Operation func(c Comparable, depth int) (done bool)
+
+// Do performs fn on all values stored in the tree in pre-order. A boolean is returned
+// indicating whether the Do traversal was interrupted by an Operation returning true.
+func (t *Tree) Do(fn Operation) bool {
+	return t.Root.do(fn, 0)
+}
+
+func (n *Node) do(fn Operation, depth int) bool {
+	if n == nil {
+		return false
+	}
+	return fn(n.Point, depth) || n.Closer.do(fn, depth+1) || n.Further.do(fn, depth+1)
+}
@@ -0,0 +1,158 @@
+// Copyright ©2012 The bíogo Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vptree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+// word is a string with Levenshtein edit distance.
+type word string
+
+func (w word) Distance(c Comparable) float64 {
+	a, b := string(w), string(c.(word))
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return float64(prev[len(b)])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// point is a Euclidean point.
+type point []float64
+
+func (p point) Distance(c Comparable) float64 {
+	q := c.(point)
+	var sum float64
+	for i := range p {
+		d := p[i] - q[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+func randPoints(n, dims int) []Comparable {
+	p := make([]Comparable, n)
+	for i := range p {
+		v := make(point, dims)
+		for j := range v {
+			v[j] = rand.Float64()
+		}
+		p[i] = v
+	}
+	return p
+}
+
+// isVPTree returns whether the vantage point invariant holds throughout the subtree.
+func (n *Node) isVPTree() bool {
+	if n == nil {
+		return true
+	}
+	ok := !n.Closer.do(func(c Comparable, _ int) bool { return n.Point.Distance(c) > n.Radius }, 0) &&
+		!n.Further.do(func(c Comparable, _ int) bool { return n.Point.Distance(c) < n.Radius }, 0)
+	return ok && n.Closer.isVPTree() && n.Further.isVPTree()
+}
+
+func (s *S) TestWords(c *check.C) {
+	words := []Comparable{
+		word("kitten"), word("sitting"), word("mitten"), word("bitten"), word("smitten"),
+		word("kitchen"), word("knitting"), word("fitting"), word("written"), word("sit"),
+	}
+	t := New(append([]Comparable(nil), words...))
+	c.Check(t.Len(), check.Equals, len(words))
+	c.Check(t.Root.isVPTree(), check.Equals, true)
+
+	got, d := t.Nearest(word("mittens"))
+	c.Check(got, check.Equals, word("mitten"))
+	c.Check(d, check.Equals, 1.)
+
+	k := NewDistKeeper(1)
+	t.NearestSet(k, word("kitten"))
+	var near []string
+	for _, cd := range k.Heap {
+		if cd.Comparable != nil {
+			near = append(near, string(cd.Comparable.(word)))
+		}
+	}
+	sort.Strings(near)
+	c.Check(near, check.DeepEquals, []string{"bitten", "kitten", "mitten"})
+
+	var n int
+	t.Do(func(Comparable, int) bool { n++; return false })
+	c.Check(n, check.Equals, len(words))
+}
+
+func (s *S) TestNearest(c *check.C) {
+	_, d := (&Tree{}).Nearest(point{0})
+	c.Check(math.IsInf(d, 1), check.Equals, true)
+
+	p := randPoints(2000, 8)
+	t := New(append([]Comparable(nil), p...))
+	c.Check(t.Root.isVPTree(), check.Equals, true)
+	for _, q := range randPoints(100, 8) {
+		got, d := t.Nearest(q)
+		want, wd := p[0], q.Distance(p[0])
+		for _, e := range p[1:] {
+			if ed := q.Distance(e); ed < wd {
+				want, wd = e, ed
+			}
+		}
+		c.Check(got, check.DeepEquals, want)
+		c.Check(d, check.Equals, wd)
+	}
+}
+
+func (s *S) TestNearestSet(c *check.C) {
+	p := randPoints(1000, 5)
+	t := New(append([]Comparable(nil), p...))
+	for _, q := range randPoints(20, 5) {
+		dists := make([]float64, len(p))
+		for i, e := range p {
+			dists[i] = q.Distance(e)
+		}
+		sort.Float64s(dists)
+
+		k := NewNKeeper(10)
+		t.NearestSet(k, q)
+		c.Assert(k.Len(), check.Equals, 10)
+		for i, cd := range k.Heap {
+			c.Check(cd.Dist, check.Equals, dists[i])
+		}
+
+		dk := NewDistKeeper(dists[25])
+		t.NearestSet(dk, q)
+		c.Check(dk.Len(), check.Equals, 27) // 26 values and the sentinel.
+	}
+}